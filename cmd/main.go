@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -45,7 +50,8 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer client.Disconnect(ctx)
+	// Context koneksi sudah habis saat shutdown, jadi disconnect memakai context baru
+	defer client.Disconnect(context.Background())
 
 	// Echo setup
 	e := echo.New()
@@ -91,7 +97,7 @@ func main() {
 	// Gabungkan kedua logger
 	combinedLogger := zapLogger.With(zap.Namespace("file_logger"))
 	//Initialize routes
-	interfaces.RegisterRoutes(e, client, cfg, fileLogger)
+	scheduler, elector, webhooks := interfaces.RegisterRoutes(e, client, cfg, fileLogger)
 
 	e.HTTPErrorHandler = interfaces.CreateErrorHandler(combinedLogger)
 	// Tambahkan di middleware
@@ -117,5 +123,35 @@ func main() {
 
 	// Start server
 	fmt.Println("Server running on :8080")
-	e.Logger.Fatal(e.Start(":8080"))
+	go func() {
+		if err := e.Start(":8080"); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	// Tunggu sinyal berhenti, lalu selesaikan request dan job yang sedang berjalan
+	quit, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-quit.Done()
+	fileLogger.Info("Aplikasi dihentikan, menunggu request dan job selesai")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		fileLogger.Error("Gagal menghentikan server", zap.Error(err))
+	}
+	if err := scheduler.Stop(shutdownCtx); err != nil {
+		fileLogger.Warn("Job yang masih berjalan dibatalkan", zap.Error(err))
+	}
+	// Delivery yang belum terkirim tetap tersimpan dan dikirim setelah aplikasi jalan lagi
+	if err := webhooks.Stop(shutdownCtx); err != nil {
+		fileLogger.Warn("Pengiriman webhook yang berjalan dibatalkan", zap.Error(err))
+	}
+	// Lease dilepas agar replika lain langsung mengambil alih job terjadwal. Context
+	// shutdown bisa sudah habis karena menunggu job, jadi pakai context baru.
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer releaseCancel()
+	if err := elector.Stop(releaseCtx); err != nil {
+		fileLogger.Error("Gagal melepas lease leader", zap.Error(err))
+	}
 }
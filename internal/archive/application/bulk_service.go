@@ -0,0 +1,329 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxBulkEditItems membatasi jumlah arsip dalam satu job
+	maxBulkEditItems = 10000
+	// bulkPageSize adalah ukuran halaman saat membaca arsip target
+	bulkPageSize = 500
+	// bulkProgressEvery menentukan seberapa sering progres job disimpan
+	bulkProgressEvery = 50
+	// bulkPreviewSample adalah jumlah contoh perubahan pada dry-run
+	bulkPreviewSample = 10
+	// bulkConflictRetries adalah jumlah percobaan ulang bila arsip berubah di tengah proses
+	bulkConflictRetries = 3
+)
+
+type BulkEditService struct {
+	jobs       domain.BulkJobRepository
+	archives   domain.ArchiveRepository
+	categories domain.CategoryRepository
+}
+
+func NewBulkEditService(jobs domain.BulkJobRepository, archives domain.ArchiveRepository, categories domain.CategoryRepository) *BulkEditService {
+	return &BulkEditService{jobs: jobs, archives: archives, categories: categories}
+}
+
+// BulkEditRequest memilih target lewat daftar ID atau filter listing
+type BulkEditRequest struct {
+	IDs       []string
+	Filter    *domain.ArchiveFilter
+	Operation domain.BulkEditOperation
+}
+
+// BulkEditPreview adalah hasil dry-run tanpa mengubah arsip
+type BulkEditPreview struct {
+	Matched     int               `json:"matched"`
+	WouldChange int               `json:"would_change"`
+	Unchanged   int               `json:"unchanged"`
+	WouldFail   int               `json:"would_fail"`
+	Sample      []BulkPreviewItem `json:"sample"`
+}
+
+type BulkPreviewItem struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Changes []domain.Change `json:"changes,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Preview menjalankan operasi tanpa mengubah arsip. Draft user lain dianggap tidak
+// ditemukan, sama seperti saat job dijalankan.
+func (s *BulkEditService) Preview(ctx context.Context, req BulkEditRequest, userID string) (*BulkEditPreview, error) {
+	ids, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &BulkEditPreview{Matched: len(ids), Sample: []BulkPreviewItem{}}
+	addSample := func(item BulkPreviewItem) {
+		if len(preview.Sample) < bulkPreviewSample {
+			preview.Sample = append(preview.Sample, item)
+		}
+	}
+
+	for start := 0; start < len(ids); start += bulkPageSize {
+		chunk := ids[start:min(start+bulkPageSize, len(ids))]
+		archives, err := s.findChunk(ctx, chunk, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range chunk {
+			archive, ok := archives[id]
+			if !ok {
+				preview.WouldFail++
+				addSample(BulkPreviewItem{ID: id, Error: domain.ErrArchiveNotFound.Error()})
+				continue
+			}
+
+			patch, changed, err := req.Operation.Patch(archive)
+			switch {
+			case err != nil:
+				preview.WouldFail++
+				addSample(BulkPreviewItem{ID: id, Name: archive.Name, Error: err.Error()})
+			case !changed:
+				preview.Unchanged++
+			default:
+				preview.WouldChange++
+				addSample(BulkPreviewItem{ID: id, Name: archive.Name, Changes: patchChanges(archive, patch)})
+			}
+		}
+	}
+	return preview, nil
+}
+
+// Start membuat job dan langsung menjalankannya di background
+func (s *BulkEditService) Start(ctx context.Context, req BulkEditRequest, userID string) (*domain.BulkJob, error) {
+	ids, err := s.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &domain.BulkJob{
+		ID:        primitive.NewObjectID(),
+		Type:      domain.BulkJobMetadataEdit,
+		Status:    domain.BulkJobPending,
+		IDs:       ids,
+		Filter:    req.Filter,
+		Operation: req.Operation,
+		Total:     len(ids),
+		Failures:  []domain.BulkFailure{},
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// Job tetap berjalan walaupun request HTTP sudah selesai
+	queued := *job
+	go s.run(context.Background(), &queued)
+	return job, nil
+}
+
+func (s *BulkEditService) Get(ctx context.Context, id, userID string) (*domain.BulkJob, error) {
+	job, err := s.jobs.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.CreatedBy != userID {
+		return nil, domain.ErrBulkJobNotFound
+	}
+	return job, nil
+}
+
+func (s *BulkEditService) List(ctx context.Context, userID string, page, limit int) ([]domain.BulkJob, int64, error) {
+	return s.jobs.FindByUser(ctx, userID, page, limit)
+}
+
+// RecoverInterrupted menandai job yang terputus karena restart sebagai gagal
+func (s *BulkEditService) RecoverInterrupted(ctx context.Context) (int64, error) {
+	return s.jobs.FailInterrupted(ctx)
+}
+
+func (s *BulkEditService) run(ctx context.Context, job *domain.BulkJob) {
+	started := time.Now()
+	job.Status = domain.BulkJobRunning
+	job.StartedAt = &started
+	if err := s.jobs.Update(ctx, job); err != nil {
+		return
+	}
+
+	for i, id := range job.IDs {
+		changed, err := s.apply(ctx, id, job.Operation, job.CreatedBy)
+		job.Processed++
+		switch {
+		case err != nil:
+			job.Failed++
+			job.Failures = append(job.Failures, domain.BulkFailure{ID: id, Error: err.Error()})
+		case changed:
+			job.Succeeded++
+		default:
+			job.Skipped++
+		}
+
+		if (i+1)%bulkProgressEvery == 0 {
+			// Progres yang gagal disimpan akan tertulis pada update berikutnya
+			_ = s.jobs.Update(ctx, job)
+		}
+	}
+
+	finished := time.Now()
+	job.Status = domain.BulkJobCompleted
+	job.FinishedAt = &finished
+	_ = s.jobs.Update(ctx, job)
+}
+
+// apply menerapkan operasi ke satu arsip. Update metadata memakai compare-and-set,
+// jadi bila arsip berubah di antara baca dan tulis, patch dihitung ulang.
+func (s *BulkEditService) apply(ctx context.Context, id string, op domain.BulkEditOperation, userID string) (bool, error) {
+	for attempt := 0; attempt < bulkConflictRetries; attempt++ {
+		archive, err := activeArchive(ctx, s.archives, id, userID)
+		if err != nil {
+			return false, err
+		}
+		if archive.DeletedAt != nil {
+			return false, domain.ErrAlreadyDeleted
+		}
+
+		patch, changed, err := op.Patch(archive)
+		if err != nil || !changed {
+			return false, err
+		}
+
+		_, err = s.archives.UpdateMetadata(ctx, id, patch, userID, domain.Precondition{})
+		if errors.Is(err, domain.ErrVersionConflict) {
+			continue
+		}
+		return err == nil, err
+	}
+	return false, domain.ErrVersionConflict
+}
+
+// prepare memvalidasi operasi lalu mengumpulkan ID target sebelum ada perubahan,
+// supaya arsip yang berubah karena job tidak bergeser keluar dari halaman filter
+func (s *BulkEditService) prepare(ctx context.Context, req BulkEditRequest) ([]string, error) {
+	if err := req.Operation.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Operation.SetCategory != nil {
+		if err := validateCategoryPath(ctx, s.categories, *req.Operation.SetCategory); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(req.IDs) > 0 {
+		ids := uniqueStrings(req.IDs)
+		if len(ids) > maxBulkEditItems {
+			return nil, domain.ErrBulkTooManyItems
+		}
+		return ids, nil
+	}
+
+	if req.Filter == nil {
+		return nil, domain.ErrBulkTargetRequired
+	}
+	return collectArchiveIDs(ctx, s.archives, *req.Filter, maxBulkEditItems)
+}
+
+// collectArchiveIDs mengumpulkan ID semua arsip yang cocok dengan filter. Periode
+// relatif dibekukan saat fungsi dipanggil; lebih dari max arsip dianggap terlalu banyak.
+func collectArchiveIDs(ctx context.Context, archives domain.ArchiveRepository, filter domain.ArchiveFilter, max int) ([]string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter = filter.Resolve(time.Now())
+
+	total, err := archives.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if total > int64(max) {
+		return nil, domain.ErrBulkTooManyItems
+	}
+
+	ids := make([]string, 0, total)
+	for page := 1; ; page++ {
+		found, _, err := archives.FindAll(ctx, filter, []string{"id"}, page, bulkPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, archive := range found {
+			ids = append(ids, archive.ID.Hex())
+		}
+		if len(found) < bulkPageSize {
+			break
+		}
+	}
+	return uniqueStrings(ids), nil
+}
+
+// findChunk mengambil arsip aktif berdasarkan ID; ID yang tidak valid dan draft yang
+// tidak boleh dilihat viewer dianggap tidak ditemukan
+func (s *BulkEditService) findChunk(ctx context.Context, ids []string, viewer string) (map[string]*domain.Archive, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if primitive.IsValidObjectID(id) {
+			valid = append(valid, id)
+		}
+	}
+
+	found := make(map[string]*domain.Archive, len(valid))
+	if len(valid) == 0 {
+		return found, nil
+	}
+
+	archives, err := s.archives.FindByIDs(ctx, valid)
+	if err != nil {
+		if errors.Is(err, domain.ErrArchiveNotFound) {
+			return found, nil
+		}
+		return nil, err
+	}
+	for i := range archives {
+		if archives[i].VisibleTo(viewer) {
+			found[archives[i].ID.Hex()] = &archives[i]
+		}
+	}
+	return found, nil
+}
+
+// patchChanges menampilkan perubahan patch terhadap arsip untuk preview
+func patchChanges(a *domain.Archive, patch domain.ArchivePatch) []domain.Change {
+	changes := []domain.Change{}
+	if patch.Category != nil {
+		changes = append(changes, domain.Change{Field: "category", OldValue: a.Category, NewValue: *patch.Category})
+	}
+	if patch.Type != nil {
+		changes = append(changes, domain.Change{Field: "type", OldValue: a.Type, NewValue: *patch.Type})
+	}
+	if patch.Tags != nil {
+		changes = append(changes, domain.Change{Field: "tags", OldValue: a.Tags, NewValue: *patch.Tags})
+	}
+	if patch.Description != nil {
+		changes = append(changes, domain.Change{Field: "description", OldValue: a.Description, NewValue: *patch.Description})
+	}
+	return changes
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		unique = append(unique, v)
+	}
+	return unique
+}
@@ -0,0 +1,61 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// idArchives mengembalikan arsip aktif yang ID-nya diminta
+type idArchives struct {
+	domain.ArchiveRepository
+	byID map[string]domain.Archive
+}
+
+func (r *idArchives) FindByIDs(_ context.Context, ids []string) ([]domain.Archive, error) {
+	var found []domain.Archive
+	for _, id := range ids {
+		if archive, ok := r.byID[id]; ok {
+			found = append(found, archive)
+		}
+	}
+	return found, nil
+}
+
+func TestBulkPreviewTreatsOtherUsersDraftsAsNotFound(t *testing.T) {
+	draft := domain.Archive{ID: primitive.NewObjectID(), Name: "rahasia.pdf", OwnerID: "alice", State: domain.StateDraft}
+	published := domain.Archive{ID: primitive.NewObjectID(), Name: "laporan.pdf", OwnerID: "alice", State: domain.StateApproved}
+	archives := &idArchives{byID: map[string]domain.Archive{
+		draft.ID.Hex():     draft,
+		published.ID.Hex(): published,
+	}}
+	service := NewBulkEditService(nil, archives, nil)
+	req := BulkEditRequest{
+		IDs:       []string{draft.ID.Hex(), published.ID.Hex()},
+		Operation: domain.BulkEditOperation{AddTags: []string{"q1"}},
+	}
+
+	preview, err := service.Preview(context.Background(), req, "bob")
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.WouldChange != 1 || preview.WouldFail != 1 {
+		t.Fatalf("expected 1 change and 1 failure, got %d and %d", preview.WouldChange, preview.WouldFail)
+	}
+	for _, item := range preview.Sample {
+		if item.ID == draft.ID.Hex() && (item.Name != "" || item.Error != domain.ErrArchiveNotFound.Error()) {
+			t.Fatalf("draft of another user must look missing, got %+v", item)
+		}
+	}
+
+	// Pemilik draft tetap melihat perubahan pada draft-nya
+	preview, err = service.Preview(context.Background(), req, "alice")
+	if err != nil {
+		t.Fatalf("preview owner: %v", err)
+	}
+	if preview.WouldChange != 2 {
+		t.Fatalf("expected 2 changes for the owner, got %d", preview.WouldChange)
+	}
+}
@@ -0,0 +1,327 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CategoryService struct {
+	categories domain.CategoryRepository
+	archives   domain.ArchiveRepository
+}
+
+func NewCategoryService(categories domain.CategoryRepository, archives domain.ArchiveRepository) *CategoryService {
+	return &CategoryService{categories: categories, archives: archives}
+}
+
+type CategoryInput struct {
+	Code string
+	Name string
+	// Description nil berarti tidak diubah; string kosong mengosongkan deskripsi
+	Description *string
+	ParentID    string
+}
+
+// CategoryChangeResult merangkum dampak rename/move terhadap arsip
+type CategoryChangeResult struct {
+	Category         *domain.Category  `json:"category"`
+	AffectedPaths    map[string]string `json:"affected_paths"`
+	ArchivesModified int64             `json:"archives_modified"`
+}
+
+func (s *CategoryService) Create(ctx context.Context, input CategoryInput, userID string) (*domain.Category, error) {
+	code := strings.TrimSpace(input.Code)
+	if err := domain.ValidateCategoryCode(code); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, domain.ErrCategoryNameRequired
+	}
+
+	var parent *domain.Category
+	if input.ParentID != "" {
+		p, err := s.categories.FindByID(ctx, input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if !p.IsActive() {
+			return nil, domain.ErrCategoryDeprecated
+		}
+		parent = p
+	}
+
+	var description string
+	if input.Description != nil {
+		description = *input.Description
+	}
+
+	now := time.Now()
+	category := &domain.Category{
+		ID:          primitive.NewObjectID(),
+		Code:        code,
+		Name:        strings.TrimSpace(input.Name),
+		Description: description,
+		Path:        domain.ChildPath(parent, code),
+		Ancestors:   []primitive.ObjectID{},
+		Status:      domain.CategoryActive,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if parent != nil {
+		category.ParentID = &parent.ID
+		category.Ancestors = append(append(category.Ancestors, parent.Ancestors...), parent.ID)
+	}
+
+	if err := s.categories.Create(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *CategoryService) Get(ctx context.Context, id string) (*domain.Category, error) {
+	return s.categories.FindByID(ctx, id)
+}
+
+// Tree menyusun daftar kategori menjadi pohon berdasarkan parent_id
+func (s *CategoryService) Tree(ctx context.Context, includeDeprecated bool) ([]*domain.Category, error) {
+	categories, err := s.categories.FindAll(ctx, includeDeprecated)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[primitive.ObjectID]*domain.Category, len(categories))
+	for i := range categories {
+		nodes[categories[i].ID] = &categories[i]
+	}
+
+	roots := []*domain.Category{}
+	for i := range categories {
+		node := &categories[i]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots, nil
+}
+
+// Rename mengganti kode, nama, atau deskripsi. Perubahan kode ikut mengubah path
+// kategori beserta turunannya, dan arsip terkait dipindahkan secara massal.
+func (s *CategoryService) Rename(ctx context.Context, id string, input CategoryInput, userID string) (*CategoryChangeResult, error) {
+	result := &CategoryChangeResult{AffectedPaths: map[string]string{}}
+	category, err := s.writable(ctx, id, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != "" {
+		category.Name = strings.TrimSpace(input.Name)
+	}
+	if input.Description != nil {
+		category.Description = *input.Description
+	}
+
+	newCode := category.Code
+	if input.Code != "" && input.Code != category.Code {
+		if err := domain.ValidateCategoryCode(input.Code); err != nil {
+			return nil, err
+		}
+		newCode = input.Code
+	}
+
+	var parent *domain.Category
+	if category.ParentID != nil {
+		if parent, err = s.categories.FindByID(ctx, category.ParentID.Hex()); err != nil {
+			return nil, err
+		}
+	}
+
+	category.Code = newCode
+	return s.relocate(ctx, category, parent, userID, result)
+}
+
+// Move memindahkan kategori ke parent lain (parentID kosong berarti ke root)
+func (s *CategoryService) Move(ctx context.Context, id, parentID, userID string) (*CategoryChangeResult, error) {
+	result := &CategoryChangeResult{AffectedPaths: map[string]string{}}
+	category, err := s.writable(ctx, id, result)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *domain.Category
+	if parentID != "" {
+		if parent, err = s.categories.FindByID(ctx, parentID); err != nil {
+			return nil, err
+		}
+		// Tidak boleh dipindah ke dirinya sendiri atau ke turunannya
+		if parent.ID == category.ID || containsObjectID(parent.Ancestors, category.ID) {
+			return nil, domain.ErrInvalidCategoryMove
+		}
+		if !parent.IsActive() {
+			return nil, domain.ErrCategoryDeprecated
+		}
+	}
+
+	return s.relocate(ctx, category, parent, userID, result)
+}
+
+// writable mengambil kategori yang akan diubah. Pemindahan sebelumnya yang terputus
+// diselesaikan dulu agar perubahan baru dihitung dari path yang sudah konsisten, lalu
+// kategori yang deprecated ditolak.
+func (s *CategoryService) writable(ctx context.Context, id string, result *CategoryChangeResult) (*domain.Category, error) {
+	category, err := s.categories.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category.Relocation != nil {
+		if err := s.finishRelocation(ctx, category, result); err != nil {
+			return nil, err
+		}
+	}
+	if !category.IsActive() {
+		return nil, domain.ErrCategoryDeprecated
+	}
+	return category, nil
+}
+
+// Deprecate menonaktifkan kategori beserta seluruh turunannya. Arsip lama tetap
+// tersimpan, tetapi upload baru ke kategori ini akan ditolak.
+func (s *CategoryService) Deprecate(ctx context.Context, id string) (*domain.Category, error) {
+	category, err := s.categories.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.categories.FindDescendants(ctx, category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	targets := append([]domain.Category{*category}, descendants...)
+	for i := range targets {
+		if !targets[i].IsActive() {
+			continue
+		}
+		targets[i].Status = domain.CategoryDeprecated
+		targets[i].DeprecatedAt = &now
+		targets[i].UpdatedAt = now
+		if err := s.categories.Update(ctx, &targets[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &targets[0], nil
+}
+
+// relocate menyimpan path baru kategori bersama catatan relokasi, lalu memindahkan
+// turunan dan arsipnya. Bila proses terputus, catatan tersebut dipakai untuk
+// melanjutkannya pada perubahan berikutnya terhadap kategori ini.
+func (s *CategoryService) relocate(ctx context.Context, category *domain.Category, parent *domain.Category, userID string, result *CategoryChangeResult) (*CategoryChangeResult, error) {
+	oldPath := category.Path
+	descendants, err := s.categories.FindDescendants(ctx, category.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	category.Path = domain.ChildPath(parent, category.Code)
+	category.Ancestors = []primitive.ObjectID{}
+	category.ParentID = nil
+	if parent != nil {
+		category.ParentID = &parent.ID
+		category.Ancestors = append(append(category.Ancestors, parent.Ancestors...), parent.ID)
+	}
+	category.UpdatedAt = now
+	if oldPath != category.Path {
+		paths := []domain.CategoryPathChange{{From: oldPath, To: category.Path}}
+		for _, d := range descendants {
+			paths = append(paths, domain.CategoryPathChange{
+				From: d.Path,
+				To:   category.Path + strings.TrimPrefix(d.Path, oldPath),
+			})
+		}
+		category.Relocation = &domain.CategoryRelocation{Paths: paths, StartedBy: userID, StartedAt: now}
+	}
+
+	if err := s.categories.Update(ctx, category); err != nil {
+		return nil, err
+	}
+	result.Category = category
+	if category.Relocation == nil {
+		return result, nil
+	}
+
+	if err := s.finishRelocation(ctx, category, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// finishRelocation memindahkan turunan dan arsip sesuai catatan relokasi kategori lalu
+// menghapus catatannya. Setiap langkah aman diulang: turunan yang path-nya sudah
+// berubah dilewati dan arsip yang sudah dipindah tidak lagi cocok dengan path lama.
+func (s *CategoryService) finishRelocation(ctx context.Context, category *domain.Category, result *CategoryChangeResult) error {
+	relocation := category.Relocation
+	root := relocation.Paths[0]
+
+	descendants, err := s.categories.FindDescendants(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+	// Perbarui path dan ancestors turunan yang belum dipindahkan
+	for i := range descendants {
+		d := &descendants[i]
+		if !domain.IsDescendantPath(d.Path, root.From) {
+			continue
+		}
+		d.Path = root.To + strings.TrimPrefix(d.Path, root.From)
+		d.Ancestors = rebaseAncestors(d.Ancestors, category.Ancestors, category.ID)
+		d.UpdatedAt = time.Now()
+		if err := s.categories.Update(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range relocation.Paths {
+		modified, err := s.archives.ReassignCategory(ctx, change.From, change.To, relocation.StartedBy)
+		if err != nil {
+			return err
+		}
+		result.AffectedPaths[change.From] = change.To
+		result.ArchivesModified += modified
+	}
+
+	category.Relocation = nil
+	return s.categories.Update(ctx, category)
+}
+
+// rebaseAncestors mengganti leluhur di atas node yang dipindah (kategori atau folder)
+// dengan leluhur barunya
+func rebaseAncestors(ancestors, movedAncestors []primitive.ObjectID, movedID primitive.ObjectID) []primitive.ObjectID {
+	rebased := append([]primitive.ObjectID{}, movedAncestors...)
+	rebased = append(rebased, movedID)
+	for i, id := range ancestors {
+		if id == movedID {
+			return append(rebased, ancestors[i+1:]...)
+		}
+	}
+	return rebased
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
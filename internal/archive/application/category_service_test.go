@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryCategories menyimpan salinan kategori seperti dokumen di collection
+type memoryCategories struct {
+	domain.CategoryRepository
+	byID map[primitive.ObjectID]domain.Category
+}
+
+func (r *memoryCategories) add(category domain.Category) *domain.Category {
+	r.byID[category.ID] = category
+	return &category
+}
+
+func (r *memoryCategories) FindByID(_ context.Context, id string) (*domain.Category, error) {
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	category, ok := r.byID[objectID]
+	if !ok {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return &category, nil
+}
+
+func (r *memoryCategories) FindDescendants(_ context.Context, id primitive.ObjectID) ([]domain.Category, error) {
+	var descendants []domain.Category
+	for _, category := range r.byID {
+		if containsObjectID(category.Ancestors, id) {
+			descendants = append(descendants, category)
+		}
+	}
+	return descendants, nil
+}
+
+func (r *memoryCategories) Update(_ context.Context, category *domain.Category) error {
+	r.byID[category.ID] = *category
+	return nil
+}
+
+// categoryArchives memetakan path kategori ke jumlah arsip; failures membuat
+// ReassignCategory gagal sebanyak nilainya
+type categoryArchives struct {
+	domain.ArchiveRepository
+	counts   map[string]int64
+	failures int
+}
+
+func (r *categoryArchives) ReassignCategory(_ context.Context, oldPath, newPath, _ string) (int64, error) {
+	if r.failures > 0 {
+		r.failures--
+		return 0, errors.New("connection reset")
+	}
+	moved := r.counts[oldPath]
+	delete(r.counts, oldPath)
+	r.counts[newPath] += moved
+	return moved, nil
+}
+
+func TestRenameResumesInterruptedRelocation(t *testing.T) {
+	categories := &memoryCategories{byID: map[primitive.ObjectID]domain.Category{}}
+	parent := categories.add(domain.Category{
+		ID: primitive.NewObjectID(), Code: "finance", Path: "finance",
+		Ancestors: []primitive.ObjectID{}, Status: domain.CategoryActive,
+	})
+	child := categories.add(domain.Category{
+		ID: primitive.NewObjectID(), Code: "invoices", Path: "finance.invoices",
+		ParentID: &parent.ID, Ancestors: []primitive.ObjectID{parent.ID}, Status: domain.CategoryActive,
+	})
+	archives := &categoryArchives{counts: map[string]int64{"finance": 2, "finance.invoices": 3}, failures: 1}
+	service := NewCategoryService(categories, archives)
+
+	if _, err := service.Rename(context.Background(), parent.ID.Hex(), CategoryInput{Code: "keuangan"}, "admin"); err == nil {
+		t.Fatal("expected the interrupted rename to fail")
+	}
+	if pending := categories.byID[parent.ID].Relocation; pending == nil || len(pending.Paths) != 2 {
+		t.Fatalf("expected a recorded relocation for the category and its child, got %+v", pending)
+	}
+
+	// Perubahan berikutnya menyelesaikan relokasi yang tertunda terlebih dahulu
+	description := ""
+	result, err := service.Rename(context.Background(), parent.ID.Hex(), CategoryInput{Description: &description}, "admin")
+	if err != nil {
+		t.Fatalf("resume rename: %v", err)
+	}
+	if result.ArchivesModified != 5 {
+		t.Fatalf("expected 5 archives moved, got %d", result.ArchivesModified)
+	}
+	if got := categories.byID[child.ID].Path; got != "keuangan.invoices" {
+		t.Fatalf("expected child path keuangan.invoices, got %s", got)
+	}
+	if archives.counts["keuangan"] != 2 || archives.counts["keuangan.invoices"] != 3 {
+		t.Fatalf("expected archives under the new paths, got %v", archives.counts)
+	}
+	if categories.byID[parent.ID].Relocation != nil {
+		t.Fatal("expected the relocation record to be cleared")
+	}
+}
+
+func TestRenameDeprecatedCategoryRejected(t *testing.T) {
+	categories := &memoryCategories{byID: map[primitive.ObjectID]domain.Category{}}
+	category := categories.add(domain.Category{
+		ID: primitive.NewObjectID(), Code: "lama", Path: "lama",
+		Ancestors: []primitive.ObjectID{}, Status: domain.CategoryDeprecated,
+	})
+	service := NewCategoryService(categories, &categoryArchives{counts: map[string]int64{}})
+
+	_, err := service.Rename(context.Background(), category.ID.Hex(), CategoryInput{Name: "Baru"}, "admin")
+	if !errors.Is(err, domain.ErrCategoryDeprecated) {
+		t.Fatalf("expected ErrCategoryDeprecated, got %v", err)
+	}
+}
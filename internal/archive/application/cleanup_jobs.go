@@ -0,0 +1,212 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+// Catatan pada item riwayat job
+const (
+	noteLegalHold = "under legal hold, will be skipped"
+)
+
+// warningLegalHold dipakai bila cleanup melewati arsip dalam legal hold
+const warningLegalHold = "some archives are under legal hold and were skipped"
+
+// ExpiredFilesJob menghapus arsip yang masa temp delete-nya sudah lewat
+func ExpiredFilesJob(service *ArchiveService) Job {
+	return Job{
+		Run: func(ctx context.Context) (*domain.JobResult, error) {
+			removed, err := service.CleanupExpiredFiles(ctx)
+			return removalItems(removed, err)
+		},
+		Preview: func(ctx context.Context) (*domain.JobResult, error) {
+			return previewArchives(service.PreviewExpiredFiles(ctx, domain.MaxJobRunItems))
+		},
+	}
+}
+
+// TempFilesJob menghapus arsip temp lama yang tidak punya jadwal penghapusan
+func TempFilesJob(service *ArchiveService) Job {
+	return Job{
+		Run: func(ctx context.Context) (*domain.JobResult, error) {
+			removed, err := service.CleanupTempFiles(ctx)
+			return removalItems(removed, err)
+		},
+		Preview: func(ctx context.Context) (*domain.JobResult, error) {
+			return previewArchives(service.PreviewTempFiles(ctx, domain.MaxJobRunItems))
+		},
+	}
+}
+
+// TrashPurgeJob menghapus permanen arsip yang melewati masa retensi trash
+func TrashPurgeJob(service *ArchiveService) Job {
+	return Job{
+		Run: func(ctx context.Context) (*domain.JobResult, error) {
+			return removalItems(service.CleanupTrash(ctx))
+		},
+		Preview: func(ctx context.Context) (*domain.JobResult, error) {
+			return previewArchives(service.PreviewTrash(ctx, domain.MaxJobRunItems))
+		},
+	}
+}
+
+// RetentionJob mengevaluasi kebijakan retensi; tidak mendukung dry run karena
+// evaluasi menyimpan jadwal retensi setiap arsip
+func RetentionJob(service *RetentionService) Job {
+	return Job{
+		Run: func(ctx context.Context) (*domain.JobResult, error) {
+			run, err := service.Evaluate(ctx)
+			if run == nil {
+				return nil, err
+			}
+
+			result := &domain.JobResult{Counts: map[string]int64{
+				"evaluated": int64(run.Evaluated),
+				"scheduled": int64(run.Scheduled),
+				"cleared":   int64(run.Cleared),
+			}}
+			for _, disposal := range run.Disposed {
+				if disposal.Error != "" {
+					result.Counts["failed"]++
+				} else {
+					result.Counts["disposed"]++
+				}
+				result.AddItem(domain.JobRunItem{ID: disposal.ArchiveID, Name: disposal.Name, Note: disposal.Error})
+			}
+			return result, err
+		},
+	}
+}
+
+// DispositionJob memusnahkan arsip yang sudah disetujui dan menerbitkan sertifikatnya
+func DispositionJob(service *DispositionService) Job {
+	return Job{
+		Run: func(ctx context.Context) (*domain.JobResult, error) {
+			executed, err := service.Execute(ctx, domain.SystemUserID)
+			if errors.Is(err, domain.ErrSigningKeyMissing) {
+				return &domain.JobResult{Warning: err.Error()}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			result := &domain.JobResult{Counts: map[string]int64{"destroyed": 0, "failed": 0}}
+			if executed == nil {
+				return result, nil
+			}
+			for _, failure := range executed.Failures {
+				result.Counts["failed"]++
+				result.AddItem(domain.JobRunItem{ID: failure.ID, Note: failure.Error})
+			}
+			if cert := executed.Certificate; cert != nil {
+				result.Counts["destroyed"] = int64(len(cert.Items))
+				for _, item := range cert.Items {
+					result.AddItem(domain.JobRunItem{ID: item.ArchiveID, Name: item.Name})
+				}
+				if cert.Error != "" {
+					result.Warning = "certificate " + cert.ID.Hex() + ": " + cert.Error
+				}
+			}
+			return result, nil
+		},
+		Preview: func(ctx context.Context) (*domain.JobResult, error) {
+			return previewArchives(service.Approved(ctx))
+		},
+	}
+}
+
+// TieringJob memindahkan arsip yang jarang diakses ke cold tier
+func TieringJob(service *TieringService) Job {
+	return Job{
+		Run: func(ctx context.Context) (*domain.JobResult, error) {
+			run, err := service.Run(ctx)
+			if run == nil {
+				return nil, err
+			}
+
+			result := &domain.JobResult{Counts: map[string]int64{
+				"evaluated":  int64(run.Evaluated),
+				"moved":      int64(run.Moved),
+				"moved_size": run.MovedSize,
+				"failed":     int64(len(run.Failures)),
+			}}
+			for _, failure := range run.Failures {
+				result.AddItem(domain.JobRunItem{ID: failure.ID, Note: failure.Error})
+			}
+			return result, err
+		},
+		Preview: func(ctx context.Context) (*domain.JobResult, error) {
+			return previewArchives(service.Candidates(ctx))
+		},
+	}
+}
+
+// ReminderJob mengingatkan pemilik arsip yang akan kedaluwarsa atau di-purge
+func ReminderJob(service *ReminderService) Job {
+	return Job{
+		Run: func(ctx context.Context) (*domain.JobResult, error) {
+			run, err := service.Run(ctx)
+			if run == nil {
+				return nil, err
+			}
+
+			result := &domain.JobResult{Counts: map[string]int64{
+				"matched": int64(run.Matched),
+				"sent":    int64(run.Sent),
+				"skipped": int64(run.Skipped),
+				"failed":  int64(len(run.Failures)),
+			}}
+			for _, failure := range run.Failures {
+				result.AddItem(domain.JobRunItem{ID: failure.ID, Note: failure.Error})
+			}
+			return result, err
+		},
+		Preview: func(ctx context.Context) (*domain.JobResult, error) {
+			return previewArchives(service.Candidates(ctx))
+		},
+	}
+}
+
+// removalResult mengubah hasil cleanup menjadi JobResult. Legal hold bukan kegagalan,
+// arsipnya hanya dilewati.
+func removalResult(removed int64, err error) (*domain.JobResult, error) {
+	result := &domain.JobResult{Counts: map[string]int64{"removed": removed}}
+	if errors.Is(err, domain.ErrUnderLegalHold) {
+		result.Warning = warningLegalHold
+		return result, nil
+	}
+	return result, err
+}
+
+// removalItems menyusun JobResult dari ID arsip yang dihapus cleanup
+func removalItems(removed []string, err error) (*domain.JobResult, error) {
+	result, err := removalResult(int64(len(removed)), err)
+	for _, id := range removed {
+		result.AddItem(domain.JobRunItem{ID: id})
+	}
+	return result, err
+}
+
+// previewArchives menyusun hasil dry run dari daftar arsip yang akan diproses
+func previewArchives(archives []domain.Archive, err error) (*domain.JobResult, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.JobResult{Counts: map[string]int64{"matched": int64(len(archives)), "on_hold": 0}}
+	for _, archive := range archives {
+		item := domain.JobRunItem{ID: archive.ID.Hex(), Name: archive.Name}
+		if archive.OnHold() {
+			result.Counts["on_hold"]++
+			item.Note = noteLegalHold
+		}
+		result.AddItem(item)
+	}
+	if len(archives) >= domain.MaxJobRunItems {
+		result.Warning = "listing is limited to the first matching archives"
+	}
+	return result, nil
+}
@@ -0,0 +1,285 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentService struct {
+	comments domain.CommentRepository
+	archives domain.ArchiveRepository
+}
+
+func NewCommentService(comments domain.CommentRepository, archives domain.ArchiveRepository) *CommentService {
+	return &CommentService{comments: comments, archives: archives}
+}
+
+type CommentInput struct {
+	Body     string
+	ParentID string
+	Anchor   *domain.CommentAnchor
+}
+
+// Create menambahkan komentar baru atau balasan pada thread. Anchor tanpa versi
+// ditempel ke versi terbaru arsip.
+func (s *CommentService) Create(ctx context.Context, archiveID string, input CommentInput, userID string) (*domain.Comment, error) {
+	archive, err := activeArchive(ctx, s.archives, archiveID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, domain.ErrCommentBodyRequired
+	}
+
+	now := time.Now()
+	comment := &domain.Comment{
+		ID:        primitive.NewObjectID(),
+		ArchiveID: archive.ID,
+		Body:      body,
+		Mentions:  domain.ParseMentions(body),
+		AuthorID:  userID,
+		CreatedAt: now,
+	}
+
+	if input.ParentID != "" {
+		parent, err := s.comments.FindByID(ctx, input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.ArchiveID != archive.ID {
+			return nil, domain.ErrInvalidReply
+		}
+		if parent.IsDeleted() {
+			return nil, domain.ErrCommentDeleted
+		}
+		comment.ParentID = &parent.ID
+	}
+
+	if input.Anchor != nil {
+		anchor := *input.Anchor
+		if anchor.Version == 0 {
+			anchor.Version = archive.Version
+		}
+		if err := anchor.Validate(); err != nil {
+			return nil, err
+		}
+		if anchor.Version > archive.Version {
+			return nil, domain.ErrInvalidAnchor
+		}
+		comment.Anchor = &anchor
+	}
+
+	comment.Record(domain.CommentCreated, userID, now)
+	if err := s.comments.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// List menyusun komentar menjadi thread. resolved nil menampilkan semua thread.
+func (s *CommentService) List(ctx context.Context, archiveID string, resolved *bool, viewer string) ([]*domain.Comment, error) {
+	archive, err := activeArchive(ctx, s.archives, archiveID, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.comments.FindByArchive(ctx, archive.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[primitive.ObjectID]*domain.Comment, len(comments))
+	for i := range comments {
+		nodes[comments[i].ID] = &comments[i]
+	}
+
+	threads := []*domain.Comment{}
+	for i := range comments {
+		node := &comments[i]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		if resolved != nil && node.Resolved != *resolved {
+			continue
+		}
+		threads = append(threads, node)
+	}
+	return threads, nil
+}
+
+// Edit mengganti isi komentar; isi sebelumnya tetap tersimpan di riwayat
+func (s *CommentService) Edit(ctx context.Context, archiveID, id, body, userID string) (*domain.Comment, error) {
+	comment, err := s.ownComment(ctx, archiveID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, domain.ErrCommentBodyRequired
+	}
+	if body == comment.Body {
+		return comment, nil
+	}
+
+	comment.Body = body
+	comment.Mentions = domain.ParseMentions(body)
+	comment.Record(domain.CommentEdited, userID, time.Now())
+	if err := s.comments.Update(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Delete mengosongkan isi komentar tetapi mempertahankan posisinya di thread
+// dan riwayat perubahannya
+func (s *CommentService) Delete(ctx context.Context, archiveID, id, userID string) error {
+	comment, err := s.ownComment(ctx, archiveID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	comment.Body = ""
+	comment.Mentions = []string{}
+	comment.DeletedAt = &now
+	comment.Record(domain.CommentDeleted, userID, now)
+	return s.comments.Update(ctx, comment)
+}
+
+// SetResolved menandai thread selesai atau membukanya kembali
+func (s *CommentService) SetResolved(ctx context.Context, archiveID, id string, resolved bool, userID string) (*domain.Comment, error) {
+	comment, err := s.find(ctx, archiveID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.IsDeleted() {
+		return nil, domain.ErrCommentDeleted
+	}
+	if comment.ParentID != nil {
+		return nil, domain.ErrResolveReply
+	}
+	if comment.Resolved == resolved {
+		return comment, nil
+	}
+
+	action := domain.CommentResolved
+	if !resolved {
+		action = domain.CommentReopened
+	}
+	comment.Resolved = resolved
+	comment.Record(action, userID, time.Now())
+	if err := s.comments.Update(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Annotations mengekspor anotasi halaman pada satu versi; version 0 berarti versi terbaru
+func (s *CommentService) Annotations(ctx context.Context, archiveID string, version int, viewer string) (*domain.AnnotationExport, error) {
+	archive, err := activeArchive(ctx, s.archives, archiveID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		version = archive.Version
+	}
+	if version < 1 || version > archive.Version {
+		return nil, domain.ErrRevisionNotFound
+	}
+
+	annotations, err := s.comments.FindAnnotations(ctx, archive.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AnnotationExport{
+		ArchiveID:   archive.ID.Hex(),
+		Name:        archive.Name,
+		Version:     version,
+		ExportedAt:  time.Now(),
+		Annotations: annotations,
+	}, nil
+}
+
+// HistoryEntries mengubah riwayat komentar menjadi entri GetHistory
+func (s *CommentService) HistoryEntries(ctx context.Context, archiveID string) ([]domain.HistoryEntry, error) {
+	objID, err := primitive.ObjectIDFromHex(archiveID)
+	if err != nil {
+		return nil, nil
+	}
+
+	comments, err := s.comments.FindByArchive(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.HistoryEntry{}
+	for _, comment := range comments {
+		previous := ""
+		for _, event := range comment.History {
+			var changes []domain.Change
+			switch event.Action {
+			case domain.CommentResolved, domain.CommentReopened:
+				changes = []domain.Change{{
+					Field:    "resolved",
+					OldValue: event.Action == domain.CommentReopened,
+					NewValue: event.Action == domain.CommentResolved,
+				}}
+			default:
+				var old interface{}
+				if event.Action != domain.CommentCreated {
+					old = previous
+				}
+				changes = []domain.Change{{Field: "body", OldValue: old, NewValue: event.Body}}
+				previous = event.Body
+			}
+
+			entries = append(entries, domain.HistoryEntry{
+				Timestamp: event.At,
+				Action:    event.Action,
+				User:      event.UserID,
+				Changes:   changes,
+				CommentID: comment.ID.Hex(),
+			})
+		}
+	}
+	return entries, nil
+}
+
+func (s *CommentService) find(ctx context.Context, archiveID, id, viewer string) (*domain.Comment, error) {
+	if _, err := activeArchive(ctx, s.archives, archiveID, viewer); err != nil {
+		return nil, err
+	}
+	comment, err := s.comments.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.ArchiveID.Hex() != archiveID {
+		return nil, domain.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// ownComment mengambil komentar aktif milik userID
+func (s *CommentService) ownComment(ctx context.Context, archiveID, id, userID string) (*domain.Comment, error) {
+	comment, err := s.find(ctx, archiveID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.IsDeleted() {
+		return nil, domain.ErrCommentDeleted
+	}
+	if comment.AuthorID != userID {
+		return nil, domain.ErrCommentForbidden
+	}
+	return comment, nil
+}
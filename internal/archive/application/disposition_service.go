@@ -0,0 +1,372 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dispositionBatchSize membatasi jumlah arsip yang dimusnahkan per sertifikat;
+// sisanya diproses pada eksekusi berikutnya
+const dispositionBatchSize = 1000
+
+type DispositionService struct {
+	archives     domain.ArchiveRepository
+	certificates domain.CertificateRepository
+	categories   domain.CategoryRepository
+	renderer     domain.CertificateRenderer
+	signingKey   []byte
+}
+
+func NewDispositionService(archives domain.ArchiveRepository, certificates domain.CertificateRepository,
+	categories domain.CategoryRepository, renderer domain.CertificateRenderer, signingKey string) *DispositionService {
+	return &DispositionService{
+		archives:     archives,
+		certificates: certificates,
+		categories:   categories,
+		renderer:     renderer,
+		signingKey:   []byte(signingKey),
+	}
+}
+
+// DispositionDecision adalah keputusan records manager untuk sekumpulan arsip.
+// Until wajib untuk defer, Category wajib untuk transfer.
+type DispositionDecision struct {
+	IDs      []string
+	Decision string
+	Until    *time.Time
+	Category string
+}
+
+// DispositionResult berisi sertifikat eksekusi beserta arsip yang gagal dimusnahkan
+type DispositionResult struct {
+	Certificate *domain.DispositionCertificate `json:"certificate"`
+	Failures    []BulkItemResult               `json:"failures"`
+}
+
+// CertificateVerification adalah hasil pemeriksaan ulang tanda tangan dan dokumen sertifikat
+type CertificateVerification struct {
+	Certificate    *domain.DispositionCertificate `json:"certificate"`
+	SignatureValid bool                           `json:"signature_valid"`
+	DocumentValid  bool                           `json:"document_valid"`
+}
+
+func (s *DispositionService) Queue(ctx context.Context, page, limit int) ([]domain.Archive, int64, error) {
+	return s.archives.FindRetentionReview(ctx, page, limit)
+}
+
+func (s *DispositionService) Decide(ctx context.Context, req DispositionDecision, userID string) ([]BulkItemResult, error) {
+	switch req.Decision {
+	case domain.DispositionApprove:
+	case domain.DispositionDefer:
+		if req.Until == nil || !req.Until.After(time.Now()) {
+			return nil, domain.ErrDispositionDateRequired
+		}
+	case domain.DispositionTransfer:
+		if req.Category == "" {
+			return nil, domain.ErrTransferCategoryRequired
+		}
+		if err := validateCategoryPath(ctx, s.categories, req.Category); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, domain.ErrInvalidReviewDecision
+	}
+
+	ids := uniqueStrings(req.IDs)
+	if len(ids) == 0 {
+		return nil, domain.ErrBulkTargetRequired
+	}
+	if len(ids) > maxBulkEditItems {
+		return nil, domain.ErrBulkTooManyItems
+	}
+
+	return runBulk(ids, func(id string) error {
+		switch req.Decision {
+		case domain.DispositionApprove:
+			return s.archives.ApproveDisposition(ctx, id, userID)
+		case domain.DispositionDefer:
+			return s.deferDisposition(ctx, id, *req.Until, userID)
+		default:
+			return s.transfer(ctx, id, req.Category, userID)
+		}
+	}), nil
+}
+
+// deferDisposition menunda pemusnahan sampai tanggal baru dan mengeluarkan arsip
+// dari antrean. Juga bisa dipakai untuk arsip yang belum masuk antrean.
+func (s *DispositionService) deferDisposition(ctx context.Context, id string, until time.Time, userID string) error {
+	archive, err := s.archives.FindMetadata(ctx, id)
+	if err != nil {
+		return err
+	}
+	if archive.DeletedAt != nil {
+		return domain.ErrAlreadyDeleted
+	}
+	if archive.Retention == nil {
+		return domain.ErrNotUnderReview
+	}
+
+	state := *archive.Retention
+	state.ReviewSince = nil
+	state.ApprovedBy = ""
+	state.ApprovedAt = nil
+	state.RetainUntil = &until
+	state.DueAt = &until
+	return s.archives.SetRetention(ctx, id, &state, userID)
+}
+
+// transfer memindahkan arsip ke kategori lain. Jadwal retensinya dihapus supaya
+// dihitung ulang dengan kebijakan kategori baru pada evaluasi berikutnya.
+func (s *DispositionService) transfer(ctx context.Context, id, category, userID string) error {
+	archive, err := s.archives.FindMetadata(ctx, id)
+	if err != nil {
+		return err
+	}
+	if archive.DeletedAt != nil {
+		return domain.ErrAlreadyDeleted
+	}
+	if !archive.Retention.InQueue() {
+		return domain.ErrNotUnderReview
+	}
+
+	if _, err := s.archives.UpdateMetadata(ctx, id, domain.ArchivePatch{Category: &category}, userID, domain.Precondition{}); err != nil {
+		return err
+	}
+	return s.archives.SetRetention(ctx, id, nil, userID)
+}
+
+// Approved mengembalikan arsip yang akan dimusnahkan pada eksekusi berikutnya
+func (s *DispositionService) Approved(ctx context.Context) ([]domain.Archive, error) {
+	return s.archives.FindDispositionApproved(ctx, dispositionBatchSize)
+}
+
+// Execute memusnahkan arsip yang sudah disetujui lalu membuat sertifikat pemusnahan
+// bertanda tangan. Sertifikat pending beserta bukti isi setiap arsip disimpan lebih
+// dulu, lalu difinalisasi setelah pemusnahan. Hasil nil berarti tidak ada arsip yang
+// menunggu eksekusi.
+func (s *DispositionService) Execute(ctx context.Context, userID string) (*DispositionResult, error) {
+	if len(s.signingKey) > 0 {
+		if err := s.RecoverPending(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	archives, err := s.archives.FindDispositionApproved(ctx, dispositionBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(archives) == 0 {
+		return nil, nil
+	}
+	// Tanpa kunci, sertifikat tidak bisa ditandatangani sehingga tidak ada yang dimusnahkan
+	if len(s.signingKey) == 0 {
+		return nil, domain.ErrSigningKeyMissing
+	}
+
+	result := &DispositionResult{Failures: []BulkItemResult{}}
+	cert := &domain.DispositionCertificate{
+		ID:         primitive.NewObjectID(),
+		Status:     domain.CertificatePending,
+		Items:      []domain.DestroyedItem{},
+		ExecutedBy: userID,
+		ExecutedAt: time.Now(),
+	}
+	for i := range archives {
+		item, err := s.evidence(ctx, &archives[i])
+		if err != nil {
+			result.Failures = append(result.Failures, BulkItemResult{ID: archives[i].ID.Hex(), Status: "failed", Error: err.Error()})
+			continue
+		}
+		cert.Items = append(cert.Items, *item)
+	}
+	if len(cert.Items) == 0 {
+		return result, nil
+	}
+	if err := s.certificates.Create(ctx, cert); err != nil {
+		return nil, err
+	}
+
+	destroyed := make([]domain.DestroyedItem, 0, len(cert.Items))
+	for _, item := range cert.Items {
+		if err := s.archives.Destroy(ctx, item.ArchiveID, item.Version, item.ApprovedAt, userID); err != nil {
+			result.Failures = append(result.Failures, BulkItemResult{ID: item.ArchiveID, Status: "failed", Error: err.Error()})
+			continue
+		}
+		item.DestroyedAt = time.Now()
+		destroyed = append(destroyed, item)
+	}
+	cert.Items = destroyed
+
+	if err := s.finalize(ctx, cert); err != nil {
+		return nil, err
+	}
+	if len(cert.Items) > 0 {
+		result.Certificate = cert
+	}
+	return result, nil
+}
+
+// dispositionPendingTimeout adalah umur sertifikat pending yang dianggap berasal dari
+// eksekusi yang terputus, bukan eksekusi yang masih berjalan
+const dispositionPendingTimeout = time.Hour
+
+// RecoverPending memfinalisasi sertifikat pending dari eksekusi yang terputus. Hanya
+// arsip yang benar-benar sudah musnah yang dicantumkan; waktu musnahnya tidak tercatat
+// sehingga dipakai waktu eksekusi.
+func (s *DispositionService) RecoverPending(ctx context.Context) error {
+	pending, err := s.certificates.FindPending(ctx, time.Now().Add(-dispositionPendingTimeout))
+	if err != nil {
+		return err
+	}
+
+	for i := range pending {
+		cert := &pending[i]
+		destroyed := make([]domain.DestroyedItem, 0, len(cert.Items))
+		for _, item := range cert.Items {
+			exists, err := s.archives.Exists(ctx, item.ArchiveID)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				item.DestroyedAt = cert.ExecutedAt
+				destroyed = append(destroyed, item)
+			}
+		}
+		cert.Items = destroyed
+		if err := s.finalize(ctx, cert); err != nil && !errors.Is(err, domain.ErrCertificateNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalize menandatangani sertifikat, menyimpan dokumennya lalu menandainya final.
+// Batch tanpa arsip yang musnah tetap difinalisasi tanpa dokumen.
+func (s *DispositionService) finalize(ctx context.Context, cert *domain.DispositionCertificate) error {
+	cert.CollectApprovers()
+	signature, err := s.sign(cert)
+	if err != nil {
+		return err
+	}
+	cert.Signature = signature
+
+	if len(cert.Items) == 0 {
+		cert.Error = "no archive was destroyed"
+	} else if err := s.storeDocument(ctx, cert); err != nil {
+		// Kegagalan menyimpan dokumen dicatat pada sertifikat; arsipnya sudah terlanjur musnah
+		cert.Error = err.Error()
+	}
+	return s.certificates.Finalize(ctx, cert)
+}
+
+// evidence menghitung hash isi arsip sebagai bukti sebelum arsip dimusnahkan
+func (s *DispositionService) evidence(ctx context.Context, archive *domain.Archive) (*domain.DestroyedItem, error) {
+	id := archive.ID.Hex()
+	current, content, err := s.archives.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Hash harus milik versi yang disetujui, bukan versi yang diunggah setelahnya
+	if current.Version != archive.Version {
+		return nil, domain.ErrDispositionChanged
+	}
+	sum := sha256.Sum256(content)
+
+	retention := archive.Retention
+	item := &domain.DestroyedItem{
+		ArchiveID:  id,
+		Name:       archive.Name,
+		Version:    archive.Version,
+		Size:       int64(len(content)),
+		SHA256:     hex.EncodeToString(sum[:]),
+		PolicyID:   retention.PolicyID.Hex(),
+		DueAt:      retention.DueAt,
+		ApprovedBy: retention.ApprovedBy,
+	}
+	if retention.ApprovedAt != nil {
+		item.ApprovedAt = *retention.ApprovedAt
+	}
+	return item, nil
+}
+
+// storeDocument menyimpan dokumen sertifikat sebagai arsip milik sistem
+func (s *DispositionService) storeDocument(ctx context.Context, cert *domain.DispositionCertificate) error {
+	document, err := s.renderer.Render(cert)
+	if err != nil {
+		return err
+	}
+
+	saved, err := s.archives.SaveWithVersioning(ctx, domain.Archive{
+		Name:        fmt.Sprintf("certificate-of-destruction-%s.pdf", cert.ID.Hex()),
+		Type:        domain.CertificateType,
+		Tags:        []string{"disposition"},
+		Description: fmt.Sprintf("Certificate of destruction for %d archives", len(cert.Items)),
+		OwnerID:     domain.SystemUserID,
+		// Sertifikat adalah rekaman final, langsung berstatus archived dan tidak bisa diubah
+		State: domain.StateArchived,
+	}, document, domain.Precondition{})
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(document)
+	cert.ArchiveID = saved.ID.Hex()
+	cert.DocumentSHA256 = hex.EncodeToString(sum[:])
+	return nil
+}
+
+func (s *DispositionService) Certificates(ctx context.Context, page, limit int) ([]domain.DispositionCertificate, int64, error) {
+	return s.certificates.FindAll(ctx, page, limit)
+}
+
+func (s *DispositionService) Certificate(ctx context.Context, id string) (*domain.DispositionCertificate, error) {
+	return s.certificates.FindByID(ctx, id)
+}
+
+// Verify memeriksa ulang tanda tangan sertifikat dan memastikan dokumen yang
+// tersimpan sebagai arsip belum berubah
+func (s *DispositionService) Verify(ctx context.Context, id string) (*CertificateVerification, error) {
+	cert, err := s.certificates.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.signingKey) == 0 {
+		return nil, domain.ErrSigningKeyMissing
+	}
+
+	signature, err := s.sign(cert)
+	if err != nil {
+		return nil, err
+	}
+	verification := &CertificateVerification{
+		Certificate:    cert,
+		SignatureValid: hmac.Equal([]byte(signature), []byte(cert.Signature)),
+	}
+
+	if cert.ArchiveID != "" {
+		_, document, err := s.archives.FindByID(ctx, cert.ArchiveID)
+		if err == nil {
+			sum := sha256.Sum256(document)
+			verification.DocumentValid = hex.EncodeToString(sum[:]) == cert.DocumentSHA256
+		}
+	}
+	return verification, nil
+}
+
+func (s *DispositionService) sign(cert *domain.DispositionCertificate) (string, error) {
+	payload, err := cert.Payload()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,321 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryCertificates menyimpan salinan sertifikat seperti dokumen di collection
+type memoryCertificates struct {
+	domain.CertificateRepository
+	byID map[primitive.ObjectID]domain.DispositionCertificate
+}
+
+func newMemoryCertificates() *memoryCertificates {
+	return &memoryCertificates{byID: map[primitive.ObjectID]domain.DispositionCertificate{}}
+}
+
+func (r *memoryCertificates) Create(_ context.Context, cert *domain.DispositionCertificate) error {
+	stored := *cert
+	stored.Items = append([]domain.DestroyedItem{}, cert.Items...)
+	r.byID[cert.ID] = stored
+	return nil
+}
+
+func (r *memoryCertificates) Finalize(_ context.Context, cert *domain.DispositionCertificate) error {
+	if current, ok := r.byID[cert.ID]; !ok || current.Status != domain.CertificatePending {
+		return domain.ErrCertificateNotFound
+	}
+	cert.Status = domain.CertificateFinal
+	return r.Create(context.Background(), cert)
+}
+
+func (r *memoryCertificates) FindPending(_ context.Context, before time.Time) ([]domain.DispositionCertificate, error) {
+	var pending []domain.DispositionCertificate
+	for _, cert := range r.byID {
+		if cert.Status == domain.CertificatePending && cert.ExecutedAt.Before(before) {
+			pending = append(pending, cert)
+		}
+	}
+	return pending, nil
+}
+
+func (r *memoryCertificates) FindByID(_ context.Context, id string) (*domain.DispositionCertificate, error) {
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	cert, ok := r.byID[objectID]
+	if !ok {
+		return nil, domain.ErrCertificateNotFound
+	}
+	return &cert, nil
+}
+
+// storedArchive adalah metadata arsip beserta isi versi terbarunya
+type storedArchive struct {
+	archive domain.Archive
+	content []byte
+}
+
+// dispositionArchives menyimpan arsip yang disetujui untuk dimusnahkan. Destroy
+// mencatat apakah sertifikat pending sudah ada sebelum arsip dimusnahkan.
+type dispositionArchives struct {
+	domain.ArchiveRepository
+	byID map[string]storedArchive
+	// approved adalah metadata arsip saat pemusnahannya disetujui
+	approved     []domain.Archive
+	certificates *memoryCertificates
+	// pendingAtDestroy bernilai false bila ada arsip dimusnahkan tanpa sertifikat pending
+	pendingAtDestroy bool
+}
+
+func newDispositionArchives(certificates *memoryCertificates) *dispositionArchives {
+	return &dispositionArchives{byID: map[string]storedArchive{}, certificates: certificates, pendingAtDestroy: true}
+}
+
+// approve menyimpan arsip yang disetujui untuk dimusnahkan pada versi tersebut
+func (r *dispositionArchives) approve(name string, version int, content string) domain.Archive {
+	due := time.Now().AddDate(0, -1, 0)
+	approvedAt := time.Now().Add(-time.Hour)
+	archive := domain.Archive{
+		ID:      primitive.NewObjectID(),
+		Name:    name,
+		Version: version,
+		Retention: &domain.RetentionState{
+			PolicyID:    primitive.NewObjectID(),
+			Action:      domain.RetentionReview,
+			DueAt:       &due,
+			ReviewSince: &due,
+			ApprovedBy:  "records",
+			ApprovedAt:  &approvedAt,
+		},
+	}
+	r.byID[archive.ID.Hex()] = storedArchive{archive: archive, content: []byte(content)}
+	r.approved = append(r.approved, archive)
+	return archive
+}
+
+func (r *dispositionArchives) FindDispositionApproved(_ context.Context, limit int) ([]domain.Archive, error) {
+	var archives []domain.Archive
+	for _, archive := range r.approved {
+		if _, ok := r.byID[archive.ID.Hex()]; ok && len(archives) < limit {
+			archives = append(archives, archive)
+		}
+	}
+	return archives, nil
+}
+
+func (r *dispositionArchives) FindByID(_ context.Context, id string) (*domain.Archive, []byte, error) {
+	stored, ok := r.byID[id]
+	if !ok {
+		return nil, nil, domain.ErrArchiveNotFound
+	}
+	return &stored.archive, stored.content, nil
+}
+
+func (r *dispositionArchives) Exists(_ context.Context, id string) (bool, error) {
+	_, ok := r.byID[id]
+	return ok, nil
+}
+
+func (r *dispositionArchives) Destroy(_ context.Context, id string, version int, _ time.Time, _ string) error {
+	stored, ok := r.byID[id]
+	if !ok || stored.archive.Version != version {
+		return domain.ErrDispositionChanged
+	}
+	if !r.listedAsPending(id) {
+		r.pendingAtDestroy = false
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+func (r *dispositionArchives) listedAsPending(id string) bool {
+	for _, cert := range r.certificates.byID {
+		if cert.Status != domain.CertificatePending {
+			continue
+		}
+		for _, item := range cert.Items {
+			if item.ArchiveID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *dispositionArchives) SaveWithVersioning(_ context.Context, archive domain.Archive, content []byte, _ domain.Precondition) (*domain.Archive, error) {
+	archive.ID = primitive.NewObjectID()
+	archive.Version = 1
+	r.byID[archive.ID.Hex()] = storedArchive{archive: archive, content: content}
+	return &archive, nil
+}
+
+// textRenderer membuat dokumen sertifikat sederhana sebagai pengganti PDF
+type textRenderer struct{}
+
+func (textRenderer) Render(cert *domain.DispositionCertificate) ([]byte, error) {
+	return []byte(fmt.Sprintf("certificate %s: %d archives", cert.ID.Hex(), len(cert.Items))), nil
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDispositionExecuteRecordsPendingEvidenceThenFinalCertificate(t *testing.T) {
+	certificates := newMemoryCertificates()
+	archives := newDispositionArchives(certificates)
+	contract := archives.approve("kontrak.pdf", 2, "isi kontrak")
+	// Versi baru diunggah setelah disetujui; hash versi itu tidak boleh masuk sertifikat
+	changed := archives.approve("neraca.pdf", 1, "neraca lama")
+	archives.byID[changed.ID.Hex()] = storedArchive{
+		archive: domain.Archive{ID: changed.ID, Name: changed.Name, Version: 2, Retention: changed.Retention},
+		content: []byte("neraca baru"),
+	}
+	service := NewDispositionService(archives, certificates, nil, textRenderer{}, "rahasia")
+
+	result, err := service.Execute(context.Background(), "records")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !archives.pendingAtDestroy {
+		t.Fatal("archives must be listed on a pending certificate before they are destroyed")
+	}
+	if len(result.Failures) != 1 || result.Failures[0].ID != changed.ID.Hex() || result.Failures[0].Error != domain.ErrDispositionChanged.Error() {
+		t.Fatalf("expected the changed archive to be rejected, got %+v", result.Failures)
+	}
+	if _, ok := archives.byID[changed.ID.Hex()]; !ok {
+		t.Fatal("an archive changed after approval must not be destroyed")
+	}
+	if _, ok := archives.byID[contract.ID.Hex()]; ok {
+		t.Fatal("expected the approved archive to be destroyed")
+	}
+
+	cert := result.Certificate
+	if cert == nil || cert.Status != domain.CertificateFinal || len(cert.Items) != 1 {
+		t.Fatalf("expected a final certificate with one item, got %+v", cert)
+	}
+	item := cert.Items[0]
+	if item.ArchiveID != contract.ID.Hex() || item.Version != 2 || item.SHA256 != sha256Hex("isi kontrak") || item.DestroyedAt.IsZero() {
+		t.Fatalf("unexpected evidence %+v", item)
+	}
+	if len(cert.Approvers) != 1 || cert.Approvers[0] != "records" {
+		t.Fatalf("expected approver records, got %v", cert.Approvers)
+	}
+	if stored := certificates.byID[cert.ID]; stored.Status != domain.CertificateFinal || stored.Signature == "" {
+		t.Fatalf("expected the stored certificate to be final and signed, got %+v", stored)
+	}
+
+	document, ok := archives.byID[cert.ArchiveID]
+	if !ok || document.archive.Type != domain.CertificateType || document.archive.OwnerID != domain.SystemUserID {
+		t.Fatalf("expected the certificate document to be stored as a system archive, got %+v", document.archive)
+	}
+	if cert.DocumentSHA256 != sha256Hex(string(document.content)) {
+		t.Fatal("expected the certificate to record the hash of its document")
+	}
+
+	verification, err := service.Verify(context.Background(), cert.ID.Hex())
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !verification.SignatureValid || !verification.DocumentValid {
+		t.Fatalf("expected a valid certificate, got %+v", verification)
+	}
+}
+
+func TestDispositionExecuteWithoutSigningKeyKeepsArchives(t *testing.T) {
+	certificates := newMemoryCertificates()
+	archives := newDispositionArchives(certificates)
+	approved := archives.approve("kontrak.pdf", 1, "isi")
+	service := NewDispositionService(archives, certificates, nil, textRenderer{}, "")
+
+	if _, err := service.Execute(context.Background(), "records"); !errors.Is(err, domain.ErrSigningKeyMissing) {
+		t.Fatalf("expected ErrSigningKeyMissing, got %v", err)
+	}
+	if _, ok := archives.byID[approved.ID.Hex()]; !ok || len(certificates.byID) != 0 {
+		t.Fatal("nothing may be destroyed or certified without a signing key")
+	}
+}
+
+func TestDispositionRecoverPendingCertifiesOnlyDestroyedArchives(t *testing.T) {
+	certificates := newMemoryCertificates()
+	archives := newDispositionArchives(certificates)
+	survivor := archives.approve("laporan.pdf", 1, "isi")
+	executedAt := time.Now().Add(-2 * time.Hour)
+	// Eksekusi terputus setelah arsip pertama musnah dan sebelum arsip kedua
+	interrupted := domain.DispositionCertificate{
+		ID:         primitive.NewObjectID(),
+		Status:     domain.CertificatePending,
+		ExecutedBy: "records",
+		ExecutedAt: executedAt,
+		Items: []domain.DestroyedItem{
+			{ArchiveID: primitive.NewObjectID().Hex(), Name: "kontrak.pdf", Version: 1, SHA256: sha256Hex("isi"), ApprovedBy: "records"},
+			{ArchiveID: survivor.ID.Hex(), Name: survivor.Name, Version: 1, SHA256: sha256Hex("isi"), ApprovedBy: "records"},
+		},
+	}
+	// Sertifikat pending yang baru dibuat masih milik eksekusi yang berjalan
+	running := domain.DispositionCertificate{ID: primitive.NewObjectID(), Status: domain.CertificatePending, ExecutedAt: time.Now()}
+	certificates.byID[interrupted.ID] = interrupted
+	certificates.byID[running.ID] = running
+	service := NewDispositionService(archives, certificates, nil, textRenderer{}, "rahasia")
+
+	if err := service.RecoverPending(context.Background()); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	recovered := certificates.byID[interrupted.ID]
+	if recovered.Status != domain.CertificateFinal || len(recovered.Items) != 1 {
+		t.Fatalf("expected a final certificate with only the destroyed archive, got %+v", recovered)
+	}
+	if recovered.Items[0].ArchiveID != interrupted.Items[0].ArchiveID || !recovered.Items[0].DestroyedAt.Equal(executedAt) {
+		t.Fatalf("expected the destroyed archive with the execution time, got %+v", recovered.Items[0])
+	}
+	if certificates.byID[running.ID].Status != domain.CertificatePending {
+		t.Fatal("a recent pending certificate must be left to its running execution")
+	}
+	verification, err := service.Verify(context.Background(), interrupted.ID.Hex())
+	if err != nil || !verification.SignatureValid || !verification.DocumentValid {
+		t.Fatalf("expected the recovered certificate to verify, got %+v (%v)", verification, err)
+	}
+}
+
+func TestDispositionVerifyDetectsTampering(t *testing.T) {
+	certificates := newMemoryCertificates()
+	archives := newDispositionArchives(certificates)
+	archives.approve("kontrak.pdf", 1, "isi")
+	service := NewDispositionService(archives, certificates, nil, textRenderer{}, "rahasia")
+	result, err := service.Execute(context.Background(), "records")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	id := result.Certificate.ID
+
+	// Kunci lain tidak menghasilkan tanda tangan yang sama
+	other := NewDispositionService(archives, certificates, nil, textRenderer{}, "kunci-lain")
+	if verification, err := other.Verify(context.Background(), id.Hex()); err != nil || verification.SignatureValid {
+		t.Fatalf("expected the signature to fail with another key, got %+v (%v)", verification, err)
+	}
+
+	tampered := certificates.byID[id]
+	tampered.Items = append([]domain.DestroyedItem{}, tampered.Items...)
+	tampered.Items[0].SHA256 = sha256Hex("isi lain")
+	certificates.byID[id] = tampered
+	document := archives.byID[tampered.ArchiveID]
+	document.content = []byte("dokumen palsu")
+	archives.byID[tampered.ArchiveID] = document
+
+	verification, err := service.Verify(context.Background(), id.Hex())
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if verification.SignatureValid || verification.DocumentValid {
+		t.Fatalf("expected tampering to be detected, got %+v", verification)
+	}
+}
@@ -0,0 +1,361 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type FolderService struct {
+	folders  domain.FolderRepository
+	archives domain.ArchiveRepository
+}
+
+func NewFolderService(folders domain.FolderRepository, archives domain.ArchiveRepository) *FolderService {
+	return &FolderService{folders: folders, archives: archives}
+}
+
+// FolderContents adalah satu halaman isi folder: subfolder dan arsip dipaginasi terpisah
+type FolderContents struct {
+	Folder       *domain.Folder
+	Breadcrumbs  []domain.Breadcrumb
+	Folders      []domain.Folder
+	FolderTotal  int64
+	Archives     []domain.Archive
+	ArchiveTotal int64
+}
+
+// FolderDeleteResult merangkum dampak delete/restore folder secara cascade. Conflicts
+// berisi arsip yang tidak ikut dipulihkan karena namanya sudah dipakai arsip aktif lain;
+// arsip itu tetap di trash dan bisa dipulihkan sendiri dengan strategi restore.
+type FolderDeleteResult struct {
+	Folder           *domain.Folder `json:"folder"`
+	FoldersAffected  int            `json:"folders_affected"`
+	ArchivesAffected int64          `json:"archives_affected"`
+	Conflicts        []string       `json:"conflicts,omitempty"`
+}
+
+func (s *FolderService) Create(ctx context.Context, name, parentID, userID string) (*domain.Folder, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.ErrFolderNameRequired
+	}
+
+	parent, err := s.activeFolder(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	folder := &domain.Folder{
+		ID:        primitive.NewObjectID(),
+		Name:      name,
+		Ancestors: []primitive.ObjectID{},
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if parent != nil {
+		folder.ParentID = &parent.ID
+		folder.Ancestors = append(append(folder.Ancestors, parent.Ancestors...), parent.ID)
+	}
+
+	if err := s.ensureUniqueName(ctx, folder.ParentID, name, folder.ID); err != nil {
+		return nil, err
+	}
+	if err := s.folders.Create(ctx, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// Get mengembalikan folder beserta breadcrumb dari root
+func (s *FolderService) Get(ctx context.Context, id string) (*domain.Folder, []domain.Breadcrumb, error) {
+	folder, err := s.folders.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	breadcrumbs, err := s.breadcrumbs(ctx, folder)
+	if err != nil {
+		return nil, nil, err
+	}
+	return folder, breadcrumbs, nil
+}
+
+// Contents menampilkan subfolder dan arsip di dalam folder. id kosong atau "root"
+// berarti level teratas.
+func (s *FolderService) Contents(ctx context.Context, id, viewer string, page, limit int) (*FolderContents, error) {
+	contents := &FolderContents{Breadcrumbs: []domain.Breadcrumb{}}
+	archiveFolder := domain.RootFolderID
+
+	var parentID *primitive.ObjectID
+	if id != "" && id != domain.RootFolderID {
+		folder, err := s.activeFolder(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if contents.Breadcrumbs, err = s.breadcrumbs(ctx, folder); err != nil {
+			return nil, err
+		}
+		contents.Folder = folder
+		parentID = &folder.ID
+		archiveFolder = folder.ID.Hex()
+	}
+
+	var err error
+	contents.Folders, contents.FolderTotal, err = s.folders.FindChildren(ctx, parentID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	contents.Archives, contents.ArchiveTotal, err = s.archives.FindAll(ctx, domain.ArchiveFilter{FolderID: archiveFolder, Viewer: viewer}, nil, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+func (s *FolderService) Rename(ctx context.Context, id, name string) (*domain.Folder, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.ErrFolderNameRequired
+	}
+
+	folder, err := s.activeFolder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueName(ctx, folder.ParentID, name, folder.ID); err != nil {
+		return nil, err
+	}
+
+	folder.Name = name
+	folder.UpdatedAt = time.Now()
+	if err := s.folders.Update(ctx, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// Move memindahkan folder ke parent lain (parentID kosong berarti ke root).
+// Arsip tetap menunjuk ke folder yang sama sehingga ikut berpindah. Ancestors turunan
+// diperbarui dalam satu update yang aman diulang, jadi move yang gagal di tengah jalan
+// diselesaikan dengan mengulang request yang sama.
+func (s *FolderService) Move(ctx context.Context, id, parentID string) (*domain.Folder, error) {
+	folder, err := s.activeFolder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := s.activeFolder(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	// Tidak boleh dipindah ke dirinya sendiri atau ke turunannya
+	if parent != nil && (parent.ID == folder.ID || containsObjectID(parent.Ancestors, folder.ID)) {
+		return nil, domain.ErrInvalidFolderMove
+	}
+
+	folder.ParentID = nil
+	folder.Ancestors = []primitive.ObjectID{}
+	if parent != nil {
+		folder.ParentID = &parent.ID
+		folder.Ancestors = append(append(folder.Ancestors, parent.Ancestors...), parent.ID)
+	}
+	if err := s.ensureUniqueName(ctx, folder.ParentID, folder.Name, folder.ID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	folder.UpdatedAt = now
+	if err := s.folders.Update(ctx, folder); err != nil {
+		return nil, err
+	}
+	if err := s.folders.RebaseDescendants(ctx, folder.ID, folder.Ancestors, now); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// Delete melakukan soft delete pada folder, subfolder dan semua arsip di dalamnya
+func (s *FolderService) Delete(ctx context.Context, id, userID string) (*FolderDeleteResult, error) {
+	folder, err := s.activeFolder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.folders.FindDescendants(ctx, folder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subfolder yang sudah dihapus sebelumnya tetap milik cascade-nya sendiri
+	ids := []primitive.ObjectID{folder.ID}
+	for _, d := range descendants {
+		if !d.IsDeleted() {
+			ids = append(ids, d.ID)
+		}
+	}
+
+	folderIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		folderIDs = append(folderIDs, id.Hex())
+	}
+
+	// Folder yang berisi arsip dalam legal hold tidak boleh dihapus
+	held, err := s.archives.CountHeldInFolders(ctx, folderIDs)
+	if err != nil {
+		return nil, err
+	}
+	if held > 0 {
+		return nil, domain.ErrUnderLegalHold
+	}
+	// Begitu pula folder yang berisi arsip yang sedang di-check-out user lain
+	locked, err := s.archives.CountLockedInFolders(ctx, folderIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	if locked > 0 {
+		return nil, domain.ErrArchiveLocked
+	}
+
+	now := time.Now()
+	if err := s.folders.MarkDeleted(ctx, ids, folder.ID, userID, now); err != nil {
+		return nil, err
+	}
+
+	archives, err := s.archives.DeleteInFolders(ctx, folderIDs, folder.ID.Hex(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folder.DeletedAt = &now
+	folder.DeletedBy = userID
+	return &FolderDeleteResult{Folder: folder, FoldersAffected: len(ids), ArchivesAffected: archives}, nil
+}
+
+// Restore memulihkan folder beserta subfolder dan arsip yang terhapus bersamanya
+func (s *FolderService) Restore(ctx context.Context, id, userID string) (*FolderDeleteResult, error) {
+	folder, err := s.folders.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !folder.IsDeleted() {
+		return nil, domain.ErrFolderNotDeleted
+	}
+	// Folder yang ikut terhapus bersama parent dipulihkan lewat parent-nya
+	if folder.DeletedWith != nil && *folder.DeletedWith != folder.ID {
+		return nil, domain.ErrFolderParentDeleted
+	}
+	if folder.ParentID != nil {
+		if _, err := s.activeFolder(ctx, folder.ParentID.Hex()); err != nil {
+			if errors.Is(err, domain.ErrFolderDeleted) {
+				return nil, domain.ErrFolderParentDeleted
+			}
+			return nil, err
+		}
+	}
+	if err := s.ensureUniqueName(ctx, folder.ParentID, folder.Name, folder.ID); err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.folders.FindDescendants(ctx, folder.ID)
+	if err != nil {
+		return nil, err
+	}
+	restored := 1
+	for _, d := range descendants {
+		if d.DeletedWith != nil && *d.DeletedWith == folder.ID {
+			restored++
+		}
+	}
+
+	if err := s.folders.RestoreCascade(ctx, folder.ID); err != nil {
+		return nil, err
+	}
+	archives, conflicts, err := s.archives.RestoreFolderCascade(ctx, folder.ID.Hex(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	folder.DeletedAt = nil
+	folder.DeletedBy = ""
+	folder.DeletedWith = nil
+	return &FolderDeleteResult{
+		Folder:           folder,
+		FoldersAffected:  restored,
+		ArchivesAffected: archives,
+		Conflicts:        conflicts,
+	}, nil
+}
+
+// MoveArchives memindahkan arsip ke folder id; "root" berarti keluar dari semua folder
+func (s *FolderService) MoveArchives(ctx context.Context, id string, archiveIDs []string, userID string) ([]BulkItemResult, error) {
+	folderID := ""
+	if id != domain.RootFolderID {
+		folder, err := s.activeFolder(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		folderID = folder.ID.Hex()
+	}
+
+	return runBulk(archiveIDs, func(archiveID string) error {
+		if _, err := activeArchive(ctx, s.archives, archiveID, userID); err != nil {
+			return err
+		}
+		_, err := s.archives.MoveToFolder(ctx, archiveID, folderID, userID)
+		return err
+	}), nil
+}
+
+// activeFolder mengambil folder yang belum dihapus; id kosong berarti root (nil)
+func (s *FolderService) activeFolder(ctx context.Context, id string) (*domain.Folder, error) {
+	if id == "" || id == domain.RootFolderID {
+		return nil, nil
+	}
+	folder, err := s.folders.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if folder.IsDeleted() {
+		return nil, domain.ErrFolderDeleted
+	}
+	return folder, nil
+}
+
+func (s *FolderService) ensureUniqueName(ctx context.Context, parentID *primitive.ObjectID, name string, self primitive.ObjectID) error {
+	existing, err := s.folders.FindChildByName(ctx, parentID, name)
+	if err != nil {
+		if errors.Is(err, domain.ErrFolderNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != self {
+		return domain.ErrFolderExists
+	}
+	return nil
+}
+
+func (s *FolderService) breadcrumbs(ctx context.Context, folder *domain.Folder) ([]domain.Breadcrumb, error) {
+	ancestors, err := s.folders.FindByIDs(ctx, folder.Ancestors)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[primitive.ObjectID]string, len(ancestors))
+	for _, a := range ancestors {
+		names[a.ID] = a.Name
+	}
+
+	breadcrumbs := make([]domain.Breadcrumb, 0, len(folder.Ancestors)+1)
+	for _, id := range folder.Ancestors {
+		breadcrumbs = append(breadcrumbs, domain.Breadcrumb{ID: id.Hex(), Name: names[id]})
+	}
+	return append(breadcrumbs, domain.Breadcrumb{ID: folder.ID.Hex(), Name: folder.Name}), nil
+}
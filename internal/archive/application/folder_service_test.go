@@ -0,0 +1,176 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryFolders menyimpan salinan folder seperti dokumen di collection
+type memoryFolders struct {
+	domain.FolderRepository
+	byID map[primitive.ObjectID]domain.Folder
+	// failRebase membuat RebaseDescendants berikutnya gagal sekali
+	failRebase bool
+}
+
+func (r *memoryFolders) add(folder domain.Folder) *domain.Folder {
+	if folder.Ancestors == nil {
+		folder.Ancestors = []primitive.ObjectID{}
+	}
+	r.byID[folder.ID] = folder
+	return &folder
+}
+
+func (r *memoryFolders) FindByID(_ context.Context, id string) (*domain.Folder, error) {
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	folder, ok := r.byID[objectID]
+	if !ok {
+		return nil, domain.ErrFolderNotFound
+	}
+	return &folder, nil
+}
+
+func (r *memoryFolders) FindDescendants(_ context.Context, id primitive.ObjectID) ([]domain.Folder, error) {
+	var descendants []domain.Folder
+	for _, folder := range r.byID {
+		if containsObjectID(folder.Ancestors, id) {
+			descendants = append(descendants, folder)
+		}
+	}
+	return descendants, nil
+}
+
+func (r *memoryFolders) FindChildByName(_ context.Context, parentID *primitive.ObjectID, name string) (*domain.Folder, error) {
+	for _, folder := range r.byID {
+		sameParent := (parentID == nil && folder.ParentID == nil) ||
+			(parentID != nil && folder.ParentID != nil && *parentID == *folder.ParentID)
+		if sameParent && folder.Name == name && !folder.IsDeleted() {
+			return &folder, nil
+		}
+	}
+	return nil, domain.ErrFolderNotFound
+}
+
+func (r *memoryFolders) Update(_ context.Context, folder *domain.Folder) error {
+	r.byID[folder.ID] = *folder
+	return nil
+}
+
+func (r *memoryFolders) MarkDeleted(_ context.Context, ids []primitive.ObjectID, cascade primitive.ObjectID, userID string, at time.Time) error {
+	for _, id := range ids {
+		folder := r.byID[id]
+		folder.DeletedAt = &at
+		folder.DeletedBy = userID
+		folder.DeletedWith = &cascade
+		r.byID[id] = folder
+	}
+	return nil
+}
+
+// folderArchives mencatat arsip per folder beserta pemegang lock-nya
+type folderArchives struct {
+	domain.ArchiveRepository
+	lockedBy map[string]string
+	deleted  []string
+}
+
+func (r *folderArchives) CountHeldInFolders(context.Context, []string) (int64, error) {
+	return 0, nil
+}
+
+func (r *folderArchives) CountLockedInFolders(_ context.Context, folderIDs []string, userID string) (int64, error) {
+	var count int64
+	for _, id := range folderIDs {
+		if owner, ok := r.lockedBy[id]; ok && owner != userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *folderArchives) DeleteInFolders(_ context.Context, folderIDs []string, _, _ string) (int64, error) {
+	r.deleted = append(r.deleted, folderIDs...)
+	return int64(len(folderIDs)), nil
+}
+
+func TestDeleteFolderWithArchiveCheckedOutByOtherUserRefused(t *testing.T) {
+	folders := &memoryFolders{byID: map[primitive.ObjectID]domain.Folder{}}
+	parent := folders.add(domain.Folder{ID: primitive.NewObjectID(), Name: "proyek"})
+	child := folders.add(domain.Folder{
+		ID: primitive.NewObjectID(), Name: "kontrak",
+		ParentID: &parent.ID, Ancestors: []primitive.ObjectID{parent.ID},
+	})
+	archives := &folderArchives{lockedBy: map[string]string{child.ID.Hex(): "alice"}}
+	service := NewFolderService(folders, archives)
+
+	_, err := service.Delete(context.Background(), parent.ID.Hex(), "bob")
+	if !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("expected ErrArchiveLocked, got %v", err)
+	}
+	if len(archives.deleted) != 0 || folders.byID[parent.ID].DeletedAt != nil {
+		t.Fatal("nothing may be deleted while an archive is checked out by someone else")
+	}
+
+	// Pemegang lock sendiri boleh menghapus folder
+	result, err := service.Delete(context.Background(), parent.ID.Hex(), "alice")
+	if err != nil {
+		t.Fatalf("delete by lock owner: %v", err)
+	}
+	if result.FoldersAffected != 2 {
+		t.Fatalf("expected 2 folders deleted, got %d", result.FoldersAffected)
+	}
+}
+
+func (r *memoryFolders) RebaseDescendants(_ context.Context, id primitive.ObjectID, ancestors []primitive.ObjectID, at time.Time) error {
+	if r.failRebase {
+		r.failRebase = false
+		return errors.New("connection reset")
+	}
+	for key, folder := range r.byID {
+		if containsObjectID(folder.Ancestors, id) {
+			folder.Ancestors = rebaseAncestors(folder.Ancestors, ancestors, id)
+			folder.UpdatedAt = at
+			r.byID[key] = folder
+		}
+	}
+	return nil
+}
+
+func TestMoveFolderRetryRepairsDescendants(t *testing.T) {
+	folders := &memoryFolders{byID: map[primitive.ObjectID]domain.Folder{}, failRebase: true}
+	target := folders.add(domain.Folder{ID: primitive.NewObjectID(), Name: "arsip"})
+	moved := folders.add(domain.Folder{ID: primitive.NewObjectID(), Name: "proyek"})
+	child := folders.add(domain.Folder{
+		ID: primitive.NewObjectID(), Name: "kontrak",
+		ParentID: &moved.ID, Ancestors: []primitive.ObjectID{moved.ID},
+	})
+	grandchild := folders.add(domain.Folder{
+		ID: primitive.NewObjectID(), Name: "2024",
+		ParentID: &child.ID, Ancestors: []primitive.ObjectID{moved.ID, child.ID},
+	})
+	service := NewFolderService(folders, nil)
+
+	if _, err := service.Move(context.Background(), moved.ID.Hex(), target.ID.Hex()); err == nil {
+		t.Fatal("expected the interrupted move to fail")
+	}
+	// Mengulang request yang sama menyelesaikan pembaruan turunan
+	if _, err := service.Move(context.Background(), moved.ID.Hex(), target.ID.Hex()); err != nil {
+		t.Fatalf("retry move: %v", err)
+	}
+
+	want := []primitive.ObjectID{target.ID, moved.ID, child.ID}
+	got := folders.byID[grandchild.ID].Ancestors
+	if len(got) != len(want) {
+		t.Fatalf("expected ancestors %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected ancestors %v, got %v", want, got)
+		}
+	}
+}
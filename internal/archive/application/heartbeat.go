@@ -0,0 +1,37 @@
+package application
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// jobHeartbeatInterval adalah jarak antar tanda hidup job background yang sedang berjalan
+	jobHeartbeatInterval = 30 * time.Second
+	// jobStaleAfter adalah batas tanpa tanda hidup sebelum job dianggap ditinggal replika yang mati
+	jobStaleAfter = 4 * jobHeartbeatInterval
+)
+
+// heartbeat memanggil beat setiap interval sampai fungsi stop yang dikembalikan dipanggil.
+// Dipakai untuk memperpanjang lease dan menandai job masih hidup selama pekerjaan panjang.
+func heartbeat(ctx context.Context, interval time.Duration, beat func(ctx context.Context)) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				beat(ctx)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,172 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxLegalHoldItems membatasi jumlah arsip yang ditambahkan dalam satu permintaan
+const maxLegalHoldItems = 50000
+
+type LegalHoldService struct {
+	holds    domain.LegalHoldRepository
+	archives domain.ArchiveRepository
+}
+
+func NewLegalHoldService(holds domain.LegalHoldRepository, archives domain.ArchiveRepository) *LegalHoldService {
+	return &LegalHoldService{holds: holds, archives: archives}
+}
+
+// LegalHoldTarget memilih arsip lewat daftar ID atau filter listing
+type LegalHoldTarget struct {
+	IDs    []string
+	Filter *domain.ArchiveFilter
+}
+
+// LegalHoldResult berisi hold beserta jumlah arsip yang baru dibekukan
+type LegalHoldResult struct {
+	Hold    *domain.LegalHold `json:"hold"`
+	Matched int               `json:"matched"`
+	Applied int64             `json:"applied"`
+}
+
+func (s *LegalHoldService) Create(ctx context.Context, name, description string, target LegalHoldTarget, userID string) (*LegalHoldResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.ErrLegalHoldNameRequired
+	}
+
+	ids, err := s.resolve(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &domain.LegalHold{
+		ID:          primitive.NewObjectID(),
+		Name:        name,
+		Description: description,
+		Status:      domain.LegalHoldActive,
+		ArchiveIDs:  []string{},
+		CreatedBy:   userID,
+		CreatedAt:   time.Now(),
+	}
+	// Hold disimpan lebih dulu supaya arsip tidak pernah menunjuk ke hold yang tidak ada
+	if err := s.holds.Create(ctx, hold); err != nil {
+		return nil, err
+	}
+	return s.apply(ctx, hold, ids, target.Filter, userID)
+}
+
+// AddArchives memperluas cakupan hold yang masih aktif
+func (s *LegalHoldService) AddArchives(ctx context.Context, id string, target LegalHoldTarget, userID string) (*LegalHoldResult, error) {
+	hold, err := s.activeHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.resolve(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return s.apply(ctx, hold, ids, target.Filter, userID)
+}
+
+func (s *LegalHoldService) Get(ctx context.Context, id string) (*domain.LegalHold, error) {
+	return s.holds.FindByID(ctx, id)
+}
+
+func (s *LegalHoldService) List(ctx context.Context, status domain.LegalHoldStatus, page, limit int) ([]domain.LegalHold, int64, error) {
+	return s.holds.FindAll(ctx, status, page, limit)
+}
+
+// Archives menampilkan arsip yang sedang dibekukan hold, termasuk yang ada di trash
+func (s *LegalHoldService) Archives(ctx context.Context, id string, page, limit int) ([]domain.Archive, int64, error) {
+	if _, err := s.holds.FindByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	filter := domain.ArchiveFilter{LegalHold: id, Deleted: domain.DeletedIncluded, Viewer: domain.AnyViewer}
+	return s.archives.FindAll(ctx, filter, nil, page, limit)
+}
+
+// Release melepas hold dari semua arsip. Alasan wajib diisi dan tercatat pada hold
+// serta change log setiap arsip.
+func (s *LegalHoldService) Release(ctx context.Context, id, reason, userID string) (*domain.LegalHold, int64, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, 0, domain.ErrReleaseReasonRequired
+	}
+
+	hold, err := s.activeHold(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	released, err := s.archives.ReleaseLegalHold(ctx, hold.ID.Hex(), userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	hold.Status = domain.LegalHoldReleased
+	hold.ReleasedBy = userID
+	hold.ReleasedAt = &now
+	hold.ReleaseReason = reason
+	if err := s.holds.Update(ctx, hold); err != nil {
+		return nil, 0, err
+	}
+	return hold, released, nil
+}
+
+func (s *LegalHoldService) apply(ctx context.Context, hold *domain.LegalHold, ids []string, filter *domain.ArchiveFilter, userID string) (*LegalHoldResult, error) {
+	applied, err := s.archives.ApplyLegalHold(ctx, hold.ID.Hex(), ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.ArchiveIDs = uniqueStrings(append(hold.ArchiveIDs, ids...))
+	if filter != nil {
+		hold.Filters = append(hold.Filters, *filter)
+	}
+	if err := s.holds.Update(ctx, hold); err != nil {
+		return nil, err
+	}
+	return &LegalHoldResult{Hold: hold, Matched: len(ids), Applied: applied}, nil
+}
+
+func (s *LegalHoldService) resolve(ctx context.Context, target LegalHoldTarget) ([]string, error) {
+	if len(target.IDs) > 0 {
+		ids := uniqueStrings(target.IDs)
+		if len(ids) > maxLegalHoldItems {
+			return nil, domain.ErrBulkTooManyItems
+		}
+		for _, id := range ids {
+			if !primitive.IsValidObjectID(id) {
+				return nil, domain.ErrArchiveNotFound
+			}
+		}
+		return ids, nil
+	}
+
+	if target.Filter == nil {
+		return nil, domain.ErrHoldTargetRequired
+	}
+	// Hold juga membekukan draft milik user lain
+	filter := *target.Filter
+	filter.Viewer = domain.AnyViewer
+	return collectArchiveIDs(ctx, s.archives, filter, maxLegalHoldItems)
+}
+
+func (s *LegalHoldService) activeHold(ctx context.Context, id string) (*domain.LegalHold, error) {
+	hold, err := s.holds.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !hold.IsActive() {
+		return nil, domain.ErrLegalHoldReleased
+	}
+	return hold, nil
+}
@@ -0,0 +1,171 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+// LeaderStatus adalah keadaan leader election dari sudut pandang replika ini
+type LeaderStatus struct {
+	Instance string `json:"instance"`
+	Leader   bool   `json:"leader"`
+	// Lease nil berarti belum ada replika yang memegang lease
+	Lease *domain.Lease `json:"lease,omitempty"`
+}
+
+// LeaderElector memperebutkan lease di Mongo dan memperbaruinya secara berkala.
+// Replika yang gagal memperbarui lease sampai TTL habis menganggap dirinya bukan
+// leader lagi, sehingga replika lain bisa mengambil alih tanpa dua leader sekaligus.
+type LeaderElector struct {
+	leases   domain.LeaseRepository
+	name     string
+	holder   string
+	ttl      time.Duration
+	interval time.Duration
+
+	mu       sync.Mutex
+	leader   bool
+	renewed  time.Time
+	onChange []func(leader bool)
+	started  bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewLeaderElector(leases domain.LeaseRepository, name, holder string, ttl, interval time.Duration) (*LeaderElector, error) {
+	if interval <= 0 || interval >= ttl {
+		return nil, domain.ErrInvalidLeaderConfig
+	}
+	return &LeaderElector{
+		leases:   leases,
+		name:     name,
+		holder:   holder,
+		ttl:      ttl,
+		interval: interval,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// OnChange mendaftarkan hook yang dipanggil saat replika ini menjadi atau berhenti menjadi leader
+func (e *LeaderElector) OnChange(hook func(leader bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onChange = append(e.onChange, hook)
+}
+
+// Start mencoba mengambil lease sekali secara langsung, lalu memperbaruinya di background
+func (e *LeaderElector) Start() {
+	e.mu.Lock()
+	if e.started {
+		e.mu.Unlock()
+		return
+	}
+	e.started = true
+	e.mu.Unlock()
+
+	e.tick()
+	e.wg.Add(1)
+	go e.loop()
+}
+
+func (e *LeaderElector) loop() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+			e.tick()
+		}
+	}
+}
+
+func (e *LeaderElector) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+
+	// Waktu sebelum request dipakai agar lease lokal tidak pernah lebih lama dari lease di Mongo
+	attempted := time.Now()
+	_, acquired, err := e.leases.Acquire(ctx, e.name, e.holder, e.ttl)
+
+	e.mu.Lock()
+	was := e.leader
+	switch {
+	case err != nil:
+		// Gangguan sementara ke Mongo tidak langsung melepas leadership, selama TTL belum habis
+		e.leader = e.leader && time.Since(e.renewed) < e.ttl
+	case acquired:
+		e.leader = true
+		e.renewed = attempted
+	default:
+		e.leader = false
+	}
+	changed := was != e.leader
+	leader := e.leader
+	hooks := append([]func(bool){}, e.onChange...)
+	e.mu.Unlock()
+
+	if changed {
+		for _, hook := range hooks {
+			hook(leader)
+		}
+	}
+}
+
+// IsLeader melaporkan apakah replika ini leader. Leadership dianggap habis begitu lease
+// tidak berhasil diperbarui selama TTL, walaupun loop belum sempat berjalan.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader && time.Since(e.renewed) < e.ttl
+}
+
+func (e *LeaderElector) Instance() string {
+	return e.holder
+}
+
+func (e *LeaderElector) Status(ctx context.Context) (*LeaderStatus, error) {
+	status := &LeaderStatus{Instance: e.holder, Leader: e.IsLeader()}
+	lease, err := e.leases.Find(ctx, e.name)
+	if err != nil && !errors.Is(err, domain.ErrLeaseNotFound) {
+		return nil, err
+	}
+	if lease != nil && lease.Active(time.Now()) {
+		status.Lease = lease
+	}
+	return status, nil
+}
+
+// Stop menghentikan pembaruan lease lalu melepasnya, sehingga replika lain langsung
+// bisa mengambil alih. Panggil setelah scheduler berhenti.
+func (e *LeaderElector) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	select {
+	case <-e.done:
+		e.mu.Unlock()
+		return nil
+	default:
+		close(e.done)
+	}
+	e.mu.Unlock()
+	e.wg.Wait()
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	hooks := append([]func(bool){}, e.onChange...)
+	e.mu.Unlock()
+
+	if wasLeader {
+		for _, hook := range hooks {
+			hook(false)
+		}
+	}
+	return e.leases.Release(ctx, e.name, e.holder)
+}
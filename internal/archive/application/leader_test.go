@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+// leaderChanges mencatat urutan panggilan hook OnChange
+type leaderChanges struct {
+	mu      sync.Mutex
+	changes []bool
+}
+
+func (c *leaderChanges) record(leader bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes = append(c.changes, leader)
+}
+
+func (c *leaderChanges) get() []bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]bool{}, c.changes...)
+}
+
+func startElector(t *testing.T, leases domain.LeaseRepository, holder string, ttl, interval time.Duration) (*LeaderElector, *leaderChanges) {
+	t.Helper()
+	elector, err := NewLeaderElector(leases, domain.SchedulerLease, holder, ttl, interval)
+	if err != nil {
+		t.Fatalf("elector: %v", err)
+	}
+	changes := &leaderChanges{}
+	elector.OnChange(changes.record)
+	elector.Start()
+	t.Cleanup(func() { _ = elector.Stop(context.Background()) })
+	return elector, changes
+}
+
+func TestLeaderElectorRejectsRenewIntervalLongerThanTTL(t *testing.T) {
+	if _, err := NewLeaderElector(newMemoryLeases(), domain.SchedulerLease, "replica-a", time.Second, time.Second); !errors.Is(err, domain.ErrInvalidLeaderConfig) {
+		t.Fatalf("expected ErrInvalidLeaderConfig, got %v", err)
+	}
+}
+
+func TestLeaderElectorAcquiresAndRenewsLease(t *testing.T) {
+	leases := newMemoryLeases()
+	leader, changes := startElector(t, leases, "replica-a", 80*time.Millisecond, 20*time.Millisecond)
+	follower, _ := startElector(t, leases, "replica-b", 80*time.Millisecond, 20*time.Millisecond)
+
+	if !leader.IsLeader() || follower.IsLeader() {
+		t.Fatalf("expected only replica-a to lead, got a=%v b=%v", leader.IsLeader(), follower.IsLeader())
+	}
+	first, err := leases.Find(context.Background(), domain.SchedulerLease)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	// Jauh melewati TTL awal; pembaruan berkala menjaga lease tetap milik replica-a
+	time.Sleep(250 * time.Millisecond)
+	if !leader.IsLeader() || follower.IsLeader() {
+		t.Fatalf("expected replica-a to keep leading, got a=%v b=%v", leader.IsLeader(), follower.IsLeader())
+	}
+	renewed, err := leases.Find(context.Background(), domain.SchedulerLease)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if renewed.Holder != "replica-a" || !renewed.ExpiresAt.After(first.ExpiresAt) || !renewed.AcquiredAt.Equal(first.AcquiredAt) {
+		t.Fatalf("expected the same lease to be extended, got %+v then %+v", first, renewed)
+	}
+	if got := changes.get(); len(got) != 1 || !got[0] {
+		t.Fatalf("expected a single change to leader, got %v", got)
+	}
+
+	status, err := follower.Status(context.Background())
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Leader || status.Instance != "replica-b" || status.Lease == nil || status.Lease.Holder != "replica-a" {
+		t.Fatalf("expected the follower to report replica-a's lease, got %+v", status)
+	}
+}
+
+func TestLeaderElectorTakesOverExpiredLease(t *testing.T) {
+	leases := newMemoryLeases()
+	// Replika yang mati meninggalkan lease yang sudah kedaluwarsa
+	leases.hold(domain.SchedulerLease, "replica-dead", -time.Second)
+
+	elector, changes := startElector(t, leases, "replica-a", 80*time.Millisecond, 20*time.Millisecond)
+	if !elector.IsLeader() {
+		t.Fatal("expected the expired lease to be taken over")
+	}
+	lease, err := leases.Find(context.Background(), domain.SchedulerLease)
+	if err != nil || lease.Holder != "replica-a" {
+		t.Fatalf("expected replica-a to hold the lease, got %+v (%v)", lease, err)
+	}
+	if got := changes.get(); len(got) != 1 || !got[0] {
+		t.Fatalf("expected a change to leader, got %v", got)
+	}
+}
+
+func TestLeaderElectorWaitsForActiveLease(t *testing.T) {
+	leases := newMemoryLeases()
+	leases.hold(domain.SchedulerLease, "replica-b", 100*time.Millisecond)
+
+	elector, changes := startElector(t, leases, "replica-a", 80*time.Millisecond, 20*time.Millisecond)
+	if elector.IsLeader() {
+		t.Fatal("a lease held by another replica must not be taken before it expires")
+	}
+
+	// replica-b berhenti memperbarui lease, sehingga replica-a mengambil alih setelah TTL
+	time.Sleep(200 * time.Millisecond)
+	if !elector.IsLeader() {
+		t.Fatal("expected the lease to be taken over after it expired")
+	}
+	if got := changes.get(); len(got) != 1 || !got[0] {
+		t.Fatalf("expected a single change to leader, got %v", got)
+	}
+}
+
+func TestLeaderElectorStepsDownWhenLeaseIsLost(t *testing.T) {
+	leases := newMemoryLeases()
+	elector, changes := startElector(t, leases, "replica-a", 80*time.Millisecond, 20*time.Millisecond)
+	if !elector.IsLeader() {
+		t.Fatal("expected replica-a to lead")
+	}
+
+	// Replika lain memegang lease, misalnya setelah replica-a sempat terputus
+	leases.hold(domain.SchedulerLease, "replica-b", time.Minute)
+	time.Sleep(60 * time.Millisecond)
+	if elector.IsLeader() {
+		t.Fatal("expected replica-a to step down once another replica holds the lease")
+	}
+	if got := changes.get(); len(got) != 2 || !got[0] || got[1] {
+		t.Fatalf("expected changes [true false], got %v", got)
+	}
+}
+
+func TestLeaderElectorKeepsLeadershipUntilTTLDuringOutage(t *testing.T) {
+	leases := newMemoryLeases()
+	elector, changes := startElector(t, leases, "replica-a", 150*time.Millisecond, 20*time.Millisecond)
+
+	// Gangguan singkat ke Mongo tidak langsung melepas leadership
+	leases.fail(errors.New("connection refused"))
+	time.Sleep(50 * time.Millisecond)
+	if !elector.IsLeader() {
+		t.Fatal("expected leadership to survive an outage shorter than the TTL")
+	}
+
+	// Setelah TTL habis, replika lain mungkin sudah mengambil alih
+	time.Sleep(150 * time.Millisecond)
+	if elector.IsLeader() {
+		t.Fatal("expected leadership to end once the lease could not be renewed for the TTL")
+	}
+	if got := changes.get(); len(got) != 2 || !got[0] || got[1] {
+		t.Fatalf("expected changes [true false], got %v", got)
+	}
+}
+
+func TestLeaderElectorStopReleasesLease(t *testing.T) {
+	leases := newMemoryLeases()
+	elector, changes := startElector(t, leases, "replica-a", time.Minute, time.Second)
+
+	if err := elector.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if elector.IsLeader() {
+		t.Fatal("a stopped elector must not lead")
+	}
+	if _, err := leases.Find(context.Background(), domain.SchedulerLease); !errors.Is(err, domain.ErrLeaseNotFound) {
+		t.Fatalf("expected the lease to be released, got %v", err)
+	}
+	if got := changes.get(); len(got) != 2 || !got[0] || got[1] {
+		t.Fatalf("expected changes [true false], got %v", got)
+	}
+
+	// Replika lain langsung bisa mengambil alih tanpa menunggu TTL
+	follower, _ := startElector(t, leases, "replica-b", time.Minute, time.Second)
+	if !follower.IsLeader() {
+		t.Fatal("expected replica-b to take over the released lease")
+	}
+}
@@ -0,0 +1,134 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+type LifecycleService struct {
+	archives  domain.ArchiveRepository
+	lifecycle *domain.Lifecycle
+	notifier  domain.Notifier
+	// onNotifyError dipanggil bila pemberitahuan transisi gagal dikirim
+	onNotifyError func(archiveID string, err error)
+}
+
+func NewLifecycleService(archives domain.ArchiveRepository, lifecycle *domain.Lifecycle, notifier domain.Notifier) *LifecycleService {
+	return &LifecycleService{archives: archives, lifecycle: lifecycle, notifier: notifier}
+}
+
+// OnNotifyError mendaftarkan hook untuk pemberitahuan yang gagal, misalnya untuk logging.
+// Dipasang sebelum server melayani request.
+func (s *LifecycleService) OnNotifyError(hook func(archiveID string, err error)) {
+	s.onNotifyError = hook
+}
+
+// TransitionRequest adalah permintaan perpindahan state. Reviewers wajib diisi
+// saat arsip masuk ke in_review.
+type TransitionRequest struct {
+	Transition string
+	Reviewers  []string
+	Comment    string
+}
+
+func (s *LifecycleService) Transitions() []domain.LifecycleTransition {
+	return s.lifecycle.Transitions
+}
+
+// Available mengembalikan transisi yang bisa dijalankan user pada arsip saat ini
+func (s *LifecycleService) Available(ctx context.Context, id, userID string, roles []string) ([]domain.LifecycleTransition, error) {
+	archive, err := s.visibleArchive(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	available := []domain.LifecycleTransition{}
+	for _, t := range s.lifecycle.Transitions {
+		if t.AppliesTo(archive.CurrentState()) && t.Permits(archive, userID, roles) {
+			available = append(available, t)
+		}
+	}
+	return available, nil
+}
+
+func (s *LifecycleService) Transition(ctx context.Context, id string, req TransitionRequest, userID string, roles []string) (*domain.Archive, error) {
+	transition, err := s.lifecycle.Find(req.Transition)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := s.visibleArchive(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	from := archive.CurrentState()
+	if !transition.AppliesTo(from) {
+		return nil, domain.ErrTransitionNotAllowed
+	}
+	if !transition.Permits(archive, userID, roles) {
+		return nil, domain.ErrTransitionForbidden
+	}
+
+	// Reviewer ditugaskan saat masuk review, tetap tercatat sampai arsip kembali ke draft
+	reviewers := archive.Reviewers
+	switch transition.To {
+	case domain.StateInReview:
+		if reviewers = uniqueStrings(req.Reviewers); len(reviewers) == 0 {
+			return nil, domain.ErrReviewersRequired
+		}
+	case domain.StateDraft:
+		reviewers = nil
+	}
+
+	updated, err := s.archives.SetLifecycleState(ctx, id, from, transition.To, reviewers, transition.Name, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kegagalan notifikasi tidak membatalkan transisi yang sudah tersimpan
+	if notification, ok := transitionNotification(updated, transition, req.Comment, userID); ok {
+		if err := s.notifier.Notify(ctx, notification); err != nil && s.onNotifyError != nil {
+			s.onNotifyError(id, err)
+		}
+	}
+	return updated, nil
+}
+
+// visibleArchive mengambil arsip aktif; draft user lain dianggap tidak ada
+func (s *LifecycleService) visibleArchive(ctx context.Context, id, userID string) (*domain.Archive, error) {
+	archive, err := s.archives.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if archive.DeletedAt != nil {
+		return nil, domain.ErrAlreadyDeleted
+	}
+	if !archive.VisibleTo(userID) {
+		return nil, domain.ErrArchiveNotFound
+	}
+	return archive, nil
+}
+
+// transitionNotification memberi tahu reviewer saat review diminta, dan pemilik
+// arsip saat orang lain memindahkan state arsipnya
+func transitionNotification(a *domain.Archive, t *domain.LifecycleTransition, comment, userID string) (domain.Notification, bool) {
+	notification := domain.Notification{ArchiveID: a.ID.Hex(), Event: "lifecycle_transition"}
+	if t.To == domain.StateInReview {
+		notification.Recipients = a.Reviewers
+		notification.Subject = fmt.Sprintf("Review requested: %s", a.Name)
+		notification.Message = fmt.Sprintf("%s asked you to review %q (version %d).", userID, a.Name, a.Version)
+	} else {
+		if a.OwnerID == userID {
+			return notification, false
+		}
+		notification.Recipients = []string{a.OwnerID}
+		notification.Subject = fmt.Sprintf("%s: %s", t.Name, a.Name)
+		notification.Message = fmt.Sprintf("%s moved %q to %s.", userID, a.Name, t.To)
+	}
+	if comment != "" {
+		notification.Message += "\n\n" + comment
+	}
+	return notification, true
+}
@@ -0,0 +1,161 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RelationService struct {
+	relations domain.RelationRepository
+	archives  domain.ArchiveRepository
+}
+
+func NewRelationService(relations domain.RelationRepository, archives domain.ArchiveRepository) *RelationService {
+	return &RelationService{relations: relations, archives: archives}
+}
+
+// Create membuat link berarah dari sourceID ke targetID. Link supersedes
+// menandai target sebagai usang sehingga bisa disembunyikan dari listing.
+func (s *RelationService) Create(ctx context.Context, sourceID, targetID string, relationType domain.RelationType, userID string) (*domain.Relation, error) {
+	if !relationType.Valid() {
+		return nil, domain.ErrInvalidRelationType
+	}
+	if sourceID == targetID {
+		return nil, domain.ErrSelfRelation
+	}
+
+	source, err := activeArchive(ctx, s.archives, sourceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := activeArchive(ctx, s.archives, targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if relationType == domain.RelationSupersedes {
+		if err := s.checkSupersedeCycle(ctx, source.ID, target.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	relation := &domain.Relation{
+		ID:        primitive.NewObjectID(),
+		SourceID:  source.ID,
+		TargetID:  target.ID,
+		Type:      relationType,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.relations.Create(ctx, relation); err != nil {
+		return nil, err
+	}
+
+	if relationType == domain.RelationSupersedes {
+		if err := s.archives.SetSupersededBy(ctx, targetID, sourceID, userID); err != nil {
+			// Link dibatalkan supaya tidak ada link supersedes tanpa penanda usang
+			_ = s.relations.Delete(ctx, relation.ID.Hex())
+			return nil, err
+		}
+	}
+	return relation, nil
+}
+
+// checkSupersedeCycle menelusuri rantai pengganti source ke atas. Bila target sudah
+// (langsung maupun tidak langsung) menggantikan source, link baru membentuk siklus.
+func (s *RelationService) checkSupersedeCycle(ctx context.Context, source, target primitive.ObjectID) error {
+	visited := map[primitive.ObjectID]bool{source: true}
+	queue := []primitive.ObjectID{source}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		incoming, err := s.relations.FindIncoming(ctx, current.Hex(), domain.RelationSupersedes)
+		if err != nil {
+			return err
+		}
+		for _, relation := range incoming {
+			if relation.SourceID == target {
+				return domain.ErrRelationCycle
+			}
+			if !visited[relation.SourceID] {
+				visited[relation.SourceID] = true
+				queue = append(queue, relation.SourceID)
+			}
+		}
+	}
+	return nil
+}
+
+// List mengembalikan link keluar dan masuk, termasuk link yang sudah rusak
+func (s *RelationService) List(ctx context.Context, archiveID, viewer string) (*domain.ArchiveRelations, error) {
+	if _, err := activeArchive(ctx, s.archives, archiveID, viewer); err != nil {
+		return nil, err
+	}
+	return s.relations.FindByArchive(ctx, archiveID)
+}
+
+func (s *RelationService) Delete(ctx context.Context, archiveID, relationID, userID string) error {
+	if _, err := activeArchive(ctx, s.archives, archiveID, userID); err != nil {
+		return err
+	}
+	relation, err := s.relations.FindByID(ctx, relationID)
+	if err != nil {
+		return err
+	}
+	// Link hanya bisa dihapus lewat salah satu ujungnya
+	if relation.SourceID.Hex() != archiveID && relation.TargetID.Hex() != archiveID {
+		return domain.ErrRelationNotFound
+	}
+
+	if err := s.relations.Delete(ctx, relationID); err != nil {
+		return err
+	}
+	if relation.Type == domain.RelationSupersedes {
+		return s.refreshSuperseded(ctx, relation.TargetID.Hex(), userID)
+	}
+	return nil
+}
+
+// HandleArchiveRemoved dipanggil setelah arsip dihapus permanen. Link yang
+// menyentuh arsip ditandai rusak dan penanda usang pada target dihitung ulang.
+func (s *RelationService) HandleArchiveRemoved(ctx context.Context, archiveID string) error {
+	broken, err := s.relations.MarkBroken(ctx, archiveID)
+	if err != nil {
+		return err
+	}
+
+	for _, relation := range broken {
+		if relation.Type != domain.RelationSupersedes || relation.SourceID.Hex() != archiveID {
+			continue
+		}
+		if err := s.refreshSuperseded(ctx, relation.TargetID.Hex(), domain.SystemUserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshSuperseded mengisi superseded_by dengan pengganti terbaru yang masih ada
+func (s *RelationService) refreshSuperseded(ctx context.Context, targetID, userID string) error {
+	incoming, err := s.relations.FindIncoming(ctx, targetID, domain.RelationSupersedes)
+	if err != nil {
+		return err
+	}
+
+	by := ""
+	if len(incoming) > 0 {
+		by = incoming[0].SourceID.Hex()
+	}
+
+	err = s.archives.SetSupersededBy(ctx, targetID, by, userID)
+	if errors.Is(err, domain.ErrArchiveNotFound) {
+		// Target sudah dihapus permanen, tidak ada yang perlu diperbarui
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,332 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// reminderBatchSize adalah jumlah arsip per halaman saat mencari kandidat reminder
+const reminderBatchSize = 500
+
+// reminderLease adalah lama satu kanal dikunci selama reminder dikirim; harus lebih
+// lama dari timeout Notifier
+const reminderLease = 5 * time.Minute
+
+// ReminderServiceConfig mengatur jendela reminder dan link satu klik
+type ReminderServiceConfig struct {
+	// Window adalah jarak sebelum tanggal penghapusan saat reminder dikirim
+	Window         time.Duration
+	TrashRetention time.Duration
+	// BaseURL adalah alamat publik API untuk link di reminder
+	BaseURL string
+	// SigningKey kosong berarti reminder dikirim tanpa link satu klik
+	SigningKey []byte
+}
+
+type ReminderService struct {
+	archives  domain.ArchiveRepository
+	reminders domain.ReminderRepository
+	service   *ArchiveService
+	channels  []domain.NotificationChannel
+	cfg       ReminderServiceConfig
+}
+
+func NewReminderService(archives domain.ArchiveRepository, reminders domain.ReminderRepository, service *ArchiveService,
+	channels []domain.NotificationChannel, cfg ReminderServiceConfig) *ReminderService {
+	return &ReminderService{archives: archives, reminders: reminders, service: service, channels: channels, cfg: cfg}
+}
+
+// reminderCandidate adalah arsip yang akan dihapus dalam jendela reminder
+type reminderCandidate struct {
+	archive domain.Archive
+	kind    domain.ReminderKind
+	dueAt   time.Time
+}
+
+// Run mengirim reminder ke pemilik arsip yang akan kedaluwarsa atau di-purge. Kanal yang
+// sudah berhasil tidak dikirim ulang; kanal yang gagal dicoba lagi pada run berikutnya.
+func (s *ReminderService) Run(ctx context.Context) (*domain.ReminderRun, error) {
+	now := time.Now()
+	candidates, err := s.candidates(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &domain.ReminderRun{Matched: len(candidates), Failures: []domain.BulkFailure{}}
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			return run, ctx.Err()
+		}
+
+		reminder, err := s.reminders.Claim(ctx, &domain.Reminder{
+			ArchiveID: candidate.archive.ID.Hex(),
+			Name:      candidate.archive.Name,
+			Kind:      candidate.kind,
+			Recipient: candidate.archive.OwnerID,
+			DueAt:     candidate.dueAt,
+			CreatedAt: now,
+		})
+		if err != nil {
+			run.Failures = append(run.Failures, domain.BulkFailure{ID: candidate.archive.ID.Hex(), Error: err.Error()})
+			continue
+		}
+
+		sent, failures := s.deliver(ctx, reminder, &candidate.archive)
+		switch {
+		case sent == 0 && len(failures) == 0:
+			run.Skipped++
+		case len(failures) > 0:
+			run.Failures = append(run.Failures, domain.BulkFailure{
+				ID:    reminder.ArchiveID,
+				Error: strings.Join(failures, "; "),
+			})
+		default:
+			run.Sent++
+		}
+	}
+	return run, nil
+}
+
+// Candidates mengembalikan arsip yang akan menerima reminder pada Run berikutnya
+func (s *ReminderService) Candidates(ctx context.Context) ([]domain.Archive, error) {
+	candidates, err := s.candidates(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	archives := make([]domain.Archive, 0, len(candidates))
+	for _, candidate := range candidates {
+		archives = append(archives, candidate.archive)
+	}
+	return archives, nil
+}
+
+// candidates mencari arsip aktif yang expires_at-nya, dan arsip di trash yang tanggal
+// purge-nya, jatuh dalam jendela reminder. Arsip dalam legal hold tidak akan dihapus,
+// jadi tidak perlu diingatkan.
+func (s *ReminderService) candidates(ctx context.Context, now time.Time) ([]reminderCandidate, error) {
+	until := now.Add(s.cfg.Window)
+
+	expiring, err := s.findAll(ctx, bson.M{
+		"metadata.expires_at": bson.M{"$gte": now, "$lt": until},
+		"metadata.deleted_at": nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Arsip di trash di-purge TrashRetention setelah deleted_at
+	purging, err := s.findAll(ctx, bson.M{
+		"metadata.deleted_at": bson.M{"$gte": now.Add(-s.cfg.TrashRetention), "$lt": until.Add(-s.cfg.TrashRetention)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]reminderCandidate, 0, len(expiring)+len(purging))
+	for _, archive := range expiring {
+		if archive.OnHold() || archive.ExpiresAt == nil {
+			continue
+		}
+		candidates = append(candidates, reminderCandidate{archive: archive, kind: domain.ReminderExpiry, dueAt: *archive.ExpiresAt})
+	}
+	for _, archive := range purging {
+		if archive.OnHold() || archive.DeletedAt == nil {
+			continue
+		}
+		candidates = append(candidates, reminderCandidate{archive: archive, kind: domain.ReminderPurge, dueAt: archive.DeletedAt.Add(s.cfg.TrashRetention)})
+	}
+	return candidates, nil
+}
+
+// findAll membaca semua arsip yang cocok per halaman _id, supaya arsip yang reminder-nya
+// sudah terkirim tidak menghalangi arsip sesudahnya
+func (s *ReminderService) findAll(ctx context.Context, filter bson.M) ([]domain.Archive, error) {
+	archives := []domain.Archive{}
+	for {
+		page, err := s.archives.FindByFilter(ctx, filter, reminderBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, page...)
+		if len(page) < reminderBatchSize {
+			return archives, nil
+		}
+		filter["_id"] = bson.M{"$gt": page[len(page)-1].ID}
+	}
+}
+
+// deliver mengirim reminder ke kanal yang belum berhasil dan mencatat hasilnya. Setiap
+// kanal di-lease dulu, jadi run yang berjalan bersamaan tidak mengirim reminder yang sama.
+func (s *ReminderService) deliver(ctx context.Context, reminder *domain.Reminder, archive *domain.Archive) (int, []string) {
+	notification := s.notification(reminder, archive)
+
+	sent := 0
+	failures := []string{}
+	for _, channel := range s.channels {
+		if !reminder.Delivery(channel.Name).Pending() {
+			continue
+		}
+
+		claimed, err := s.reminders.ClaimDelivery(ctx, reminder.ID, channel.Name, time.Now(), reminderLease)
+		if err != nil {
+			failures = append(failures, channel.Name+": "+err.Error())
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		var sentAt *time.Time
+		errMsg := ""
+		if err := channel.Notifier.Notify(ctx, notification); err != nil {
+			errMsg = err.Error()
+			failures = append(failures, channel.Name+": "+errMsg)
+		} else {
+			now := time.Now()
+			sentAt = &now
+			sent++
+		}
+		if err := s.reminders.FinishDelivery(ctx, reminder.ID, channel.Name, sentAt, errMsg); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	return sent, failures
+}
+
+func (s *ReminderService) notification(reminder *domain.Reminder, archive *domain.Archive) domain.Notification {
+	due := reminder.DueAt.Format(time.RFC1123)
+	notification := domain.Notification{
+		Recipients: []string{reminder.Recipient},
+		ArchiveID:  reminder.ArchiveID,
+		Links:      map[string]string{},
+	}
+
+	var action domain.ReminderAction
+	if reminder.Kind == domain.ReminderPurge {
+		notification.Event = "archive_purge_scheduled"
+		notification.Subject = fmt.Sprintf("%s will be permanently deleted on %s", archive.Name, due)
+		notification.Message = fmt.Sprintf("%q is in the trash and will be permanently deleted on %s.", archive.Name, due)
+		action = domain.ReminderRestore
+	} else {
+		notification.Event = "archive_expiring"
+		notification.Subject = fmt.Sprintf("%s expires on %s", archive.Name, due)
+		notification.Message = fmt.Sprintf("%q expires on %s and will be deleted after that.", archive.Name, due)
+		// Hanya temp delete yang bisa diperpanjang; jadwal retensi mengikuti kebijakan
+		if archive.IsTemp {
+			action = domain.ReminderExtend
+		}
+	}
+
+	if action != "" && len(s.cfg.SigningKey) > 0 {
+		notification.Links[string(action)] = s.link(reminder, action)
+	}
+	return notification
+}
+
+// reminderToken adalah isi link satu klik; link berlaku sampai arsip dihapus
+type reminderToken struct {
+	Reminder string                `json:"r"`
+	Action   domain.ReminderAction `json:"a"`
+	Expires  int64                 `json:"x"`
+}
+
+func (s *ReminderService) link(reminder *domain.Reminder, action domain.ReminderAction) string {
+	payload, _ := json.Marshal(reminderToken{
+		Reminder: reminder.ID.Hex(),
+		Action:   action,
+		Expires:  reminder.DueAt.Unix(),
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return strings.TrimRight(s.cfg.BaseURL, "/") + "/reminders/actions/" + encoded + "." + s.sign(encoded)
+}
+
+func (s *ReminderService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.cfg.SigningKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *ReminderService) parseToken(token string) (*reminderToken, error) {
+	if len(s.cfg.SigningKey) == 0 {
+		return nil, domain.ErrReminderLinksDisabled
+	}
+
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return nil, domain.ErrInvalidReminderToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, domain.ErrInvalidReminderToken
+	}
+	var parsed reminderToken
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, domain.ErrInvalidReminderToken
+	}
+	if time.Now().Unix() >= parsed.Expires {
+		return nil, domain.ErrReminderTokenExpired
+	}
+	return &parsed, nil
+}
+
+// Inspect memeriksa link satu klik tanpa menjalankannya, untuk halaman konfirmasi
+func (s *ReminderService) Inspect(ctx context.Context, token string) (*domain.Reminder, domain.ReminderAction, error) {
+	parsed, err := s.parseToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+	reminder, err := s.reminders.FindByID(ctx, parsed.Reminder)
+	if err != nil {
+		return nil, "", err
+	}
+	if reminder.Action != "" {
+		return reminder, parsed.Action, domain.ErrReminderActionUsed
+	}
+	return reminder, parsed.Action, nil
+}
+
+// Act menjalankan link satu klik atas nama pemilik arsip. Extend memakai masa tenggang
+// default temp delete; restore memakai nama baru bila namanya sudah dipakai arsip lain.
+func (s *ReminderService) Act(ctx context.Context, token string) (*domain.Reminder, *domain.Archive, error) {
+	parsed, err := s.parseToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	reminder, err := s.reminders.FindByID(ctx, parsed.Reminder)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.reminders.ClaimAction(ctx, parsed.Reminder, parsed.Action, time.Now()); err != nil {
+		return reminder, nil, err
+	}
+
+	var archive *domain.Archive
+	switch parsed.Action {
+	case domain.ReminderExtend:
+		archive, err = s.service.ExtendDeletion(ctx, reminder.ArchiveID, 0, reminder.Recipient)
+	case domain.ReminderRestore:
+		var result *domain.RestoreResult
+		if result, err = s.service.RestoreArchive(ctx, reminder.ArchiveID, reminder.Recipient, domain.RestoreRename); err == nil {
+			archive = result.Archive
+		}
+	default:
+		err = domain.ErrInvalidReminderToken
+	}
+	if err != nil {
+		// Link boleh dipakai lagi bila tindakannya gagal; gagal melepas claim tidak menutupi error aslinya
+		_ = s.reminders.ReleaseAction(ctx, parsed.Reminder)
+		return reminder, nil, err
+	}
+	reminder.Action = parsed.Action
+	return reminder, archive, nil
+}
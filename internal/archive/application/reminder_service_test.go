@@ -0,0 +1,232 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reminderArchives hanya menjawab pencarian arsip yang akan kedaluwarsa, urut _id seperti repository asli
+type reminderArchives struct {
+	domain.ArchiveRepository
+	expiring []domain.Archive
+}
+
+func (r *reminderArchives) FindByFilter(_ context.Context, filter bson.M, limit int) ([]domain.Archive, error) {
+	if _, ok := filter["metadata.expires_at"]; !ok {
+		return nil, nil
+	}
+	var after primitive.ObjectID
+	if cond, ok := filter["_id"].(bson.M); ok {
+		after = cond["$gt"].(primitive.ObjectID)
+	}
+
+	page := []domain.Archive{}
+	for _, archive := range r.expiring {
+		if archive.ID.Hex() > after.Hex() && len(page) < limit {
+			page = append(page, archive)
+		}
+	}
+	return page, nil
+}
+
+// memoryReminders meniru operasi atomik ReminderRepository dengan mutex
+type memoryReminders struct {
+	mu        sync.Mutex
+	reminders map[primitive.ObjectID]*domain.Reminder
+}
+
+func newMemoryReminders() *memoryReminders {
+	return &memoryReminders{reminders: map[primitive.ObjectID]*domain.Reminder{}}
+}
+
+func (r *memoryReminders) Claim(_ context.Context, reminder *domain.Reminder) (*domain.Reminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.reminders {
+		if existing.ArchiveID == reminder.ArchiveID && existing.Kind == reminder.Kind && existing.DueAt.Equal(reminder.DueAt) {
+			copied := *existing
+			copied.Deliveries = append([]domain.ReminderDelivery{}, existing.Deliveries...)
+			return &copied, nil
+		}
+	}
+	stored := *reminder
+	stored.ID = primitive.NewObjectID()
+	r.reminders[stored.ID] = &stored
+	copied := stored
+	return &copied, nil
+}
+
+func (r *memoryReminders) FindByID(_ context.Context, id string) (*domain.Reminder, error) {
+	return nil, domain.ErrReminderNotFound
+}
+
+func (r *memoryReminders) ClaimDelivery(_ context.Context, id primitive.ObjectID, channel string, now time.Time, lease time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reminder, ok := r.reminders[id]
+	if !ok {
+		return false, domain.ErrReminderNotFound
+	}
+	delivery := reminder.Delivery(channel)
+	if !delivery.Pending() || (delivery.SendingUntil != nil && delivery.SendingUntil.After(now)) {
+		return false, nil
+	}
+	until := now.Add(lease)
+	delivery.SendingUntil = &until
+	delivery.Attempts++
+	return true, nil
+}
+
+func (r *memoryReminders) FinishDelivery(_ context.Context, id primitive.ObjectID, channel string, sentAt *time.Time, errMsg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reminder, ok := r.reminders[id]
+	if !ok {
+		return domain.ErrReminderNotFound
+	}
+	delivery := reminder.Delivery(channel)
+	delivery.SendingUntil = nil
+	delivery.SentAt = sentAt
+	delivery.Error = errMsg
+	return nil
+}
+
+func (r *memoryReminders) ClaimAction(context.Context, string, domain.ReminderAction, time.Time) error {
+	return nil
+}
+
+func (r *memoryReminders) ReleaseAction(context.Context, string) error {
+	return nil
+}
+
+// countingNotifier menghitung pemberitahuan per arsip
+type countingNotifier struct {
+	mu    sync.Mutex
+	sent  map[string]int
+	delay time.Duration
+	err   error
+}
+
+func (n *countingNotifier) Notify(_ context.Context, notification domain.Notification) error {
+	time.Sleep(n.delay)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.sent == nil {
+		n.sent = map[string]int{}
+	}
+	n.sent[notification.ArchiveID]++
+	return n.err
+}
+
+func (n *countingNotifier) total() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	total := 0
+	for _, count := range n.sent {
+		total += count
+	}
+	return total
+}
+
+func expiringArchives(n int) []domain.Archive {
+	expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	archives := make([]domain.Archive, 0, n)
+	for i := 0; i < n; i++ {
+		archives = append(archives, domain.Archive{
+			ID:        primitive.NewObjectID(),
+			Name:      "report.pdf",
+			OwnerID:   "user123",
+			ExpiresAt: &expires,
+		})
+	}
+	return archives
+}
+
+func newTestReminderService(archives []domain.Archive, notifier domain.Notifier) (*ReminderService, *memoryReminders) {
+	reminders := newMemoryReminders()
+	service := NewReminderService(&reminderArchives{expiring: archives}, reminders, nil,
+		[]domain.NotificationChannel{{Name: "email", Notifier: notifier}},
+		ReminderServiceConfig{Window: 72 * time.Hour, TrashRetention: 30 * 24 * time.Hour})
+	return service, reminders
+}
+
+func TestReminderRunPagesPastFirstBatch(t *testing.T) {
+	archives := expiringArchives(2*reminderBatchSize + 1)
+	notifier := &countingNotifier{}
+	service, _ := newTestReminderService(archives, notifier)
+
+	run, err := service.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Matched != len(archives) || run.Sent != len(archives) {
+		t.Fatalf("matched %d sent %d, want %d", run.Matched, run.Sent, len(archives))
+	}
+
+	// Run berikutnya tidak mengirim ulang, dan semua arsip tetap terlihat
+	run, err = service.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Sent != 0 || run.Skipped != len(archives) {
+		t.Fatalf("second run sent %d skipped %d, want 0 and %d", run.Sent, run.Skipped, len(archives))
+	}
+	if got := notifier.total(); got != len(archives) {
+		t.Fatalf("notified %d times, want %d", got, len(archives))
+	}
+}
+
+func TestReminderConcurrentRunsSendOnce(t *testing.T) {
+	archives := expiringArchives(20)
+	notifier := &countingNotifier{delay: time.Millisecond}
+	service, _ := newTestReminderService(archives, notifier)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.Run(context.Background()); err != nil {
+				t.Errorf("Run: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, archive := range archives {
+		if got := notifier.sent[archive.ID.Hex()]; got != 1 {
+			t.Fatalf("archive %s notified %d times, want 1", archive.ID.Hex(), got)
+		}
+	}
+}
+
+func TestReminderFailedChannelRetriesUpToLimit(t *testing.T) {
+	archives := expiringArchives(1)
+	notifier := &countingNotifier{err: errors.New("smtp down")}
+	service, reminders := newTestReminderService(archives, notifier)
+
+	for i := 0; i < domain.MaxReminderAttempts+2; i++ {
+		if _, err := service.Run(context.Background()); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+	if got := notifier.total(); got != domain.MaxReminderAttempts {
+		t.Fatalf("notified %d times, want %d", got, domain.MaxReminderAttempts)
+	}
+	for _, reminder := range reminders.reminders {
+		delivery := reminder.Delivery("email")
+		if delivery.Error != "smtp down" || delivery.SentAt != nil || delivery.SendingUntil != nil {
+			t.Fatalf("unexpected delivery %+v", delivery)
+		}
+	}
+}
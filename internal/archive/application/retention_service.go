@@ -0,0 +1,225 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// retentionBatchSize adalah jumlah arsip yang dibaca per batch saat evaluasi
+const retentionBatchSize = 500
+
+type RetentionService struct {
+	repo       domain.RetentionRepository
+	archives   domain.ArchiveRepository
+	categories domain.CategoryRepository
+	events     domain.EventPublisher
+}
+
+func NewRetentionService(repo domain.RetentionRepository, archives domain.ArchiveRepository, categories domain.CategoryRepository) *RetentionService {
+	return &RetentionService{repo: repo, archives: archives, categories: categories}
+}
+
+// UseEvents mengirim event archive.deleted untuk arsip yang di-soft delete oleh retensi
+func (s *RetentionService) UseEvents(publisher domain.EventPublisher) {
+	s.events = publisher
+}
+
+func (s *RetentionService) CreatePolicy(ctx context.Context, policy *domain.RetentionPolicy, userID string) error {
+	if err := s.validate(ctx, policy); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	policy.ID = primitive.NewObjectID()
+	policy.CreatedBy = userID
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+	return s.repo.CreatePolicy(ctx, policy)
+}
+
+func (s *RetentionService) GetPolicy(ctx context.Context, id string) (*domain.RetentionPolicy, error) {
+	return s.repo.FindPolicy(ctx, id)
+}
+
+func (s *RetentionService) ListPolicies(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	return s.repo.FindPolicies(ctx)
+}
+
+// UpdatePolicy mengganti isi kebijakan; jadwal arsip dihitung ulang pada evaluasi berikutnya
+func (s *RetentionService) UpdatePolicy(ctx context.Context, id string, changes domain.RetentionPolicy) (*domain.RetentionPolicy, error) {
+	policy, err := s.repo.FindPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Name = changes.Name
+	policy.Category = changes.Category
+	policy.Type = changes.Type
+	policy.Period = changes.Period
+	policy.Trigger = changes.Trigger
+	policy.Action = changes.Action
+	if err := s.validate(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	policy.UpdatedAt = time.Now()
+	if err := s.repo.UpdatePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *RetentionService) DeletePolicy(ctx context.Context, id string) error {
+	return s.repo.DeletePolicy(ctx, id)
+}
+
+func (s *RetentionService) ListRuns(ctx context.Context, page, limit int) ([]domain.RetentionRun, int64, error) {
+	return s.repo.FindRuns(ctx, page, limit)
+}
+
+// Evaluate menghitung ulang jadwal retensi semua arsip aktif lalu menjalankan
+// tindakan untuk arsip yang sudah jatuh tempo. Hasilnya disimpan sebagai laporan.
+func (s *RetentionService) Evaluate(ctx context.Context) (*domain.RetentionRun, error) {
+	run := &domain.RetentionRun{StartedAt: time.Now(), Disposed: []domain.RetentionDisposal{}}
+
+	err := s.evaluate(ctx, run)
+	if err != nil {
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now()
+
+	if saveErr := s.repo.SaveRun(ctx, run); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return run, err
+}
+
+func (s *RetentionService) evaluate(ctx context.Context, run *domain.RetentionRun) error {
+	policies, err := s.repo.FindPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	afterID := ""
+	for {
+		archives, err := s.archives.ScanActive(ctx, afterID, retentionBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range archives {
+			archive := &archives[i]
+			run.Evaluated++
+
+			state := domain.Schedule(domain.SelectPolicy(policies, archive), archive)
+			if state.Equal(archive.Retention) {
+				continue
+			}
+			if err := s.archives.SetRetention(ctx, archive.ID.Hex(), state, domain.SystemUserID); err != nil {
+				return err
+			}
+			if state == nil {
+				run.Cleared++
+			} else {
+				run.Scheduled++
+			}
+		}
+
+		if len(archives) < retentionBatchSize {
+			break
+		}
+		afterID = archives[len(archives)-1].ID.Hex()
+	}
+
+	return s.dispose(ctx, run, time.Now())
+}
+
+// dispose memproses arsip yang jatuh tempo. Arsip yang gagal tetap jatuh tempo,
+// jadi ID yang sudah diproses dilewati supaya loop tidak berulang.
+func (s *RetentionService) dispose(ctx context.Context, run *domain.RetentionRun, now time.Time) error {
+	processed := map[string]bool{}
+	for {
+		archives, err := s.archives.FindRetentionDue(ctx, now, retentionBatchSize)
+		if err != nil {
+			return err
+		}
+
+		progressed := false
+		for i := range archives {
+			archive := &archives[i]
+			id := archive.ID.Hex()
+			if processed[id] {
+				continue
+			}
+			processed[id] = true
+			progressed = true
+
+			disposal := domain.RetentionDisposal{
+				ArchiveID: id,
+				Name:      archive.Name,
+				PolicyID:  archive.Retention.PolicyID.Hex(),
+				Action:    archive.Retention.Action,
+				DueAt:     archive.Retention.DueAt,
+			}
+			if err := s.apply(ctx, archive, now); err != nil {
+				disposal.Error = err.Error()
+			}
+			run.Disposed = append(run.Disposed, disposal)
+		}
+
+		if !progressed {
+			return nil
+		}
+	}
+}
+
+// apply menjalankan tindakan retensi. Pemusnahan permanen tidak pernah langsung
+// dijalankan; arsip masuk antrean disposisi sampai disetujui records manager.
+func (s *RetentionService) apply(ctx context.Context, archive *domain.Archive, now time.Time) error {
+	id := archive.ID.Hex()
+	switch archive.Retention.Action {
+	case domain.RetentionSoftDelete:
+		if err := s.archives.Delete(ctx, id, domain.SoftDelete, domain.SystemUserID); err != nil {
+			return err
+		}
+		if s.events != nil {
+			// Kegagalan mencatat event tidak membatalkan disposal yang sudah terjadi
+			_ = s.events.Publish(ctx, domain.ArchiveEvent{
+				Type:       domain.EventArchiveDeleted,
+				OccurredAt: now,
+				Data: map[string]interface{}{
+					"id":          id,
+					"name":        archive.Name,
+					"delete_type": domain.SoftDelete.String(),
+					"deleted_by":  domain.SystemUserID,
+					"reason":      "retention",
+				},
+			})
+		}
+		return nil
+	// hard_delete berarti "masuk antrean disposisi", sama seperti review
+	case domain.RetentionHardDelete, domain.RetentionReview:
+		state := *archive.Retention
+		state.ReviewSince = &now
+		return s.archives.SetRetention(ctx, id, &state, domain.SystemUserID)
+	default:
+		return domain.ErrInvalidRetentionAction
+	}
+}
+
+func (s *RetentionService) validate(ctx context.Context, policy *domain.RetentionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if policy.Category != "" {
+		if err := validateCategoryPath(ctx, s.categories, policy.Category); err != nil && !errors.Is(err, domain.ErrCategoryDeprecated) {
+			// Kategori deprecated tetap boleh, arsip lamanya masih butuh retensi
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRetention menyimpan kebijakan dan laporan evaluasi retensi
+type memoryRetention struct {
+	domain.RetentionRepository
+	policies []domain.RetentionPolicy
+	runs     []domain.RetentionRun
+}
+
+func (r *memoryRetention) FindPolicies(context.Context) ([]domain.RetentionPolicy, error) {
+	return r.policies, nil
+}
+
+func (r *memoryRetention) SaveRun(_ context.Context, run *domain.RetentionRun) error {
+	r.runs = append(r.runs, *run)
+	return nil
+}
+
+// retentionArchives meniru query retensi di Mongo: arsip aktif dibaca urut ID dan
+// arsip yang sudah di antrean disposisi tidak dianggap jatuh tempo lagi
+type retentionArchives struct {
+	domain.ArchiveRepository
+	byID    map[string]*domain.Archive
+	deleted []string
+	// failDelete membuat soft delete arsip tersebut gagal
+	failDelete map[string]bool
+}
+
+func (r *retentionArchives) add(archive domain.Archive) *domain.Archive {
+	archive.ID = primitive.NewObjectID()
+	r.byID[archive.ID.Hex()] = &archive
+	return &archive
+}
+
+func (r *retentionArchives) ScanActive(_ context.Context, afterID string, limit int) ([]domain.Archive, error) {
+	var ids []string
+	for id, archive := range r.byID {
+		if id > afterID && archive.DeletedAt == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	var archives []domain.Archive
+	for _, id := range ids {
+		if len(archives) == limit {
+			break
+		}
+		archives = append(archives, *r.byID[id])
+	}
+	return archives, nil
+}
+
+func (r *retentionArchives) SetRetention(_ context.Context, id string, state *domain.RetentionState, _ string) error {
+	r.byID[id].Retention = state
+	return nil
+}
+
+func (r *retentionArchives) FindRetentionDue(_ context.Context, now time.Time, limit int) ([]domain.Archive, error) {
+	var archives []domain.Archive
+	for _, archive := range r.byID {
+		state := archive.Retention
+		if archive.DeletedAt != nil || state == nil || state.DueAt == nil || state.DueAt.After(now) || state.InQueue() {
+			continue
+		}
+		if len(archives) < limit {
+			archives = append(archives, *archive)
+		}
+	}
+	return archives, nil
+}
+
+func (r *retentionArchives) Delete(_ context.Context, id string, deleteType domain.DeleteType, _ string) error {
+	if r.failDelete[id] {
+		return domain.ErrUnderLegalHold
+	}
+	if deleteType != domain.SoftDelete {
+		return errors.New("retention must only soft delete")
+	}
+	now := time.Now()
+	r.byID[id].DeletedAt = &now
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// recordedEvents mencatat event yang dipublikasikan
+type recordedEvents struct {
+	events []domain.ArchiveEvent
+}
+
+func (p *recordedEvents) Publish(_ context.Context, event domain.ArchiveEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func retentionPolicy(category string, action domain.RetentionAction, period domain.RetentionPeriod) domain.RetentionPolicy {
+	return domain.RetentionPolicy{
+		ID:       primitive.NewObjectID(),
+		Name:     category + " " + string(action),
+		Category: category,
+		Period:   period,
+		Trigger:  domain.TriggerCreated,
+		Action:   action,
+	}
+}
+
+func TestRetentionEvaluateSchedulesAndDisposes(t *testing.T) {
+	old := time.Now().AddDate(-3, 0, 0)
+	recent := time.Now().AddDate(0, -1, 0)
+	trash := retentionPolicy("sementara", domain.RetentionSoftDelete, domain.RetentionPeriod{Years: 1})
+	review := retentionPolicy("keuangan", domain.RetentionReview, domain.RetentionPeriod{Years: 2})
+	hardDelete := retentionPolicy("hukum", domain.RetentionHardDelete, domain.RetentionPeriod{Years: 1})
+	retention := &memoryRetention{policies: []domain.RetentionPolicy{trash, review, hardDelete}}
+
+	archives := &retentionArchives{byID: map[string]*domain.Archive{}}
+	expired := archives.add(domain.Archive{Name: "draf.pdf", Category: "sementara", CreatedAt: old})
+	notDue := archives.add(domain.Archive{Name: "baru.pdf", Category: "sementara", CreatedAt: recent})
+	reviewed := archives.add(domain.Archive{Name: "neraca.pdf", Category: "keuangan.neraca", CreatedAt: old})
+	contract := archives.add(domain.Archive{Name: "kontrak.pdf", Category: "hukum", CreatedAt: old})
+	// Jadwal lama dari kebijakan yang sudah dihapus harus dibersihkan
+	unmanaged := archives.add(domain.Archive{Name: "foto.jpg", Category: "umum", CreatedAt: old,
+		Retention: &domain.RetentionState{PolicyID: primitive.NewObjectID(), Action: domain.RetentionSoftDelete}})
+
+	events := &recordedEvents{}
+	service := NewRetentionService(retention, archives, nil)
+	service.UseEvents(events)
+
+	run, err := service.Evaluate(context.Background())
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if run.Evaluated != 5 || run.Scheduled != 4 || run.Cleared != 1 {
+		t.Fatalf("expected 5 evaluated, 4 scheduled, 1 cleared, got %d, %d, %d", run.Evaluated, run.Scheduled, run.Cleared)
+	}
+	if len(run.Disposed) != 3 {
+		t.Fatalf("expected 3 disposals, got %+v", run.Disposed)
+	}
+	if len(retention.runs) != 1 {
+		t.Fatalf("expected the run report to be saved, got %d", len(retention.runs))
+	}
+
+	if len(archives.deleted) != 1 || archives.deleted[0] != expired.ID.Hex() {
+		t.Fatalf("expected only the soft_delete archive in the trash, got %v", archives.deleted)
+	}
+	if len(events.events) != 1 || events.events[0].Data["reason"] != "retention" {
+		t.Fatalf("expected one archive.deleted event with reason retention, got %+v", events.events)
+	}
+	// hard_delete tidak memusnahkan apa pun; arsip menunggu keputusan seperti review
+	for _, queued := range []*domain.Archive{reviewed, contract} {
+		state := archives.byID[queued.ID.Hex()].Retention
+		if archives.byID[queued.ID.Hex()].DeletedAt != nil || !state.InQueue() {
+			t.Fatalf("expected %s in the disposition queue, got %+v", queued.Name, state)
+		}
+	}
+	if state := archives.byID[notDue.ID.Hex()].Retention; state == nil || state.InQueue() || state.PolicyID != trash.ID {
+		t.Fatalf("expected a pending schedule for the recent archive, got %+v", state)
+	}
+	if state := archives.byID[unmanaged.ID.Hex()].Retention; state != nil {
+		t.Fatalf("expected the orphaned schedule to be cleared, got %+v", state)
+	}
+
+	// Evaluasi berikutnya tidak mengubah apa pun dan tidak mengantrekan ulang
+	run, err = service.Evaluate(context.Background())
+	if err != nil {
+		t.Fatalf("second evaluate: %v", err)
+	}
+	if run.Scheduled != 0 || run.Cleared != 0 || len(run.Disposed) != 0 {
+		t.Fatalf("expected a second run without changes, got %+v", run)
+	}
+}
+
+func TestRetentionEvaluateReportsFailedDisposalAndContinues(t *testing.T) {
+	old := time.Now().AddDate(-3, 0, 0)
+	policy := retentionPolicy("sementara", domain.RetentionSoftDelete, domain.RetentionPeriod{Years: 1})
+	archives := &retentionArchives{byID: map[string]*domain.Archive{}}
+	held := archives.add(domain.Archive{Name: "sengketa.pdf", Category: "sementara", CreatedAt: old})
+	free := archives.add(domain.Archive{Name: "draf.pdf", Category: "sementara", CreatedAt: old})
+	archives.failDelete = map[string]bool{held.ID.Hex(): true}
+
+	service := NewRetentionService(&memoryRetention{policies: []domain.RetentionPolicy{policy}}, archives, nil)
+	run, err := service.Evaluate(context.Background())
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+
+	errorsByID := map[string]string{}
+	for _, disposal := range run.Disposed {
+		errorsByID[disposal.ArchiveID] = disposal.Error
+	}
+	if len(errorsByID) != 2 {
+		t.Fatalf("expected each due archive reported once, got %+v", run.Disposed)
+	}
+	if errorsByID[held.ID.Hex()] != domain.ErrUnderLegalHold.Error() || errorsByID[free.ID.Hex()] != "" {
+		t.Fatalf("expected only the held archive to fail, got %v", errorsByID)
+	}
+	if archives.byID[held.ID.Hex()].DeletedAt != nil || archives.byID[free.ID.Hex()].DeletedAt == nil {
+		t.Fatal("the failed disposal must not stop the other archive from being deleted")
+	}
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SavedSearchService struct {
+	searches domain.SavedSearchRepository
+	archives domain.ArchiveRepository
+}
+
+func NewSavedSearchService(searches domain.SavedSearchRepository, archives domain.ArchiveRepository) *SavedSearchService {
+	return &SavedSearchService{searches: searches, archives: archives}
+}
+
+func (s *SavedSearchService) Create(ctx context.Context, userID, name, description string, filter domain.ArchiveFilter) (*domain.SavedSearch, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.ErrSavedSearchNameRequired
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	search := &domain.SavedSearch{
+		ID:          primitive.NewObjectID(),
+		Name:        name,
+		Description: description,
+		OwnerID:     userID,
+		Filter:      filter,
+		SharedWith:  []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.searches.Create(ctx, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// List mengembalikan saved search milik user dan yang dibagikan kepadanya
+func (s *SavedSearchService) List(ctx context.Context, userID string) ([]domain.SavedSearch, error) {
+	return s.searches.FindVisible(ctx, userID)
+}
+
+func (s *SavedSearchService) Get(ctx context.Context, id, userID string) (*domain.SavedSearch, error) {
+	search, err := s.searches.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !search.CanView(userID) {
+		// Jangan bocorkan keberadaan saved search milik orang lain
+		return nil, domain.ErrSavedSearchNotFound
+	}
+	return search, nil
+}
+
+// Run menjalankan filter tersimpan terhadap data arsip saat ini
+func (s *SavedSearchService) Run(ctx context.Context, id, userID string, page, limit int) (*domain.SavedSearch, []domain.Archive, int64, error) {
+	search, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	filter := search.Filter
+	filter.Viewer = userID
+	archives, total, err := s.archives.FindAll(ctx, filter, nil, page, limit)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return search, archives, total, nil
+}
+
+func (s *SavedSearchService) Share(ctx context.Context, id, userID string, userIDs []string) (*domain.SavedSearch, error) {
+	search, err := s.ownedSearch(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := []string{}
+	seen := map[string]bool{userID: true}
+	for _, u := range userIDs {
+		u = strings.TrimSpace(u)
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		shared = append(shared, u)
+	}
+
+	search.SharedWith = shared
+	search.UpdatedAt = time.Now()
+	if err := s.searches.Update(ctx, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *SavedSearchService) Delete(ctx context.Context, id, userID string) error {
+	if _, err := s.ownedSearch(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.searches.Delete(ctx, id)
+}
+
+// Collections menampilkan saved search sebagai folder virtual beserta jumlah hasilnya
+func (s *SavedSearchService) Collections(ctx context.Context, userID string) ([]domain.SmartCollection, error) {
+	searches, err := s.searches.FindVisible(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collections := make([]domain.SmartCollection, 0, len(searches))
+	for _, search := range searches {
+		filter := search.Filter
+		filter.Viewer = userID
+		count, err := s.archives.Count(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, domain.SmartCollection{
+			ID:          search.ID.Hex(),
+			Name:        search.Name,
+			OwnerID:     search.OwnerID,
+			Shared:      search.OwnerID != userID,
+			ResultCount: count,
+		})
+	}
+	return collections, nil
+}
+
+func (s *SavedSearchService) ownedSearch(ctx context.Context, id, userID string) (*domain.SavedSearch, error) {
+	search, err := s.searches.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if search.OwnerID != userID {
+		if search.CanView(userID) {
+			return nil, domain.ErrSavedSearchForbidden
+		}
+		return nil, domain.ErrSavedSearchNotFound
+	}
+	return search, nil
+}
@@ -0,0 +1,387 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job adalah pekerjaan yang bisa dijadwalkan. Preview nil berarti job tidak
+// mendukung dry run.
+type Job struct {
+	Run     func(ctx context.Context) (*domain.JobResult, error)
+	Preview func(ctx context.Context) (*domain.JobResult, error)
+}
+
+// JobStatus adalah keadaan job terdaftar untuk ditampilkan ke admin
+type JobStatus struct {
+	Name string `json:"name"`
+	// Schedule kosong berarti job hanya bisa dijalankan manual
+	Schedule string     `json:"schedule,omitempty"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	Running  bool       `json:"running"`
+	DryRun   bool       `json:"dry_run"`
+}
+
+// Leader menentukan apakah replika ini boleh menjalankan job terjadwal
+type Leader interface {
+	IsLeader() bool
+}
+
+type scheduledJob struct {
+	name     string
+	schedule *domain.CronSchedule
+	job      Job
+	next     time.Time
+	running  bool
+	// cancel membatalkan eksekusi terjadwal yang sedang berjalan
+	cancel context.CancelFunc
+}
+
+// Scheduler menjalankan job sesuai jadwal cron masing-masing, mencatat setiap
+// eksekusi sebagai riwayat, dan bisa dihentikan dengan menunggu job yang berjalan.
+type Scheduler struct {
+	runs    domain.JobRunRepository
+	timeout time.Duration
+	leader  Leader
+	// locks nil berarti job hanya dijaga agar tidak paralel di replika ini
+	locks   domain.LeaseRepository
+	holder  string
+	lockTTL time.Duration
+
+	mu       sync.Mutex
+	jobs     map[string]*scheduledJob
+	onFinish []func(run *domain.JobRun, saveErr error)
+	started  bool
+	stopped  bool
+
+	// base dibatalkan saat Stop melewati batas waktu, sehingga job yang berjalan ikut berhenti
+	base   context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(runs domain.JobRunRepository, timeout time.Duration) *Scheduler {
+	base, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		runs:    runs,
+		timeout: timeout,
+		jobs:    map[string]*scheduledJob{},
+		base:    base,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+}
+
+// Register mendaftarkan job; schedule nil berarti job hanya dijalankan manual.
+// Job harus didaftarkan sebelum Start.
+func (s *Scheduler) Register(name string, schedule *domain.CronSchedule, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = &scheduledJob{name: name, schedule: schedule, job: job}
+}
+
+// UseLeader membatasi job terjadwal agar hanya berjalan saat replika ini leader.
+// Tanpa Leader, setiap replika menjalankan jadwalnya sendiri.
+func (s *Scheduler) UseLeader(leader Leader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = leader
+}
+
+// UseJobLocks memakai lease per job di Mongo, sehingga job manual di satu replika tidak
+// bersamaan dengan job terjadwal di leader. Lease diperpanjang selama job berjalan;
+// job dibatalkan bila lease-nya hilang.
+func (s *Scheduler) UseJobLocks(locks domain.LeaseRepository, holder string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locks = locks
+	s.holder = holder
+	s.lockTTL = ttl
+}
+
+// OnFinish mendaftarkan hook yang dipanggil setelah setiap eksekusi, misalnya untuk logging.
+// saveErr berisi error saat menyimpan riwayat.
+func (s *Scheduler) OnFinish(hook func(run *domain.JobRun, saveErr error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onFinish = append(s.onFinish, hook)
+}
+
+// Start menjalankan satu goroutine per job terjadwal
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+
+	for _, job := range s.jobs {
+		if job.schedule == nil {
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+}
+
+func (s *Scheduler) loop(job *scheduledJob) {
+	defer s.wg.Done()
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		s.mu.Lock()
+		job.next = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Replika lain yang memegang lease menjalankan jadwal ini
+		if !s.isLeader() {
+			continue
+		}
+		// Jadwal yang jatuh saat job masih berjalan manual dilewati
+		_, _ = s.execute(job, domain.JobTriggerSchedule, false, domain.SystemUserID)
+	}
+}
+
+func (s *Scheduler) isLeader() bool {
+	s.mu.Lock()
+	leader := s.leader
+	s.mu.Unlock()
+	return leader == nil || leader.IsLeader()
+}
+
+// CancelScheduled membatalkan eksekusi terjadwal yang sedang berjalan, misalnya saat
+// replika ini kehilangan leadership. Eksekusi manual tidak ikut dibatalkan.
+func (s *Scheduler) CancelScheduled() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.cancel != nil {
+			job.cancel()
+		}
+	}
+}
+
+// Stop menghentikan penjadwalan lalu menunggu job yang sedang berjalan. Bila ctx
+// habis lebih dulu, job yang berjalan dibatalkan lewat context-nya.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-finished
+		return ctx.Err()
+	}
+}
+
+// Jobs mengembalikan semua job terdaftar diurutkan berdasarkan nama
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		status := JobStatus{Name: job.name, Running: job.running, DryRun: job.job.Preview != nil}
+		if job.schedule != nil {
+			status.Schedule = job.schedule.String()
+			if !job.next.IsZero() {
+				next := job.next
+				status.NextRun = &next
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Trigger menjalankan job saat ini juga dan menunggu hasilnya. Dry run hanya
+// mengembalikan daftar arsip yang akan diproses tanpa mengubah apa pun.
+func (s *Scheduler) Trigger(name string, dryRun bool, userID string) (*domain.JobRun, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	stopped := s.stopped
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, domain.ErrUnknownJob
+	}
+	if stopped {
+		return nil, domain.ErrSchedulerStopped
+	}
+	if dryRun && job.job.Preview == nil {
+		return nil, domain.ErrDryRunUnsupported
+	}
+	return s.execute(job, domain.JobTriggerManual, dryRun, userID)
+}
+
+func (s *Scheduler) Runs(ctx context.Context, job string, page, limit int) ([]domain.JobRun, int64, error) {
+	if job != "" {
+		s.mu.Lock()
+		_, ok := s.jobs[job]
+		s.mu.Unlock()
+		if !ok {
+			return nil, 0, domain.ErrUnknownJob
+		}
+	}
+	return s.runs.FindRuns(ctx, job, page, limit)
+}
+
+func (s *Scheduler) Run(ctx context.Context, id string) (*domain.JobRun, error) {
+	return s.runs.FindByID(ctx, id)
+}
+
+// lock mengambil lease job dan memperpanjangnya di background sampai release dipanggil.
+// ErrJobRunning bila job sedang berjalan di replika lain.
+func (s *Scheduler) lock(ctx context.Context, cancel context.CancelFunc, name string, locks domain.LeaseRepository, holder string, ttl time.Duration) (func(), error) {
+	lease := domain.JobLease(name)
+	if _, acquired, err := locks.Acquire(ctx, lease, holder, ttl); err != nil {
+		return nil, err
+	} else if !acquired {
+		return nil, domain.ErrJobRunning
+	}
+
+	renewed := time.Now()
+	stop := heartbeat(ctx, ttl/3, func(ctx context.Context) {
+		attempted := time.Now()
+		_, acquired, err := locks.Acquire(ctx, lease, holder, ttl)
+		switch {
+		case err == nil && acquired:
+			renewed = attempted
+		// Replika lain boleh mengambil alih lease yang habis, jadi job ini harus berhenti
+		case err == nil, time.Since(renewed) >= ttl:
+			cancel()
+		}
+	})
+	return func() {
+		stop()
+		// Lease yang gagal dilepas habis sendiri setelah TTL
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer releaseCancel()
+		_ = locks.Release(releaseCtx, lease, holder)
+	}, nil
+}
+
+// execute menjalankan job dengan timeout dan mencatat riwayatnya. Job yang sama tidak
+// pernah berjalan paralel; dry run tidak mengubah data sehingga boleh berjalan bersamaan.
+func (s *Scheduler) execute(job *scheduledJob, trigger domain.JobTrigger, dryRun bool, userID string) (*domain.JobRun, error) {
+	s.mu.Lock()
+	if s.stopped && trigger == domain.JobTriggerManual {
+		s.mu.Unlock()
+		return nil, domain.ErrSchedulerStopped
+	}
+	if !dryRun {
+		if job.running {
+			s.mu.Unlock()
+			return nil, domain.ErrJobRunning
+		}
+		job.running = true
+	}
+	// Job manual juga ditunggu oleh Stop
+	if trigger == domain.JobTriggerManual {
+		s.wg.Add(1)
+		defer s.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(s.base, s.timeout)
+	defer cancel()
+	if trigger == domain.JobTriggerSchedule {
+		job.cancel = cancel
+	}
+	locks, holder, lockTTL := s.locks, s.holder, s.lockTTL
+	s.mu.Unlock()
+
+	if !dryRun && locks != nil {
+		release, err := s.lock(ctx, cancel, job.name, locks, holder, lockTTL)
+		if err != nil {
+			s.mu.Lock()
+			job.running = false
+			if trigger == domain.JobTriggerSchedule {
+				job.cancel = nil
+			}
+			s.mu.Unlock()
+			return nil, err
+		}
+		defer release()
+	}
+
+	run := &domain.JobRun{
+		ID:          primitive.NewObjectID(),
+		Job:         job.name,
+		Trigger:     trigger,
+		DryRun:      dryRun,
+		TriggeredBy: userID,
+		StartedAt:   time.Now(),
+		Counts:      map[string]int64{},
+	}
+
+	fn := job.job.Run
+	if dryRun {
+		fn = job.job.Preview
+	}
+	result, err := fn(ctx)
+	if result != nil {
+		if result.Counts != nil {
+			run.Counts = result.Counts
+		}
+		run.Items = result.Items
+		run.Warning = result.Warning
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now()
+	run.DurationMS = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+
+	s.mu.Lock()
+	if !dryRun {
+		job.running = false
+	}
+	if trigger == domain.JobTriggerSchedule {
+		job.cancel = nil
+	}
+	s.mu.Unlock()
+
+	// Riwayat tetap disimpan walaupun context job sudah habis
+	saveCtx, saveCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer saveCancel()
+	saveErr := s.runs.Create(saveCtx, run)
+
+	s.mu.Lock()
+	hooks := append([]func(*domain.JobRun, error){}, s.onFinish...)
+	s.mu.Unlock()
+	for _, hook := range hooks {
+		hook(run, saveErr)
+	}
+	return run, saveErr
+}
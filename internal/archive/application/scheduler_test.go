@@ -0,0 +1,282 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+// memoryLeases meniru LeaseRepository di Mongo: lease milik sendiri diperpanjang,
+// lease kosong atau kedaluwarsa diambil alih, selain itu dilaporkan masih dipegang
+type memoryLeases struct {
+	mu     sync.Mutex
+	leases map[string]domain.Lease
+	// err membuat setiap panggilan gagal, meniru Mongo yang tidak bisa dihubungi
+	err error
+}
+
+func newMemoryLeases() *memoryLeases {
+	return &memoryLeases{leases: map[string]domain.Lease{}}
+}
+
+func (r *memoryLeases) Acquire(_ context.Context, name, holder string, ttl time.Duration) (*domain.Lease, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, false, r.err
+	}
+
+	now := time.Now()
+	lease, ok := r.leases[name]
+	switch {
+	case ok && lease.Holder == holder:
+		lease.RenewedAt = now
+		lease.ExpiresAt = now.Add(ttl)
+	case !ok || !lease.Active(now):
+		lease = domain.Lease{Name: name, Holder: holder, AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(ttl)}
+	default:
+		return &lease, false, nil
+	}
+	r.leases[name] = lease
+	return &lease, true, nil
+}
+
+func (r *memoryLeases) Release(_ context.Context, name, holder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if lease, ok := r.leases[name]; ok && lease.Holder == holder {
+		delete(r.leases, name)
+	}
+	return nil
+}
+
+func (r *memoryLeases) Find(_ context.Context, name string) (*domain.Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	lease, ok := r.leases[name]
+	if !ok {
+		return nil, domain.ErrLeaseNotFound
+	}
+	return &lease, nil
+}
+
+// hold membuat holder memegang lease selama ttl, misalnya replika lain yang mengambil alih
+func (r *memoryLeases) hold(name, holder string, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.leases[name] = domain.Lease{Name: name, Holder: holder, AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(ttl)}
+}
+
+func (r *memoryLeases) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+type memoryRuns struct {
+	domain.JobRunRepository
+	mu   sync.Mutex
+	runs []domain.JobRun
+}
+
+func (r *memoryRuns) Create(_ context.Context, run *domain.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, *run)
+	return nil
+}
+
+func (r *memoryRuns) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.runs)
+}
+
+// waitForCancel adalah job yang berjalan sampai context-nya dibatalkan
+func waitForCancel(started chan<- struct{}) func(ctx context.Context) (*domain.JobResult, error) {
+	return func(ctx context.Context) (*domain.JobResult, error) {
+		if started != nil {
+			close(started)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("job was not cancelled")
+		}
+	}
+}
+
+func TestSchedulerCancelsJobWhenLeaseIsTakenOver(t *testing.T) {
+	leases := newMemoryLeases()
+	runs := &memoryRuns{}
+	scheduler := NewScheduler(runs, time.Minute)
+	scheduler.UseJobLocks(leases, "replica-a", 60*time.Millisecond)
+	scheduler.Register("cleanup", nil, Job{Run: func(ctx context.Context) (*domain.JobResult, error) {
+		// Replika lain mengambil alih lease selagi job berjalan
+		leases.hold(domain.JobLease("cleanup"), "replica-b", time.Minute)
+		return waitForCancel(nil)(ctx)
+	}})
+
+	run, err := scheduler.Trigger("cleanup", false, "admin")
+	if err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	if run.Error != context.Canceled.Error() {
+		t.Fatalf("expected the job to be cancelled, got error %q", run.Error)
+	}
+	lease, err := leases.Find(context.Background(), domain.JobLease("cleanup"))
+	if err != nil || lease.Holder != "replica-b" {
+		t.Fatalf("the new holder must keep its lease, got %+v (%v)", lease, err)
+	}
+}
+
+func TestSchedulerCancelsJobWhenLeaseCannotBeRenewed(t *testing.T) {
+	leases := newMemoryLeases()
+	scheduler := NewScheduler(&memoryRuns{}, time.Minute)
+	scheduler.UseJobLocks(leases, "replica-a", 60*time.Millisecond)
+	scheduler.Register("cleanup", nil, Job{Run: func(ctx context.Context) (*domain.JobResult, error) {
+		// Mongo tidak bisa dihubungi, sehingga lease habis tanpa bisa diperpanjang
+		leases.fail(errors.New("connection refused"))
+		return waitForCancel(nil)(ctx)
+	}})
+
+	run, err := scheduler.Trigger("cleanup", false, "admin")
+	if err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	if run.Error != context.Canceled.Error() {
+		t.Fatalf("expected the job to stop once its lease expired, got error %q", run.Error)
+	}
+}
+
+func TestSchedulerKeepsLeaseAliveDuringLongJob(t *testing.T) {
+	leases := newMemoryLeases()
+	scheduler := NewScheduler(&memoryRuns{}, time.Minute)
+	scheduler.UseJobLocks(leases, "replica-a", 60*time.Millisecond)
+	scheduler.Register("tiering", nil, Job{Run: func(ctx context.Context) (*domain.JobResult, error) {
+		// Berjalan jauh lebih lama dari TTL; heartbeat harus terus memperpanjang lease
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+		}
+		_, acquired, err := leases.Acquire(ctx, domain.JobLease("tiering"), "replica-b", time.Minute)
+		if err != nil || acquired {
+			return nil, errors.New("another replica could take the lease of a running job")
+		}
+		return &domain.JobResult{Counts: map[string]int64{"moved": 1}}, nil
+	}})
+
+	run, err := scheduler.Trigger("tiering", false, "admin")
+	if err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	if run.Error != "" || run.Counts["moved"] != 1 {
+		t.Fatalf("expected a successful run, got error %q counts %v", run.Error, run.Counts)
+	}
+	if _, err := leases.Find(context.Background(), domain.JobLease("tiering")); !errors.Is(err, domain.ErrLeaseNotFound) {
+		t.Fatalf("expected the lease to be released after the run, got %v", err)
+	}
+}
+
+func TestSchedulerRefusesJobRunningOnAnotherReplica(t *testing.T) {
+	leases := newMemoryLeases()
+	leases.hold(domain.JobLease("cleanup"), "replica-b", time.Minute)
+	runs := &memoryRuns{}
+	scheduler := NewScheduler(runs, time.Minute)
+	scheduler.UseJobLocks(leases, "replica-a", time.Minute)
+	scheduler.Register("cleanup", nil, Job{Run: func(context.Context) (*domain.JobResult, error) {
+		t.Error("job must not run while another replica holds its lease")
+		return nil, nil
+	}})
+
+	if _, err := scheduler.Trigger("cleanup", false, "admin"); !errors.Is(err, domain.ErrJobRunning) {
+		t.Fatalf("expected ErrJobRunning, got %v", err)
+	}
+	if runs.count() != 0 {
+		t.Fatalf("a refused job must not be recorded, got %d runs", runs.count())
+	}
+	if jobs := scheduler.Jobs(); jobs[0].Running {
+		t.Fatal("a refused job must not stay marked as running")
+	}
+}
+
+func TestSchedulerDryRunUsesPreviewWithoutLease(t *testing.T) {
+	leases := newMemoryLeases()
+	leases.hold(domain.JobLease("retention"), "replica-b", time.Minute)
+	runs := &memoryRuns{}
+	scheduler := NewScheduler(runs, time.Minute)
+	scheduler.UseJobLocks(leases, "replica-a", time.Minute)
+	scheduler.Register("retention", nil, Job{
+		Run: func(context.Context) (*domain.JobResult, error) {
+			t.Error("dry run must not call Run")
+			return nil, nil
+		},
+		Preview: func(context.Context) (*domain.JobResult, error) {
+			return &domain.JobResult{Counts: map[string]int64{"would_delete": 2}}, nil
+		},
+	})
+	scheduler.Register("cleanup", nil, Job{Run: func(context.Context) (*domain.JobResult, error) { return nil, nil }})
+
+	run, err := scheduler.Trigger("retention", true, "admin")
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !run.DryRun || run.Counts["would_delete"] != 2 {
+		t.Fatalf("expected a dry run with preview counts, got dry_run=%v counts=%v", run.DryRun, run.Counts)
+	}
+	if _, err := scheduler.Trigger("cleanup", true, "admin"); !errors.Is(err, domain.ErrDryRunUnsupported) {
+		t.Fatalf("expected ErrDryRunUnsupported, got %v", err)
+	}
+	if _, err := scheduler.Trigger("unknown", false, "admin"); !errors.Is(err, domain.ErrUnknownJob) {
+		t.Fatalf("expected ErrUnknownJob, got %v", err)
+	}
+}
+
+func TestSchedulerStopCancelsJobsAfterDeadline(t *testing.T) {
+	runs := &memoryRuns{}
+	scheduler := NewScheduler(runs, time.Minute)
+	started := make(chan struct{})
+	scheduler.Register("purge", nil, Job{Run: waitForCancel(started)})
+
+	result := make(chan *domain.JobRun, 1)
+	go func() {
+		run, _ := scheduler.Trigger("purge", false, "admin")
+		result <- run
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := scheduler.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected Stop to report the deadline, got %v", err)
+	}
+
+	// Stop baru kembali setelah riwayat job yang dibatalkan tersimpan
+	if runs.count() != 1 {
+		t.Fatalf("expected the cancelled run to be recorded before Stop returns, got %d runs", runs.count())
+	}
+	select {
+	case run := <-result:
+		if run == nil || run.Error != context.Canceled.Error() {
+			t.Fatalf("expected a cancelled run, got %+v", run)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("trigger did not return after Stop")
+	}
+	if _, err := scheduler.Trigger("purge", false, "admin"); !errors.Is(err, domain.ErrSchedulerStopped) {
+		t.Fatalf("expected ErrSchedulerStopped, got %v", err)
+	}
+}
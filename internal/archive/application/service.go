@@ -2,21 +2,68 @@ package application
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ArchiveService struct {
-	repo domain.ArchiveRepository
+	repo          domain.ArchiveRepository
+	categories    domain.CategoryRepository
+	fingerprinter domain.Fingerprinter
+	comparer      domain.VersionComparer
+	cfg           ArchiveServiceConfig
+	// historySources menambahkan entri dari luar change log, misalnya komentar
+	historySources []func(ctx context.Context, id string) ([]domain.HistoryEntry, error)
+	// events menerima event arsip untuk webhook; nil berarti event tidak dikirim
+	events domain.EventPublisher
 }
 
-func NewArchiveService(repo domain.ArchiveRepository) *ArchiveService {
-	return &ArchiveService{repo: repo}
+// ArchiveServiceConfig berisi pengaturan perilaku service yang berasal dari konfigurasi aplikasi
+type ArchiveServiceConfig struct {
+	// TrashRetention adalah lama arsip di trash sebelum dihapus permanen
+	TrashRetention time.Duration
+	// SimilarityThreshold adalah skor minimal (0-1) agar dua arsip dianggap mirip
+	SimilarityThreshold float64
+	// SimilarLimit membatasi jumlah arsip mirip yang dikembalikan
+	SimilarLimit int
+	// CheckoutTTL adalah lama default lock check-out
+	CheckoutTTL time.Duration
+	// TempDeleteTTL adalah masa tenggang default temp delete
+	TempDeleteTTL time.Duration
+	// TempDeleteMaxTTL membatasi masa tenggang yang boleh diminta client
+	TempDeleteMaxTTL time.Duration
+	// ColdRetrieval menentukan apakah download arsip cold langsung di-rehydrate
+	// atau dijawab dengan retrieval job
+	ColdRetrieval domain.RetrievalMode
 }
 
-func (s *ArchiveService) UploadArchive(ctx context.Context, file domain.FileContent, metadata domain.ArchiveMetadata) (*domain.Archive, error) {
+// maxCheckoutTTL membatasi lama lock yang boleh diminta client
+const maxCheckoutTTL = 24 * time.Hour
+
+// BulkItemResult adalah hasil per arsip pada operasi massal
+type BulkItemResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func NewArchiveService(repo domain.ArchiveRepository, categories domain.CategoryRepository,
+	fingerprinter domain.Fingerprinter, comparer domain.VersionComparer, cfg ArchiveServiceConfig) *ArchiveService {
+	return &ArchiveService{repo: repo, categories: categories, fingerprinter: fingerprinter, comparer: comparer, cfg: cfg}
+}
+
+// UploadArchive menyimpan file sebagai arsip baru atau versi baru dari arsip dengan
+// nama yang sama. cond berisi If-Match terhadap versi yang akan diganti.
+func (s *ArchiveService) UploadArchive(ctx context.Context, file domain.FileContent, metadata domain.ArchiveMetadata, cond domain.Precondition) (*domain.UploadResult, error) {
+	if err := s.validateCategory(ctx, metadata.Category); err != nil {
+		return nil, err
+	}
+
 	// Validasi unik
 	existing, err := s.repo.FindExistingArchive(ctx, domain.Archive{
 		Name:     file.Name,
@@ -28,6 +75,10 @@ func (s *ArchiveService) UploadArchive(ctx context.Context, file domain.FileCont
 	if err != nil {
 		return nil, err
 	}
+	// Draft milik user lain tidak boleh menerima versi baru dan ID-nya tidak boleh bocor
+	if existing != nil && !existing.VisibleTo(metadata.OwnerID) {
+		return nil, domain.ErrNameConflict
+	}
 
 	archive := domain.Archive{
 		Name:        file.Name,
@@ -46,69 +97,574 @@ func (s *ArchiveService) UploadArchive(ctx context.Context, file domain.FileCont
 		archive.CreatedAt = existing.CreatedAt
 	}
 
-	return s.repo.SaveWithVersioning(ctx, archive, file.Content)
+	archive.Signature = s.fingerprinter.Fingerprint(file.Content)
+
+	saved, err := s.repo.SaveWithVersioning(ctx, archive, file.Content, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := domain.EventArchiveUploaded
+	if saved.Version > 1 {
+		eventType = domain.EventArchiveVersioned
+	}
+	s.publish(ctx, eventType, saved, nil)
+
+	result := &domain.UploadResult{Archive: saved}
+	if len(saved.Signature) > 0 {
+		// Kegagalan pencarian duplikat tidak menggagalkan upload
+		similar, _ := s.repo.FindSimilar(ctx, saved.Signature, saved.ID.Hex(), s.cfg.SimilarityThreshold, s.cfg.SimilarLimit)
+		result.Similar = visibleSimilar(similar, metadata.OwnerID)
+	}
+	return result, nil
 }
 
-func (s *ArchiveService) GetArchive(ctx context.Context, id string) (*domain.Archive, []byte, error) {
-	return s.repo.FindByID(ctx, id)
+// FindSimilar mencari arsip yang kemungkinan duplikat. Arsip lama yang belum
+// memiliki signature akan dihitung dan disimpan signature-nya terlebih dahulu.
+func (s *ArchiveService) FindSimilar(ctx context.Context, id string, threshold float64, viewer string) ([]domain.SimilarArchive, error) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = s.cfg.SimilarityThreshold
+	}
+	if _, err := visibleArchive(ctx, s.repo, id, viewer); err != nil {
+		return nil, err
+	}
+
+	signature, err := s.repo.GetSignature(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signature) == 0 {
+		_, content, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if signature = s.fingerprinter.Fingerprint(content); len(signature) == 0 {
+			return nil, domain.ErrNoSignature
+		}
+		if err := s.repo.SaveSignature(ctx, id, signature); err != nil {
+			return nil, err
+		}
+	}
+
+	similar, err := s.repo.FindSimilar(ctx, signature, id, threshold, s.cfg.SimilarLimit)
+	if err != nil {
+		return nil, err
+	}
+	return visibleSimilar(similar, viewer), nil
 }
 
-func (s *ArchiveService) ListArchives(ctx context.Context, page, limit int) ([]domain.Archive, int64, error) {
-	return s.repo.FindAll(ctx, page, limit)
+// visibleSimilar membuang draft user lain dari hasil pencarian arsip mirip
+func visibleSimilar(similar []domain.SimilarArchive, viewer string) []domain.SimilarArchive {
+	visible := make([]domain.SimilarArchive, 0, len(similar))
+	for _, candidate := range similar {
+		if candidate.Archive.VisibleTo(viewer) {
+			visible = append(visible, candidate)
+		}
+	}
+	return visible
+}
+
+// activeArchive mengembalikan metadata arsip aktif bila boleh dilihat viewer
+func activeArchive(ctx context.Context, repo domain.ArchiveRepository, id, viewer string) (*domain.Archive, error) {
+	archive, err := repo.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !archive.VisibleTo(viewer) {
+		return nil, domain.ErrArchiveNotFound
+	}
+	return archive, nil
 }
 
-func (s *ArchiveService) GetArchivesByIDs(ctx context.Context, ids []string) ([]domain.Archive, error) {
-	return s.repo.FindByIDs(ctx, ids)
+// visibleArchive mengembalikan arsip, aktif maupun di trash, bila boleh dilihat viewer.
+// Draft user lain dianggap tidak ada agar keberadaannya tidak bocor.
+func visibleArchive(ctx context.Context, repo domain.ArchiveRepository, id, viewer string) (*domain.Archive, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrArchiveNotFound
+	}
+	archives, err := repo.FindByFilter(ctx, bson.M{"_id": objID}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(archives) == 0 || !archives[0].VisibleTo(viewer) {
+		return nil, domain.ErrArchiveNotFound
+	}
+	return &archives[0], nil
+}
+
+// deletableArchive seperti visibleArchive, tetapi menolak dokumen sertifikat pemusnahan
+func deletableArchive(ctx context.Context, repo domain.ArchiveRepository, id, viewer string) (*domain.Archive, error) {
+	archive, err := visibleArchive(ctx, repo, id, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if archive.IsCertificate() {
+		return nil, domain.ErrCertificateProtected
+	}
+	return archive, nil
+}
+
+// GetArchive mengambil arsip beserta kontennya; draft user lain dianggap tidak ada.
+// Arsip cold dikembalikan ke hot tier lebih dulu, atau ErrArchiveCold bila retrieval
+// berjalan async dan client harus menunggu retrieval job.
+func (s *ArchiveService) GetArchive(ctx context.Context, id, viewer string) (*domain.Archive, []byte, error) {
+	archive, err := s.repo.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !archive.VisibleTo(viewer) {
+		return nil, nil, domain.ErrArchiveNotFound
+	}
+	if archive.Expired(time.Now()) {
+		return nil, nil, domain.ErrAlreadyExpire
+	}
+
+	if archive.Cold() {
+		if s.cfg.ColdRetrieval == domain.RetrievalAsync {
+			return nil, nil, domain.ErrArchiveCold
+		}
+		// Rehydrate paralel ditolak; konten tetap terbaca dari cold store
+		_, err := s.repo.Rehydrate(ctx, id)
+		if err != nil && !errors.Is(err, domain.ErrTierConflict) && !errors.Is(err, domain.ErrNotCold) {
+			return nil, nil, err
+		}
+	}
+
+	archive, content, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Waktu akses dipakai kebijakan tiering; gagal mencatat tidak menggagalkan download
+	_ = s.repo.TouchAccess(ctx, id, time.Now())
+	return archive, content, nil
+}
+
+func (s *ArchiveService) ListArchives(ctx context.Context, filter domain.ArchiveFilter, fields []string, page, limit int) ([]domain.Archive, int64, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if err := domain.ValidateFields(fields); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindAll(ctx, filter, fields, page, limit)
+}
+
+func (s *ArchiveService) GetArchivesByIDs(ctx context.Context, ids []string, viewer string) ([]domain.Archive, error) {
+	archives, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]domain.Archive, 0, len(archives))
+	for _, archive := range archives {
+		if archive.VisibleTo(viewer) {
+			visible = append(visible, archive)
+		}
+	}
+	if len(visible) == 0 {
+		return nil, domain.ErrArchiveNotFound
+	}
+	return visible, nil
+}
+
+func (s *ArchiveService) DeleteArchive(ctx context.Context, id string, deleteType domain.DeleteType, userID string) error {
+	if _, err := deletableArchive(ctx, s.repo, id, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id, deleteType, userID); err != nil {
+		return err
+	}
+	s.publishID(ctx, domain.EventArchiveDeleted, id, map[string]interface{}{"delete_type": deleteType.String(), "deleted_by": userID})
+	return nil
+}
+
+// ScheduleDeletion menjadwalkan temp delete. ttl nol memakai masa tenggang default
+// dan ttl yang melebihi batas dipotong ke batas maksimum.
+func (s *ArchiveService) ScheduleDeletion(ctx context.Context, id string, ttl time.Duration, userID string) (*domain.Archive, error) {
+	if _, err := deletableArchive(ctx, s.repo, id, userID); err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		ttl = s.cfg.TempDeleteTTL
+	}
+	return s.repo.ScheduleDeletion(ctx, id, time.Now().Add(s.clampTempTTL(ttl)), userID)
+}
+
+// ExtendDeletion menunda jadwal penghapusan sebesar ttl dari expires_at saat ini
+func (s *ArchiveService) ExtendDeletion(ctx context.Context, id string, ttl time.Duration, userID string) (*domain.Archive, error) {
+	archive, err := activeArchive(ctx, s.repo, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !archive.IsTemp || archive.ExpiresAt == nil {
+		return nil, domain.ErrNotScheduled
+	}
+	if ttl <= 0 {
+		ttl = s.cfg.TempDeleteTTL
+	}
+
+	now := time.Now()
+	remaining := archive.ExpiresAt.Add(ttl).Sub(now)
+	return s.repo.ScheduleDeletion(ctx, id, now.Add(s.clampTempTTL(remaining)), userID)
+}
+
+func (s *ArchiveService) CancelDeletion(ctx context.Context, id, userID string) (*domain.Archive, error) {
+	if _, err := visibleArchive(ctx, s.repo, id, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.CancelDeletion(ctx, id, userID)
+}
+
+func (s *ArchiveService) clampTempTTL(ttl time.Duration) time.Duration {
+	if s.cfg.TempDeleteMaxTTL > 0 && ttl > s.cfg.TempDeleteMaxTTL {
+		return s.cfg.TempDeleteMaxTTL
+	}
+	return ttl
+}
+
+// RestoreArchive memulihkan arsip dari trash. strategy menentukan hasilnya bila nama arsip
+// sudah dipakai arsip aktif lain; kosong berarti restore ditolak dengan ErrRestoreConflict.
+func (s *ArchiveService) RestoreArchive(ctx context.Context, id, userID string, strategy domain.RestoreStrategy) (*domain.RestoreResult, error) {
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	return s.restore(ctx, id, userID, strategy)
+}
+
+func (s *ArchiveService) restore(ctx context.Context, id, userID string, strategy domain.RestoreStrategy) (*domain.RestoreResult, error) {
+	if _, err := visibleArchive(ctx, s.repo, id, userID); err != nil {
+		return nil, err
+	}
+	result, err := s.repo.RestoreArchive(ctx, id, userID, strategy)
+	if err != nil {
+		return result, err
+	}
+	s.publishRestored(ctx, id, userID, result)
+	return result, nil
 }
 
-func (s *ArchiveService) DeleteArchive(ctx context.Context, id string, deleteType domain.DeleteType) error {
-	exists, err := s.repo.Exists(ctx, id)
+// ListTrash menampilkan isi trash beserta tanggal penghapusan permanennya; draft user lain disembunyikan
+func (s *ArchiveService) ListTrash(ctx context.Context, viewer string, page, limit int) ([]domain.TrashItem, int64, error) {
+	archives, total, err := s.repo.FindTrash(ctx, viewer, page, limit)
 	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]domain.TrashItem, 0, len(archives))
+	for _, archive := range archives {
+		item := domain.TrashItem{Archive: archive}
+		if archive.DeletedAt != nil {
+			item.PurgeAt = archive.DeletedAt.Add(s.cfg.TrashRetention)
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+func (s *ArchiveService) RestoreArchives(ctx context.Context, ids []string, userID string, strategy domain.RestoreStrategy) ([]BulkItemResult, error) {
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	return runBulk(ids, func(id string) error {
+		_, err := s.restore(ctx, id, userID, strategy)
+		return err
+	}), nil
+}
+
+func (s *ArchiveService) publishRestored(ctx context.Context, id, userID string, result *domain.RestoreResult) {
+	data := map[string]interface{}{"restored_by": userID}
+	if result.Strategy != "" {
+		data["strategy"] = result.Strategy
+	}
+	// Pada strategi merge isi arsip menjadi versi baru arsip aktif, bukan arsip yang dipulihkan
+	if result.Archive != nil && result.Archive.ID.Hex() != id {
+		data["restored_id"] = id
+	}
+	s.publish(ctx, domain.EventArchiveRestored, result.Archive, data)
+}
+
+func (s *ArchiveService) PurgeArchive(ctx context.Context, id, userID string) error {
+	if _, err := deletableArchive(ctx, s.repo, id, userID); err != nil {
 		return err
 	}
-	if !exists {
-		return domain.ErrArchiveNotFound
+	if err := s.repo.Purge(ctx, id, userID); err != nil {
+		return err
+	}
+	s.publishPurged(ctx, []string{id}, userID)
+	return nil
+}
+
+func (s *ArchiveService) PurgeArchives(ctx context.Context, ids []string, userID string) []BulkItemResult {
+	return runBulk(ids, func(id string) error {
+		return s.PurgeArchive(ctx, id, userID)
+	})
+}
+
+// CleanupTrash menghapus permanen arsip yang sudah melewati masa retensi trash
+func (s *ArchiveService) CleanupTrash(ctx context.Context) ([]string, error) {
+	purged, err := s.repo.PurgeDeletedBefore(ctx, time.Now().Add(-s.cfg.TrashRetention))
+	s.publishPurged(ctx, purged, domain.SystemUserID)
+	return purged, err
+}
+
+func (s *ArchiveService) publishPurged(ctx context.Context, ids []string, userID string) {
+	for _, id := range ids {
+		s.publishID(ctx, domain.EventArchiveDeleted, id, map[string]interface{}{"delete_type": "purge", "deleted_by": userID})
 	}
+}
 
-	return s.repo.Delete(ctx, id, deleteType)
+func runBulk(ids []string, fn func(id string) error) []BulkItemResult {
+	results := make([]BulkItemResult, 0, len(ids))
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			results = append(results, BulkItemResult{ID: id, Status: "failed", Error: err.Error()})
+			continue
+		}
+		results = append(results, BulkItemResult{ID: id, Status: "success"})
+	}
+	return results
 }
 
-func (s *ArchiveService) RestoreArchive(ctx context.Context, id string) error {
-	return s.repo.RestoreArchive(ctx, id)
+// CleanupExpiredFiles menghapus arsip yang expires_at-nya sudah lewat dan mengembalikan ID-nya
+func (s *ArchiveService) CleanupExpiredFiles(ctx context.Context) ([]string, error) {
+	removed, err := s.repo.DeleteExpiredFiles(ctx)
+	s.publishExpired(ctx, removed, "expires_at")
+	return removed, err
 }
 
-func (s *ArchiveService) CleanupExpiredFiles(ctx context.Context) (int64, error) {
-	return s.repo.DeleteExpiredFiles(ctx)
+// CleanupTempFiles menghapus arsip temp lama yang tidak memiliki expires_at. Arsip
+// yang dijadwalkan lewat temp delete baru dihapus setelah expires_at-nya lewat.
+func (s *ArchiveService) CleanupTempFiles(ctx context.Context) ([]string, error) {
+	removed, err := s.repo.DeleteByFilter(ctx, tempFilesFilter(time.Now()))
+	s.publishExpired(ctx, removed, "temp")
+	return removed, err
+}
+
+func (s *ArchiveService) publishExpired(ctx context.Context, ids []string, reason string) {
+	for _, id := range ids {
+		s.publishID(ctx, domain.EventArchiveExpired, id, map[string]interface{}{"reason": reason})
+	}
 }
 
-func (s *ArchiveService) CleanupTempFiles(ctx context.Context) (int64, error) {
-	filter := bson.M{
-		"metadata.is_temp": true,
+func tempFilesFilter(now time.Time) bson.M {
+	return bson.M{
+		"metadata.is_temp":    true,
+		"metadata.expires_at": nil,
 		"metadata.created_at": bson.M{
-			"$lt": time.Now().Add(-24 * time.Hour),
+			"$lt": now.Add(-24 * time.Hour),
 		},
 	}
-	return s.repo.DeleteByFilter(ctx, filter)
 }
 
-func (s *ArchiveService) GetHistory(ctx context.Context, id string) (*domain.History, error) {
-	return s.repo.GetHistory(ctx, id)
+// PreviewExpiredFiles, PreviewTempFiles dan PreviewTrash mengembalikan arsip yang akan
+// dihapus oleh cleanup yang sesuai, paling banyak limit arsip
+func (s *ArchiveService) PreviewExpiredFiles(ctx context.Context, limit int) ([]domain.Archive, error) {
+	return s.repo.FindExpiredFiles(ctx, time.Now(), limit)
+}
+
+func (s *ArchiveService) PreviewTempFiles(ctx context.Context, limit int) ([]domain.Archive, error) {
+	return s.repo.FindByFilter(ctx, tempFilesFilter(time.Now()), limit)
+}
+
+func (s *ArchiveService) PreviewTrash(ctx context.Context, limit int) ([]domain.Archive, error) {
+	return s.repo.FindDeletedBefore(ctx, time.Now().Add(-s.cfg.TrashRetention), limit)
+}
+
+func (s *ArchiveService) GetHistory(ctx context.Context, id, viewer string) (*domain.History, error) {
+	if _, err := visibleArchive(ctx, s.repo, id, viewer); err != nil {
+		return nil, err
+	}
+	history, err := s.repo.GetHistory(ctx, id)
+	if err != nil || len(s.historySources) == 0 {
+		return history, err
+	}
+
+	for _, source := range s.historySources {
+		entries, err := source(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		history.Logs = append(history.Logs, entries...)
+	}
+	sort.SliceStable(history.Logs, func(i, j int) bool {
+		return history.Logs[i].Timestamp.After(history.Logs[j].Timestamp)
+	})
+	return history, nil
+}
+
+// AddHistorySource mendaftarkan sumber entri tambahan untuk GetHistory
+func (s *ArchiveService) AddHistorySource(source func(ctx context.Context, id string) ([]domain.HistoryEntry, error)) {
+	s.historySources = append(s.historySources, source)
+}
+
+// UseEvents mengirim event arsip ke publisher, misalnya webhook
+func (s *ArchiveService) UseEvents(publisher domain.EventPublisher) {
+	s.events = publisher
+}
+
+// publish mengirim event untuk arsip. Kegagalan mencatat event tidak menggagalkan
+// operasi arsip yang sudah berhasil.
+func (s *ArchiveService) publish(ctx context.Context, eventType string, archive *domain.Archive, extra map[string]interface{}) {
+	if s.events == nil || archive == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"id":       archive.ID.Hex(),
+		"name":     archive.Name,
+		"version":  archive.Version,
+		"size":     archive.Size,
+		"category": archive.Category,
+		"type":     archive.Type,
+		"tags":     archive.Tags,
+		"owner_id": archive.OwnerID,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	_ = s.events.Publish(ctx, domain.ArchiveEvent{Type: eventType, OccurredAt: time.Now(), Data: data})
+}
+
+// publishID mengirim event untuk arsip yang sudah tidak bisa dibaca lengkap, misalnya setelah dihapus
+func (s *ArchiveService) publishID(ctx context.Context, eventType, id string, extra map[string]interface{}) {
+	if s.events == nil {
+		return
+	}
+
+	data := map[string]interface{}{"id": id}
+	for key, value := range extra {
+		data[key] = value
+	}
+	_ = s.events.Publish(ctx, domain.ArchiveEvent{Type: eventType, OccurredAt: time.Now(), Data: data})
 }
 
-func (s *ArchiveService) GetByCategory(ctx context.Context, category string, page, limit int) ([]domain.Archive, int64, error) {
+// ListVersions menampilkan versi terbaru beserta revisi konten sebelumnya
+func (s *ArchiveService) ListVersions(ctx context.Context, id, viewer string) ([]domain.Archive, error) {
+	if _, err := visibleArchive(ctx, s.repo, id, viewer); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, id)
+}
+
+// RollbackArchive menjadikan revisi lama sebagai versi terbaru yang baru
+func (s *ArchiveService) RollbackArchive(ctx context.Context, id string, version int, userID string) (*domain.Archive, error) {
+	if _, err := visibleArchive(ctx, s.repo, id, userID); err != nil {
+		return nil, err
+	}
+	archive, err := s.repo.Rollback(ctx, id, version, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, domain.EventArchiveVersioned, archive, map[string]interface{}{"rollback_from": version})
+	return archive, nil
+}
+
+// CompareVersions membandingkan dua versi arsip, baik versi terbaru maupun revisi lama
+func (s *ArchiveService) CompareVersions(ctx context.Context, id string, from, to int, viewer string) (*domain.VersionDiff, error) {
+	if from < 1 || to < 1 {
+		return nil, domain.ErrInvalidVersion
+	}
+	if _, err := visibleArchive(ctx, s.repo, id, viewer); err != nil {
+		return nil, err
+	}
+
+	fromArchive, fromContent, err := s.repo.FindRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toArchive, toContent, err := s.repo.FindRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.comparer.Compare(fromArchive, toArchive, fromContent, toContent), nil
+}
+
+func (s *ArchiveService) GetByCategory(ctx context.Context, category string, includeDescendants bool, viewer string, page, limit int) ([]domain.Archive, int64, error) {
 	if category == "" {
 		return nil, 0, domain.ErrInvalidCategory
 	}
-	return s.repo.GetByCategory(ctx, category, page, limit)
+	return s.repo.GetByCategory(ctx, category, includeDescendants, viewer, page, limit)
+}
+
+// validateCategory memastikan kategori terdaftar di taksonomi dan masih aktif
+func (s *ArchiveService) validateCategory(ctx context.Context, path string) error {
+	return validateCategoryPath(ctx, s.categories, path)
+}
+
+func validateCategoryPath(ctx context.Context, categories domain.CategoryRepository, path string) error {
+	if path == "" {
+		return domain.ErrInvalidCategory
+	}
+
+	category, err := categories.FindByPath(ctx, path)
+	if err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return domain.ErrInvalidCategory
+		}
+		return err
+	}
+	if !category.IsActive() {
+		return domain.ErrCategoryDeprecated
+	}
+	return nil
 }
 
-func (s *ArchiveService) GetByTags(ctx context.Context, tags []string, page, limit int) ([]domain.Archive, int64, error) {
+func (s *ArchiveService) GetByTags(ctx context.Context, tags []string, viewer string, page, limit int) ([]domain.Archive, int64, error) {
 	if len(tags) == 0 {
 		return nil, 0, domain.ErrTagsRequired
 	}
-	return s.repo.GetByTags(ctx, tags, page, limit)
+	return s.repo.GetByTags(ctx, tags, viewer, page, limit)
 }
 
-func (s *ArchiveService) UpdateArchive(ctx context.Context, archive domain.Archive, content []byte) (*domain.Archive, error) {
-	return s.repo.SaveWithVersioning(ctx, archive, content)
+func (s *ArchiveService) GetArchiveInfo(ctx context.Context, id, viewer string) (*domain.Archive, error) {
+	return activeArchive(ctx, s.repo, id, viewer)
+}
+
+// UpdateArchive mengubah metadata arsip tanpa upload ulang file. Perubahan
+// dicatat sebagai revisi metadata, bukan versi konten baru.
+func (s *ArchiveService) UpdateArchive(ctx context.Context, id string, patch domain.ArchivePatch, userID string, cond domain.Precondition) (*domain.Archive, error) {
+	if _, err := s.GetArchiveInfo(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if patch.Category != nil {
+		if err := s.validateCategory(ctx, *patch.Category); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.UpdateMetadata(ctx, id, patch, userID, cond)
+}
+
+// Checkout mengunci arsip agar hanya userID yang bisa mengunggah versi baru atau
+// mengubah metadata. ttl nol memakai durasi default.
+func (s *ArchiveService) Checkout(ctx context.Context, id, userID string, ttl time.Duration) (*domain.Archive, error) {
+	if ttl <= 0 {
+		ttl = s.cfg.CheckoutTTL
+	}
+	if ttl > maxCheckoutTTL {
+		ttl = maxCheckoutTTL
+	}
+	if _, err := s.GetArchiveInfo(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Checkout(ctx, id, userID, ttl)
+}
+
+func (s *ArchiveService) Checkin(ctx context.Context, id, userID string) error {
+	if _, err := s.GetArchiveInfo(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.repo.Checkin(ctx, id, userID, false)
+}
+
+// BreakLock melepas lock milik user lain, hanya untuk admin
+func (s *ArchiveService) BreakLock(ctx context.Context, id, userID string) error {
+	if _, err := s.GetArchiveInfo(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.repo.Checkin(ctx, id, userID, true)
 }
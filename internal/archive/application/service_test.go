@@ -0,0 +1,115 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// uploadArchives menyimpan satu arsip per nama seperti versioning di repository asli
+type uploadArchives struct {
+	domain.ArchiveRepository
+	byName map[string]*domain.Archive
+	saves  int
+}
+
+func (r *uploadArchives) FindExistingArchive(_ context.Context, archive domain.Archive) (*domain.Archive, error) {
+	existing, ok := r.byName[archive.Name]
+	if !ok {
+		return nil, nil
+	}
+	copied := *existing
+	return &copied, nil
+}
+
+func (r *uploadArchives) SaveWithVersioning(_ context.Context, archive domain.Archive, _ []byte, _ domain.Precondition) (*domain.Archive, error) {
+	r.saves++
+	if existing, ok := r.byName[archive.Name]; ok {
+		existing.Version++
+		copied := *existing
+		return &copied, nil
+	}
+	archive.ID = primitive.NewObjectID()
+	archive.Version = 1
+	archive.State = domain.StateDraft
+	r.byName[archive.Name] = &archive
+	copied := archive
+	return &copied, nil
+}
+
+// activeCategories menganggap setiap path kategori ada dan aktif
+type activeCategories struct {
+	domain.CategoryRepository
+}
+
+func (activeCategories) FindByPath(_ context.Context, path string) (*domain.Category, error) {
+	return &domain.Category{Path: path, Status: domain.CategoryActive}, nil
+}
+
+type noFingerprint struct{}
+
+func (noFingerprint) Fingerprint([]byte) []uint64 {
+	return nil
+}
+
+func upload(service *ArchiveService, name, userID string) (*domain.UploadResult, error) {
+	return service.UploadArchive(context.Background(),
+		domain.FileContent{Name: name, Content: []byte("isi"), Size: 3},
+		domain.ArchiveMetadata{Category: "umum", Type: "pdf", OwnerID: userID},
+		domain.Precondition{})
+}
+
+func TestUploadSameNameAsOtherUsersDraftConflicts(t *testing.T) {
+	archives := &uploadArchives{byName: map[string]*domain.Archive{}}
+	service := NewArchiveService(archives, activeCategories{}, noFingerprint{}, nil, ArchiveServiceConfig{})
+
+	first, err := upload(service, "laporan.pdf", "alice")
+	if err != nil {
+		t.Fatalf("upload alice: %v", err)
+	}
+
+	result, err := upload(service, "laporan.pdf", "bob")
+	if !errors.Is(err, domain.ErrNameConflict) {
+		t.Fatalf("upload bob: expected ErrNameConflict, got %v", err)
+	}
+	if result != nil {
+		t.Fatalf("upload bob must not return the draft, got %s", result.Archive.ID.Hex())
+	}
+	if archives.saves != 1 {
+		t.Fatalf("expected only alice's upload to be saved, got %d saves", archives.saves)
+	}
+
+	// Pemilik draft tetap bisa mengunggah versi baru
+	second, err := upload(service, "laporan.pdf", "alice")
+	if err != nil {
+		t.Fatalf("second upload alice: %v", err)
+	}
+	if second.Archive.ID != first.Archive.ID || second.Archive.Version != 2 {
+		t.Fatalf("expected version 2 of %s, got version %d of %s",
+			first.Archive.ID.Hex(), second.Archive.Version, second.Archive.ID.Hex())
+	}
+	if second.Archive.OwnerID != "alice" {
+		t.Fatalf("expected owner alice, got %q", second.Archive.OwnerID)
+	}
+}
+
+func TestUploadSameNameAsPublishedArchiveAddsVersion(t *testing.T) {
+	archives := &uploadArchives{byName: map[string]*domain.Archive{}}
+	service := NewArchiveService(archives, activeCategories{}, noFingerprint{}, nil, ArchiveServiceConfig{})
+
+	if _, err := upload(service, "laporan.pdf", "alice"); err != nil {
+		t.Fatalf("upload alice: %v", err)
+	}
+	archives.byName["laporan.pdf"].State = domain.StateInReview
+
+	result, err := upload(service, "laporan.pdf", "bob")
+	if err != nil {
+		t.Fatalf("upload bob: %v", err)
+	}
+	if result.Archive.Version != 2 || result.Archive.OwnerID != "alice" {
+		t.Fatalf("expected version 2 owned by alice, got version %d owned by %q", result.Archive.Version, result.Archive.OwnerID)
+	}
+}
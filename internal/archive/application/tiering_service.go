@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// tieringBatchSize membatasi jumlah arsip yang dipindah ke cold tier per kali jalan;
+// sisanya dipindah pada putaran cleanup berikutnya
+const tieringBatchSize = 500
+
+type TieringService struct {
+	archives domain.ArchiveRepository
+	jobs     domain.RetrievalJobRepository
+	policy   domain.TierPolicy
+	// enabled false berarti cold store tidak dikonfigurasi
+	enabled bool
+	// instance adalah replika ini, dicatat sebagai pemilik retrieval job
+	instance string
+}
+
+func NewTieringService(archives domain.ArchiveRepository, jobs domain.RetrievalJobRepository, policy domain.TierPolicy, enabled bool) *TieringService {
+	return &TieringService{archives: archives, jobs: jobs, policy: policy, enabled: enabled}
+}
+
+// UseInstance mencatat replika ini sebagai pemilik retrieval job yang dibuatnya
+func (s *TieringService) UseInstance(instance string) {
+	s.instance = instance
+}
+
+// Run memindahkan arsip yang memenuhi kebijakan tiering ke cold tier. Kegagalan
+// per arsip dicatat dan tidak menghentikan arsip lainnya.
+func (s *TieringService) Run(ctx context.Context) (*domain.TieringRun, error) {
+	if !s.enabled {
+		return nil, domain.ErrColdStoreDisabled
+	}
+
+	candidates, err := s.archives.FindTierCandidates(ctx, s.policy, time.Now(), tieringBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &domain.TieringRun{Evaluated: len(candidates), Failures: []domain.BulkFailure{}}
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			return run, ctx.Err()
+		}
+		archive, err := s.archives.MoveToCold(ctx, candidate.ID.Hex())
+		if err != nil {
+			run.Failures = append(run.Failures, domain.BulkFailure{ID: candidate.ID.Hex(), Error: err.Error()})
+			continue
+		}
+		run.Moved++
+		run.MovedSize += archive.Size
+	}
+	return run, nil
+}
+
+// Candidates mengembalikan arsip yang akan dipindah pada Run berikutnya
+func (s *TieringService) Candidates(ctx context.Context) ([]domain.Archive, error) {
+	if !s.enabled {
+		return nil, domain.ErrColdStoreDisabled
+	}
+	return s.archives.FindTierCandidates(ctx, s.policy, time.Now(), tieringBatchSize)
+}
+
+// Retrieve membuat job untuk mengembalikan arsip cold ke hot tier. Bila sudah ada
+// job yang berjalan untuk arsip yang sama, job itu yang dikembalikan.
+func (s *TieringService) Retrieve(ctx context.Context, id, userID string) (*domain.RetrievalJob, error) {
+	if !s.enabled {
+		return nil, domain.ErrColdStoreDisabled
+	}
+
+	archive, err := s.archives.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !archive.Cold() {
+		return nil, domain.ErrNotCold
+	}
+
+	active, err := s.jobs.FindActive(ctx, archive.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return active, nil
+	}
+
+	now := time.Now()
+	job := &domain.RetrievalJob{
+		ID:          primitive.NewObjectID(),
+		ArchiveID:   archive.ID.Hex(),
+		Status:      domain.RetrievalPending,
+		RequestedBy: userID,
+		CreatedAt:   now,
+		Owner:       s.instance,
+		HeartbeatAt: &now,
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// Job tetap berjalan walaupun request HTTP sudah selesai
+	queued := *job
+	go s.retrieve(context.Background(), &queued)
+	return job, nil
+}
+
+func (s *TieringService) Job(ctx context.Context, id string) (*domain.RetrievalJob, error) {
+	return s.jobs.FindByID(ctx, id)
+}
+
+// RecoverInterrupted menandai retrieval job yang terputus sebagai gagal: milik replika ini
+// sebelum restart, atau milik replika lain yang berhenti mengirim tanda hidup
+func (s *TieringService) RecoverInterrupted(ctx context.Context) (int64, error) {
+	return s.jobs.FailInterrupted(ctx, s.instance, time.Now().Add(-jobStaleAfter))
+}
+
+func (s *TieringService) retrieve(ctx context.Context, job *domain.RetrievalJob) {
+	started := time.Now()
+	job.Status = domain.RetrievalRunning
+	job.StartedAt = &started
+	if err := s.jobs.Update(ctx, job); err != nil {
+		return
+	}
+
+	stop := heartbeat(ctx, jobHeartbeatInterval, func(ctx context.Context) {
+		_ = s.jobs.Heartbeat(ctx, job.ID, time.Now())
+	})
+	_, err := s.archives.Rehydrate(ctx, job.ArchiveID)
+	stop()
+	finished := time.Now()
+	job.FinishedAt = &finished
+	// Arsip yang sudah hot berarti sudah dikembalikan oleh download sinkron atau job lain
+	if err != nil && !errors.Is(err, domain.ErrNotCold) {
+		job.Status = domain.RetrievalFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = domain.RetrievalCompleted
+	}
+	_ = s.jobs.Update(ctx, job)
+}
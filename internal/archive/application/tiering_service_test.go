@@ -0,0 +1,284 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRetrievalJobs menyimpan retrieval job; dikunci karena job diperbarui dari goroutine
+type memoryRetrievalJobs struct {
+	domain.RetrievalJobRepository
+	mu   sync.Mutex
+	byID map[string]domain.RetrievalJob
+	// interruptedOwner dan staleBefore mencatat argumen FailInterrupted terakhir
+	interruptedOwner string
+	staleBefore      time.Time
+}
+
+func newMemoryRetrievalJobs() *memoryRetrievalJobs {
+	return &memoryRetrievalJobs{byID: map[string]domain.RetrievalJob{}}
+}
+
+func (r *memoryRetrievalJobs) Create(_ context.Context, job *domain.RetrievalJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID[job.ID.Hex()] = *job
+	return nil
+}
+
+func (r *memoryRetrievalJobs) FindByID(_ context.Context, id string) (*domain.RetrievalJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.byID[id]
+	if !ok {
+		return nil, domain.ErrRetrievalJobNotFound
+	}
+	return &job, nil
+}
+
+func (r *memoryRetrievalJobs) FindActive(_ context.Context, archiveID string) (*domain.RetrievalJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range r.byID {
+		if job.ArchiveID == archiveID && (job.Status == domain.RetrievalPending || job.Status == domain.RetrievalRunning) {
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRetrievalJobs) Update(_ context.Context, job *domain.RetrievalJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID[job.ID.Hex()] = *job
+	return nil
+}
+
+func (r *memoryRetrievalJobs) Heartbeat(context.Context, primitive.ObjectID, time.Time) error {
+	return nil
+}
+
+func (r *memoryRetrievalJobs) FailInterrupted(_ context.Context, owner string, staleBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interruptedOwner = owner
+	r.staleBefore = staleBefore
+	var failed int64
+	for id, job := range r.byID {
+		if job.Status == domain.RetrievalPending || job.Status == domain.RetrievalRunning {
+			job.Status = domain.RetrievalFailed
+			r.byID[id] = job
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// waitFinished menunggu job selesai dijalankan goroutine retrieve
+func (r *memoryRetrievalJobs) waitFinished(t *testing.T, id string) domain.RetrievalJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, _ := r.FindByID(context.Background(), id)
+		if job.Status == domain.RetrievalCompleted || job.Status == domain.RetrievalFailed {
+			return *job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("retrieval job %s did not finish", id)
+	return domain.RetrievalJob{}
+}
+
+// tierArchives meniru pemindahan tier di repository
+type tierArchives struct {
+	domain.ArchiveRepository
+	mu         sync.Mutex
+	byID       map[string]*domain.Archive
+	candidates []domain.Archive
+	// failMove dan failRehydrate membuat operasi untuk arsip tersebut gagal
+	failMove      map[string]error
+	failRehydrate map[string]error
+}
+
+func newTierArchives() *tierArchives {
+	return &tierArchives{byID: map[string]*domain.Archive{}, failMove: map[string]error{}, failRehydrate: map[string]error{}}
+}
+
+func (r *tierArchives) add(archive domain.Archive) *domain.Archive {
+	archive.ID = primitive.NewObjectID()
+	r.byID[archive.ID.Hex()] = &archive
+	return &archive
+}
+
+func (r *tierArchives) FindTierCandidates(_ context.Context, _ domain.TierPolicy, _ time.Time, limit int) ([]domain.Archive, error) {
+	if len(r.candidates) > limit {
+		return r.candidates[:limit], nil
+	}
+	return r.candidates, nil
+}
+
+func (r *tierArchives) MoveToCold(_ context.Context, id string) (*domain.Archive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.failMove[id]; err != nil {
+		return nil, err
+	}
+	archive := r.byID[id]
+	archive.Tier = domain.TierCold
+	moved := *archive
+	return &moved, nil
+}
+
+func (r *tierArchives) FindMetadata(_ context.Context, id string) (*domain.Archive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	archive, ok := r.byID[id]
+	if !ok {
+		return nil, domain.ErrArchiveNotFound
+	}
+	found := *archive
+	return &found, nil
+}
+
+func (r *tierArchives) Rehydrate(_ context.Context, id string) (*domain.Archive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.failRehydrate[id]; err != nil {
+		return nil, err
+	}
+	archive := r.byID[id]
+	if !archive.Cold() {
+		return nil, domain.ErrNotCold
+	}
+	archive.Tier = ""
+	rehydrated := *archive
+	return &rehydrated, nil
+}
+
+func TestTieringRunCountsMovedAndFailures(t *testing.T) {
+	archives := newTierArchives()
+	first := archives.add(domain.Archive{Name: "a.pdf", Size: 100})
+	second := archives.add(domain.Archive{Name: "b.pdf", Size: 50})
+	broken := archives.add(domain.Archive{Name: "c.pdf", Size: 70})
+	archives.candidates = []domain.Archive{*first, *broken, *second}
+	archives.failMove[broken.ID.Hex()] = domain.ErrTierConflict
+
+	run, err := NewTieringService(archives, newMemoryRetrievalJobs(), domain.TierPolicy{}, true).Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if run.Evaluated != 3 || run.Moved != 2 || run.MovedSize != 150 {
+		t.Fatalf("expected 2 of 3 moved with 150 bytes, got %+v", run)
+	}
+	if len(run.Failures) != 1 || run.Failures[0].ID != broken.ID.Hex() || run.Failures[0].Error != domain.ErrTierConflict.Error() {
+		t.Fatalf("expected the conflict to be reported, got %+v", run.Failures)
+	}
+
+	// Tanpa cold store tiering tidak berjalan sama sekali
+	disabled := NewTieringService(archives, newMemoryRetrievalJobs(), domain.TierPolicy{}, false)
+	if _, err := disabled.Run(context.Background()); !errors.Is(err, domain.ErrColdStoreDisabled) {
+		t.Fatalf("expected ErrColdStoreDisabled, got %v", err)
+	}
+	if _, err := disabled.Retrieve(context.Background(), first.ID.Hex(), "alice"); !errors.Is(err, domain.ErrColdStoreDisabled) {
+		t.Fatalf("expected ErrColdStoreDisabled, got %v", err)
+	}
+}
+
+func TestTieringRetrieveRunsInBackground(t *testing.T) {
+	archives := newTierArchives()
+	jobs := newMemoryRetrievalJobs()
+	service := NewTieringService(archives, jobs, domain.TierPolicy{}, true)
+	service.UseInstance("replika-1")
+	ctx := context.Background()
+
+	hot := archives.add(domain.Archive{Name: "hot.pdf"})
+	if _, err := service.Retrieve(ctx, hot.ID.Hex(), "alice"); !errors.Is(err, domain.ErrNotCold) {
+		t.Fatalf("expected ErrNotCold for a hot archive, got %v", err)
+	}
+
+	cold := archives.add(domain.Archive{Name: "cold.pdf", Tier: domain.TierCold})
+	job, err := service.Retrieve(ctx, cold.ID.Hex(), "alice")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if job.Status != domain.RetrievalPending || job.Owner != "replika-1" || job.RequestedBy != "alice" {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	finished := jobs.waitFinished(t, job.ID.Hex())
+	if finished.Status != domain.RetrievalCompleted || finished.StartedAt == nil || finished.FinishedAt == nil {
+		t.Fatalf("expected a completed job, got %+v", finished)
+	}
+	if current, _ := archives.FindMetadata(ctx, cold.ID.Hex()); current.Cold() {
+		t.Fatal("expected the archive to be hot after retrieval")
+	}
+}
+
+func TestTieringRetrieveReturnsActiveJob(t *testing.T) {
+	archives := newTierArchives()
+	jobs := newMemoryRetrievalJobs()
+	service := NewTieringService(archives, jobs, domain.TierPolicy{}, true)
+	cold := archives.add(domain.Archive{Name: "cold.pdf", Tier: domain.TierCold})
+
+	running := &domain.RetrievalJob{ID: primitive.NewObjectID(), ArchiveID: cold.ID.Hex(), Status: domain.RetrievalRunning}
+	jobs.Create(context.Background(), running)
+
+	job, err := service.Retrieve(context.Background(), cold.ID.Hex(), "bob")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if job.ID != running.ID || len(jobs.byID) != 1 {
+		t.Fatalf("expected the running job to be reused, got %s with %d jobs", job.ID.Hex(), len(jobs.byID))
+	}
+}
+
+func TestTieringRetrieveRecordsFailure(t *testing.T) {
+	archives := newTierArchives()
+	jobs := newMemoryRetrievalJobs()
+	service := NewTieringService(archives, jobs, domain.TierPolicy{}, true)
+	cold := archives.add(domain.Archive{Name: "cold.pdf", Tier: domain.TierCold})
+	archives.failRehydrate[cold.ID.Hex()] = domain.ErrColdObjectNotFound
+
+	job, err := service.Retrieve(context.Background(), cold.ID.Hex(), "alice")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	finished := jobs.waitFinished(t, job.ID.Hex())
+	if finished.Status != domain.RetrievalFailed || finished.Error != domain.ErrColdObjectNotFound.Error() {
+		t.Fatalf("expected a failed job with the rehydrate error, got %+v", finished)
+	}
+
+	// Arsip yang sudah dikembalikan oleh proses lain dianggap selesai
+	archives.failRehydrate[cold.ID.Hex()] = domain.ErrNotCold
+	job, err = service.Retrieve(context.Background(), cold.ID.Hex(), "alice")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if finished := jobs.waitFinished(t, job.ID.Hex()); finished.Status != domain.RetrievalCompleted {
+		t.Fatalf("expected an already hot archive to complete the job, got %+v", finished)
+	}
+}
+
+func TestTieringRecoverInterrupted(t *testing.T) {
+	jobs := newMemoryRetrievalJobs()
+	service := NewTieringService(newTierArchives(), jobs, domain.TierPolicy{}, true)
+	service.UseInstance("replika-1")
+	jobs.Create(context.Background(), &domain.RetrievalJob{ID: primitive.NewObjectID(), Status: domain.RetrievalRunning})
+	jobs.Create(context.Background(), &domain.RetrievalJob{ID: primitive.NewObjectID(), Status: domain.RetrievalCompleted})
+
+	failed, err := service.RecoverInterrupted(context.Background())
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if failed != 1 || jobs.interruptedOwner != "replika-1" {
+		t.Fatalf("expected one job failed for replika-1, got %d for %q", failed, jobs.interruptedOwner)
+	}
+	if age := time.Since(jobs.staleBefore); age < jobStaleAfter || age > jobStaleAfter+time.Minute {
+		t.Fatalf("expected jobs older than %s to be stale, got %s", jobStaleAfter, age)
+	}
+}
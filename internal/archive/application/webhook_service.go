@@ -0,0 +1,451 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxWebhookBackoff membatasi jeda antar percobaan kirim
+const maxWebhookBackoff = time.Hour
+
+// Header yang dikirim bersama setiap webhook
+const (
+	HeaderWebhookEvent     = "X-Archiven-Event"
+	HeaderWebhookDelivery  = "X-Archiven-Delivery"
+	HeaderWebhookTimestamp = "X-Archiven-Timestamp"
+	HeaderWebhookSignature = "X-Archiven-Signature"
+)
+
+// WebhookServiceConfig mengatur percobaan ulang dan worker pengiriman webhook
+type WebhookServiceConfig struct {
+	// MaxAttempts adalah jumlah percobaan sebelum delivery menjadi dead
+	MaxAttempts int
+	// BackoffBase adalah jeda setelah percobaan pertama gagal, dua kali lipat setiap percobaan berikutnya
+	BackoffBase time.Duration
+	// Timeout membatasi satu request ke URL langganan
+	Timeout time.Duration
+	// PollInterval adalah jarak worker memeriksa delivery yang jatuh tempo
+	PollInterval time.Duration
+	Workers      int
+}
+
+// WebhookService menyimpan langganan webhook, mencatat delivery untuk setiap event arsip
+// dan mengirimkannya lewat worker. Delivery disimpan di database, sehingga percobaan ulang
+// tetap berjalan setelah restart dan bisa dikerjakan replika mana pun.
+type WebhookService struct {
+	subscriptions domain.WebhookSubscriptionRepository
+	deliveries    domain.WebhookDeliveryRepository
+	sender        domain.WebhookSender
+	cfg           WebhookServiceConfig
+
+	mu         sync.Mutex
+	onDelivery []func(delivery *domain.WebhookDelivery, err error)
+	started    bool
+	stopped    bool
+
+	// wake membangunkan worker saat ada delivery baru tanpa menunggu PollInterval
+	wake   chan struct{}
+	base   context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewWebhookService(subscriptions domain.WebhookSubscriptionRepository, deliveries domain.WebhookDeliveryRepository,
+	sender domain.WebhookSender, cfg WebhookServiceConfig) *WebhookService {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	base, cancel := context.WithCancel(context.Background())
+	return &WebhookService{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		sender:        sender,
+		cfg:           cfg,
+		wake:          make(chan struct{}, cfg.Workers),
+		base:          base,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+}
+
+// OnDelivery mendaftarkan hook yang dipanggil setelah setiap percobaan kirim, misalnya
+// untuk logging. err berisi kegagalan kirim atau kegagalan menyimpan hasilnya; delivery
+// nil berarti antrean delivery gagal dibaca.
+func (s *WebhookService) OnDelivery(hook func(delivery *domain.WebhookDelivery, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDelivery = append(s.onDelivery, hook)
+}
+
+// Publish mencatat delivery event untuk setiap langganan aktif yang menerimanya.
+// Pengiriman dilakukan worker, jadi pemanggil tidak menunggu URL langganan.
+func (s *WebhookService) Publish(ctx context.Context, event domain.ArchiveEvent) error {
+	if event.ID == "" {
+		event.ID = primitive.NewObjectID().Hex()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	subscriptions, err := s.subscriptions.FindByEvent(ctx, event.Type)
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %v", err)
+	}
+
+	now := time.Now()
+	deliveries := make([]domain.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, newDelivery(subscription, event.ID, event.Type, string(payload), now))
+	}
+	if err := s.deliveries.CreateMany(ctx, deliveries); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+func newDelivery(subscription domain.WebhookSubscription, eventID, eventType, payload string, now time.Time) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		ID:             primitive.NewObjectID(),
+		SubscriptionID: subscription.ID.Hex(),
+		EventID:        eventID,
+		EventType:      eventType,
+		URL:            subscription.URL,
+		Payload:        payload,
+		Status:         domain.DeliveryPending,
+		Attempts:       []domain.WebhookAttempt{},
+		NextAttemptAt:  &now,
+		CreatedAt:      now,
+	}
+}
+
+// Create mendaftarkan langganan baru. Secret kosong berarti dibuatkan secara acak.
+func (s *WebhookService) Create(ctx context.Context, subscription domain.WebhookSubscription, userID string) (*domain.WebhookSubscription, error) {
+	subscription.URL = strings.TrimSpace(subscription.URL)
+	subscription.Events = uniqueEvents(subscription.Events)
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+
+	now := time.Now()
+	subscription.ID = primitive.NewObjectID()
+	subscription.Active = true
+	subscription.CreatedBy = userID
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+	if err := s.subscriptions.Create(ctx, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, page, limit int) ([]domain.WebhookSubscription, int64, error) {
+	return s.subscriptions.FindAll(ctx, page, limit)
+}
+
+func (s *WebhookService) Get(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	return s.subscriptions.FindByID(ctx, id)
+}
+
+func (s *WebhookService) Update(ctx context.Context, id string, patch domain.WebhookSubscriptionPatch) (*domain.WebhookSubscription, error) {
+	subscription, err := s.subscriptions.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.URL != nil {
+		subscription.URL = strings.TrimSpace(*patch.URL)
+	}
+	if patch.Events != nil {
+		subscription.Events = uniqueEvents(patch.Events)
+	}
+	if patch.Active != nil {
+		subscription.Active = *patch.Active
+	}
+	if patch.Description != nil {
+		subscription.Description = *patch.Description
+	}
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+
+	subscription.UpdatedAt = time.Now()
+	if err := s.subscriptions.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// Delete menghapus langganan; delivery yang masih pending akan menjadi dead saat dikirim
+func (s *WebhookService) Delete(ctx context.Context, id string) error {
+	return s.subscriptions.Delete(ctx, id)
+}
+
+// Deliveries menampilkan log delivery satu langganan
+func (s *WebhookService) Deliveries(ctx context.Context, subscriptionID string, status domain.DeliveryStatus, page, limit int) ([]domain.WebhookDelivery, int64, error) {
+	if err := status.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if _, err := s.subscriptions.FindByID(ctx, subscriptionID); err != nil {
+		return nil, 0, err
+	}
+	return s.deliveries.FindBySubscription(ctx, subscriptionID, status, page, limit)
+}
+
+func (s *WebhookService) Delivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	return s.deliveries.FindByID(ctx, id)
+}
+
+// Redeliver mengirim ulang payload delivery sebagai delivery baru ke URL langganan saat ini.
+// Event ID tetap sama agar penerima bisa mengenali event yang sudah pernah diproses.
+func (s *WebhookService) Redeliver(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	original, err := s.deliveries.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := s.subscriptions.FindByID(ctx, original.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := newDelivery(*subscription, original.EventID, original.EventType, original.Payload, time.Now())
+	delivery.RedeliveryOf = original.ID.Hex()
+	if err := s.deliveries.CreateMany(ctx, []domain.WebhookDelivery{delivery}); err != nil {
+		return nil, err
+	}
+	s.notify()
+	return &delivery, nil
+}
+
+// Start menjalankan worker pengiriman
+func (s *WebhookService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+		go s.loop()
+	}
+}
+
+// Stop menghentikan worker dan menunggu pengiriman yang berjalan sampai ctx habis.
+// Delivery yang terputus dikirim ulang setelah kuncinya lepas.
+func (s *WebhookService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-finished
+		return ctx.Err()
+	}
+}
+
+func (s *WebhookService) notify() {
+	for i := 0; i < s.cfg.Workers; i++ {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+func (s *WebhookService) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Kirim semua delivery yang jatuh tempo sebelum menunggu lagi
+		for s.deliverNext() {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverNext mengirim satu delivery yang jatuh tempo dan melaporkan apakah ada yang dikirim
+func (s *WebhookService) deliverNext() bool {
+	// Lease lebih panjang dari timeout kirim agar delivery tidak diambil worker lain saat masih dikirim
+	delivery, err := s.deliveries.ClaimDue(s.base, time.Now(), s.cfg.Timeout+30*time.Second)
+	if err != nil {
+		s.finish(nil, err)
+		return false
+	}
+	if delivery == nil {
+		return false
+	}
+
+	sendErr := s.attempt(delivery)
+	if err := s.deliveries.Update(s.base, delivery); err != nil {
+		sendErr = err
+	}
+	s.finish(delivery, sendErr)
+	return true
+}
+
+// attempt mengirim delivery sekali dan memperbarui status serta jadwal percobaan berikutnya
+func (s *WebhookService) attempt(delivery *domain.WebhookDelivery) error {
+	started := time.Now()
+	attempt := domain.WebhookAttempt{At: started}
+
+	var err error
+	subscription, findErr := s.subscriptions.FindByID(s.base, delivery.SubscriptionID)
+	switch {
+	case findErr != nil:
+		err = findErr
+	case !subscription.Active:
+		err = fmt.Errorf("webhook subscription is inactive")
+	default:
+		ctx, cancel := context.WithTimeout(s.base, s.cfg.Timeout)
+		attempt.StatusCode, err = s.sender.Send(ctx, s.request(subscription, delivery, started))
+		cancel()
+	}
+	attempt.DurationMS = time.Since(started).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.AttemptCount++
+	switch {
+	case err == nil:
+		now := time.Now()
+		delivery.Status = domain.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case findErr != nil || !subscription.Active || delivery.AttemptCount >= s.cfg.MaxAttempts:
+		// Langganan yang dihapus atau dinonaktifkan tidak dicoba lagi
+		delivery.Status = domain.DeliveryDead
+		delivery.NextAttemptAt = nil
+	default:
+		next := time.Now().Add(s.backoff(delivery.AttemptCount))
+		delivery.NextAttemptAt = &next
+	}
+	return err
+}
+
+// backoff mengembalikan jeda sebelum percobaan berikutnya setelah attempts kali gagal
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.cfg.BackoffBase
+	for i := 1; i < attempts && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxWebhookBackoff {
+		return maxWebhookBackoff
+	}
+	return delay
+}
+
+// request menandatangani payload dengan HMAC-SHA256 atas "timestamp.body" memakai secret langganan
+func (s *WebhookService) request(subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery, at time.Time) domain.WebhookRequest {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return domain.WebhookRequest{
+		URL:  subscription.URL,
+		Body: []byte(delivery.Payload),
+		Headers: map[string]string{
+			HeaderWebhookEvent:     delivery.EventType,
+			HeaderWebhookDelivery:  delivery.ID.Hex(),
+			HeaderWebhookTimestamp: timestamp,
+			HeaderWebhookSignature: "sha256=" + SignWebhook(subscription.Secret, timestamp, []byte(delivery.Payload)),
+		},
+	}
+}
+
+// SignWebhook menghitung tanda tangan hex yang dikirim di header X-Archiven-Signature.
+// Penerima menghitung ulang nilai ini untuk memverifikasi webhook.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) finish(delivery *domain.WebhookDelivery, err error) {
+	s.mu.Lock()
+	hooks := s.onDelivery
+	s.mu.Unlock()
+	for _, hook := range hooks {
+		hook(delivery, err)
+	}
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+func uniqueEvents(events []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if event == "" || seen[event] {
+			continue
+		}
+		seen[event] = true
+		unique = append(unique, event)
+	}
+	return unique
+}
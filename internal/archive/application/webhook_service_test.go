@@ -0,0 +1,331 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memorySubscriptions menyimpan langganan webhook di memori
+type memorySubscriptions struct {
+	domain.WebhookSubscriptionRepository
+	subscriptions []domain.WebhookSubscription
+}
+
+func (r *memorySubscriptions) FindByID(_ context.Context, id string) (*domain.WebhookSubscription, error) {
+	for _, subscription := range r.subscriptions {
+		if subscription.ID.Hex() == id {
+			copied := subscription
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (r *memorySubscriptions) FindByEvent(_ context.Context, eventType string) ([]domain.WebhookSubscription, error) {
+	var found []domain.WebhookSubscription
+	for _, subscription := range r.subscriptions {
+		if subscription.Active && subscription.Wants(eventType) {
+			found = append(found, subscription)
+		}
+	}
+	return found, nil
+}
+
+// memoryDeliveries meniru ClaimDue dan Update WebhookDeliveryRepository, termasuk lease-nya
+type memoryDeliveries struct {
+	mu         sync.Mutex
+	deliveries map[primitive.ObjectID]*domain.WebhookDelivery
+}
+
+func newMemoryDeliveries() *memoryDeliveries {
+	return &memoryDeliveries{deliveries: map[primitive.ObjectID]*domain.WebhookDelivery{}}
+}
+
+func (r *memoryDeliveries) CreateMany(_ context.Context, deliveries []domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range deliveries {
+		stored := delivery
+		r.deliveries[stored.ID] = &stored
+	}
+	return nil
+}
+
+func (r *memoryDeliveries) FindByID(_ context.Context, id string) (*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range r.deliveries {
+		if delivery.ID.Hex() == id {
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrWebhookDeliveryNotFound
+}
+
+func (r *memoryDeliveries) FindBySubscription(context.Context, string, domain.DeliveryStatus, int, int) ([]domain.WebhookDelivery, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *memoryDeliveries) ClaimDue(_ context.Context, now time.Time, lease time.Duration) (*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range r.deliveries {
+		if delivery.Status != domain.DeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		if delivery.LockedUntil != nil && delivery.LockedUntil.After(now) {
+			continue
+		}
+		until := now.Add(lease)
+		delivery.LockedUntil = &until
+		copied := *delivery
+		copied.Attempts = append([]domain.WebhookAttempt{}, delivery.Attempts...)
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryDeliveries) Update(_ context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.deliveries[delivery.ID]
+	if !ok || stored.LockedUntil == nil || delivery.LockedUntil == nil || !stored.LockedUntil.Equal(*delivery.LockedUntil) {
+		return domain.ErrWebhookLeaseLost
+	}
+	copied := *delivery
+	copied.LockedUntil = nil
+	r.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+// due membuat delivery yang menunggu backoff langsung jatuh tempo
+func (r *memoryDeliveries) due() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	past := time.Now().Add(-time.Second)
+	for _, delivery := range r.deliveries {
+		if delivery.NextAttemptAt != nil {
+			delivery.NextAttemptAt = &past
+		}
+	}
+}
+
+func (r *memoryDeliveries) only(t *testing.T) domain.WebhookDelivery {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(r.deliveries))
+	}
+	for _, delivery := range r.deliveries {
+		return *delivery
+	}
+	return domain.WebhookDelivery{}
+}
+
+// webhookReceiver adalah penerima webhook lokal yang mencatat setiap request
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, status int) (*webhookReceiver, *httptest.Server) {
+	t.Helper()
+	receiver := &webhookReceiver{status: status}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := receiver.status
+		receiver.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return receiver, server
+}
+
+func (r *webhookReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook{}, r.requests...)
+}
+
+func newTestWebhookService(url string, cfg WebhookServiceConfig) (*WebhookService, *memoryDeliveries, domain.WebhookSubscription) {
+	subscription := domain.WebhookSubscription{
+		ID:     primitive.NewObjectID(),
+		URL:    url,
+		Events: []string{domain.EventAll},
+		Secret: "whsec_test",
+		Active: true,
+	}
+	deliveries := newMemoryDeliveries()
+	cfg.Timeout = 5 * time.Second
+	cfg.PollInterval = time.Minute
+	service := NewWebhookService(&memorySubscriptions{subscriptions: []domain.WebhookSubscription{subscription}},
+		deliveries, infrastructure.NewHTTPWebhookSender(cfg.Timeout), cfg)
+	return service, deliveries, subscription
+}
+
+func publishUploaded(t *testing.T, service *WebhookService) domain.ArchiveEvent {
+	t.Helper()
+	event := domain.ArchiveEvent{
+		ID:   primitive.NewObjectID().Hex(),
+		Type: domain.EventArchiveUploaded,
+		Data: map[string]interface{}{"id": "archive-1", "name": "laporan.pdf"},
+	}
+	if err := service.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	return event
+}
+
+func TestWebhookDeliverySignedWithSubscriptionSecret(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusNoContent)
+	service, deliveries, subscription := newTestWebhookService(server.URL, WebhookServiceConfig{MaxAttempts: 3, BackoffBase: time.Second})
+	event := publishUploaded(t, service)
+
+	if !service.deliverNext() {
+		t.Fatal("expected a due delivery")
+	}
+
+	requests := receiver.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	timestamp := req.header.Get(HeaderWebhookTimestamp)
+	want := "sha256=" + SignWebhook(subscription.Secret, timestamp, req.body)
+	if got := req.header.Get(HeaderWebhookSignature); got != want {
+		t.Fatalf("signature %q, want %q", got, want)
+	}
+	if got := req.header.Get(HeaderWebhookEvent); got != domain.EventArchiveUploaded {
+		t.Fatalf("event header %q", got)
+	}
+
+	var body domain.ArchiveEvent
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.ID != event.ID {
+		t.Fatalf("event id %q, want %q", body.ID, event.ID)
+	}
+
+	delivery := deliveries.only(t)
+	if delivery.Status != domain.DeliveryDelivered || delivery.AttemptCount != 1 || delivery.LockedUntil != nil {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+	if got := req.header.Get(HeaderWebhookDelivery); got != delivery.ID.Hex() {
+		t.Fatalf("delivery header %q, want %q", got, delivery.ID.Hex())
+	}
+}
+
+func TestWebhookDeliveryRetriesWithBackoffUntilDead(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusInternalServerError)
+	base := time.Minute
+	service, deliveries, _ := newTestWebhookService(server.URL, WebhookServiceConfig{MaxAttempts: 3, BackoffBase: base})
+	publishUploaded(t, service)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if !service.deliverNext() {
+			t.Fatalf("attempt %d: expected a due delivery", attempt)
+		}
+		delivery := deliveries.only(t)
+		if delivery.AttemptCount != attempt || len(delivery.Attempts) != attempt {
+			t.Fatalf("attempt %d: recorded %d attempts (%d in log)", attempt, delivery.AttemptCount, len(delivery.Attempts))
+		}
+		last := delivery.Attempts[attempt-1]
+		if last.StatusCode != http.StatusInternalServerError || last.Error == "" {
+			t.Fatalf("attempt %d: unexpected attempt log %+v", attempt, last)
+		}
+
+		if attempt < 3 {
+			// Jeda berlipat dua setiap percobaan gagal
+			wait := base << (attempt - 1)
+			if delivery.Status != domain.DeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.Sub(last.At) < wait {
+				t.Fatalf("attempt %d: expected retry after at least %s, got %+v", attempt, wait, delivery)
+			}
+			// Sebelum backoff habis delivery tidak dikirim lagi
+			if service.deliverNext() {
+				t.Fatalf("attempt %d: delivery retried before its backoff", attempt)
+			}
+			deliveries.due()
+			continue
+		}
+		if delivery.Status != domain.DeliveryDead || delivery.NextAttemptAt != nil {
+			t.Fatalf("expected dead delivery after max attempts, got %+v", delivery)
+		}
+	}
+
+	deliveries.due()
+	if service.deliverNext() {
+		t.Fatal("dead delivery must not be sent again")
+	}
+	if got := len(receiver.received()); got != 3 {
+		t.Fatalf("receiver got %d requests, want 3", got)
+	}
+}
+
+func TestWebhookRedeliveryKeepsEventID(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusBadGateway)
+	service, deliveries, _ := newTestWebhookService(server.URL, WebhookServiceConfig{MaxAttempts: 1, BackoffBase: time.Second})
+	event := publishUploaded(t, service)
+
+	service.deliverNext()
+	dead := deliveries.only(t)
+	if dead.Status != domain.DeliveryDead {
+		t.Fatalf("expected dead delivery, got %s", dead.Status)
+	}
+
+	receiver.setStatus(http.StatusOK)
+	redelivery, err := service.Redeliver(context.Background(), dead.ID.Hex())
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivery.ID == dead.ID || redelivery.EventID != event.ID || redelivery.RedeliveryOf != dead.ID.Hex() {
+		t.Fatalf("unexpected redelivery %+v", redelivery)
+	}
+	if !service.deliverNext() {
+		t.Fatal("expected the redelivery to be due")
+	}
+
+	requests := receiver.received()
+	if len(requests) != 2 {
+		t.Fatalf("receiver got %d requests, want 2", len(requests))
+	}
+	if string(requests[0].body) != string(requests[1].body) {
+		t.Fatalf("redelivered payload differs:\n%s\n%s", requests[0].body, requests[1].body)
+	}
+	if got := requests[1].header.Get(HeaderWebhookDelivery); got != redelivery.ID.Hex() {
+		t.Fatalf("delivery header %q, want %q", got, redelivery.ID.Hex())
+	}
+	stored, err := deliveries.FindByID(context.Background(), redelivery.ID.Hex())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.Status != domain.DeliveryDelivered || stored.EventID != event.ID {
+		t.Fatalf("unexpected stored redelivery %+v", stored)
+	}
+}
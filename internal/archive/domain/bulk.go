@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxTags sama dengan batas tag saat upload
+const MaxTags = 5
+
+// DescriptionReplace mengganti pola pada deskripsi; Regex false berarti teks biasa
+type DescriptionReplace struct {
+	Pattern     string `bson:"pattern" json:"pattern"`
+	Replacement string `bson:"replacement" json:"replacement"`
+	Regex       bool   `bson:"regex" json:"regex"`
+}
+
+// BulkEditOperation adalah perubahan metadata yang diterapkan ke setiap arsip target
+type BulkEditOperation struct {
+	AddTags            []string            `bson:"add_tags,omitempty" json:"add_tags,omitempty"`
+	RemoveTags         []string            `bson:"remove_tags,omitempty" json:"remove_tags,omitempty"`
+	SetCategory        *string             `bson:"set_category,omitempty" json:"set_category,omitempty"`
+	SetType            *string             `bson:"set_type,omitempty" json:"set_type,omitempty"`
+	ReplaceDescription *DescriptionReplace `bson:"replace_description,omitempty" json:"replace_description,omitempty"`
+}
+
+func (op BulkEditOperation) Validate() error {
+	if len(op.AddTags) == 0 && len(op.RemoveTags) == 0 && op.SetCategory == nil &&
+		op.SetType == nil && op.ReplaceDescription == nil {
+		return ErrEmptyBulkOperation
+	}
+	if op.SetType != nil && strings.TrimSpace(*op.SetType) == "" {
+		return ErrInvalidBulkOperation
+	}
+	if r := op.ReplaceDescription; r != nil {
+		if r.Pattern == "" {
+			return ErrInvalidBulkOperation
+		}
+		if r.Regex {
+			if _, err := regexp.Compile(r.Pattern); err != nil {
+				return ErrInvalidBulkOperation
+			}
+		}
+	}
+	return nil
+}
+
+// Patch menghitung perubahan untuk satu arsip. Field yang hasilnya sama dengan
+// nilai sekarang tidak dimasukkan ke patch; changed false berarti arsip dilewati.
+func (op BulkEditOperation) Patch(a *Archive) (patch ArchivePatch, changed bool, err error) {
+	if len(op.AddTags) > 0 || len(op.RemoveTags) > 0 {
+		tags := applyTagChanges(a.Tags, op.AddTags, op.RemoveTags)
+		if !sameTags(tags, a.Tags) {
+			if len(tags) > MaxTags {
+				return patch, false, ErrTooManyTags
+			}
+			patch.Tags = &tags
+			changed = true
+		}
+	}
+	if op.SetCategory != nil && *op.SetCategory != a.Category {
+		patch.Category = op.SetCategory
+		changed = true
+	}
+	if op.SetType != nil && *op.SetType != a.Type {
+		patch.Type = op.SetType
+		changed = true
+	}
+	if r := op.ReplaceDescription; r != nil {
+		var description string
+		if r.Regex {
+			description = regexp.MustCompile(r.Pattern).ReplaceAllString(a.Description, r.Replacement)
+		} else {
+			description = strings.ReplaceAll(a.Description, r.Pattern, r.Replacement)
+		}
+		if description != a.Description {
+			patch.Description = &description
+			changed = true
+		}
+	}
+	return patch, changed, nil
+}
+
+func applyTagChanges(current, add, remove []string) []string {
+	removed := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		removed[tag] = true
+	}
+
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, tag := range append(append([]string{}, current...), add...) {
+		if removed[tag] || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type BulkJobStatus string
+
+const (
+	BulkJobPending   BulkJobStatus = "pending"
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobCompleted BulkJobStatus = "completed"
+	BulkJobFailed    BulkJobStatus = "failed"
+)
+
+// BulkFailure adalah arsip yang gagal diproses beserta alasannya
+type BulkFailure struct {
+	ID    string `bson:"id" json:"id"`
+	Error string `bson:"error" json:"error"`
+}
+
+// BulkJob mencatat progres operasi massal yang berjalan di background
+type BulkJob struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Type       string             `bson:"type" json:"type"`
+	Status     BulkJobStatus      `bson:"status" json:"status"`
+	IDs        []string           `bson:"ids,omitempty" json:"ids,omitempty"`
+	Filter     *ArchiveFilter     `bson:"filter,omitempty" json:"filter,omitempty"`
+	Operation  BulkEditOperation  `bson:"operation" json:"operation"`
+	Total      int                `bson:"total" json:"total"`
+	Processed  int                `bson:"processed" json:"processed"`
+	Succeeded  int                `bson:"succeeded" json:"succeeded"`
+	Skipped    int                `bson:"skipped" json:"skipped"`
+	Failed     int                `bson:"failed" json:"failed"`
+	Failures   []BulkFailure      `bson:"failures" json:"failures"`
+	Error      string             `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedBy  string             `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	StartedAt  *time.Time         `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+// BulkJobMetadataEdit adalah jenis job untuk edit metadata massal
+const BulkJobMetadataEdit = "metadata_edit"
+
+type BulkJobRepository interface {
+	Create(ctx context.Context, job *BulkJob) error
+	FindByID(ctx context.Context, id string) (*BulkJob, error)
+	FindByUser(ctx context.Context, userID string, page, limit int) ([]BulkJob, int64, error)
+	Update(ctx context.Context, job *BulkJob) error
+	// FailInterrupted menandai job yang masih berjalan saat aplikasi berhenti sebagai gagal
+	FailInterrupted(ctx context.Context) (int64, error)
+}
+
+var (
+	ErrBulkJobNotFound      = errors.New("bulk job not found")
+	ErrEmptyBulkOperation   = errors.New("bulk operation has no changes")
+	ErrInvalidBulkOperation = errors.New("invalid bulk operation")
+	ErrBulkTargetRequired   = errors.New("either ids or filter is required")
+	ErrBulkTooManyItems     = errors.New("too many archives for one bulk job")
+	ErrTooManyTags          = errors.New("too many tags, maximum 5 allowed")
+)
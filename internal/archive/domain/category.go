@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryPathSeparator memisahkan kode kategori pada path, misal "finance.invoices".
+const CategoryPathSeparator = "."
+
+type CategoryStatus string
+
+const (
+	CategoryActive     CategoryStatus = "active"
+	CategoryDeprecated CategoryStatus = "deprecated"
+)
+
+type Category struct {
+	ID           primitive.ObjectID   `bson:"_id" json:"id"`
+	Code         string               `bson:"code" json:"code"`
+	Name         string               `bson:"name" json:"name"`
+	Description  string               `bson:"description" json:"description"`
+	ParentID     *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id"`
+	Ancestors    []primitive.ObjectID `bson:"ancestors" json:"ancestors"`
+	Path         string               `bson:"path" json:"path"`
+	Status       CategoryStatus       `bson:"status" json:"status"`
+	CreatedBy    string               `bson:"created_by" json:"created_by"`
+	CreatedAt    time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time            `bson:"updated_at" json:"updated_at"`
+	DeprecatedAt *time.Time           `bson:"deprecated_at,omitempty" json:"deprecated_at,omitempty"`
+	Relocation   *CategoryRelocation  `bson:"relocation,omitempty" json:"relocation,omitempty"`
+	Children     []*Category          `bson:"-" json:"children,omitempty"`
+}
+
+// CategoryRelocation mencatat rename/move yang belum selesai. Dicatat bersama path baru
+// kategori sebelum turunan dan arsip dipindahkan, sehingga proses yang terputus bisa
+// dilanjutkan. Entri pertama Paths adalah kategori itu sendiri.
+type CategoryRelocation struct {
+	Paths     []CategoryPathChange `bson:"paths" json:"paths"`
+	StartedBy string               `bson:"started_by" json:"started_by"`
+	StartedAt time.Time            `bson:"started_at" json:"started_at"`
+}
+
+type CategoryPathChange struct {
+	From string `bson:"from" json:"from"`
+	To   string `bson:"to" json:"to"`
+}
+
+func (c *Category) IsActive() bool {
+	return c.Status == CategoryActive
+}
+
+// ChildPath menghasilkan path untuk kategori dengan kode tertentu di bawah parent.
+func ChildPath(parent *Category, code string) string {
+	if parent == nil {
+		return code
+	}
+	return parent.Path + CategoryPathSeparator + code
+}
+
+// IsDescendantPath memeriksa apakah path berada di bawah ancestor, tidak termasuk ancestor itu sendiri.
+func IsDescendantPath(path, ancestor string) bool {
+	return strings.HasPrefix(path, ancestor+CategoryPathSeparator)
+}
+
+var categoryCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+func ValidateCategoryCode(code string) error {
+	if !categoryCodePattern.MatchString(code) {
+		return ErrInvalidCategoryCode
+	}
+	return nil
+}
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *Category) error
+	FindByID(ctx context.Context, id string) (*Category, error)
+	FindByPath(ctx context.Context, path string) (*Category, error)
+	FindAll(ctx context.Context, includeDeprecated bool) ([]Category, error)
+	FindDescendants(ctx context.Context, id primitive.ObjectID) ([]Category, error)
+	Update(ctx context.Context, category *Category) error
+}
+
+var (
+	ErrCategoryNotFound     = errors.New("category not found")
+	ErrCategoryExists       = errors.New("category already exists")
+	ErrCategoryDeprecated   = errors.New("category is deprecated")
+	ErrInvalidCategoryCode  = errors.New("invalid category code")
+	ErrInvalidCategoryMove  = errors.New("category cannot be moved below itself")
+	ErrCategoryNameRequired = errors.New("category name is required")
+)
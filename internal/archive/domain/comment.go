@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event pada riwayat komentar, juga dipakai sebagai action di GetHistory
+const (
+	CommentCreated  = "comment"
+	CommentEdited   = "comment_edit"
+	CommentDeleted  = "comment_delete"
+	CommentResolved = "comment_resolve"
+	CommentReopened = "comment_reopen"
+)
+
+// AnnotationRect adalah area pada halaman PDF dalam satuan point, dengan titik
+// (0,0) di pojok kiri bawah seperti koordinat PDF
+type AnnotationRect struct {
+	X      float64 `bson:"x" json:"x"`
+	Y      float64 `bson:"y" json:"y"`
+	Width  float64 `bson:"width" json:"width"`
+	Height float64 `bson:"height" json:"height"`
+}
+
+// CommentAnchor mengaitkan komentar ke versi tertentu, dan opsional ke halaman/area
+type CommentAnchor struct {
+	Version int             `bson:"version" json:"version"`
+	Page    int             `bson:"page,omitempty" json:"page,omitempty"`
+	Rect    *AnnotationRect `bson:"rect,omitempty" json:"rect,omitempty"`
+}
+
+func (a *CommentAnchor) Validate() error {
+	if a.Version < 1 || a.Page < 0 {
+		return ErrInvalidAnchor
+	}
+	if a.Rect != nil && (a.Page == 0 || a.Rect.Width <= 0 || a.Rect.Height <= 0 || a.Rect.X < 0 || a.Rect.Y < 0) {
+		return ErrInvalidAnchor
+	}
+	return nil
+}
+
+// CommentEvent adalah satu entri riwayat komentar; Body berisi isi setelah event
+type CommentEvent struct {
+	Action string    `bson:"action" json:"action"`
+	Body   string    `bson:"body" json:"body"`
+	UserID string    `bson:"user_id" json:"user_id"`
+	At     time.Time `bson:"at" json:"at"`
+}
+
+type Comment struct {
+	ID        primitive.ObjectID  `bson:"_id" json:"id"`
+	ArchiveID primitive.ObjectID  `bson:"archive_id" json:"archive_id"`
+	ParentID  *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Body      string              `bson:"body" json:"body"`
+	Mentions  []string            `bson:"mentions" json:"mentions"`
+	Anchor    *CommentAnchor      `bson:"anchor,omitempty" json:"anchor,omitempty"`
+	AuthorID  string              `bson:"author_id" json:"author_id"`
+	Resolved  bool                `bson:"resolved" json:"resolved"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	History   []CommentEvent      `bson:"history" json:"history"`
+	Replies   []*Comment          `bson:"-" json:"replies,omitempty"`
+}
+
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// Record menambahkan event ke riwayat komentar
+func (c *Comment) Record(action, userID string, at time.Time) {
+	c.History = append(c.History, CommentEvent{Action: action, Body: c.Body, UserID: userID, At: at})
+	c.UpdatedAt = at
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9][A-Za-z0-9_.-]*)`)
+
+// ParseMentions mengambil user ID unik yang di-mention dengan @user_id
+func ParseMentions(body string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// Titik di akhir biasanya tanda baca, bukan bagian user ID
+		user := strings.TrimRight(match[1], ".")
+		if user == "" || seen[user] {
+			continue
+		}
+		seen[user] = true
+		mentions = append(mentions, user)
+	}
+	return mentions
+}
+
+// AnnotationExport berisi semua anotasi halaman untuk satu versi arsip
+type AnnotationExport struct {
+	ArchiveID   string    `json:"archive_id"`
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	ExportedAt  time.Time `json:"exported_at"`
+	Annotations []Comment `json:"annotations"`
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *Comment) error
+	FindByID(ctx context.Context, id string) (*Comment, error)
+	FindByArchive(ctx context.Context, archiveID primitive.ObjectID) ([]Comment, error)
+	FindAnnotations(ctx context.Context, archiveID primitive.ObjectID, version int) ([]Comment, error)
+	Update(ctx context.Context, comment *Comment) error
+}
+
+var (
+	ErrCommentNotFound     = errors.New("comment not found")
+	ErrCommentBodyRequired = errors.New("comment body is required")
+	ErrCommentForbidden    = errors.New("only the author can change this comment")
+	ErrCommentDeleted      = errors.New("comment is deleted")
+	ErrInvalidAnchor       = errors.New("invalid annotation anchor")
+	ErrInvalidReply        = errors.New("reply must belong to a thread on the same archive")
+	ErrResolveReply        = errors.New("only top-level comments can be resolved")
+)
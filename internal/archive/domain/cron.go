@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronDescriptors adalah singkatan jadwal yang umum dipakai
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSearchLimit membatasi pencarian jadwal berikutnya, misalnya untuk 30 Februari
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// CronSchedule adalah jadwal cron lima field (menit jam tanggal bulan hari) atau
+// interval tetap lewat "@every <durasi>". Waktu dihitung dalam zona waktu server.
+type CronSchedule struct {
+	expr   string
+	every  time.Duration
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domAny dan dowAny hanya menandai field yang persis "*"; "*/2" dihitung sebagai
+	// batasan. Bila tanggal dan hari sama-sama dibatasi, cukup salah satu yang cocok
+	// seperti cron Vixie, jadi "0 0 */2 * 1" jatuh pada tanggal ganjil atau hari Senin.
+	domAny bool
+	dowAny bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var (
+	cronMinute = cronField{0, 59}
+	cronHour   = cronField{0, 23}
+	cronDom    = cronField{1, 31}
+	cronMonth  = cronField{1, 12}
+	// Minggu boleh ditulis 0 atau 7
+	cronDow = cronField{0, 7}
+)
+
+// ParseCron membaca ekspresi seperti "0 3 * * *", "*/15 * * * 1-5", "@daily" atau "@every 30m"
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	schedule := &CronSchedule{expr: expr}
+
+	if strings.HasPrefix(expr, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil || every < time.Minute {
+			return nil, ErrInvalidCronExpression
+		}
+		schedule.every = every
+		return schedule, nil
+	}
+	if full, ok := cronDescriptors[expr]; ok {
+		expr = full
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, ErrInvalidCronExpression
+	}
+
+	var err error
+	if schedule.minute, err = cronMinute.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = cronHour.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = cronDom.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = cronMonth.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = cronDow.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domAny = fields[2] == "*"
+	schedule.dowAny = fields[4] == "*"
+
+	// Jadwal yang tidak pernah jatuh, misalnya "0 0 30 2 *", ditolak sejak awal
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, ErrInvalidCronExpression
+	}
+	return schedule, nil
+}
+
+// parse mengubah satu field menjadi bitset. Mendukung "*", angka, rentang "a-b",
+// langkah "*/n" atau "a-b/n", dan daftar yang dipisah koma.
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, ErrInvalidCronExpression
+			}
+			rangePart, step = part[:i], n
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			start, err1 = strconv.Atoi(bounds[0])
+			end, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, ErrInvalidCronExpression
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, ErrInvalidCronExpression
+			}
+			start, end = n, n
+			// "5/10" berarti mulai dari 5 sampai akhir dengan langkah 10
+			if step > 1 {
+				end = f.max
+			}
+		}
+		if start < f.min || end > f.max || start > end {
+			return 0, ErrInvalidCronExpression
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next mengembalikan waktu jadwal pertama setelah after, atau waktu nol bila tidak ada
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	if c.every > 0 {
+		return after.Add(c.every)
+	}
+
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (c *CronSchedule) String() string {
+	return c.expr
+}
+
+var ErrInvalidCronExpression = errors.New("invalid cron expression")
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 1 Maret 2024 jatuh pada hari Jumat
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"daily descriptor", "@daily", at(3, 10, 15, 4), at(3, 11, 0, 0)},
+		{"hourly descriptor", "@hourly", at(3, 10, 15, 4), at(3, 10, 16, 0)},
+		{"weekly descriptor runs on sunday", "@weekly", at(3, 13, 8, 0), at(3, 17, 0, 0)},
+		{"monthly descriptor", "@monthly", at(2, 15, 0, 0), at(3, 1, 0, 0)},
+		{"every interval", "@every 90m", at(3, 10, 15, 4), at(3, 10, 16, 34)},
+		{"minute step", "*/15 * * * *", at(3, 10, 10, 7), at(3, 10, 10, 15)},
+		{"exact minute is skipped", "*/15 * * * *", at(3, 10, 10, 15), at(3, 10, 10, 30)},
+		{"range with step", "0 9-17/4 * * *", at(3, 10, 10, 0), at(3, 10, 13, 0)},
+		{"start with step", "5/20 * * * *", at(3, 10, 10, 30), at(3, 10, 10, 45)},
+		{"list", "0 8,20 * * *", at(3, 10, 9, 0), at(3, 10, 20, 0)},
+		{"weekday range", "0 7 * * 1-5", at(3, 9, 12, 0), at(3, 11, 7, 0)},
+		{"seven is sunday", "30 2 * * 7", at(3, 13, 8, 0), at(3, 17, 2, 30)},
+		{"zero is sunday", "30 2 * * 0", at(3, 13, 8, 0), at(3, 17, 2, 30)},
+		{"leap day", "0 0 29 2 *", at(3, 1, 0, 0), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Tanggal dan hari sama-sama dibatasi: cukup salah satu yang cocok
+		{"odd day before monday", "0 0 */2 * 1", at(3, 1, 0, 0), at(3, 3, 0, 0)},
+		{"monday on even day", "0 0 */2 * 1", at(3, 3, 0, 0), at(3, 4, 0, 0)},
+		{"day of month with any weekday", "0 0 15 * *", at(3, 1, 0, 0), at(3, 15, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.expr, err)
+			}
+			if got := schedule.Next(tt.after); !got.Equal(tt.want) {
+				t.Fatalf("Next(%s) = %s, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 30s",
+		"@every soon",
+		"@fortnightly",
+		// Tanggal yang tidak pernah ada
+		"0 0 30 2 *",
+		"0 0 31 4,6,9,11 *",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCronExpression) {
+				t.Fatalf("ParseCron(%q): expected ErrInvalidCronExpression, got %v", expr, err)
+			}
+		})
+	}
+}
+
+func TestCronStringKeepsExpression(t *testing.T) {
+	schedule, err := ParseCron(" @daily ")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if schedule.String() != "@daily" {
+		t.Fatalf("expected @daily, got %q", schedule.String())
+	}
+}
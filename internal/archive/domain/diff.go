@@ -0,0 +1,22 @@
+package domain
+
+// VersionDiff adalah perbandingan dua versi konten sebuah arsip
+type VersionDiff struct {
+	ArchiveID      string
+	Name           string
+	From           int
+	To             int
+	Metadata       []Change
+	SizeFrom       int64
+	SizeTo         int64
+	ChecksumFrom   string
+	ChecksumTo     string
+	ContentChanged bool
+	// TextDiff adalah unified diff dari teks PDF kedua versi, kosong bila teksnya sama
+	TextDiff string
+}
+
+// VersionComparer membandingkan metadata dan konten dua versi arsip
+type VersionComparer interface {
+	Compare(from, to *Archive, fromContent, toContent []byte) *VersionDiff
+}
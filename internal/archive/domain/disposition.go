@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Keputusan records manager untuk arsip di antrean disposisi
+const (
+	DispositionApprove  = "approve"
+	DispositionDefer    = "defer"
+	DispositionTransfer = "transfer"
+)
+
+// Action disposisi pada change log arsip
+const (
+	ActionDispositionApprove = "disposition_approve"
+	ActionDispose            = "dispose"
+)
+
+// CertificateType adalah type arsip untuk sertifikat pemusnahan
+const CertificateType = "certificate_of_destruction"
+
+// IsCertificate melaporkan apakah arsip adalah dokumen sertifikat pemusnahan, yang
+// menjadi bukti pemusnahan sehingga tidak boleh ikut dihapus
+func (a *Archive) IsCertificate() bool {
+	return a.Type == CertificateType && a.OwnerID == SystemUserID
+}
+
+// DestroyedItem adalah satu arsip yang dimusnahkan beserta bukti isinya
+type DestroyedItem struct {
+	ArchiveID   string     `bson:"archive_id" json:"archive_id"`
+	Name        string     `bson:"name" json:"name"`
+	Version     int        `bson:"version" json:"version"`
+	Size        int64      `bson:"size" json:"size"`
+	SHA256      string     `bson:"sha256" json:"sha256"`
+	PolicyID    string     `bson:"policy_id" json:"policy_id"`
+	DueAt       *time.Time `bson:"due_at,omitempty" json:"due_at,omitempty"`
+	ApprovedBy  string     `bson:"approved_by" json:"approved_by"`
+	ApprovedAt  time.Time  `bson:"approved_at" json:"approved_at"`
+	DestroyedAt time.Time  `bson:"destroyed_at" json:"destroyed_at"`
+}
+
+// Status sertifikat. Sertifikat pending berisi daftar arsip yang akan dimusnahkan dan
+// disimpan sebelum pemusnahan dimulai, sehingga eksekusi yang terputus tetap tercatat.
+// Sertifikat lama tanpa status dianggap final.
+const (
+	CertificatePending = "pending"
+	CertificateFinal   = "final"
+)
+
+// DispositionCertificate adalah sertifikat pemusnahan satu batch. Signature adalah
+// HMAC-SHA256 dari Payload; dokumennya juga disimpan sebagai arsip tersendiri.
+type DispositionCertificate struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Status     string             `bson:"status,omitempty" json:"status,omitempty"`
+	Items      []DestroyedItem    `bson:"items" json:"items"`
+	Approvers  []string           `bson:"approvers" json:"approvers"`
+	ExecutedBy string             `bson:"executed_by" json:"executed_by"`
+	ExecutedAt time.Time          `bson:"executed_at" json:"executed_at"`
+	Signature  string             `bson:"signature" json:"signature"`
+	// ArchiveID menunjuk arsip dokumen sertifikat, DocumentSHA256 adalah hash isinya
+	ArchiveID      string `bson:"archive_id,omitempty" json:"archive_id,omitempty"`
+	DocumentSHA256 string `bson:"document_sha256,omitempty" json:"document_sha256,omitempty"`
+	Error          string `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// Payload adalah isi sertifikat yang ditandatangani. Waktu dinormalisasi ke UTC
+// per milidetik supaya hasilnya sama setelah disimpan dan dibaca ulang dari MongoDB.
+func (c *DispositionCertificate) Payload() ([]byte, error) {
+	items := make([]DestroyedItem, len(c.Items))
+	for i, item := range c.Items {
+		item.ApprovedAt = normalizeTime(item.ApprovedAt)
+		item.DestroyedAt = normalizeTime(item.DestroyedAt)
+		if item.DueAt != nil {
+			due := normalizeTime(*item.DueAt)
+			item.DueAt = &due
+		}
+		items[i] = item
+	}
+	return json.Marshal(struct {
+		ID         string          `json:"id"`
+		Items      []DestroyedItem `json:"items"`
+		Approvers  []string        `json:"approvers"`
+		ExecutedBy string          `json:"executed_by"`
+		ExecutedAt time.Time       `json:"executed_at"`
+	}{c.ID.Hex(), items, c.Approvers, c.ExecutedBy, normalizeTime(c.ExecutedAt)})
+}
+
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+// CollectApprovers mengisi daftar approver unik dari item, terurut
+func (c *DispositionCertificate) CollectApprovers() {
+	seen := map[string]bool{}
+	c.Approvers = []string{}
+	for _, item := range c.Items {
+		if !seen[item.ApprovedBy] {
+			seen[item.ApprovedBy] = true
+			c.Approvers = append(c.Approvers, item.ApprovedBy)
+		}
+	}
+	sort.Strings(c.Approvers)
+}
+
+// CertificateRenderer membuat dokumen sertifikat yang disimpan sebagai arsip
+type CertificateRenderer interface {
+	Render(cert *DispositionCertificate) ([]byte, error)
+}
+
+type CertificateRepository interface {
+	Create(ctx context.Context, cert *DispositionCertificate) error
+	// Finalize menyimpan sertifikat pending yang sudah ditandatangani sebagai final;
+	// ErrCertificateNotFound bila sertifikat sudah tidak pending
+	Finalize(ctx context.Context, cert *DispositionCertificate) error
+	// FindPending mengembalikan sertifikat pending yang dibuat sebelum waktu tertentu
+	FindPending(ctx context.Context, before time.Time) ([]DispositionCertificate, error)
+	FindByID(ctx context.Context, id string) (*DispositionCertificate, error)
+	FindAll(ctx context.Context, page, limit int) ([]DispositionCertificate, int64, error)
+}
+
+var (
+	ErrCertificateNotFound      = errors.New("certificate of destruction not found")
+	ErrCertificateProtected     = errors.New("certificate of destruction cannot be deleted")
+	ErrSigningKeyMissing        = errors.New("disposition signing key is not configured")
+	ErrDispositionDateRequired  = errors.New("defer needs a future date")
+	ErrTransferCategoryRequired = errors.New("transfer needs a category")
+	ErrDispositionChanged       = errors.New("archive or its approval changed after the evidence was recorded")
+)
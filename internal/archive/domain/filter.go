@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Periode relatif yang dihitung ulang setiap kali filter dijalankan
+const (
+	PeriodToday       = "today"
+	PeriodThisWeek    = "this_week"
+	PeriodThisMonth   = "this_month"
+	PeriodThisQuarter = "this_quarter"
+	PeriodThisYear    = "this_year"
+)
+
+// DeletedScope menentukan apakah arsip yang di-soft delete ikut ditampilkan
+type DeletedScope string
+
+const (
+	DeletedExcluded DeletedScope = ""
+	DeletedIncluded DeletedScope = "include"
+	DeletedOnly     DeletedScope = "only"
+)
+
+// ArchiveFields adalah atribut yang boleh diminta lewat parameter fields=
+var ArchiveFields = []string{
+	"id", "name", "size", "size_mb", "category", "type", "tags", "description",
+	"owner_id", "version", "metadata_revision", "created_at", "updated_at", "deleted_at", "deleted_by", "expires_at",
+	"is_temp", "change_logs", "superseded_by", "folder_id", "event_date", "retention",
+	"legal_holds", "state", "reviewers", "tier", "tiered_at", "last_accessed_at",
+}
+
+func ValidateFields(fields []string) error {
+	for _, field := range fields {
+		known := false
+		for _, f := range ArchiveFields {
+			if f == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return ErrUnknownField
+		}
+	}
+	return nil
+}
+
+// ArchiveFilter berisi filter listing arsip. Struktur ini juga disimpan apa adanya
+// sebagai query pada saved search.
+type ArchiveFilter struct {
+	Category           string       `bson:"category,omitempty" json:"category,omitempty"`
+	IncludeDescendants bool         `bson:"include_descendants,omitempty" json:"include_descendants,omitempty"`
+	Type               string       `bson:"type,omitempty" json:"type,omitempty"`
+	Tags               []string     `bson:"tags,omitempty" json:"tags,omitempty"`
+	OwnerID            string       `bson:"owner_id,omitempty" json:"owner_id,omitempty"`
+	Query              string       `bson:"q,omitempty" json:"q,omitempty"`
+	CreatedFrom        *time.Time   `bson:"created_from,omitempty" json:"created_from,omitempty"`
+	CreatedTo          *time.Time   `bson:"created_to,omitempty" json:"created_to,omitempty"`
+	Period             string       `bson:"period,omitempty" json:"period,omitempty"`
+	Deleted            DeletedScope `bson:"deleted,omitempty" json:"deleted,omitempty"`
+	HideSuperseded     bool         `bson:"hide_superseded,omitempty" json:"hide_superseded,omitempty"`
+	// FolderID "root" berarti arsip yang tidak berada di folder mana pun
+	FolderID string `bson:"folder_id,omitempty" json:"folder_id,omitempty"`
+	// LegalHold menampilkan arsip yang dibekukan oleh legal hold tertentu
+	LegalHold string `bson:"legal_hold,omitempty" json:"legal_hold,omitempty"`
+	// Scheduled hanya menampilkan arsip yang dijadwalkan untuk temp delete
+	Scheduled bool           `bson:"scheduled,omitempty" json:"scheduled,omitempty"`
+	State     LifecycleState `bson:"state,omitempty" json:"state,omitempty"`
+	Tier      StorageTier    `bson:"tier,omitempty" json:"tier,omitempty"`
+	// Viewer adalah user yang melihat listing; draft user lain tidak ditampilkan.
+	// Tidak disimpan bersama saved search karena bergantung pada user yang menjalankan.
+	Viewer string `bson:"-" json:"-"`
+}
+
+func (f ArchiveFilter) Validate() error {
+	switch f.Deleted {
+	case DeletedExcluded, DeletedIncluded, DeletedOnly:
+	default:
+		return ErrInvalidDeletedScope
+	}
+
+	switch f.Period {
+	case "", PeriodToday, PeriodThisWeek, PeriodThisMonth, PeriodThisQuarter, PeriodThisYear:
+	default:
+		return ErrInvalidPeriod
+	}
+	if f.State != "" && !f.State.valid() {
+		return ErrInvalidLifecycleState
+	}
+	switch f.Tier {
+	case "", TierHot, TierCold:
+	default:
+		return ErrInvalidTier
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedTo.Before(*f.CreatedFrom) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// Resolve mengganti Period dengan rentang CreatedFrom/CreatedTo yang konkret
+// relatif terhadap now, misalnya "this_quarter" menjadi awal kuartal berjalan.
+func (f ArchiveFilter) Resolve(now time.Time) ArchiveFilter {
+	if f.Period == "" {
+		return f
+	}
+
+	y, m, d := now.Date()
+	loc := now.Location()
+	var from, to time.Time
+	switch f.Period {
+	case PeriodToday:
+		from = time.Date(y, m, d, 0, 0, 0, 0, loc)
+		to = from.AddDate(0, 0, 1)
+	case PeriodThisWeek:
+		// Minggu dimulai hari Senin
+		offset := (int(now.Weekday()) + 6) % 7
+		from = time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+		to = from.AddDate(0, 0, 7)
+	case PeriodThisMonth:
+		from = time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		to = from.AddDate(0, 1, 0)
+	case PeriodThisQuarter:
+		first := time.Month((int(m)-1)/3*3 + 1)
+		from = time.Date(y, first, 1, 0, 0, 0, 0, loc)
+		to = from.AddDate(0, 3, 0)
+	case PeriodThisYear:
+		from = time.Date(y, 1, 1, 0, 0, 0, 0, loc)
+		to = from.AddDate(1, 0, 0)
+	default:
+		return f
+	}
+
+	f.Period = ""
+	f.CreatedFrom = &from
+	f.CreatedTo = &to
+	return f
+}
+
+var (
+	ErrInvalidPeriod       = errors.New("invalid period")
+	ErrInvalidDateRange    = errors.New("created_to must not be before created_from")
+	ErrInvalidDeletedScope = errors.New("invalid deleted scope")
+	ErrUnknownField        = errors.New("unknown field requested")
+)
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RootFolderID dipakai pada filter dan request untuk arsip/folder tanpa parent
+const RootFolderID = "root"
+
+type Folder struct {
+	ID        primitive.ObjectID   `bson:"_id" json:"id"`
+	Name      string               `bson:"name" json:"name"`
+	ParentID  *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id"`
+	Ancestors []primitive.ObjectID `bson:"ancestors" json:"ancestors"`
+	CreatedBy string               `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time            `bson:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time           `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string               `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	// DeletedWith berisi ID folder yang penghapusannya ikut menghapus folder ini
+	DeletedWith *primitive.ObjectID `bson:"deleted_with,omitempty" json:"-"`
+}
+
+func (f *Folder) IsDeleted() bool {
+	return f.DeletedAt != nil
+}
+
+// Breadcrumb adalah satu langkah jalur dari root ke sebuah folder
+type Breadcrumb struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type FolderRepository interface {
+	Create(ctx context.Context, folder *Folder) error
+	FindByID(ctx context.Context, id string) (*Folder, error)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]Folder, error)
+	// FindChildren mengembalikan subfolder aktif; parentID nil berarti root
+	FindChildren(ctx context.Context, parentID *primitive.ObjectID, page, limit int) ([]Folder, int64, error)
+	FindChildByName(ctx context.Context, parentID *primitive.ObjectID, name string) (*Folder, error)
+	FindDescendants(ctx context.Context, id primitive.ObjectID) ([]Folder, error)
+	Update(ctx context.Context, folder *Folder) error
+	// RebaseDescendants mengganti leluhur di atas folder id pada semua turunannya dengan
+	// ancestors; aman diulang karena hasilnya hanya bergantung pada ancestors
+	RebaseDescendants(ctx context.Context, id primitive.ObjectID, ancestors []primitive.ObjectID, at time.Time) error
+	// MarkDeleted menandai folder dan turunannya yang masih aktif sebagai terhapus oleh cascade
+	MarkDeleted(ctx context.Context, ids []primitive.ObjectID, cascade primitive.ObjectID, userID string, at time.Time) error
+	// RestoreCascade memulihkan semua folder yang terhapus oleh cascade yang sama
+	RestoreCascade(ctx context.Context, cascade primitive.ObjectID) error
+}
+
+var (
+	ErrFolderNotFound      = errors.New("folder not found")
+	ErrFolderExists        = errors.New("a folder with this name already exists here")
+	ErrFolderNameRequired  = errors.New("folder name is required")
+	ErrInvalidFolderMove   = errors.New("folder cannot be moved below itself")
+	ErrFolderDeleted       = errors.New("folder is deleted")
+	ErrFolderNotDeleted    = errors.New("folder is not deleted")
+	ErrFolderParentDeleted = errors.New("parent folder is deleted, restore it first")
+)
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LegalHoldStatus string
+
+const (
+	LegalHoldActive   LegalHoldStatus = "active"
+	LegalHoldReleased LegalHoldStatus = "released"
+)
+
+// Action legal hold pada change log arsip
+const (
+	ActionLegalHold        = "legal_hold"
+	ActionLegalHoldRelease = "legal_hold_release"
+)
+
+// LegalHold membekukan arsip terkait perkara. Selama hold aktif, arsip tidak bisa
+// dihapus lewat jalur apa pun, termasuk expiry, cleanup dan retensi.
+type LegalHold struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Status      LegalHoldStatus    `bson:"status" json:"status"`
+	// ArchiveIDs adalah semua arsip yang pernah ditambahkan ke hold, termasuk hasil filter
+	ArchiveIDs    []string        `bson:"archive_ids" json:"archive_ids"`
+	Filters       []ArchiveFilter `bson:"filters,omitempty" json:"filters,omitempty"`
+	CreatedBy     string          `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time       `bson:"created_at" json:"created_at"`
+	ReleasedBy    string          `bson:"released_by,omitempty" json:"released_by,omitempty"`
+	ReleasedAt    *time.Time      `bson:"released_at,omitempty" json:"released_at,omitempty"`
+	ReleaseReason string          `bson:"release_reason,omitempty" json:"release_reason,omitempty"`
+}
+
+func (h *LegalHold) IsActive() bool {
+	return h.Status == LegalHoldActive
+}
+
+type LegalHoldRepository interface {
+	Create(ctx context.Context, hold *LegalHold) error
+	FindByID(ctx context.Context, id string) (*LegalHold, error)
+	FindAll(ctx context.Context, status LegalHoldStatus, page, limit int) ([]LegalHold, int64, error)
+	Update(ctx context.Context, hold *LegalHold) error
+}
+
+var (
+	ErrUnderLegalHold        = errors.New("archive is under legal hold")
+	ErrLegalHoldNotFound     = errors.New("legal hold not found")
+	ErrLegalHoldNameRequired = errors.New("legal hold name is required")
+	ErrLegalHoldReleased     = errors.New("legal hold already released")
+	ErrReleaseReasonRequired = errors.New("release reason is required")
+	ErrHoldTargetRequired    = errors.New("either ids or filter is required")
+)
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Nama job terjadwal
+const (
+	JobExpiredFiles = "expired_files"
+	JobTempFiles    = "temp_files"
+	JobTrashPurge   = "trash_purge"
+	JobRetention    = "retention"
+	JobDisposition  = "disposition"
+	JobTiering      = "tiering"
+	JobReminders    = "reminders"
+)
+
+type JobTrigger string
+
+const (
+	JobTriggerSchedule JobTrigger = "schedule"
+	JobTriggerManual   JobTrigger = "manual"
+)
+
+// MaxJobRunItems membatasi jumlah arsip yang disimpan per riwayat job
+const MaxJobRunItems = 1000
+
+// JobRunItem adalah arsip yang diproses, atau yang akan diproses pada dry run
+type JobRunItem struct {
+	ID   string `bson:"id" json:"id"`
+	Name string `bson:"name,omitempty" json:"name,omitempty"`
+	// Note menjelaskan arsip yang dilewati atau gagal, misalnya karena legal hold
+	Note string `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+// JobResult adalah hasil satu kali eksekusi job sebelum dicatat sebagai riwayat
+type JobResult struct {
+	Counts  map[string]int64
+	Items   []JobRunItem
+	Warning string
+}
+
+// AddItem menambahkan item selama batas MaxJobRunItems belum tercapai
+func (r *JobResult) AddItem(item JobRunItem) {
+	if len(r.Items) < MaxJobRunItems {
+		r.Items = append(r.Items, item)
+	}
+}
+
+// JobRun adalah riwayat eksekusi job, termasuk dry run
+type JobRun struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Job         string             `bson:"job" json:"job"`
+	Trigger     JobTrigger         `bson:"trigger" json:"trigger"`
+	DryRun      bool               `bson:"dry_run" json:"dry_run"`
+	TriggeredBy string             `bson:"triggered_by" json:"triggered_by"`
+	StartedAt   time.Time          `bson:"started_at" json:"started_at"`
+	FinishedAt  time.Time          `bson:"finished_at" json:"finished_at"`
+	DurationMS  int64              `bson:"duration_ms" json:"duration_ms"`
+	Counts      map[string]int64   `bson:"counts" json:"counts"`
+	Items       []JobRunItem       `bson:"items,omitempty" json:"items,omitempty"`
+	Warning     string             `bson:"warning,omitempty" json:"warning,omitempty"`
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+type JobRunRepository interface {
+	Create(ctx context.Context, run *JobRun) error
+	FindByID(ctx context.Context, id string) (*JobRun, error)
+	// FindRuns mengembalikan riwayat terbaru lebih dulu; job kosong berarti semua job
+	FindRuns(ctx context.Context, job string, page, limit int) ([]JobRun, int64, error)
+}
+
+var (
+	ErrUnknownJob         = errors.New("unknown job")
+	ErrJobRunning         = errors.New("job is already running")
+	ErrDryRunUnsupported  = errors.New("job does not support dry run")
+	ErrJobRunNotFound     = errors.New("job run not found")
+	ErrSchedulerStopped   = errors.New("scheduler is stopped")
+	ErrInvalidJobSchedule = errors.New("invalid job schedule")
+)
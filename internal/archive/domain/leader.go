@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SchedulerLease adalah nama lease yang menentukan replika mana yang menjalankan job terjadwal
+const SchedulerLease = "scheduler"
+
+// JobLease adalah nama lease yang mencegah satu job berjalan di dua replika sekaligus
+func JobLease(job string) string {
+	return "job:" + job
+}
+
+// Lease adalah kepemilikan leader yang disimpan di Mongo. Pemegang lease harus
+// memperbaruinya sebelum ExpiresAt, kalau tidak replika lain boleh mengambil alih.
+type Lease struct {
+	Name       string    `bson:"_id" json:"name"`
+	Holder     string    `bson:"holder" json:"holder"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"`
+	RenewedAt  time.Time `bson:"renewed_at" json:"renewed_at"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// Active melaporkan apakah lease masih berlaku pada waktu now
+func (l *Lease) Active(now time.Time) bool {
+	return now.Before(l.ExpiresAt)
+}
+
+type LeaseRepository interface {
+	// Acquire mengambil lease yang kosong atau kedaluwarsa, atau memperpanjang lease
+	// milik holder sendiri. false berarti lease masih dipegang holder lain; lease yang
+	// dikembalikan adalah milik pemegangnya saat ini.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (*Lease, bool, error)
+	// Release melepas lease bila masih dipegang holder, agar replika lain tidak perlu menunggu TTL
+	Release(ctx context.Context, name, holder string) error
+	Find(ctx context.Context, name string) (*Lease, error)
+}
+
+var (
+	ErrLeaseNotFound       = errors.New("lease not found")
+	ErrInvalidLeaderConfig = errors.New("leader renew interval must be shorter than the lease duration")
+)
@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// LifecycleState adalah tahap arsip pada alur kerja dokumen
+type LifecycleState string
+
+const (
+	StateDraft    LifecycleState = "draft"
+	StateInReview LifecycleState = "in_review"
+	StateApproved LifecycleState = "approved"
+	StateArchived LifecycleState = "archived"
+)
+
+// ActionTransition dicatat pada change log setiap perpindahan state
+const ActionTransition = "transition"
+
+// AnyViewer pada ArchiveFilter.Viewer menampilkan draft milik siapa pun,
+// dipakai oleh proses yang harus menjangkau semua arsip seperti legal hold
+const AnyViewer = "*"
+
+// ReservedUserID melaporkan apakah ID dipakai internal sehingga tidak boleh dipakai
+// sebagai identitas request, misalnya AnyViewer yang bisa melihat semua draft
+func ReservedUserID(id string) bool {
+	return id == AnyViewer || id == SystemUserID
+}
+
+func (s LifecycleState) valid() bool {
+	switch s {
+	case StateDraft, StateInReview, StateApproved, StateArchived:
+		return true
+	}
+	return false
+}
+
+// ParseLifecycleState memvalidasi state dari query atau request
+func ParseLifecycleState(s string) (LifecycleState, error) {
+	state := LifecycleState(s)
+	if !state.valid() {
+		return "", ErrInvalidLifecycleState
+	}
+	return state, nil
+}
+
+// CurrentState adalah state arsip untuk keperluan transisi. Arsip yang dibuat
+// sebelum alur kerja ada tidak memiliki state dan masuk lewat transisi dari draft.
+func (a *Archive) CurrentState() LifecycleState {
+	if a.State == "" {
+		return StateDraft
+	}
+	return a.State
+}
+
+// Frozen melaporkan apakah konten arsip sudah dibekukan karena disetujui
+func (a *Archive) Frozen() bool {
+	return a.State == StateApproved || a.State == StateArchived
+}
+
+// VisibleTo melaporkan apakah arsip boleh dilihat user; draft hanya untuk pemiliknya
+func (a *Archive) VisibleTo(userID string) bool {
+	return a.State != StateDraft || userID == AnyViewer || (userID != "" && a.OwnerID == userID)
+}
+
+// LifecycleTransition adalah satu perpindahan state beserta siapa yang boleh menjalankannya.
+// Owner dan Reviewers mengizinkan pemilik arsip dan reviewer yang ditugaskan,
+// Roles mengizinkan user dengan salah satu role tersebut.
+type LifecycleTransition struct {
+	Name      string           `json:"name"`
+	From      []LifecycleState `json:"from"`
+	To        LifecycleState   `json:"to"`
+	Roles     []string         `json:"roles,omitempty"`
+	Owner     bool             `json:"owner,omitempty"`
+	Reviewers bool             `json:"reviewers,omitempty"`
+}
+
+// AppliesTo melaporkan apakah transisi bisa dijalankan dari state tersebut
+func (t *LifecycleTransition) AppliesTo(state LifecycleState) bool {
+	for _, from := range t.From {
+		if from == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Permits melaporkan apakah user boleh menjalankan transisi pada arsip
+func (t *LifecycleTransition) Permits(a *Archive, userID string, roles []string) bool {
+	if t.Owner && a.OwnerID == userID {
+		return true
+	}
+	if t.Reviewers {
+		for _, reviewer := range a.Reviewers {
+			if reviewer == userID {
+				return true
+			}
+		}
+	}
+	for _, role := range roles {
+		for _, allowed := range t.Roles {
+			if role == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Lifecycle adalah daftar transisi yang berlaku
+type Lifecycle struct {
+	Transitions []LifecycleTransition `json:"transitions"`
+}
+
+// DefaultLifecycle dipakai bila tidak ada konfigurasi lifecycle
+func DefaultLifecycle() *Lifecycle {
+	return &Lifecycle{Transitions: []LifecycleTransition{
+		{Name: "submit", From: []LifecycleState{StateDraft}, To: StateInReview, Owner: true},
+		{Name: "withdraw", From: []LifecycleState{StateInReview}, To: StateDraft, Owner: true},
+		{Name: "approve", From: []LifecycleState{StateInReview}, To: StateApproved, Reviewers: true, Roles: []string{"admin"}},
+		{Name: "reject", From: []LifecycleState{StateInReview}, To: StateDraft, Reviewers: true, Roles: []string{"admin"}},
+		{Name: "archive", From: []LifecycleState{StateApproved}, To: StateArchived, Roles: []string{"admin", "records_manager"}},
+		{Name: "reopen", From: []LifecycleState{StateApproved, StateArchived}, To: StateDraft, Roles: []string{"admin"}},
+	}}
+}
+
+func (l *Lifecycle) Validate() error {
+	if len(l.Transitions) == 0 {
+		return ErrInvalidLifecycle
+	}
+	seen := map[string]bool{}
+	for _, t := range l.Transitions {
+		name := strings.TrimSpace(t.Name)
+		if name == "" || seen[name] || !t.To.valid() || len(t.From) == 0 {
+			return ErrInvalidLifecycle
+		}
+		for _, from := range t.From {
+			if !from.valid() {
+				return ErrInvalidLifecycle
+			}
+		}
+		if !t.Owner && !t.Reviewers && len(t.Roles) == 0 {
+			return ErrInvalidLifecycle
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func (l *Lifecycle) Find(name string) (*LifecycleTransition, error) {
+	for i := range l.Transitions {
+		if l.Transitions[i].Name == name {
+			return &l.Transitions[i], nil
+		}
+	}
+	return nil, ErrUnknownTransition
+}
+
+// Notification adalah pemberitahuan kepada user terkait arsip
+type Notification struct {
+	Recipients []string
+	Subject    string
+	Message    string
+	ArchiveID  string
+	// Event dan Links dipakai kanal yang mengirim data terstruktur, misalnya webhook
+	Event string
+	Links map[string]string
+}
+
+// Notifier mengirim pemberitahuan ke user, misalnya reviewer yang ditugaskan
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+var (
+	ErrInvalidLifecycleState = errors.New("invalid lifecycle state")
+	ErrInvalidLifecycle      = errors.New("invalid lifecycle configuration")
+	ErrUnknownTransition     = errors.New("unknown lifecycle transition")
+	ErrTransitionNotAllowed  = errors.New("transition is not allowed from the current state")
+	ErrTransitionForbidden   = errors.New("not permitted to perform this transition")
+	ErrReviewersRequired     = errors.New("at least one reviewer is required")
+	ErrContentFrozen         = errors.New("archive content is frozen after approval")
+	ErrStateConflict         = errors.New("archive state changed, retry the transition")
+)
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchiveLock adalah check-out eksplisit. Selama lock aktif hanya pemiliknya
+// yang boleh mengunggah versi baru atau mengubah metadata.
+type ArchiveLock struct {
+	Owner      string    `bson:"owner" json:"owner"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+func (l *ArchiveLock) Active(now time.Time) bool {
+	return l != nil && l.ExpiresAt.After(now)
+}
+
+// LockedFor bernilai true bila arsip sedang di-check-out oleh user lain
+func (a *Archive) LockedFor(userID string, now time.Time) bool {
+	return a.Lock.Active(now) && a.Lock.Owner != userID
+}
+
+// ETag mengidentifikasi versi konten dan revisi metadata arsip
+func (a *Archive) ETag() string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%d.%d", a.Version, a.MetadataRevision))
+}
+
+// Precondition berisi nilai header If-Match. Tanpa nilai berarti tanpa syarat.
+type Precondition struct {
+	IfMatch []string
+}
+
+// Matches menerima "*", ETag lengkap, atau nomor versi konten saja
+func (p Precondition) Matches(a *Archive) bool {
+	if len(p.IfMatch) == 0 {
+		return true
+	}
+	if a == nil {
+		return false
+	}
+
+	etag := strings.Trim(a.ETag(), `"`)
+	version := strconv.Itoa(a.Version)
+	for _, tag := range p.IfMatch {
+		tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+		if tag == "*" || tag == etag || tag == version {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrPreconditionFailed = errors.New("archive has changed since it was read")
+	ErrVersionConflict    = errors.New("archive is being modified by another request")
+	ErrArchiveLocked      = errors.New("archive is checked out by another user")
+	ErrNotCheckedOut      = errors.New("archive is not checked out")
+)
@@ -21,26 +21,69 @@ type Archive struct {
 	Description string             `bson:"description" json:"description"`
 	OwnerID     string             `bson:"owner_id" json:"owner_id"`
 	Version     int                `bson:"version" json:"version"`
-	DeletedAt   *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at"`
-	ExpiresAt   *time.Time         `bson:"expires_at,omitempty" json:"expires_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	IsTemp      bool               `bson:"is_temp" json:"is_temp"`
-	ChangeLogs  []ChangeLog        `bson:"change_logs" json:"change_logs"`
+	// MetadataRevision bertambah setiap metadata diubah tanpa upload ulang konten
+	MetadataRevision int             `bson:"metadata_revision" json:"metadata_revision"`
+	DeletedAt        *time.Time      `bson:"deleted_at,omitempty" json:"deleted_at"`
+	DeletedBy        string          `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	ExpiresAt        *time.Time      `bson:"expires_at,omitempty" json:"expires_at"`
+	UpdatedAt        time.Time       `bson:"updated_at" json:"updated_at"`
+	CreatedAt        time.Time       `bson:"created_at" json:"created_at"`
+	IsTemp           bool            `bson:"is_temp" json:"is_temp"`
+	ChangeLogs       []ChangeLog     `bson:"change_logs" json:"change_logs"`
+	Lock             *ArchiveLock    `bson:"lock,omitempty" json:"lock,omitempty"`
+	SupersededBy     string          `bson:"superseded_by,omitempty" json:"superseded_by,omitempty"`
+	FolderID         string          `bson:"folder_id,omitempty" json:"folder_id,omitempty"`
+	EventDate        *time.Time      `bson:"event_date,omitempty" json:"event_date,omitempty"`
+	Retention        *RetentionState `bson:"retention,omitempty" json:"retention,omitempty"`
+	LegalHolds       []string        `bson:"legal_holds,omitempty" json:"legal_holds,omitempty"`
+	State            LifecycleState  `bson:"state,omitempty" json:"state,omitempty"`
+	Reviewers        []string        `bson:"reviewers,omitempty" json:"reviewers,omitempty"`
+	Tier             StorageTier     `bson:"tier,omitempty" json:"tier,omitempty"`
+	TieredAt         *time.Time      `bson:"tiered_at,omitempty" json:"tiered_at,omitempty"`
+	LastAccessedAt   *time.Time      `bson:"last_accessed_at,omitempty" json:"last_accessed_at,omitempty"`
+	Signature        []uint64        `bson:"-" json:"-"`
+	// ContentID adalah files_id chunks konten versi terbaru; kosong berarti sama dengan ID
+	ContentID primitive.ObjectID `bson:"-" json:"-"`
 }
 
 type ChangeLog struct {
 	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
-	Action    string    `bson:"action" json:"action"` // upload, update, delete, restore
+	Action    string    `bson:"action" json:"action"`
 	UserID    string    `bson:"user_id" json:"user_id"`
 	Changes   []Change  `bson:"changes" json:"changes"`
 }
 
+// SystemUserID dipakai pada change log untuk perubahan oleh proses otomatis (cleanup, expiry)
+const SystemUserID = "system"
+
+// Action yang dicatat pada change log
+const (
+	ActionUpload         = "upload"
+	ActionUpdate         = "update"
+	ActionUpdateMetadata = "update_metadata"
+	ActionDelete         = "delete"
+	ActionTempDelete     = "temp_delete"
+	ActionRestore        = "restore"
+	ActionPurge          = "purge"
+	ActionExpire         = "expire"
+	ActionCleanup        = "cleanup"
+	ActionRollback       = "rollback"
+	ActionCheckout       = "checkout"
+	ActionCheckin        = "checkin"
+	ActionBreakLock      = "break_lock"
+	ActionMove           = "move"
+	ActionRetention      = "retention"
+	ActionExtendDeletion = "extend_deletion"
+	ActionCancelDeletion = "cancel_deletion"
+)
+
 type HistoryEntry struct {
 	Timestamp time.Time `json:"timestamp"`
 	Action    string    `json:"action"`
 	User      string    `json:"user"`
 	Changes   []Change  `json:"changes"`
+	// CommentID diisi untuk entri yang berasal dari komentar
+	CommentID string `json:"comment_id,omitempty"`
 }
 type History struct {
 	ID       string         `json:"id"`
@@ -54,6 +97,18 @@ type Change struct {
 	NewValue interface{} `bson:"new_value" json:"new_value"`
 }
 
+// Expired melaporkan apakah arsip sudah kedaluwarsa karena temp delete. Arsip dengan
+// jadwal retensi tetap bisa diakses sampai disposition benar-benar dijalankan, dan
+// arsip dalam legal hold tidak pernah kedaluwarsa.
+func (a *Archive) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && a.ExpiresAt.Before(now) && a.Retention == nil && !a.OnHold()
+}
+
+// OnHold melaporkan apakah arsip sedang dibekukan legal hold
+func (a *Archive) OnHold() bool {
+	return len(a.LegalHolds) > 0
+}
+
 func (a *Archive) FormatSize() {
 	const (
 		KB = 1024
@@ -67,19 +122,86 @@ func (a *Archive) FormatSize() {
 type ArchiveRepository interface {
 	Save(ctx context.Context, file FileContent) error
 	FindByID(ctx context.Context, id string) (*Archive, []byte, error)
-	FindAll(ctx context.Context, page, limit int) ([]Archive, int64, error)
+	FindMetadata(ctx context.Context, id string) (*Archive, error)
+	UpdateMetadata(ctx context.Context, id string, patch ArchivePatch, userID string, cond Precondition) (*Archive, error)
+	FindAll(ctx context.Context, filter ArchiveFilter, fields []string, page, limit int) ([]Archive, int64, error)
+	Count(ctx context.Context, filter ArchiveFilter) (int64, error)
 	FindByIDs(ctx context.Context, ids []string) ([]Archive, error)
-	Delete(ctx context.Context, id string, deleteType DeleteType) error
-	RestoreArchive(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string, deleteType DeleteType, userID string) error
+	RestoreArchive(ctx context.Context, id, userID string, strategy RestoreStrategy) (*RestoreResult, error)
 	Exists(ctx context.Context, id string) (bool, error)
 	DeleteExpiredTempFiles(ctx context.Context) error
 	FindExistingArchive(ctx context.Context, archive Archive) (*Archive, error)
-	SaveWithVersioning(context.Context, Archive, []byte) (*Archive, error)
+	SaveWithVersioning(ctx context.Context, archive Archive, content []byte, cond Precondition) (*Archive, error)
 	GetHistory(ctx context.Context, id string) (*History, error)
-	GetByCategory(ctx context.Context, category string, page, limit int) ([]Archive, int64, error)
-	GetByTags(ctx context.Context, tags []string, page, limit int) ([]Archive, int64, error)
-	DeleteExpiredFiles(ctx context.Context) (int64, error)
-	DeleteByFilter(ctx context.Context, filter bson.M) (int64, error)
+	GetByCategory(ctx context.Context, category string, includeDescendants bool, viewer string, page, limit int) ([]Archive, int64, error)
+	GetByTags(ctx context.Context, tags []string, viewer string, page, limit int) ([]Archive, int64, error)
+	DeleteExpiredFiles(ctx context.Context) ([]string, error)
+	DeleteByFilter(ctx context.Context, filter bson.M) ([]string, error)
+	// FindExpiredFiles, FindDeletedBefore dan FindByFilter dipakai dry run cleanup
+	// untuk melihat arsip yang akan dihapus tanpa menghapusnya
+	FindExpiredFiles(ctx context.Context, now time.Time, limit int) ([]Archive, error)
+	FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]Archive, error)
+	FindByFilter(ctx context.Context, filter bson.M, limit int) ([]Archive, error)
+	ReassignCategory(ctx context.Context, oldPath, newPath, userID string) (int64, error)
+	FindTrash(ctx context.Context, viewer string, page, limit int) ([]Archive, int64, error)
+	Purge(ctx context.Context, id, userID string) error
+	PurgeDeletedBefore(ctx context.Context, before time.Time) ([]string, error)
+	GetSignature(ctx context.Context, id string) ([]uint64, error)
+	SaveSignature(ctx context.Context, id string, signature []uint64) error
+	FindSimilar(ctx context.Context, signature []uint64, excludeID string, threshold float64, limit int) ([]SimilarArchive, error)
+	ListRevisions(ctx context.Context, id string) ([]Archive, error)
+	FindRevision(ctx context.Context, id string, version int) (*Archive, []byte, error)
+	Rollback(ctx context.Context, id string, version int, userID string) (*Archive, error)
+	Checkout(ctx context.Context, id, userID string, ttl time.Duration) (*Archive, error)
+	Checkin(ctx context.Context, id, userID string, force bool) error
+	// SetSupersededBy mengisi penanda arsip usang; by kosong berarti penanda dihapus
+	SetSupersededBy(ctx context.Context, id, by, userID string) error
+	// MoveToFolder memindahkan arsip ke folder lain; folderID kosong berarti ke root
+	MoveToFolder(ctx context.Context, id, folderID, userID string) (*Archive, error)
+	// ScanActive membaca arsip aktif berurutan berdasarkan ID, dimulai setelah afterID
+	ScanActive(ctx context.Context, afterID string, limit int) ([]Archive, error)
+	// SetRetention menyimpan jadwal retensi dan expires_at; state nil menghapus jadwal
+	SetRetention(ctx context.Context, id string, state *RetentionState, userID string) error
+	FindRetentionDue(ctx context.Context, now time.Time, limit int) ([]Archive, error)
+	FindRetentionReview(ctx context.Context, page, limit int) ([]Archive, int64, error)
+	// ApproveDisposition menyetujui pemusnahan arsip yang ada di antrean disposisi
+	ApproveDisposition(ctx context.Context, id, userID string) error
+	FindDispositionApproved(ctx context.Context, limit int) ([]Archive, error)
+	// Destroy memusnahkan arsip beserta chunks dan revisinya, hanya bila arsip masih versi
+	// yang sama dan persetujuannya masih persetujuan yang dicatat sebagai bukti
+	Destroy(ctx context.Context, id string, version int, approvedAt time.Time, userID string) error
+	// ApplyLegalHold menambahkan hold ke arsip; mengembalikan jumlah arsip yang baru dibekukan
+	ApplyLegalHold(ctx context.Context, holdID string, ids []string, userID string) (int64, error)
+	// ReleaseLegalHold melepas hold dari semua arsip yang dibekukannya
+	ReleaseLegalHold(ctx context.Context, holdID, userID string) (int64, error)
+	DeleteInFolders(ctx context.Context, folderIDs []string, cascadeID, userID string) (int64, error)
+	// ScheduleDeletion menjadwalkan temp delete, atau memindahkan jadwal bila arsip sudah dijadwalkan
+	ScheduleDeletion(ctx context.Context, id string, expiresAt time.Time, userID string) (*Archive, error)
+	CancelDeletion(ctx context.Context, id, userID string) (*Archive, error)
+	// CountHeldInFolders menghitung arsip dalam folder yang sedang dibekukan legal hold
+	CountHeldInFolders(ctx context.Context, folderIDs []string) (int64, error)
+	// CountLockedInFolders menghitung arsip dalam folder yang sedang di-check-out user lain
+	CountLockedInFolders(ctx context.Context, folderIDs []string, userID string) (int64, error)
+	// RestoreFolderCascade memulihkan arsip yang terhapus bersama folder; arsip yang namanya
+	// sudah dipakai arsip aktif lain tetap di trash dan ID-nya dikembalikan sebagai konflik
+	RestoreFolderCascade(ctx context.Context, cascadeID, userID string) (int64, []string, error)
+	// SetLifecycleState memindahkan state arsip bila state saat ini masih from
+	SetLifecycleState(ctx context.Context, id string, from, to LifecycleState, reviewers []string, transition, userID string) (*Archive, error)
+	// FindTierCandidates mencari arsip hot yang memenuhi kebijakan tiering
+	FindTierCandidates(ctx context.Context, policy TierPolicy, now time.Time, limit int) ([]Archive, error)
+	// MoveToCold memindahkan konten ke cold store; metadata tetap di GridFS
+	MoveToCold(ctx context.Context, id string) (*Archive, error)
+	// Rehydrate mengembalikan konten arsip cold ke chunks GridFS
+	Rehydrate(ctx context.Context, id string) (*Archive, error)
+	// TouchAccess mencatat waktu akses terakhir tanpa menambah change log
+	TouchAccess(ctx context.Context, id string, at time.Time) error
+}
+
+// TrashItem adalah arsip yang di-soft delete beserta jadwal penghapusan permanennya
+type TrashItem struct {
+	Archive Archive
+	PurgeAt time.Time
 }
 
 type FileContent struct {
@@ -99,6 +221,37 @@ type ArchiveMetadata struct {
 	OwnerID     string   `bson:"owner_id" json:"owner_id" validate:"required"`
 }
 
+// ArchivePatch berisi perubahan metadata parsial; field nil tidak diubah
+type ArchivePatch struct {
+	Name        *string
+	Category    *string
+	Type        *string
+	Tags        *[]string
+	Description *string
+	EventDate   *time.Time
+}
+
+func (p ArchivePatch) Apply(a *Archive) {
+	if p.Name != nil {
+		a.Name = *p.Name
+	}
+	if p.Category != nil {
+		a.Category = *p.Category
+	}
+	if p.Type != nil {
+		a.Type = *p.Type
+	}
+	if p.Tags != nil {
+		a.Tags = *p.Tags
+	}
+	if p.Description != nil {
+		a.Description = *p.Description
+	}
+	if p.EventDate != nil {
+		a.EventDate = p.EventDate
+	}
+}
+
 type DeleteType int
 
 const (
@@ -116,6 +269,8 @@ var (
 	ErrInvalidCategory   = errors.New("invalid category")
 	ErrTagsRequired      = errors.New("tags are required")
 	ErrNotDeleted        = errors.New("archive not deleted")
+	ErrNameConflict      = errors.New("another archive already uses this name")
+	ErrNotScheduled      = errors.New("archive is not scheduled for deletion")
 )
 
 func (dt DeleteType) String() string {
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RelationType adalah jenis link berarah antar arsip
+type RelationType string
+
+const (
+	// RelationSupersedes: source menggantikan target, target dianggap usang
+	RelationSupersedes RelationType = "supersedes"
+	// RelationAmends: source adalah amandemen dari target
+	RelationAmends RelationType = "amends"
+	// RelationAttachmentOf: source adalah lampiran dari target
+	RelationAttachmentOf RelationType = "attachment_of"
+	// RelationReferences: source merujuk ke target, misalnya invoice ke purchase order
+	RelationReferences RelationType = "references"
+)
+
+func (t RelationType) Valid() bool {
+	switch t {
+	case RelationSupersedes, RelationAmends, RelationAttachmentOf, RelationReferences:
+		return true
+	}
+	return false
+}
+
+type Relation struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	SourceID  primitive.ObjectID `bson:"source_id" json:"source_id"`
+	TargetID  primitive.ObjectID `bson:"target_id" json:"target_id"`
+	Type      RelationType       `bson:"type" json:"type"`
+	CreatedBy string             `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	// BrokenAt diisi saat salah satu ujung link dihapus permanen
+	BrokenAt *time.Time `bson:"broken_at,omitempty" json:"broken_at,omitempty"`
+}
+
+// ArchiveRelations berisi link keluar (arsip sebagai source) dan masuk (arsip sebagai target)
+type ArchiveRelations struct {
+	Outgoing []Relation
+	Incoming []Relation
+}
+
+type RelationRepository interface {
+	Create(ctx context.Context, relation *Relation) error
+	FindByID(ctx context.Context, id string) (*Relation, error)
+	FindByArchive(ctx context.Context, archiveID string) (*ArchiveRelations, error)
+	// FindIncoming hanya mengembalikan link yang belum rusak
+	FindIncoming(ctx context.Context, archiveID string, relationType RelationType) ([]Relation, error)
+	Delete(ctx context.Context, id string) error
+	// MarkBroken menandai semua link aktif yang menyentuh arsip dan mengembalikannya
+	MarkBroken(ctx context.Context, archiveID string) ([]Relation, error)
+}
+
+var (
+	ErrRelationNotFound    = errors.New("relation not found")
+	ErrRelationExists      = errors.New("relation already exists")
+	ErrInvalidRelationType = errors.New("invalid relation type")
+	ErrSelfRelation        = errors.New("archive cannot be related to itself")
+	ErrRelationCycle       = errors.New("archives cannot supersede each other")
+)
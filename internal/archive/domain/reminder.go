@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReminderKind membedakan arsip yang akan kedaluwarsa dan arsip di trash yang akan di-purge
+type ReminderKind string
+
+const (
+	ReminderExpiry ReminderKind = "expiry"
+	ReminderPurge  ReminderKind = "purge"
+)
+
+// ReminderAction adalah tindakan satu klik yang ditawarkan di reminder
+type ReminderAction string
+
+const (
+	ReminderExtend  ReminderAction = "extend"
+	ReminderRestore ReminderAction = "restore"
+)
+
+// MaxReminderAttempts membatasi percobaan kirim ulang per kanal yang gagal
+const MaxReminderAttempts = 3
+
+// NotificationChannel adalah Notifier bernama, agar hasil pengiriman bisa dicatat per kanal
+type NotificationChannel struct {
+	Name     string
+	Notifier Notifier
+}
+
+// ReminderDelivery adalah hasil pengiriman reminder lewat satu kanal
+type ReminderDelivery struct {
+	Channel  string     `bson:"channel" json:"channel"`
+	SentAt   *time.Time `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	Attempts int        `bson:"attempts" json:"attempts"`
+	Error    string     `bson:"error,omitempty" json:"error,omitempty"`
+	// SendingUntil adalah lease run yang sedang mengirim lewat kanal ini, supaya run yang
+	// berjalan bersamaan tidak mengirim dua kali
+	SendingUntil *time.Time `bson:"sending_until,omitempty" json:"-"`
+}
+
+// Reminder mencatat pemberitahuan untuk satu arsip dan satu tanggal penghapusan. Tanggal
+// yang berubah, misalnya karena diperpanjang, menghasilkan reminder baru.
+type Reminder struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	ArchiveID string             `bson:"archive_id" json:"archive_id"`
+	Name      string             `bson:"name" json:"name"`
+	Kind      ReminderKind       `bson:"kind" json:"kind"`
+	Recipient string             `bson:"recipient" json:"recipient"`
+	// DueAt adalah expires_at arsip, atau tanggal purge untuk arsip di trash
+	DueAt      time.Time          `bson:"due_at" json:"due_at"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	Deliveries []ReminderDelivery `bson:"deliveries" json:"deliveries"`
+	// Action dan ActionAt terisi setelah link satu klik dipakai; setiap link hanya berlaku sekali
+	Action   ReminderAction `bson:"action,omitempty" json:"action,omitempty"`
+	ActionAt *time.Time     `bson:"action_at,omitempty" json:"action_at,omitempty"`
+}
+
+// Delivery mengembalikan catatan pengiriman untuk kanal, dibuat bila belum ada
+func (r *Reminder) Delivery(channel string) *ReminderDelivery {
+	for i := range r.Deliveries {
+		if r.Deliveries[i].Channel == channel {
+			return &r.Deliveries[i]
+		}
+	}
+	r.Deliveries = append(r.Deliveries, ReminderDelivery{Channel: channel})
+	return &r.Deliveries[len(r.Deliveries)-1]
+}
+
+// Pending melaporkan apakah kanal belum berhasil dan masih boleh dicoba lagi
+func (d *ReminderDelivery) Pending() bool {
+	return d.SentAt == nil && d.Attempts < MaxReminderAttempts
+}
+
+// ReminderRun adalah hasil satu kali pengiriman reminder
+type ReminderRun struct {
+	Matched int `json:"matched"`
+	Sent    int `json:"sent"`
+	// Skipped adalah arsip yang reminder-nya sudah terkirim ke semua kanal
+	Skipped  int           `json:"skipped"`
+	Failures []BulkFailure `json:"failures"`
+}
+
+type ReminderRepository interface {
+	// Claim mengembalikan reminder untuk arsip, jenis dan tanggal yang sama, atau
+	// menyimpan reminder baru bila belum ada
+	Claim(ctx context.Context, reminder *Reminder) (*Reminder, error)
+	FindByID(ctx context.Context, id string) (*Reminder, error)
+	// ClaimDelivery mengambil lease kirim untuk kanal yang belum berhasil, belum habis
+	// percobaannya dan tidak sedang dikirim run lain; false bila kanal tidak boleh dikirim
+	ClaimDelivery(ctx context.Context, id primitive.ObjectID, channel string, now time.Time, lease time.Duration) (bool, error)
+	// FinishDelivery mencatat hasil pengiriman kanal dan melepas lease-nya; sentAt nil berarti gagal
+	FinishDelivery(ctx context.Context, id primitive.ObjectID, channel string, sentAt *time.Time, errMsg string) error
+	// ClaimAction mencatat tindakan satu klik; ErrReminderActionUsed bila sudah pernah dipakai
+	ClaimAction(ctx context.Context, id string, action ReminderAction, at time.Time) error
+	// ReleaseAction membatalkan ClaimAction bila tindakannya gagal dijalankan
+	ReleaseAction(ctx context.Context, id string) error
+}
+
+var (
+	ErrReminderNotFound      = errors.New("reminder not found")
+	ErrInvalidReminderToken  = errors.New("invalid reminder link")
+	ErrReminderTokenExpired  = errors.New("reminder link has expired")
+	ErrReminderActionUsed    = errors.New("reminder link has already been used")
+	ErrReminderLinksDisabled = errors.New("reminder links are disabled, REMINDER_SIGNING_KEY is not set")
+	ErrNoEmailRecipient      = errors.New("no email address for notification recipients")
+)
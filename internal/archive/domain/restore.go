@@ -0,0 +1,39 @@
+package domain
+
+import "errors"
+
+// RestoreStrategy menentukan cara restore bila nama arsip yang dihapus sudah dipakai
+// arsip aktif lain, misalnya karena file dengan nama sama diunggah setelah soft delete
+type RestoreStrategy string
+
+const (
+	// RestoreFail menolak restore dengan ErrRestoreConflict
+	RestoreFail RestoreStrategy = "fail"
+	// RestoreRename memulihkan arsip dengan nama baru yang belum dipakai
+	RestoreRename RestoreStrategy = "rename"
+	// RestoreMerge menyimpan konten arsip yang dihapus sebagai versi baru arsip aktif
+	RestoreMerge RestoreStrategy = "merge"
+)
+
+func (s RestoreStrategy) Validate() error {
+	switch s {
+	case "", RestoreFail, RestoreRename, RestoreMerge:
+		return nil
+	default:
+		return ErrInvalidRestoreStrategy
+	}
+}
+
+// RestoreResult adalah arsip aktif setelah restore. Strategy kosong berarti tidak ada
+// konflik nama; pada merge, Archive adalah arsip aktif yang menerima versi baru.
+type RestoreResult struct {
+	Archive  *Archive
+	Strategy RestoreStrategy
+	// ConflictID adalah arsip aktif yang memakai nama yang sama
+	ConflictID string
+}
+
+var (
+	ErrRestoreConflict        = errors.New("another live archive already uses this name")
+	ErrInvalidRestoreStrategy = errors.New("invalid restore strategy, use fail, rename or merge")
+)
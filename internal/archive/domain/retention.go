@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RetentionTrigger menentukan tanggal awal perhitungan masa retensi
+type RetentionTrigger string
+
+const (
+	TriggerCreated  RetentionTrigger = "created"
+	TriggerModified RetentionTrigger = "modified"
+	// TriggerEvent memakai event_date pada arsip, misalnya tanggal berakhirnya kontrak
+	TriggerEvent RetentionTrigger = "event"
+)
+
+// RetentionAction adalah tindakan saat masa retensi berakhir
+type RetentionAction string
+
+const (
+	RetentionSoftDelete RetentionAction = "soft_delete"
+	// RetentionHardDelete tidak memusnahkan arsip secara langsung. Sama seperti
+	// RetentionReview, arsip masuk antrean disposisi dan baru dimusnahkan setelah
+	// disetujui records manager. Nilainya tetap diterima agar kebijakan lama tetap valid.
+	RetentionHardDelete RetentionAction = "hard_delete"
+	RetentionReview     RetentionAction = "review"
+)
+
+// RetentionPeriod adalah lama retensi dalam kalender, misal 10 tahun
+type RetentionPeriod struct {
+	Years  int `bson:"years,omitempty" json:"years,omitempty"`
+	Months int `bson:"months,omitempty" json:"months,omitempty"`
+	Days   int `bson:"days,omitempty" json:"days,omitempty"`
+}
+
+func (p RetentionPeriod) AddTo(t time.Time) time.Time {
+	return t.AddDate(p.Years, p.Months, p.Days)
+}
+
+func (p RetentionPeriod) valid() bool {
+	return p.Years >= 0 && p.Months >= 0 && p.Days >= 0 && p.Years+p.Months+p.Days > 0
+}
+
+type RetentionPolicy struct {
+	ID   primitive.ObjectID `bson:"_id" json:"id"`
+	Name string             `bson:"name" json:"name"`
+	// Category berlaku juga untuk sub-kategori; kosong berarti semua kategori
+	Category  string           `bson:"category,omitempty" json:"category,omitempty"`
+	Type      string           `bson:"type,omitempty" json:"type,omitempty"`
+	Period    RetentionPeriod  `bson:"period" json:"period"`
+	Trigger   RetentionTrigger `bson:"trigger" json:"trigger"`
+	Action    RetentionAction  `bson:"action" json:"action"`
+	CreatedBy string           `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+func (p *RetentionPolicy) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return ErrRetentionNameRequired
+	}
+	if p.Category == "" && p.Type == "" {
+		return ErrRetentionScopeRequired
+	}
+	if !p.Period.valid() {
+		return ErrInvalidRetentionPeriod
+	}
+	switch p.Trigger {
+	case TriggerCreated, TriggerModified, TriggerEvent:
+	default:
+		return ErrInvalidRetentionTrigger
+	}
+	switch p.Action {
+	case RetentionSoftDelete, RetentionHardDelete, RetentionReview:
+	default:
+		return ErrInvalidRetentionAction
+	}
+	return nil
+}
+
+// Matches melaporkan apakah kebijakan berlaku untuk arsip
+func (p *RetentionPolicy) Matches(a *Archive) bool {
+	if p.Type != "" && p.Type != a.Type {
+		return false
+	}
+	if p.Category != "" && a.Category != p.Category && !IsDescendantPath(a.Category, p.Category) {
+		return false
+	}
+	return true
+}
+
+// specificity: cocok kategori + type paling spesifik, lalu kategori terdalam
+func (p *RetentionPolicy) specificity() int {
+	score := 0
+	if p.Category != "" {
+		score += 1 + strings.Count(p.Category, CategoryPathSeparator)
+	}
+	if p.Type != "" {
+		score += 100
+	}
+	return score
+}
+
+// DueDate menghitung akhir masa retensi; nil bila tanggal pemicu belum ada
+func (p *RetentionPolicy) DueDate(a *Archive) *time.Time {
+	var start time.Time
+	switch p.Trigger {
+	case TriggerCreated:
+		start = a.CreatedAt
+	case TriggerModified:
+		start = a.UpdatedAt
+	case TriggerEvent:
+		if a.EventDate == nil {
+			return nil
+		}
+		start = *a.EventDate
+	}
+	if start.IsZero() {
+		return nil
+	}
+	due := p.Period.AddTo(start)
+	return &due
+}
+
+// SelectPolicy memilih kebijakan yang berlaku untuk arsip. Kebijakan paling
+// spesifik menang; bila sama spesifiknya, masa retensi terpanjang yang dipakai.
+func SelectPolicy(policies []RetentionPolicy, a *Archive) *RetentionPolicy {
+	var selected *RetentionPolicy
+	reference := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range policies {
+		p := &policies[i]
+		if !p.Matches(a) {
+			continue
+		}
+		if selected == nil || p.specificity() > selected.specificity() ||
+			(p.specificity() == selected.specificity() && p.Period.AddTo(reference).After(selected.Period.AddTo(reference))) {
+			selected = p
+		}
+	}
+	return selected
+}
+
+// RetentionState adalah jadwal retensi yang tersimpan pada arsip
+type RetentionState struct {
+	PolicyID primitive.ObjectID `bson:"policy_id" json:"policy_id"`
+	Action   RetentionAction    `bson:"action" json:"action"`
+	DueAt    *time.Time         `bson:"due_at,omitempty" json:"due_at,omitempty"`
+	// ReviewSince diisi saat arsip masuk antrean review
+	ReviewSince *time.Time `bson:"review_since,omitempty" json:"review_since,omitempty"`
+	// RetainUntil adalah perpanjangan hasil review
+	RetainUntil *time.Time `bson:"retain_until,omitempty" json:"retain_until,omitempty"`
+	// ApprovedBy dan ApprovedAt diisi saat pemusnahan disetujui records manager
+	ApprovedBy string     `bson:"approved_by,omitempty" json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `bson:"approved_at,omitempty" json:"approved_at,omitempty"`
+}
+
+// Schedule menghitung jadwal retensi arsip berdasarkan kebijakan. Perpanjangan,
+// status review dan persetujuan dipertahankan selama kebijakannya tidak berganti.
+func Schedule(policy *RetentionPolicy, a *Archive) *RetentionState {
+	if policy == nil {
+		return nil
+	}
+
+	state := &RetentionState{PolicyID: policy.ID, Action: policy.Action, DueAt: policy.DueDate(a)}
+	if current := a.Retention; current != nil && current.PolicyID == policy.ID {
+		state.ReviewSince = current.ReviewSince
+		state.RetainUntil = current.RetainUntil
+		state.ApprovedBy = current.ApprovedBy
+		state.ApprovedAt = current.ApprovedAt
+	}
+	if state.RetainUntil != nil && (state.DueAt == nil || state.RetainUntil.After(*state.DueAt)) {
+		due := *state.RetainUntil
+		state.DueAt = &due
+	}
+	return state
+}
+
+// Equal membandingkan dua jadwal retensi; waktu dibandingkan per milidetik seperti di MongoDB
+func (s *RetentionState) Equal(other *RetentionState) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	return s.PolicyID == other.PolicyID && s.Action == other.Action &&
+		sameInstant(s.DueAt, other.DueAt) && sameInstant(s.ReviewSince, other.ReviewSince) &&
+		sameInstant(s.RetainUntil, other.RetainUntil) &&
+		s.ApprovedBy == other.ApprovedBy && sameInstant(s.ApprovedAt, other.ApprovedAt)
+}
+
+// InQueue melaporkan apakah arsip menunggu keputusan disposisi
+func (s *RetentionState) InQueue() bool {
+	return s != nil && s.ReviewSince != nil
+}
+
+func sameInstant(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Truncate(time.Millisecond).Equal(b.Truncate(time.Millisecond))
+}
+
+// RetentionDisposal adalah satu arsip yang diproses saat retensinya berakhir
+type RetentionDisposal struct {
+	ArchiveID string          `bson:"archive_id" json:"archive_id"`
+	Name      string          `bson:"name" json:"name"`
+	PolicyID  string          `bson:"policy_id" json:"policy_id"`
+	Action    RetentionAction `bson:"action" json:"action"`
+	DueAt     *time.Time      `bson:"due_at,omitempty" json:"due_at,omitempty"`
+	Error     string          `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// RetentionRun adalah laporan satu kali evaluasi retensi
+type RetentionRun struct {
+	ID         primitive.ObjectID  `bson:"_id" json:"id"`
+	StartedAt  time.Time           `bson:"started_at" json:"started_at"`
+	FinishedAt time.Time           `bson:"finished_at" json:"finished_at"`
+	Evaluated  int                 `bson:"evaluated" json:"evaluated"`
+	Scheduled  int                 `bson:"scheduled" json:"scheduled"`
+	Cleared    int                 `bson:"cleared" json:"cleared"`
+	Disposed   []RetentionDisposal `bson:"disposed" json:"disposed"`
+	Error      string              `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+type RetentionRepository interface {
+	CreatePolicy(ctx context.Context, policy *RetentionPolicy) error
+	FindPolicy(ctx context.Context, id string) (*RetentionPolicy, error)
+	FindPolicies(ctx context.Context) ([]RetentionPolicy, error)
+	UpdatePolicy(ctx context.Context, policy *RetentionPolicy) error
+	DeletePolicy(ctx context.Context, id string) error
+	SaveRun(ctx context.Context, run *RetentionRun) error
+	FindRuns(ctx context.Context, page, limit int) ([]RetentionRun, int64, error)
+}
+
+var (
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrRetentionNameRequired   = errors.New("retention policy name is required")
+	ErrRetentionScopeRequired  = errors.New("retention policy needs a category or a type")
+	ErrInvalidRetentionPeriod  = errors.New("invalid retention period")
+	ErrInvalidRetentionTrigger = errors.New("invalid retention trigger")
+	ErrInvalidRetentionAction  = errors.New("invalid retention action")
+	ErrNotUnderReview          = errors.New("archive is not in the disposition queue")
+	ErrInvalidReviewDecision   = errors.New("invalid disposition decision")
+)
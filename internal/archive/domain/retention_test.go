@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSelectPolicyPrefersMostSpecific(t *testing.T) {
+	policies := []RetentionPolicy{
+		{Name: "semua pdf", Type: "pdf", Period: RetentionPeriod{Years: 1}},
+		{Name: "keuangan", Category: "keuangan", Period: RetentionPeriod{Years: 10}},
+		{Name: "pajak", Category: "keuangan.pajak", Period: RetentionPeriod{Years: 5}},
+		{Name: "pajak pdf", Category: "keuangan.pajak", Type: "pdf", Period: RetentionPeriod{Years: 2}},
+		{Name: "pajak panjang", Category: "keuangan.pajak", Period: RetentionPeriod{Years: 7}},
+		{Name: "pajak bulanan", Category: "keuangan.pajak", Period: RetentionPeriod{Months: 90}},
+	}
+
+	tests := []struct {
+		name    string
+		archive Archive
+		want    string
+	}{
+		{"type and category beat category alone", Archive{Category: "keuangan.pajak", Type: "pdf"}, "pajak pdf"},
+		{"type beats a category without type", Archive{Category: "keuangan", Type: "pdf"}, "semua pdf"},
+		{"deepest category, then longest period", Archive{Category: "keuangan.pajak.2024", Type: "xlsx"}, "pajak bulanan"},
+		{"parent category covers sub-categories", Archive{Category: "keuangan.gaji", Type: "xlsx"}, "keuangan"},
+		{"no match", Archive{Category: "hukum", Type: "docx"}, ""},
+		{"prefix is not a parent", Archive{Category: "keuangan2", Type: "docx"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectPolicy(policies, &tt.archive)
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Fatalf("expected policy %q, got %q", tt.want, name)
+			}
+		})
+	}
+}
+
+func TestScheduleUsesTriggerDate(t *testing.T) {
+	created := time.Date(2020, 1, 31, 8, 0, 0, 0, time.UTC)
+	updated := time.Date(2022, 6, 1, 8, 0, 0, 0, time.UTC)
+	event := time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)
+	archive := &Archive{CreatedAt: created, UpdatedAt: updated, EventDate: &event}
+
+	tests := []struct {
+		trigger RetentionTrigger
+		want    time.Time
+	}{
+		// 31 Februari 2021 tidak ada, jadi dinormalisasi ke 3 Maret seperti time.AddDate
+		{TriggerCreated, time.Date(2021, 3, 3, 8, 0, 0, 0, time.UTC)},
+		{TriggerModified, time.Date(2023, 7, 1, 8, 0, 0, 0, time.UTC)},
+		{TriggerEvent, time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.trigger), func(t *testing.T) {
+			policy := &RetentionPolicy{ID: primitive.NewObjectID(), Period: RetentionPeriod{Years: 1, Months: 1}, Trigger: tt.trigger, Action: RetentionSoftDelete}
+			state := Schedule(policy, archive)
+			if state.PolicyID != policy.ID || state.Action != RetentionSoftDelete {
+				t.Fatalf("unexpected state %+v", state)
+			}
+			if state.DueAt == nil || !state.DueAt.Equal(tt.want) {
+				t.Fatalf("expected due %s, got %v", tt.want, state.DueAt)
+			}
+		})
+	}
+
+	// Tanpa event_date, arsip punya kebijakan tetapi belum punya tanggal jatuh tempo
+	policy := &RetentionPolicy{ID: primitive.NewObjectID(), Period: RetentionPeriod{Years: 1}, Trigger: TriggerEvent}
+	if state := Schedule(policy, &Archive{CreatedAt: created}); state == nil || state.DueAt != nil {
+		t.Fatalf("expected a schedule without due date, got %+v", state)
+	}
+	if state := Schedule(nil, archive); state != nil {
+		t.Fatalf("expected no schedule without a policy, got %+v", state)
+	}
+}
+
+func TestScheduleKeepsReviewStateOnlyForSamePolicy(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	reviewed := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+	retainUntil := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &RetentionPolicy{ID: primitive.NewObjectID(), Period: RetentionPeriod{Years: 1}, Trigger: TriggerCreated, Action: RetentionReview}
+	archive := &Archive{CreatedAt: created, Retention: &RetentionState{
+		PolicyID:    policy.ID,
+		ReviewSince: &reviewed,
+		RetainUntil: &retainUntil,
+		ApprovedBy:  "records",
+	}}
+
+	state := Schedule(policy, archive)
+	if !state.InQueue() || state.ApprovedBy != "records" {
+		t.Fatalf("review state must survive a recalculation, got %+v", state)
+	}
+	// Perpanjangan hasil review menggeser jatuh tempo
+	if !state.DueAt.Equal(retainUntil) {
+		t.Fatalf("expected due at the extension %s, got %v", retainUntil, state.DueAt)
+	}
+	if state.Equal(archive.Retention) {
+		t.Fatal("a recalculated state with a new due date must differ from the stored one")
+	}
+
+	other := &RetentionPolicy{ID: primitive.NewObjectID(), Period: RetentionPeriod{Years: 1}, Trigger: TriggerCreated, Action: RetentionReview}
+	state = Schedule(other, archive)
+	if state.InQueue() || state.RetainUntil != nil || state.ApprovedBy != "" {
+		t.Fatalf("a different policy must start a fresh schedule, got %+v", state)
+	}
+}
+
+func TestRetentionPolicyValidateAcceptsHardDelete(t *testing.T) {
+	policy := RetentionPolicy{Name: "kontrak", Category: "hukum", Period: RetentionPeriod{Years: 5}, Trigger: TriggerCreated}
+	for _, action := range []RetentionAction{RetentionSoftDelete, RetentionHardDelete, RetentionReview} {
+		policy.Action = action
+		if err := policy.Validate(); err != nil {
+			t.Fatalf("action %s: %v", action, err)
+		}
+	}
+	policy.Action = "purge"
+	if err := policy.Validate(); !errors.Is(err, ErrInvalidRetentionAction) {
+		t.Fatalf("expected ErrInvalidRetentionAction, got %v", err)
+	}
+}
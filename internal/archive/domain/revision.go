@@ -0,0 +1,10 @@
+package domain
+
+import "errors"
+
+// Versi terbaru selalu berada di bucket utama, sedangkan konten versi sebelumnya
+// disimpan di bucket revisi dengan archive_id yang sama.
+var (
+	ErrRevisionNotFound = errors.New("archive revision not found")
+	ErrInvalidVersion   = errors.New("invalid target version")
+)
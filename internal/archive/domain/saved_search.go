@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SavedSearch struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description" json:"description"`
+	OwnerID     string             `bson:"owner_id" json:"owner_id"`
+	Filter      ArchiveFilter      `bson:"filter" json:"filter"`
+	SharedWith  []string           `bson:"shared_with" json:"shared_with"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CanView mengizinkan pemilik dan pengguna yang diberi akses share
+func (s *SavedSearch) CanView(userID string) bool {
+	if s.OwnerID == userID {
+		return true
+	}
+	for _, id := range s.SharedWith {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// SmartCollection adalah saved search yang ditampilkan sebagai folder virtual
+type SmartCollection struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	OwnerID     string `json:"owner_id"`
+	Shared      bool   `json:"shared"`
+	ResultCount int64  `json:"result_count"`
+}
+
+type SavedSearchRepository interface {
+	Create(ctx context.Context, search *SavedSearch) error
+	FindByID(ctx context.Context, id string) (*SavedSearch, error)
+	FindVisible(ctx context.Context, userID string) ([]SavedSearch, error)
+	Update(ctx context.Context, search *SavedSearch) error
+	Delete(ctx context.Context, id string) error
+}
+
+var (
+	ErrSavedSearchNotFound     = errors.New("saved search not found")
+	ErrSavedSearchExists       = errors.New("saved search with this name already exists")
+	ErrSavedSearchForbidden    = errors.New("saved search belongs to another user")
+	ErrSavedSearchNameRequired = errors.New("saved search name is required")
+)
@@ -0,0 +1,22 @@
+package domain
+
+import "errors"
+
+// Fingerprinter membuat signature konten untuk deteksi dokumen yang mirip
+type Fingerprinter interface {
+	// Fingerprint mengembalikan nil bila konten tidak memiliki teks
+	Fingerprint(content []byte) []uint64
+}
+
+type SimilarArchive struct {
+	Archive Archive
+	Score   float64
+}
+
+// UploadResult adalah hasil upload beserta arsip lain yang isinya mirip
+type UploadResult struct {
+	Archive *Archive
+	Similar []SimilarArchive
+}
+
+var ErrNoSignature = errors.New("archive has no extractable text to compare")
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StorageTier menunjukkan di mana konten arsip disimpan
+type StorageTier string
+
+const (
+	// TierHot berarti konten ada di chunks GridFS utama
+	TierHot StorageTier = "hot"
+	// TierCold berarti konten sudah dipindah ke cold store, metadata tetap di GridFS
+	TierCold StorageTier = "cold"
+)
+
+// Action tiering yang dicatat pada change log
+const (
+	ActionMoveToCold = "move_to_cold"
+	ActionRehydrate  = "rehydrate"
+)
+
+// CurrentTier mengembalikan tier arsip; arsip lama tanpa field tier dianggap hot
+func (a *Archive) CurrentTier() StorageTier {
+	if a.Tier == "" {
+		return TierHot
+	}
+	return a.Tier
+}
+
+// Cold melaporkan apakah konten arsip ada di cold store
+func (a *Archive) Cold() bool {
+	return a.Tier == TierCold
+}
+
+// ColdStore menyimpan konten arsip yang jarang diakses di luar GridFS utama
+type ColdStore interface {
+	Put(ctx context.Context, key string, content []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// RetrievalMode menentukan perilaku download arsip cold
+type RetrievalMode string
+
+const (
+	// RetrievalSync mengembalikan arsip ke hot tier saat itu juga lalu mengirim kontennya
+	RetrievalSync RetrievalMode = "sync"
+	// RetrievalAsync membuat retrieval job dan download dijawab 202
+	RetrievalAsync RetrievalMode = "async"
+)
+
+// TierPolicy menentukan arsip mana yang dipindah ke cold tier. Arsip dipindah bila
+// umurnya minimal MinAge dan tidak diakses selama IdleFor. Categories kosong berarti
+// semua kategori, selain itu hanya kategori tersebut beserta turunannya.
+type TierPolicy struct {
+	MinAge     time.Duration
+	IdleFor    time.Duration
+	Categories []string
+	Retrieval  RetrievalMode
+}
+
+func (p TierPolicy) Validate() error {
+	if p.MinAge < 0 || p.IdleFor < 0 {
+		return ErrInvalidTierPolicy
+	}
+	if p.Retrieval != RetrievalSync && p.Retrieval != RetrievalAsync {
+		return ErrInvalidRetrievalMode
+	}
+	return nil
+}
+
+// Cutoffs adalah batas waktu yang dipakai repository untuk mencari arsip yang akan dipindah
+func (p TierPolicy) Cutoffs(now time.Time) (createdBefore, idleSince time.Time) {
+	return now.Add(-p.MinAge), now.Add(-p.IdleFor)
+}
+
+// TieringRun adalah ringkasan satu kali evaluasi kebijakan tiering
+type TieringRun struct {
+	Evaluated int           `json:"evaluated"`
+	Moved     int           `json:"moved"`
+	MovedSize int64         `json:"moved_size"`
+	Failures  []BulkFailure `json:"failures"`
+}
+
+type RetrievalStatus string
+
+const (
+	RetrievalPending   RetrievalStatus = "pending"
+	RetrievalRunning   RetrievalStatus = "running"
+	RetrievalCompleted RetrievalStatus = "completed"
+	RetrievalFailed    RetrievalStatus = "failed"
+)
+
+// RetrievalJob mencatat pengembalian arsip cold ke hot tier yang berjalan di background
+type RetrievalJob struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	ArchiveID   string             `bson:"archive_id" json:"archive_id"`
+	Status      RetrievalStatus    `bson:"status" json:"status"`
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	RequestedBy string             `bson:"requested_by" json:"requested_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	StartedAt   *time.Time         `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt  *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	// Owner adalah replika yang menjalankan job; HeartbeatAt diperbarui selama job berjalan
+	Owner       string     `bson:"owner,omitempty" json:"owner,omitempty"`
+	HeartbeatAt *time.Time `bson:"heartbeat_at,omitempty" json:"-"`
+}
+
+type RetrievalJobRepository interface {
+	Create(ctx context.Context, job *RetrievalJob) error
+	FindByID(ctx context.Context, id string) (*RetrievalJob, error)
+	// FindActive mengembalikan job pending atau running untuk arsip, nil bila tidak ada
+	FindActive(ctx context.Context, archiveID string) (*RetrievalJob, error)
+	Update(ctx context.Context, job *RetrievalJob) error
+	// Heartbeat menandai job yang masih berjalan sebagai hidup
+	Heartbeat(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// FailInterrupted menandai job pending atau running milik owner, atau yang tanda hidupnya
+	// lebih lama dari staleBefore, sebagai gagal
+	FailInterrupted(ctx context.Context, owner string, staleBefore time.Time) (int64, error)
+}
+
+var (
+	ErrArchiveCold           = errors.New("archive content is in cold storage")
+	ErrNotCold               = errors.New("archive is not in cold storage")
+	ErrColdObjectNotFound    = errors.New("cold storage object not found")
+	ErrColdStoreDisabled     = errors.New("cold storage is not configured")
+	ErrRetrievalJobNotFound  = errors.New("retrieval job not found")
+	ErrInvalidTier           = errors.New("invalid tier, use hot or cold")
+	ErrInvalidTierPolicy     = errors.New("invalid tiering policy")
+	ErrInvalidRetrievalMode  = errors.New("invalid cold retrieval mode, use sync or async")
+	ErrTierConflict          = errors.New("archive changed while moving between tiers")
+	ErrUnknownColdBackend    = errors.New("unknown cold storage backend, use filesystem or mongo")
+	ErrColdDirectoryRequired = errors.New("cold storage directory is required")
+)
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis event arsip yang bisa dilanggan webhook
+const (
+	EventArchiveUploaded  = "archive.uploaded"
+	EventArchiveVersioned = "archive.versioned"
+	EventArchiveDeleted   = "archive.deleted"
+	EventArchiveRestored  = "archive.restored"
+	EventArchiveExpired   = "archive.expired"
+
+	// EventAll berarti langganan menerima semua jenis event
+	EventAll = "*"
+)
+
+// WebhookEvents adalah daftar jenis event yang valid
+var WebhookEvents = []string{
+	EventArchiveUploaded,
+	EventArchiveVersioned,
+	EventArchiveDeleted,
+	EventArchiveRestored,
+	EventArchiveExpired,
+}
+
+// ArchiveEvent adalah kejadian pada arsip yang dikirim ke webhook
+type ArchiveEvent struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// EventPublisher meneruskan event arsip ke pelanggan
+type EventPublisher interface {
+	Publish(ctx context.Context, event ArchiveEvent) error
+}
+
+// WebhookSubscription adalah URL yang menerima event arsip. Secret dipakai untuk
+// tanda tangan HMAC dan hanya ditampilkan saat langganan dibuat.
+type WebhookSubscription struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	URL         string             `bson:"url" json:"url"`
+	Events      []string           `bson:"events" json:"events"`
+	Secret      string             `bson:"secret" json:"-"`
+	Active      bool               `bson:"active" json:"active"`
+	Description string             `bson:"description" json:"description"`
+	CreatedBy   string             `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Wants melaporkan apakah langganan menerima jenis event
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	for _, event := range s.Events {
+		if event == eventType || event == EventAll {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate memeriksa URL http(s) dan jenis event langganan
+func (s *WebhookSubscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if len(s.Events) == 0 {
+		return ErrInvalidWebhookEvents
+	}
+	for _, event := range s.Events {
+		if !validWebhookEvent(event) {
+			return ErrInvalidWebhookEvents
+		}
+	}
+	return nil
+}
+
+func validWebhookEvent(event string) bool {
+	if event == EventAll {
+		return true
+	}
+	for _, valid := range WebhookEvents {
+		if event == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscriptionPatch berisi field langganan yang boleh diubah; nil berarti tidak diubah
+type WebhookSubscriptionPatch struct {
+	URL         *string
+	Events      []string
+	Active      *bool
+	Description *string
+}
+
+// DeliveryStatus adalah status pengiriman satu event ke satu langganan
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryDead berarti pengiriman berhenti dicoba setelah batas percobaan
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// Validate mengizinkan status kosong, yang berarti semua status
+func (s DeliveryStatus) Validate() error {
+	switch s {
+	case "", DeliveryPending, DeliveryDelivered, DeliveryDead:
+		return nil
+	}
+	return ErrInvalidDeliveryStatus
+}
+
+// WebhookAttempt adalah hasil satu kali percobaan kirim
+type WebhookAttempt struct {
+	At         time.Time `bson:"at" json:"at"`
+	StatusCode int       `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMS int64     `bson:"duration_ms" json:"duration_ms"`
+}
+
+// WebhookDelivery mencatat pengiriman satu event ke satu langganan beserta semua percobaannya
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `bson:"_id" json:"id"`
+	SubscriptionID string             `bson:"subscription_id" json:"subscription_id"`
+	EventID        string             `bson:"event_id" json:"event_id"`
+	EventType      string             `bson:"event_type" json:"event_type"`
+	URL            string             `bson:"url" json:"url"`
+	// Payload adalah body JSON yang dikirim, disimpan apa adanya agar tanda tangannya sama saat dikirim ulang
+	Payload       string           `bson:"payload" json:"-"`
+	Status        DeliveryStatus   `bson:"status" json:"status"`
+	Attempts      []WebhookAttempt `bson:"attempts" json:"attempts"`
+	AttemptCount  int              `bson:"attempt_count" json:"attempt_count"`
+	NextAttemptAt *time.Time       `bson:"next_attempt_at,omitempty" json:"next_attempt_at,omitempty"`
+	// LockedUntil mencegah dua worker mengirim delivery yang sama
+	LockedUntil *time.Time `bson:"locked_until,omitempty" json:"-"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	DeliveredAt *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	// RedeliveryOf terisi bila delivery ini hasil kirim ulang delivery lain
+	RedeliveryOf string `bson:"redelivery_of,omitempty" json:"redelivery_of,omitempty"`
+}
+
+// WebhookRequest adalah satu HTTP request ke URL langganan
+type WebhookRequest struct {
+	URL     string
+	Body    []byte
+	Headers map[string]string
+}
+
+// WebhookSender mengirim request webhook dan mengembalikan status code HTTP-nya
+type WebhookSender interface {
+	Send(ctx context.Context, req WebhookRequest) (int, error)
+}
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *WebhookSubscription) error
+	FindByID(ctx context.Context, id string) (*WebhookSubscription, error)
+	FindAll(ctx context.Context, page, limit int) ([]WebhookSubscription, int64, error)
+	// FindByEvent mengembalikan langganan aktif yang menerima jenis event
+	FindByEvent(ctx context.Context, eventType string) ([]WebhookSubscription, error)
+	Update(ctx context.Context, subscription *WebhookSubscription) error
+	Delete(ctx context.Context, id string) error
+}
+
+type WebhookDeliveryRepository interface {
+	CreateMany(ctx context.Context, deliveries []WebhookDelivery) error
+	FindByID(ctx context.Context, id string) (*WebhookDelivery, error)
+	// FindBySubscription menampilkan delivery tanpa payload dan riwayat percobaan, terbaru lebih dulu
+	FindBySubscription(ctx context.Context, subscriptionID string, status DeliveryStatus, page, limit int) ([]WebhookDelivery, int64, error)
+	// ClaimDue mengunci satu delivery pending yang sudah waktunya dikirim selama lease;
+	// nil bila tidak ada
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*WebhookDelivery, error)
+	// Update menyimpan hasil percobaan dan melepas kunci delivery. ErrWebhookLeaseLost bila
+	// kunci dari ClaimDue sudah habis atau diambil worker lain.
+	Update(ctx context.Context, delivery *WebhookDelivery) error
+}
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookLeaseLost        = errors.New("webhook delivery lease expired before the attempt was saved")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookEvents    = errors.New("webhook events must be one or more of archive.uploaded, archive.versioned, archive.deleted, archive.restored, archive.expired or *")
+	ErrInvalidDeliveryStatus   = errors.New("invalid delivery status, must be pending, delivered or dead")
+)
@@ -0,0 +1,118 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BulkJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewBulkJobRepository(client *mongo.Client, dbName string) (*BulkJobRepository, error) {
+	collection := client.Database(dbName).Collection("bulk_jobs")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_by", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bulk job indexes: %v", err)
+	}
+
+	return &BulkJobRepository{collection: collection}, nil
+}
+
+func (r *BulkJobRepository) Create(ctx context.Context, job *domain.BulkJob) error {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("failed to insert bulk job: %v", err)
+	}
+	return nil
+}
+
+func (r *BulkJobRepository) FindByID(ctx context.Context, id string) (*domain.BulkJob, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrBulkJobNotFound
+	}
+
+	var job domain.BulkJob
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&job); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrBulkJobNotFound
+		}
+		return nil, fmt.Errorf("failed to find bulk job: %v", err)
+	}
+	return &job, nil
+}
+
+func (r *BulkJobRepository) FindByUser(ctx context.Context, userID string, page, limit int) ([]domain.BulkJob, int64, error) {
+	filter := bson.M{"created_by": userID}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count bulk jobs: %v", err)
+	}
+
+	// Daftar ID dan kegagalan bisa besar, cukup ditampilkan di detail job
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"ids": 0, "failures": 0})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find bulk jobs: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	jobs := []domain.BulkJob{}
+	if err := cur.All(ctx, &jobs); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode bulk jobs: %v", err)
+	}
+	return jobs, total, nil
+}
+
+func (r *BulkJobRepository) Update(ctx context.Context, job *domain.BulkJob) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	if err != nil {
+		return fmt.Errorf("failed to update bulk job: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrBulkJobNotFound
+	}
+	return nil
+}
+
+func (r *BulkJobRepository) FailInterrupted(ctx context.Context) (int64, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"status": bson.M{"$in": []domain.BulkJobStatus{domain.BulkJobPending, domain.BulkJobRunning}}},
+		bson.M{"$set": bson.M{
+			"status":      domain.BulkJobFailed,
+			"error":       "interrupted by server restart",
+			"finished_at": now,
+		}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark interrupted bulk jobs: %v", err)
+	}
+	return result.ModifiedCount, nil
+}
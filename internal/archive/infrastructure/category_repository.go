@@ -0,0 +1,145 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CategoryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCategoryRepository(client *mongo.Client, dbName string) (*CategoryRepository, error) {
+	collection := client.Database(dbName).Collection("categories")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Path harus unik agar satu kategori tidak terdaftar dua kali
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "path", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category indexes: %v", err)
+	}
+
+	return &CategoryRepository{collection: collection}, nil
+}
+
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	if category.ID.IsZero() {
+		category.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.collection.InsertOne(ctx, category)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrCategoryExists
+		}
+		return fmt.Errorf("failed to insert category: %v", err)
+	}
+	return nil
+}
+
+func (r *CategoryRepository) FindByID(ctx context.Context, id string) (*domain.Category, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return r.findOne(ctx, bson.M{"_id": objID})
+}
+
+func (r *CategoryRepository) FindByPath(ctx context.Context, path string) (*domain.Category, error) {
+	return r.findOne(ctx, bson.M{"path": path})
+}
+
+func (r *CategoryRepository) findOne(ctx context.Context, filter bson.M) (*domain.Category, error) {
+	var category domain.Category
+	err := r.collection.FindOne(ctx, filter).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to find category: %v", err)
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepository) FindAll(ctx context.Context, includeDeprecated bool) ([]domain.Category, error) {
+	filter := bson.M{}
+	if !includeDeprecated {
+		filter["status"] = domain.CategoryActive
+	}
+	return r.find(ctx, filter)
+}
+
+func (r *CategoryRepository) FindDescendants(ctx context.Context, id primitive.ObjectID) ([]domain.Category, error) {
+	return r.find(ctx, bson.M{"ancestors": id})
+}
+
+func (r *CategoryRepository) find(ctx context.Context, filter bson.M) ([]domain.Category, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "path", Value: 1}})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categories: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var categories []domain.Category
+	if err := cur.All(ctx, &categories); err != nil {
+		return nil, fmt.Errorf("failed to decode categories: %v", err)
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	set := bson.M{
+		"code":        category.Code,
+		"name":        category.Name,
+		"description": category.Description,
+		"ancestors":   category.Ancestors,
+		"path":        category.Path,
+		"status":      category.Status,
+		"updated_at":  category.UpdatedAt,
+	}
+	unset := bson.M{}
+	if category.ParentID != nil {
+		set["parent_id"] = category.ParentID
+	} else {
+		unset["parent_id"] = ""
+	}
+	if category.DeprecatedAt != nil {
+		set["deprecated_at"] = category.DeprecatedAt
+	}
+	if category.Relocation != nil {
+		set["relocation"] = category.Relocation
+	} else {
+		unset["relocation"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": category.ID}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrCategoryExists
+		}
+		return fmt.Errorf("failed to update category: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+const (
+	// Ukuran halaman A4 dalam point dan tata letak teks sertifikat
+	certPageWidth    = 595
+	certPageHeight   = 842
+	certMargin       = 50
+	certFontSize     = 9
+	certLineHeight   = 12
+	certLinesPerPage = (certPageHeight - 2*certMargin) / certLineHeight
+)
+
+// PDFCertificateRenderer menulis sertifikat pemusnahan sebagai PDF teks sederhana
+// dengan font standar Courier, tanpa library tambahan
+type PDFCertificateRenderer struct{}
+
+func NewPDFCertificateRenderer() *PDFCertificateRenderer {
+	return &PDFCertificateRenderer{}
+}
+
+func (r *PDFCertificateRenderer) Render(cert *domain.DispositionCertificate) ([]byte, error) {
+	lines := []string{
+		"CERTIFICATE OF DESTRUCTION",
+		"",
+		"Certificate ID : " + cert.ID.Hex(),
+		"Executed by    : " + cert.ExecutedBy,
+		"Executed at    : " + cert.ExecutedAt.UTC().Format(time.RFC3339),
+		"Approvers      : " + strings.Join(cert.Approvers, ", "),
+		fmt.Sprintf("Records        : %d", len(cert.Items)),
+		"",
+	}
+	for i, item := range cert.Items {
+		due := "-"
+		if item.DueAt != nil {
+			due = item.DueAt.UTC().Format(time.RFC3339)
+		}
+		lines = append(lines,
+			fmt.Sprintf("%d. %s (version %d, %d bytes)", i+1, item.Name, item.Version, item.Size),
+			"   Archive ID   : "+item.ArchiveID,
+			"   SHA-256      : "+item.SHA256,
+			"   Policy       : "+item.PolicyID+", due "+due,
+			"   Approved     : "+item.ApprovedBy+" at "+item.ApprovedAt.UTC().Format(time.RFC3339),
+			"   Destroyed at : "+item.DestroyedAt.UTC().Format(time.RFC3339),
+			"",
+		)
+	}
+	lines = append(lines,
+		"Signature (HMAC-SHA256):",
+		cert.Signature,
+	)
+	return renderTextPDF(lines), nil
+}
+
+// renderTextPDF menyusun PDF 1.4 berisi baris teks, dipecah per halaman
+func renderTextPDF(lines []string) []byte {
+	var pages [][]string
+	for start := 0; start < len(lines); start += certLinesPerPage {
+		pages = append(pages, lines[start:min(start+certLinesPerPage, len(lines))])
+	}
+
+	// Objek 1 catalog, 2 pages, 3 font, lalu pasangan page dan content per halaman
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for i, page := range pages {
+		pageObj := 4 + i*2
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", certFontSize, certLineHeight, certMargin, certPageHeight-certMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escapePDFText(line))
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				certPageWidth, certPageHeight, pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// escapePDFText meng-escape karakter khusus string PDF; karakter non-ASCII diganti '?'
+func escapePDFText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
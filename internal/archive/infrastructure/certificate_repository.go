@@ -0,0 +1,120 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CertificateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCertificateRepository(client *mongo.Client, dbName string) (*CertificateRepository, error) {
+	collection := client.Database(dbName).Collection("disposition_certificates")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "executed_at", Value: -1}}},
+		{Keys: bson.D{{Key: "items.archive_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "executed_at", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate indexes: %v", err)
+	}
+
+	return &CertificateRepository{collection: collection}, nil
+}
+
+func (r *CertificateRepository) Create(ctx context.Context, cert *domain.DispositionCertificate) error {
+	if cert.ID.IsZero() {
+		cert.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, cert); err != nil {
+		return fmt.Errorf("failed to insert certificate: %v", err)
+	}
+	return nil
+}
+
+func (r *CertificateRepository) Finalize(ctx context.Context, cert *domain.DispositionCertificate) error {
+	cert.Status = domain.CertificateFinal
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": cert.ID, "status": domain.CertificatePending}, cert)
+	if err != nil {
+		return fmt.Errorf("failed to finalize certificate: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCertificateNotFound
+	}
+	return nil
+}
+
+func (r *CertificateRepository) FindPending(ctx context.Context, before time.Time) ([]domain.DispositionCertificate, error) {
+	cur, err := r.collection.Find(ctx, bson.M{
+		"status":      domain.CertificatePending,
+		"executed_at": bson.M{"$lt": before},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending certificates: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	certs := []domain.DispositionCertificate{}
+	if err := cur.All(ctx, &certs); err != nil {
+		return nil, fmt.Errorf("failed to decode certificates: %v", err)
+	}
+	return certs, nil
+}
+
+func (r *CertificateRepository) FindByID(ctx context.Context, id string) (*domain.DispositionCertificate, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrCertificateNotFound
+	}
+
+	var cert domain.DispositionCertificate
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&cert); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrCertificateNotFound
+		}
+		return nil, fmt.Errorf("failed to find certificate: %v", err)
+	}
+	return &cert, nil
+}
+
+func (r *CertificateRepository) FindAll(ctx context.Context, page, limit int) ([]domain.DispositionCertificate, int64, error) {
+	filter := bson.M{}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count certificates: %v", err)
+	}
+
+	// Daftar item bisa besar, cukup ditampilkan di detail sertifikat
+	opts := options.Find().
+		SetSort(bson.D{{Key: "executed_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"items": 0})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find certificates: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	certs := []domain.DispositionCertificate{}
+	if err := cur.All(ctx, &certs); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode certificates: %v", err)
+	}
+	return certs, total, nil
+}
@@ -0,0 +1,320 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// trackedField adalah atribut Archive yang dicatat perubahannya
+type trackedField struct {
+	name  string
+	value func(a *domain.Archive) interface{}
+}
+
+var trackedFields = []trackedField{
+	{"name", func(a *domain.Archive) interface{} { return a.Name }},
+	{"size", func(a *domain.Archive) interface{} { return a.Size }},
+	{"category", func(a *domain.Archive) interface{} { return a.Category }},
+	{"type", func(a *domain.Archive) interface{} { return a.Type }},
+	{"tags", func(a *domain.Archive) interface{} { return normalizeStrings(a.Tags) }},
+	{"description", func(a *domain.Archive) interface{} { return a.Description }},
+	{"owner_id", func(a *domain.Archive) interface{} { return a.OwnerID }},
+	{"version", func(a *domain.Archive) interface{} { return a.Version }},
+	{"is_temp", func(a *domain.Archive) interface{} { return a.IsTemp }},
+	{"deleted_at", func(a *domain.Archive) interface{} { return timeOrNil(a.DeletedAt) }},
+	{"deleted_by", func(a *domain.Archive) interface{} { return a.DeletedBy }},
+	{"expires_at", func(a *domain.Archive) interface{} { return timeOrNil(a.ExpiresAt) }},
+	{"folder_id", func(a *domain.Archive) interface{} { return a.FolderID }},
+	{"event_date", func(a *domain.Archive) interface{} { return timeOrNil(a.EventDate) }},
+	{"superseded_by", func(a *domain.Archive) interface{} { return a.SupersededBy }},
+	{"state", func(a *domain.Archive) interface{} { return string(a.State) }},
+	{"reviewers", func(a *domain.Archive) interface{} { return normalizeStrings(a.Reviewers) }},
+	{"legal_holds", func(a *domain.Archive) interface{} { return normalizeStrings(a.LegalHolds) }},
+	{"tier", func(a *domain.Archive) interface{} { return string(a.CurrentTier()) }},
+	{"locked_by", func(a *domain.Archive) interface{} {
+		if a.Lock == nil {
+			return nil
+		}
+		return a.Lock.Owner
+	}},
+	{"lock_expires_at", func(a *domain.Archive) interface{} {
+		if a.Lock == nil {
+			return nil
+		}
+		return timeOrNil(&a.Lock.ExpiresAt)
+	}},
+	// Status retensi dicatat per field agar nilai lama/baru tetap bertipe sederhana
+	{"retention_policy_id", func(a *domain.Archive) interface{} {
+		if a.Retention == nil {
+			return ""
+		}
+		return a.Retention.PolicyID.Hex()
+	}},
+	{"retention_action", func(a *domain.Archive) interface{} {
+		if a.Retention == nil {
+			return ""
+		}
+		return string(a.Retention.Action)
+	}},
+	{"retention_due_at", func(a *domain.Archive) interface{} {
+		if a.Retention == nil {
+			return nil
+		}
+		return timeOrNil(a.Retention.DueAt)
+	}},
+	{"retention_review_since", func(a *domain.Archive) interface{} {
+		if a.Retention == nil {
+			return nil
+		}
+		return timeOrNil(a.Retention.ReviewSince)
+	}},
+	{"retention_retain_until", func(a *domain.Archive) interface{} {
+		if a.Retention == nil {
+			return nil
+		}
+		return timeOrNil(a.Retention.RetainUntil)
+	}},
+	{"disposition_approved_by", func(a *domain.Archive) interface{} {
+		if a.Retention == nil {
+			return ""
+		}
+		return a.Retention.ApprovedBy
+	}},
+	{"disposition_approved_at", func(a *domain.Archive) interface{} {
+		if a.Retention == nil {
+			return nil
+		}
+		return timeOrNil(a.Retention.ApprovedAt)
+	}},
+}
+
+// DiffArchives membandingkan semua field yang dilacak dan mengembalikan perubahan
+// dengan nilai lama/baru bertipe asli (string, int, []string, time.Time).
+// old nil diperlakukan sebagai arsip kosong, misalnya saat upload pertama.
+func DiffArchives(old, new *domain.Archive) []domain.Change {
+	if old == nil {
+		old = &domain.Archive{}
+	}
+	if new == nil {
+		new = &domain.Archive{}
+	}
+
+	changes := []domain.Change{}
+	for _, field := range trackedFields {
+		oldValue, newValue := field.value(old), field.value(new)
+		if valuesEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, domain.Change{
+			Field:    field.name,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+	return changes
+}
+
+func NewChangeLog(action, userID string, changes []domain.Change) domain.ChangeLog {
+	if changes == nil {
+		changes = []domain.Change{}
+	}
+	return domain.ChangeLog{
+		Timestamp: time.Now(),
+		Action:    action,
+		UserID:    userID,
+		Changes:   changes,
+	}
+}
+
+func CreateChangeLog(action, userID string, old, new *domain.Archive) domain.ChangeLog {
+	return NewChangeLog(action, userID, DiffArchives(old, new))
+}
+
+// withChangeLog menambahkan $push change log ke update sehingga entri baru
+// ditambahkan secara atomik bersama perubahan lainnya
+func withChangeLog(update bson.M, changeLog domain.ChangeLog) bson.M {
+	push, _ := update["$push"].(bson.M)
+	if push == nil {
+		push = bson.M{}
+	}
+	push["metadata.change_logs"] = changeLog
+	update["$push"] = push
+	return update
+}
+
+// removeFile menghapus file beserta chunks dan revisinya. Sebelum dihapus, metadata dan
+// riwayat lengkap ditambah entri terakhir disimpan sebagai tombstone agar
+// GetHistory tetap bisa menampilkan riwayat arsip yang sudah dihapus permanen.
+// guard berisi syarat tambahan yang harus tetap berlaku sampai file dihapus; arsip yang
+// tidak memenuhinya menghasilkan ErrVersionConflict.
+func (r *ArchiveRepository) removeFile(ctx context.Context, id primitive.ObjectID, action, userID string, guard bson.M) error {
+	filter := bson.M{"_id": id}
+	for key, value := range guard {
+		filter[key] = value
+	}
+
+	var file bson.M
+	if err := r.bucket.GetFilesCollection().FindOne(ctx, filter).Decode(&file); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if len(guard) > 0 {
+				return r.removeConflict(ctx, id)
+			}
+			return domain.ErrArchiveNotFound
+		}
+		return fmt.Errorf("failed to find document: %v", err)
+	}
+
+	archive := mapToArchive(file)
+	if archive.OnHold() {
+		return domain.ErrUnderLegalHold
+	}
+	// Arsip musnah seluruhnya, jadi semua field tercatat berubah menjadi kosong
+	changeLog := CreateChangeLog(action, userID, &archive, nil)
+
+	tombstone := bson.M{
+		"_id":         id,
+		"filename":    archive.Name,
+		"length":      archive.Size,
+		"metadata":    file["metadata"],
+		"change_logs": append(archive.ChangeLogs, changeLog),
+		"removed_at":  changeLog.Timestamp,
+		"removed_by":  userID,
+		"reason":      action,
+	}
+	_, err := r.tombstones.ReplaceOne(ctx, bson.M{"_id": id}, tombstone, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to write tombstone: %v", err)
+	}
+
+	// Pemeriksaan hold dan guard di atas bisa basi. Dokumen files hanya dihapus bila masih
+	// tidak dibekukan, masih versi yang sama dan masih memenuhi guard, baru setelah itu
+	// chunks-nya disentuh.
+	filter["metadata.version"] = archive.Version
+	result, err := r.bucket.GetFilesCollection().DeleteOne(ctx, notHeld(filter))
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		// Tombstone yang tertinggal membuat arsip yang masih ada tercatat sudah musnah
+		if _, err := r.tombstones.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+			return fmt.Errorf("failed to delete tombstone: %v", err)
+		}
+		return r.removeConflict(ctx, id)
+	}
+
+	chunks := r.bucket.GetChunksCollection()
+	if _, err := chunks.DeleteMany(ctx, bson.M{"files_id": id}); err != nil {
+		return fmt.Errorf("failed to delete chunks: %v", err)
+	}
+	// Konten versi terbaru bisa berada di chunks dengan files_id berbeda dari ID arsip
+	if contentID(&archive) != id {
+		if _, err := chunks.DeleteMany(ctx, bson.M{"files_id": contentID(&archive)}); err != nil {
+			return fmt.Errorf("failed to delete chunks: %v", err)
+		}
+	}
+	if err := r.dropCold(ctx, &archive); err != nil {
+		return err
+	}
+	if err := r.removeRevisions(ctx, id); err != nil {
+		return err
+	}
+	for _, hook := range r.onRemove {
+		hook(ctx, id.Hex())
+	}
+	return nil
+}
+
+// removeConflict menjelaskan penghapusan bersyarat yang gagal: arsip sudah dihapus,
+// baru saja dibekukan, atau berganti versi di tengah proses
+func (r *ArchiveRepository) removeConflict(ctx context.Context, id primitive.ObjectID) error {
+	var file bson.M
+	err := r.bucket.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"_id": id},
+		options.FindOne().SetProjection(bson.M{"metadata.legal_holds": 1}),
+	).Decode(&file)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.ErrArchiveNotFound
+		}
+		return fmt.Errorf("failed to find document: %v", err)
+	}
+	if archive := mapToArchive(file); archive.OnHold() {
+		return domain.ErrUnderLegalHold
+	}
+	return domain.ErrVersionConflict
+}
+
+// removeMatching menghapus semua file yang cocok dengan filter lewat removeFile.
+// Arsip dalam legal hold dilewati; bila ada yang dilewati, ErrUnderLegalHold
+// dikembalikan bersama daftar arsip yang tetap terhapus.
+func (r *ArchiveRepository) removeMatching(ctx context.Context, filter bson.M, action string) ([]string, error) {
+	ids, err := r.findIDs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0, len(ids))
+	held := 0
+	for _, id := range ids {
+		if err := r.removeFile(ctx, id, action, domain.SystemUserID, nil); err != nil {
+			// Arsip yang sudah hilang atau berganti versi di tengah proses dilewati
+			if errors.Is(err, domain.ErrArchiveNotFound) || errors.Is(err, domain.ErrVersionConflict) {
+				continue
+			}
+			if errors.Is(err, domain.ErrUnderLegalHold) {
+				held++
+				continue
+			}
+			return removed, err
+		}
+		removed = append(removed, id.Hex())
+	}
+	if held > 0 {
+		return removed, domain.ErrUnderLegalHold
+	}
+	return removed, nil
+}
+
+func normalizeStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func timeOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+func valuesEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case []string:
+		bv, ok := b.([]string)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if av[i] != bv[i] {
+				return false
+			}
+		}
+		return true
+	case time.Time:
+		bv, ok := b.(time.Time)
+		return ok && av.Equal(bv)
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func changedFields(changes []domain.Change) map[string]domain.Change {
+	fields := make(map[string]domain.Change, len(changes))
+	for _, change := range changes {
+		fields[change.Field] = change
+	}
+	return fields
+}
+
+func TestDiffArchivesTracksStateHoldsRetentionAndLock(t *testing.T) {
+	now := time.Now()
+	old := &domain.Archive{
+		Name:  "laporan.pdf",
+		State: domain.StateInReview,
+		Retention: &domain.RetentionState{
+			PolicyID:    primitive.NewObjectID(),
+			ReviewSince: &now,
+		},
+	}
+	updated := *old
+	retention := *old.Retention
+	retention.ApprovedBy = "records"
+	retention.ApprovedAt = &now
+	updated.Retention = &retention
+	updated.State = domain.StateApproved
+	updated.Reviewers = []string{"bob"}
+	updated.LegalHolds = []string{"hold-1"}
+	updated.SupersededBy = "next"
+	updated.Tier = domain.TierCold
+	updated.Lock = &domain.ArchiveLock{Owner: "alice", ExpiresAt: now.Add(time.Hour)}
+
+	fields := changedFields(DiffArchives(old, &updated))
+	for _, field := range []string{
+		"state", "reviewers", "legal_holds", "superseded_by", "tier",
+		"locked_by", "lock_expires_at", "disposition_approved_by", "disposition_approved_at",
+	} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("expected change for %s", field)
+		}
+	}
+	for _, field := range []string{"name", "retention_policy_id", "retention_review_since"} {
+		if change, ok := fields[field]; ok {
+			t.Errorf("unexpected change for %s: %v -> %v", field, change.OldValue, change.NewValue)
+		}
+	}
+	if change := fields["disposition_approved_by"]; change.OldValue != "" || change.NewValue != "records" {
+		t.Errorf("disposition_approved_by: got %v -> %v", change.OldValue, change.NewValue)
+	}
+}
+
+func TestDiffArchivesRemovalClearsEveryField(t *testing.T) {
+	archive := &domain.Archive{Name: "laporan.pdf", Version: 3, LegalHolds: []string{}, Tier: domain.TierCold}
+
+	fields := changedFields(DiffArchives(archive, nil))
+	if change := fields["name"]; change.OldValue != "laporan.pdf" || change.NewValue != "" {
+		t.Errorf("name: got %v -> %v", change.OldValue, change.NewValue)
+	}
+	if change := fields["tier"]; change.OldValue != string(domain.TierCold) || change.NewValue != string(domain.TierHot) {
+		t.Errorf("tier: got %v -> %v", change.OldValue, change.NewValue)
+	}
+	if _, ok := fields["legal_holds"]; ok {
+		t.Errorf("empty legal holds must not be reported as changed")
+	}
+}
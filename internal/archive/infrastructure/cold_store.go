@@ -0,0 +1,200 @@
+package infrastructure
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Backend cold store yang bisa dipilih lewat konfigurasi
+const (
+	ColdBackendFilesystem = "filesystem"
+	ColdBackendMongo      = "mongo"
+)
+
+// NewColdStore membuat cold store sesuai backend. Backend kosong berarti tiering
+// dimatikan dan nil dikembalikan tanpa error.
+func NewColdStore(backend, dir string, client *mongo.Client, dbName string) (domain.ColdStore, error) {
+	switch backend {
+	case "":
+		return nil, nil
+	case ColdBackendFilesystem:
+		return NewFilesystemColdStore(dir)
+	case ColdBackendMongo:
+		return NewMongoColdStore(client, dbName)
+	default:
+		return nil, domain.ErrUnknownColdBackend
+	}
+}
+
+// FilesystemColdStore menyimpan konten sebagai file gzip di satu direktori
+type FilesystemColdStore struct {
+	dir string
+}
+
+func NewFilesystemColdStore(dir string) (*FilesystemColdStore, error) {
+	if dir == "" {
+		return nil, domain.ErrColdDirectoryRequired
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create cold storage directory: %v", err)
+	}
+	return &FilesystemColdStore{dir: dir}, nil
+}
+
+func (s *FilesystemColdStore) path(key string) string {
+	return filepath.Join(s.dir, key+".gz")
+}
+
+// Put menulis ke file sementara lalu rename, supaya file setengah jadi tidak pernah terbaca
+func (s *FilesystemColdStore) Put(_ context.Context, key string, content []byte) error {
+	compressed, err := gzipBytes(content)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, key+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cold object: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(compressed); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cold object: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync cold object: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close cold object: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return fmt.Errorf("failed to store cold object: %v", err)
+	}
+	return nil
+}
+
+func (s *FilesystemColdStore) Get(_ context.Context, key string) ([]byte, error) {
+	compressed, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domain.ErrColdObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to read cold object: %v", err)
+	}
+	return gunzipBytes(compressed)
+}
+
+func (s *FilesystemColdStore) Delete(_ context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return domain.ErrColdObjectNotFound
+		}
+		return fmt.Errorf("failed to delete cold object: %v", err)
+	}
+	return nil
+}
+
+// MongoColdStore menyimpan konten terkompresi di bucket GridFS pada database terpisah,
+// sehingga bisa ditempatkan di cluster atau storage yang lebih murah
+type MongoColdStore struct {
+	bucket *gridfs.Bucket
+}
+
+func NewMongoColdStore(client *mongo.Client, dbName string) (*MongoColdStore, error) {
+	bucket, err := gridfs.NewBucket(
+		client.Database(dbName),
+		options.GridFSBucket().SetName("cold_archives"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cold storage bucket: %v", err)
+	}
+	return &MongoColdStore{bucket: bucket}, nil
+}
+
+// Put mengganti objek lama dengan key yang sama
+func (s *MongoColdStore) Put(ctx context.Context, key string, content []byte) error {
+	compressed, err := gzipBytes(content)
+	if err != nil {
+		return err
+	}
+	if err := s.Delete(ctx, key); err != nil && !errors.Is(err, domain.ErrColdObjectNotFound) {
+		return err
+	}
+	if _, err := s.bucket.UploadFromStream(key, bytes.NewReader(compressed)); err != nil {
+		return fmt.Errorf("failed to store cold object: %v", err)
+	}
+	return nil
+}
+
+func (s *MongoColdStore) Get(_ context.Context, key string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := s.bucket.DownloadToStreamByName(key, &buf); err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, domain.ErrColdObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to read cold object: %v", err)
+	}
+	return gunzipBytes(buf.Bytes())
+}
+
+func (s *MongoColdStore) Delete(ctx context.Context, key string) error {
+	cur, err := s.bucket.GetFilesCollection().Find(ctx, bson.M{"filename": key}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return fmt.Errorf("failed to find cold object: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var files []bson.M
+	if err := cur.All(ctx, &files); err != nil {
+		return fmt.Errorf("failed to decode cold object: %v", err)
+	}
+	if len(files) == 0 {
+		return domain.ErrColdObjectNotFound
+	}
+	for _, file := range files {
+		if err := s.bucket.Delete(file["_id"]); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return fmt.Errorf("failed to delete cold object: %v", err)
+		}
+	}
+	return nil
+}
+
+func gzipBytes(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to compress content: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress content: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(compressed []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content: %v", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content: %v", err)
+	}
+	return content, nil
+}
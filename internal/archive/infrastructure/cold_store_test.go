@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+// testColdStoreRoundTrip memeriksa perilaku yang sama untuk setiap backend cold store
+func testColdStoreRoundTrip(t *testing.T, store domain.ColdStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "arsip-v1"); !errors.Is(err, domain.ErrColdObjectNotFound) {
+		t.Fatalf("get missing: expected ErrColdObjectNotFound, got %v", err)
+	}
+	if err := store.Put(ctx, "arsip-v1", []byte("isi versi satu")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	// Put dengan key yang sama mengganti objek lama
+	if err := store.Put(ctx, "arsip-v1", []byte("isi pengganti")); err != nil {
+		t.Fatalf("put again: %v", err)
+	}
+	content, err := store.Get(ctx, "arsip-v1")
+	if err != nil || string(content) != "isi pengganti" {
+		t.Fatalf("expected the replaced content, got %q (%v)", content, err)
+	}
+	if err := store.Put(ctx, "kosong-v1", nil); err != nil {
+		t.Fatalf("put empty: %v", err)
+	}
+	if content, err := store.Get(ctx, "kosong-v1"); err != nil || len(content) != 0 {
+		t.Fatalf("expected empty content, got %q (%v)", content, err)
+	}
+
+	if err := store.Delete(ctx, "arsip-v1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "arsip-v1"); !errors.Is(err, domain.ErrColdObjectNotFound) {
+		t.Fatalf("get deleted: expected ErrColdObjectNotFound, got %v", err)
+	}
+	if err := store.Delete(ctx, "arsip-v1"); !errors.Is(err, domain.ErrColdObjectNotFound) {
+		t.Fatalf("delete missing: expected ErrColdObjectNotFound, got %v", err)
+	}
+}
+
+func TestFilesystemColdStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cold")
+	store, err := NewFilesystemColdStore(dir)
+	if err != nil {
+		t.Fatalf("cold store: %v", err)
+	}
+	testColdStoreRoundTrip(t, store)
+
+	// File sementara dari Put tidak boleh tertinggal
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if err != nil || len(matches) != 0 {
+		t.Fatalf("expected no temporary files, got %v (%v)", matches, err)
+	}
+	// Objek disimpan terkompresi, bukan teks asli
+	if err := store.Put(context.Background(), "teks-v1", []byte("isi yang bisa dibaca")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "teks-v1.gz"))
+	if err != nil || string(raw) == "isi yang bisa dibaca" {
+		t.Fatalf("expected gzip content on disk, got %q (%v)", raw, err)
+	}
+}
+
+func TestMongoColdStore(t *testing.T) {
+	client, dbName := testDatabase(t)
+	store, err := NewMongoColdStore(client, dbName)
+	if err != nil {
+		t.Fatalf("cold store: %v", err)
+	}
+	testColdStoreRoundTrip(t, store)
+}
+
+func TestNewColdStoreBackends(t *testing.T) {
+	store, err := NewColdStore("", "", nil, "")
+	if err != nil || store != nil {
+		t.Fatalf("expected tiering disabled without a backend, got %v (%v)", store, err)
+	}
+	if _, err := NewColdStore("s3", "", nil, ""); !errors.Is(err, domain.ErrUnknownColdBackend) {
+		t.Fatalf("expected ErrUnknownColdBackend, got %v", err)
+	}
+	if _, err := NewColdStore(ColdBackendFilesystem, "", nil, ""); !errors.Is(err, domain.ErrColdDirectoryRequired) {
+		t.Fatalf("expected ErrColdDirectoryRequired, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CommentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCommentRepository(client *mongo.Client, dbName string) (*CommentRepository, error) {
+	collection := client.Database(dbName).Collection("archive_comments")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "archive_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "archive_id", Value: 1}, {Key: "anchor.version", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment indexes: %v", err)
+	}
+
+	return &CommentRepository{collection: collection}, nil
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, comment); err != nil {
+		return fmt.Errorf("failed to insert comment: %v", err)
+	}
+	return nil
+}
+
+func (r *CommentRepository) FindByID(ctx context.Context, id string) (*domain.Comment, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrCommentNotFound
+	}
+
+	var comment domain.Comment
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&comment); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to find comment: %v", err)
+	}
+	return &comment, nil
+}
+
+func (r *CommentRepository) FindByArchive(ctx context.Context, archiveID primitive.ObjectID) ([]domain.Comment, error) {
+	return r.find(ctx, bson.M{"archive_id": archiveID})
+}
+
+// FindAnnotations mengembalikan komentar aktif yang ditempel ke halaman pada versi tertentu
+func (r *CommentRepository) FindAnnotations(ctx context.Context, archiveID primitive.ObjectID, version int) ([]domain.Comment, error) {
+	return r.find(ctx, bson.M{
+		"archive_id":     archiveID,
+		"anchor.version": version,
+		"anchor.page":    bson.M{"$gt": 0},
+		"deleted_at":     nil,
+	})
+}
+
+func (r *CommentRepository) find(ctx context.Context, filter bson.M) ([]domain.Comment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find comments: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	comments := []domain.Comment{}
+	if err := cur.All(ctx, &comments); err != nil {
+		return nil, fmt.Errorf("failed to decode comments: %v", err)
+	}
+	return comments, nil
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	set := bson.M{
+		"body":       comment.Body,
+		"mentions":   comment.Mentions,
+		"resolved":   comment.Resolved,
+		"updated_at": comment.UpdatedAt,
+		"history":    comment.History,
+	}
+	if comment.DeletedAt != nil {
+		set["deleted_at"] = comment.DeletedAt
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": comment.ID}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
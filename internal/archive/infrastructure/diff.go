@@ -0,0 +1,219 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+const (
+	// diffContext adalah jumlah baris konteks di sekitar perubahan pada unified diff
+	diffContext = 3
+	// maxDiffCells membatasi ukuran tabel LCS; di atas batas ini blok yang berbeda
+	// ditampilkan sebagai satu penggantian utuh
+	maxDiffCells = 4 << 20
+)
+
+// TextComparer membandingkan dua versi arsip berdasarkan metadata, checksum dan teks PDF
+type TextComparer struct{}
+
+func NewTextComparer() *TextComparer {
+	return &TextComparer{}
+}
+
+func (c *TextComparer) Compare(from, to *domain.Archive, fromContent, toContent []byte) *domain.VersionDiff {
+	diff := &domain.VersionDiff{
+		ArchiveID:    to.ID.Hex(),
+		Name:         to.Name,
+		From:         from.Version,
+		To:           to.Version,
+		Metadata:     []domain.Change{},
+		SizeFrom:     int64(len(fromContent)),
+		SizeTo:       int64(len(toContent)),
+		ChecksumFrom: checksum(fromContent),
+		ChecksumTo:   checksum(toContent),
+	}
+	diff.ContentChanged = diff.ChecksumFrom != diff.ChecksumTo
+
+	// Versi dan ukuran sudah ditampilkan terpisah; lock dan tier adalah status operasional,
+	// bukan bagian dari isi versi
+	for _, change := range DiffArchives(from, to) {
+		switch change.Field {
+		case "version", "size", "locked_by", "lock_expires_at", "tier":
+			continue
+		}
+		diff.Metadata = append(diff.Metadata, change)
+	}
+
+	if diff.ContentChanged {
+		diff.TextDiff = unifiedDiff(
+			textLines(ExtractPDFText(fromContent)),
+			textLines(ExtractPDFText(toContent)),
+			fmt.Sprintf("%s@v%d", from.Name, from.Version),
+			fmt.Sprintf("%s@v%d", to.Name, to.Version),
+		)
+	}
+	return diff
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// textLines merapikan spasi tiap baris dan membuang baris kosong
+func textLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' atau '+'
+	text string
+}
+
+// unifiedDiff menghasilkan diff format unified dengan konteks diffContext baris
+func unifiedDiff(a, b []string, fromLabel, toLabel string) string {
+	ops := diffLines(a, b)
+
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+
+	for i := 0; i < len(changes); {
+		// Gabungkan perubahan yang jaraknya masih dalam dua kali konteks
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*diffContext+1 {
+			j++
+		}
+		start := max(changes[i]-diffContext, 0)
+		end := min(changes[j]+diffContext+1, len(ops))
+
+		aLine, bLine := 0, 0
+		for _, op := range ops[:start] {
+			if op.kind != '+' {
+				aLine++
+			}
+			if op.kind != '-' {
+				bLine++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.text)
+			out.WriteByte('\n')
+		}
+		i = j + 1
+	}
+	return out.String()
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line)
+	}
+	return fmt.Sprintf("%d,%d", line+1, count)
+}
+
+// diffLines menghitung edit script baris a menjadi b dengan LCS setelah
+// prefix dan suffix yang sama dibuang
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+func diffMiddle(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	ops := make([]diffOp, 0, n+m)
+
+	if n == 0 || m == 0 || (n+1)*(m+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] adalah panjang LCS dari a[i:] dan b[j:]
+	width := m + 1
+	lcs := make([]int32, (n+1)*width)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// textPDF membuat PDF minimal dengan satu baris teks per elemen lines
+func textPDF(lines ...string) []byte {
+	var stream strings.Builder
+	stream.WriteString("BT\n")
+	for _, line := range lines {
+		fmt.Fprintf(&stream, "(%s) Tj T*\n", line)
+	}
+	stream.WriteString("ET")
+	return []byte(fmt.Sprintf("%%PDF-1.4\n1 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n%%%%EOF\n", stream.Len(), stream.String()))
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	from := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+	to := []string{"a", "B", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m"}
+
+	want := "--- doc@v1\n+++ doc@v2\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -10,3 +10,4 @@\n j\n k\n l\n+m\n"
+	if got := unifiedDiff(from, to, "doc@v1", "doc@v2"); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffMergesNearbyChangesAndHandlesEmptySides(t *testing.T) {
+	got := unifiedDiff([]string{"a", "b", "c", "d"}, []string{"a", "x", "c", "y"}, "v1", "v2")
+	if strings.Count(got, "@@ ") != 1 {
+		t.Fatalf("expected nearby changes in a single hunk, got:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -1,4 +1,4 @@\n a\n-b\n+x\n c\n-d\n+y\n") {
+		t.Fatalf("unexpected hunk:\n%s", got)
+	}
+
+	if got := unifiedDiff(nil, []string{"baru"}, "v1", "v2"); got != "--- v1\n+++ v2\n@@ -0,0 +1,1 @@\n+baru\n" {
+		t.Fatalf("unexpected diff for added text:\n%s", got)
+	}
+	if got := unifiedDiff([]string{"lama"}, nil, "v1", "v2"); got != "--- v1\n+++ v2\n@@ -1,1 +0,0 @@\n-lama\n" {
+		t.Fatalf("unexpected diff for removed text:\n%s", got)
+	}
+	if got := unifiedDiff([]string{"sama"}, []string{"sama"}, "v1", "v2"); got != "" {
+		t.Fatalf("expected no diff for equal text, got:\n%s", got)
+	}
+}
+
+func TestTextComparerCompare(t *testing.T) {
+	id := primitive.NewObjectID()
+	from := &domain.Archive{ID: id, Name: "laporan.pdf", Version: 1, Size: 10, Description: "draf", Tier: domain.TierCold}
+	to := &domain.Archive{ID: id, Name: "laporan.pdf", Version: 2, Size: 20, Description: "final",
+		Lock: &domain.ArchiveLock{Owner: "alice", ExpiresAt: time.Now().Add(time.Hour)}}
+	fromContent := textPDF("Pendapatan   100", "Biaya 50")
+	toContent := textPDF("Pendapatan 100", "Biaya 70")
+
+	diff := NewTextComparer().Compare(from, to, fromContent, toContent)
+	if diff.ArchiveID != id.Hex() || diff.From != 1 || diff.To != 2 {
+		t.Fatalf("unexpected header %+v", diff)
+	}
+	if !diff.ContentChanged || diff.ChecksumFrom == diff.ChecksumTo || diff.SizeFrom != int64(len(fromContent)) {
+		t.Fatalf("expected a content change with both checksums, got %+v", diff)
+	}
+	// Versi, ukuran, lock dan tier tidak termasuk perubahan metadata
+	fields := changedFields(diff.Metadata)
+	if len(fields) != 1 || fields["description"].NewValue != "final" {
+		t.Fatalf("expected only the description change, got %+v", diff.Metadata)
+	}
+	// Spasi berlebih dirapikan, jadi hanya baris biaya yang berubah
+	want := "--- laporan.pdf@v1\n+++ laporan.pdf@v2\n@@ -1,2 +1,2 @@\n Pendapatan 100\n-Biaya 50\n+Biaya 70\n"
+	if diff.TextDiff != want {
+		t.Fatalf("unexpected text diff:\n%s\nwant:\n%s", diff.TextDiff, want)
+	}
+
+	same := NewTextComparer().Compare(from, to, fromContent, fromContent)
+	if same.ContentChanged || same.TextDiff != "" || same.ChecksumFrom != same.ChecksumTo {
+		t.Fatalf("expected identical content to have no text diff, got %+v", same)
+	}
+}
@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *ArchiveRepository) ApproveDisposition(ctx context.Context, id, userID string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	current, err := r.findDocument(ctx, bson.M{"_id": objID, "metadata.deleted_at": nil})
+	if err != nil {
+		return err
+	}
+	if !current.Retention.InQueue() {
+		return domain.ErrNotUnderReview
+	}
+	if current.OnHold() {
+		return domain.ErrUnderLegalHold
+	}
+
+	now := time.Now()
+	updated := *current
+	retention := *current.Retention
+	retention.ApprovedBy = userID
+	retention.ApprovedAt = &now
+	updated.Retention = &retention
+	changeLog := CreateChangeLog(domain.ActionDispositionApprove, userID, current, &updated)
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		notHeld(bson.M{"_id": objID, "metadata.retention.review_since": bson.M{"$ne": nil}}),
+		withChangeLog(bson.M{"$set": bson.M{
+			"metadata.retention.approved_by": userID,
+			"metadata.retention.approved_at": now,
+		}}, changeLog),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to approve disposition: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotUnderReview
+	}
+	return nil
+}
+
+// FindDispositionApproved mengembalikan arsip yang pemusnahannya sudah disetujui,
+// kecuali yang dibekukan legal hold setelah disetujui
+func (r *ArchiveRepository) FindDispositionApproved(ctx context.Context, limit int) ([]domain.Archive, error) {
+	filter := notHeld(bson.M{
+		"metadata.deleted_at":             nil,
+		"metadata.retention.review_since": bson.M{"$ne": nil},
+		"metadata.retention.approved_at":  bson.M{"$ne": nil},
+	})
+	opts := options.Find().
+		SetSort(bson.D{{Key: "metadata.retention.approved_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(listProjection(nil))
+	return r.findArchives(ctx, filter, opts)
+}
+
+func (r *ArchiveRepository) Destroy(ctx context.Context, id string, version int, approvedAt time.Time, userID string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid object ID: %v", err)
+	}
+	// Versi baru, defer atau transfer sejak bukti dihitung membuat bukti tidak berlaku lagi
+	err = r.removeFile(ctx, objID, domain.ActionDispose, userID, bson.M{
+		"metadata.version":                version,
+		"metadata.retention.review_since": bson.M{"$ne": nil},
+		"metadata.retention.approved_at":  approvedAt,
+	})
+	if errors.Is(err, domain.ErrVersionConflict) {
+		return domain.ErrDispositionChanged
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *ArchiveRepository) MoveToFolder(ctx context.Context, id, folderID, userID string) (*domain.Archive, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrArchiveNotFound
+	}
+
+	current, err := r.findDocument(ctx, bson.M{"_id": objID, "metadata.deleted_at": nil})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWrite(current, userID, domain.Precondition{}); err != nil {
+		return nil, err
+	}
+	if current.FolderID == folderID {
+		return current, nil
+	}
+
+	now := time.Now()
+	updated := *current
+	updated.FolderID = folderID
+	updated.UpdatedAt = now
+
+	update := bson.M{"$set": bson.M{"metadata.updated_at": now}}
+	if folderID == "" {
+		update["$unset"] = bson.M{"metadata.folder_id": ""}
+	} else {
+		update["$set"].(bson.M)["metadata.folder_id"] = folderID
+	}
+
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{
+			"_id":                 objID,
+			"metadata.deleted_at": nil,
+			"$and":                []bson.M{writableBy(userID, now)},
+		},
+		withChangeLog(update, CreateChangeLog(domain.ActionMove, userID, current, &updated)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move archive: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, domain.ErrArchiveLocked
+	}
+	return &updated, nil
+}
+
+// DeleteInFolders melakukan soft delete pada semua arsip aktif di dalam folderIDs.
+// Arsip ditandai dengan cascadeID agar restore folder hanya memulihkan arsip
+// yang terhapus bersama folder, bukan yang sudah dihapus sebelumnya. Arsip yang
+// di-check-out user lain tidak ikut terhapus.
+func (r *ArchiveRepository) DeleteInFolders(ctx context.Context, folderIDs []string, cascadeID, userID string) (int64, error) {
+	if len(folderIDs) == 0 {
+		return 0, nil
+	}
+
+	// Hanya field hapus yang berubah dan nilai lamanya sama untuk semua arsip aktif,
+	// jadi satu change log berlaku untuk seluruh UpdateMany
+	now := time.Now()
+	changeLog := CreateChangeLog(domain.ActionDelete, userID,
+		&domain.Archive{},
+		&domain.Archive{DeletedAt: &now, DeletedBy: userID})
+
+	result, err := r.bucket.GetFilesCollection().UpdateMany(
+		ctx,
+		// Arsip dalam legal hold tidak ikut terhapus walaupun foldernya dihapus
+		notHeld(bson.M{
+			"metadata.folder_id":  bson.M{"$in": folderIDs},
+			"metadata.deleted_at": nil,
+			"$and":                []bson.M{writableBy(userID, now)},
+		}),
+		withChangeLog(bson.M{"$set": bson.M{
+			"metadata.deleted_at":          now,
+			"metadata.deleted_by":          userID,
+			"metadata.deleted_with_folder": cascadeID,
+			"metadata.updated_at":          now,
+		}}, changeLog),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete folder archives: %v", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// CountLockedInFolders menghitung arsip aktif dalam folder yang lock-nya masih berlaku
+// dan dipegang user selain userID
+func (r *ArchiveRepository) CountLockedInFolders(ctx context.Context, folderIDs []string, userID string) (int64, error) {
+	if len(folderIDs) == 0 {
+		return 0, nil
+	}
+
+	count, err := r.bucket.GetFilesCollection().CountDocuments(ctx, bson.M{
+		"metadata.folder_id":       bson.M{"$in": folderIDs},
+		"metadata.deleted_at":      nil,
+		"metadata.lock.owner":      bson.M{"$ne": userID},
+		"metadata.lock.expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count locked archives: %v", err)
+	}
+	return count, nil
+}
+
+// RestoreFolderCascade memulihkan arsip yang terhapus bersama folder cascadeID. Setiap
+// arsip dipulihkan lewat claim nama seperti RestoreArchive; arsip yang namanya sudah
+// dipakai arsip aktif lain tetap di trash dan ID-nya dikembalikan sebagai konflik.
+func (r *ArchiveRepository) RestoreFolderCascade(ctx context.Context, cascadeID, userID string) (int64, []string, error) {
+	ids, err := r.findIDs(ctx, bson.M{
+		"metadata.deleted_with_folder": cascadeID,
+		"metadata.deleted_at":          bson.M{"$ne": nil},
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var restored int64
+	conflicts := []string{}
+	for _, id := range ids {
+		err := r.restoreWithFolder(ctx, id, cascadeID, userID)
+		switch {
+		case err == nil:
+			restored++
+		case errors.Is(err, domain.ErrRestoreConflict):
+			conflicts = append(conflicts, id.Hex())
+		case errors.Is(err, domain.ErrArchiveNotFound), errors.Is(err, domain.ErrNotDeleted):
+			// Sudah dipulihkan atau di-purge di tengah proses
+		default:
+			return restored, conflicts, err
+		}
+	}
+	return restored, conflicts, nil
+}
+
+// restoreWithFolder memulihkan satu arsip dari cascade folder bila namanya masih bebas
+func (r *ArchiveRepository) restoreWithFolder(ctx context.Context, id primitive.ObjectID, cascadeID, userID string) error {
+	current, err := r.findDocument(ctx, bson.M{
+		"_id":                          id,
+		"metadata.deleted_with_folder": cascadeID,
+		"metadata.deleted_at":          bson.M{"$ne": nil},
+	})
+	if err != nil {
+		return err
+	}
+
+	release, err := r.claimName(ctx, current.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrVersionConflict) {
+			// Nama sedang dipakai upload lain
+			return domain.ErrRestoreConflict
+		}
+		return err
+	}
+	defer release()
+
+	live, err := r.FindExistingArchive(ctx, domain.Archive{Name: current.Name})
+	if err != nil {
+		return err
+	}
+	if live != nil {
+		return domain.ErrRestoreConflict
+	}
+	_, err = r.undelete(ctx, current, current.Name, userID)
+	return err
+}
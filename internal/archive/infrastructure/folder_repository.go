@@ -0,0 +1,208 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FolderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewFolderRepository(client *mongo.Client, dbName string) (*FolderRepository, error) {
+	collection := client.Database(dbName).Collection("folders")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_with", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder indexes: %v", err)
+	}
+
+	return &FolderRepository{collection: collection}, nil
+}
+
+func (r *FolderRepository) Create(ctx context.Context, folder *domain.Folder) error {
+	if folder.ID.IsZero() {
+		folder.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, folder); err != nil {
+		return fmt.Errorf("failed to insert folder: %v", err)
+	}
+	return nil
+}
+
+func (r *FolderRepository) FindByID(ctx context.Context, id string) (*domain.Folder, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrFolderNotFound
+	}
+
+	var folder domain.Folder
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&folder); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrFolderNotFound
+		}
+		return nil, fmt.Errorf("failed to find folder: %v", err)
+	}
+	return &folder, nil
+}
+
+func (r *FolderRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Folder, error) {
+	if len(ids) == 0 {
+		return []domain.Folder{}, nil
+	}
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find())
+}
+
+func (r *FolderRepository) FindChildren(ctx context.Context, parentID *primitive.ObjectID, page, limit int) ([]domain.Folder, int64, error) {
+	filter := bson.M{"parent_id": nil, "deleted_at": nil}
+	if parentID != nil {
+		filter["parent_id"] = *parentID
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count folders: %v", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	folders, err := r.find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return folders, total, nil
+}
+
+func (r *FolderRepository) FindChildByName(ctx context.Context, parentID *primitive.ObjectID, name string) (*domain.Folder, error) {
+	filter := bson.M{"parent_id": nil, "name": name, "deleted_at": nil}
+	if parentID != nil {
+		filter["parent_id"] = *parentID
+	}
+
+	var folder domain.Folder
+	if err := r.collection.FindOne(ctx, filter).Decode(&folder); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrFolderNotFound
+		}
+		return nil, fmt.Errorf("failed to find folder: %v", err)
+	}
+	return &folder, nil
+}
+
+func (r *FolderRepository) FindDescendants(ctx context.Context, id primitive.ObjectID) ([]domain.Folder, error) {
+	return r.find(ctx, bson.M{"ancestors": id}, options.Find())
+}
+
+func (r *FolderRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.Folder, error) {
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find folders: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	folders := []domain.Folder{}
+	if err := cur.All(ctx, &folders); err != nil {
+		return nil, fmt.Errorf("failed to decode folders: %v", err)
+	}
+	return folders, nil
+}
+
+func (r *FolderRepository) Update(ctx context.Context, folder *domain.Folder) error {
+	set := bson.M{
+		"name":       folder.Name,
+		"ancestors":  folder.Ancestors,
+		"updated_at": folder.UpdatedAt,
+	}
+	update := bson.M{"$set": set}
+	if folder.ParentID != nil {
+		set["parent_id"] = folder.ParentID
+	} else {
+		update["$unset"] = bson.M{"parent_id": ""}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": folder.ID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update folder: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrFolderNotFound
+	}
+	return nil
+}
+
+func (r *FolderRepository) RebaseDescendants(ctx context.Context, id primitive.ObjectID, ancestors []primitive.ObjectID, at time.Time) error {
+	if ancestors == nil {
+		// $concatArrays menghasilkan null bila salah satu argumennya null
+		ancestors = []primitive.ObjectID{}
+	}
+	// Leluhur baru disambung dengan bagian ancestors mulai dari id, dalam satu UpdateMany
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"ancestors": id},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"ancestors": bson.M{"$concatArrays": bson.A{
+				ancestors,
+				bson.M{"$slice": bson.A{
+					"$ancestors",
+					bson.M{"$indexOfArray": bson.A{"$ancestors", id}},
+					bson.M{"$size": "$ancestors"},
+				}},
+			}},
+			"updated_at": at,
+		}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update folder descendants: %v", err)
+	}
+	return nil
+}
+
+func (r *FolderRepository) MarkDeleted(ctx context.Context, ids []primitive.ObjectID, cascade primitive.ObjectID, userID string, at time.Time) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "deleted_at": nil},
+		bson.M{"$set": bson.M{
+			"deleted_at":   at,
+			"deleted_by":   userID,
+			"deleted_with": cascade,
+			"updated_at":   at,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete folders: %v", err)
+	}
+	return nil
+}
+
+func (r *FolderRepository) RestoreCascade(ctx context.Context, cascade primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"deleted_with": cascade},
+		bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"deleted_at": "", "deleted_by": "", "deleted_with": ""},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore folders: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRebaseDescendantsReplacesAncestorsAboveMovedFolder(t *testing.T) {
+	client, dbName := testDatabase(t)
+	repo, err := NewFolderRepository(client, dbName)
+	if err != nil {
+		t.Fatalf("repository: %v", err)
+	}
+	ctx := context.Background()
+
+	oldParent, newParent, moved := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	child := &domain.Folder{
+		ID: primitive.NewObjectID(), Name: "kontrak",
+		ParentID: &moved, Ancestors: []primitive.ObjectID{oldParent, moved},
+	}
+	grandchild := &domain.Folder{
+		ID: primitive.NewObjectID(), Name: "2024",
+		ParentID: &child.ID, Ancestors: []primitive.ObjectID{oldParent, moved, child.ID},
+	}
+	for _, folder := range []*domain.Folder{child, grandchild} {
+		if err := repo.Create(ctx, folder); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	// Diulang dua kali untuk memastikan hasilnya tetap sama
+	for i := 0; i < 2; i++ {
+		if err := repo.RebaseDescendants(ctx, moved, []primitive.ObjectID{newParent}, time.Now()); err != nil {
+			t.Fatalf("rebase: %v", err)
+		}
+	}
+
+	found, err := repo.FindByID(ctx, grandchild.ID.Hex())
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	want := []primitive.ObjectID{newParent, moved, child.ID}
+	if len(found.Ancestors) != len(want) {
+		t.Fatalf("expected ancestors %v, got %v", want, found.Ancestors)
+	}
+	for i := range want {
+		if found.Ancestors[i] != want[i] {
+			t.Fatalf("expected ancestors %v, got %v", want, found.Ancestors)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeleteInFoldersSkipsArchivesCheckedOutByOthers(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	folderID := primitive.NewObjectID().Hex()
+
+	locked := saveArchive(t, repo, "kontrak.pdf", "alice", "isi")
+	free := saveArchive(t, repo, "lampiran.pdf", "alice", "isi")
+	for _, archive := range []string{locked.ID.Hex(), free.ID.Hex()} {
+		if _, err := repo.MoveToFolder(ctx, archive, folderID, "alice"); err != nil {
+			t.Fatalf("move: %v", err)
+		}
+	}
+	if _, err := repo.Checkout(ctx, locked.ID.Hex(), "alice", time.Hour); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	if count, err := repo.CountLockedInFolders(ctx, []string{folderID}, "bob"); err != nil || count != 1 {
+		t.Fatalf("expected 1 archive locked for bob, got %d (%v)", count, err)
+	}
+	if count, err := repo.CountLockedInFolders(ctx, []string{folderID}, "alice"); err != nil || count != 0 {
+		t.Fatalf("expected no archive locked for alice, got %d (%v)", count, err)
+	}
+
+	deleted, err := repo.DeleteInFolders(ctx, []string{folderID}, folderID, "bob")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected only the unlocked archive deleted, got %d", deleted)
+	}
+	if _, err := repo.FindMetadata(ctx, locked.ID.Hex()); err != nil {
+		t.Fatalf("checked out archive must stay active: %v", err)
+	}
+}
+
+func TestRestoreFolderCascadeLeavesNameConflictsInTrash(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	folderID := primitive.NewObjectID().Hex()
+
+	taken := saveArchive(t, repo, "laporan.pdf", "alice", "lama")
+	free := saveArchive(t, repo, "lampiran.pdf", "alice", "isi")
+	for _, archive := range []string{taken.ID.Hex(), free.ID.Hex()} {
+		if _, err := repo.MoveToFolder(ctx, archive, folderID, "alice"); err != nil {
+			t.Fatalf("move: %v", err)
+		}
+	}
+	if _, err := repo.DeleteInFolders(ctx, []string{folderID}, folderID, "alice"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// Selama folder terhapus, arsip baru dengan nama yang sama diunggah
+	replacement := saveArchive(t, repo, "laporan.pdf", "bob", "baru")
+
+	restored, conflicts, err := repo.RestoreFolderCascade(ctx, folderID, "alice")
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored != 1 {
+		t.Fatalf("expected 1 archive restored, got %d", restored)
+	}
+	if len(conflicts) != 1 || conflicts[0] != taken.ID.Hex() {
+		t.Fatalf("expected conflict for %s, got %v", taken.ID.Hex(), conflicts)
+	}
+	if _, err := repo.FindMetadata(ctx, taken.ID.Hex()); !errors.Is(err, domain.ErrArchiveNotFound) {
+		t.Fatalf("conflicting archive must stay in the trash, got %v", err)
+	}
+	live, err := repo.FindExistingArchive(ctx, domain.Archive{Name: "laporan.pdf"})
+	if err != nil || live == nil || live.ID != replacement.ID {
+		t.Fatalf("expected the replacement to stay the only active laporan.pdf, got %v (%v)", live, err)
+	}
+}
@@ -4,27 +4,83 @@ import (
 	"time"
 
 	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func CreateChangeLog(action, userID string, old, new *domain.Archive) domain.ChangeLog {
-	changes := []domain.Change{}
-
-	if old != nil {
-		// Deteksi perubahan
-		if old.Name != new.Name {
-			changes = append(changes, domain.Change{
-				Field:    "name",
-				OldValue: old.Name,
-				NewValue: new.Name,
-			})
-		}
-		// Tambahkan field lainnya yang perlu dilacak...
+func stringValue(v interface{}) string {
+	if str, ok := v.(string); ok {
+		return str
+	}
+	return ""
+}
+
+func int64Value(v interface{}) int64 {
+	switch n := v.(type) {
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
+
+func boolValue(v interface{}) bool {
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return false
+}
+
+func timeValue(v interface{}) time.Time {
+	switch t := v.(type) {
+	case primitive.DateTime:
+		return t.Time()
+	case time.Time:
+		return t
+	default:
+		return time.Time{}
 	}
+}
+
+func timePointer(v interface{}) *time.Time {
+	t := timeValue(v)
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
 
-	return domain.ChangeLog{
-		Timestamp: time.Now(),
-		Action:    action,
-		UserID:    userID,
-		Changes:   changes,
+// decodeChangeLogs membaca change_logs beserta detail perubahannya
+func decodeChangeLogs(logs primitive.A) []domain.ChangeLog {
+	changeLogs := make([]domain.ChangeLog, 0, len(logs))
+	for _, l := range logs {
+		logMap, ok := l.(bson.M)
+		if !ok {
+			continue
+		}
+
+		changeLog := domain.ChangeLog{
+			Timestamp: timeValue(logMap["timestamp"]),
+			Action:    stringValue(logMap["action"]),
+			UserID:    stringValue(logMap["user_id"]),
+			Changes:   []domain.Change{},
+		}
+		if changes, ok := logMap["changes"].(primitive.A); ok {
+			for _, c := range changes {
+				if change, ok := c.(bson.M); ok {
+					changeLog.Changes = append(changeLog.Changes, domain.Change{
+						Field:    stringValue(change["field"]),
+						OldValue: change["old_value"],
+						NewValue: change["new_value"],
+					})
+				}
+			}
+		}
+		changeLogs = append(changeLogs, changeLog)
 	}
+	return changeLogs
 }
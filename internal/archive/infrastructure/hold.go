@@ -0,0 +1,145 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// heldField ada bila arsip memiliki minimal satu legal hold
+const heldField = "metadata.legal_holds.0"
+
+// legalHoldRetries adalah jumlah percobaan ulang bila legal hold arsip diubah hold lain bersamaan
+const legalHoldRetries = 3
+
+// ApplyLegalHold menambahkan hold ke arsip, termasuk arsip yang sudah ada di trash
+func (r *ArchiveRepository) ApplyLegalHold(ctx context.Context, holdID string, ids []string, userID string) (int64, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return 0, fmt.Errorf("invalid object ID: %v", err)
+		}
+		objIDs = append(objIDs, objID)
+	}
+
+	var applied int64
+	for _, objID := range objIDs {
+		changed, err := r.updateLegalHolds(ctx, objID, domain.ActionLegalHold, userID, func(holds []string) []string {
+			if slices.Contains(holds, holdID) {
+				return nil
+			}
+			return append(append([]string{}, holds...), holdID)
+		})
+		if err != nil {
+			// Arsip yang tidak ada dilewati seperti sebelumnya
+			if errors.Is(err, domain.ErrArchiveNotFound) {
+				continue
+			}
+			return applied, err
+		}
+		if changed {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+func (r *ArchiveRepository) ReleaseLegalHold(ctx context.Context, holdID, userID string) (int64, error) {
+	ids, err := r.findIDs(ctx, bson.M{"metadata.legal_holds": holdID})
+	if err != nil {
+		return 0, err
+	}
+
+	var released int64
+	for _, objID := range ids {
+		changed, err := r.updateLegalHolds(ctx, objID, domain.ActionLegalHoldRelease, userID, func(holds []string) []string {
+			if !slices.Contains(holds, holdID) {
+				return nil
+			}
+			remaining := []string{}
+			for _, id := range holds {
+				if id != holdID {
+					remaining = append(remaining, id)
+				}
+			}
+			return remaining
+		})
+		if err != nil {
+			if errors.Is(err, domain.ErrArchiveNotFound) {
+				continue
+			}
+			return released, err
+		}
+		if changed {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// updateLegalHolds mengganti legal_holds satu arsip dengan hasil change dan mencatat
+// perubahannya lewat DiffArchives. change mengembalikan nil bila tidak ada yang berubah.
+// Update mensyaratkan legal_holds masih sama dengan yang dibaca, jadi hold lain yang
+// berubah bersamaan membuat arsip dibaca ulang, bukan menimpa atau salah dicatat.
+func (r *ArchiveRepository) updateLegalHolds(ctx context.Context, id primitive.ObjectID, action, userID string, change func(holds []string) []string) (bool, error) {
+	for attempt := 0; attempt < legalHoldRetries; attempt++ {
+		current, err := r.findDocument(ctx, bson.M{"_id": id})
+		if err != nil {
+			return false, err
+		}
+		holds := change(current.LegalHolds)
+		if holds == nil {
+			return false, nil
+		}
+
+		updated := *current
+		updated.LegalHolds = holds
+		filter := bson.M{"_id": id}
+		if len(current.LegalHolds) == 0 {
+			filter[heldField] = bson.M{"$exists": false}
+		} else {
+			filter["metadata.legal_holds"] = current.LegalHolds
+		}
+		result, err := r.bucket.GetFilesCollection().UpdateOne(
+			ctx,
+			filter,
+			withChangeLog(bson.M{"$set": bson.M{"metadata.legal_holds": holds}},
+				CreateChangeLog(action, userID, current, &updated)),
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to update legal holds: %v", err)
+		}
+		if result.MatchedCount > 0 {
+			return true, nil
+		}
+	}
+	return false, domain.ErrVersionConflict
+}
+
+func (r *ArchiveRepository) CountHeldInFolders(ctx context.Context, folderIDs []string) (int64, error) {
+	if len(folderIDs) == 0 {
+		return 0, nil
+	}
+
+	count, err := r.bucket.GetFilesCollection().CountDocuments(ctx, bson.M{
+		"metadata.folder_id":  bson.M{"$in": folderIDs},
+		"metadata.deleted_at": nil,
+		heldField:             bson.M{"$exists": true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count held archives: %v", err)
+	}
+	return count, nil
+}
+
+// notHeld menambahkan syarat arsip tidak sedang dibekukan ke filter
+func notHeld(filter bson.M) bson.M {
+	filter[heldField] = bson.M{"$exists": false}
+	return filter
+}
@@ -0,0 +1,103 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LegalHoldRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLegalHoldRepository(client *mongo.Client, dbName string) (*LegalHoldRepository, error) {
+	collection := client.Database(dbName).Collection("legal_holds")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create legal hold indexes: %v", err)
+	}
+
+	return &LegalHoldRepository{collection: collection}, nil
+}
+
+func (r *LegalHoldRepository) Create(ctx context.Context, hold *domain.LegalHold) error {
+	if hold.ID.IsZero() {
+		hold.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, hold); err != nil {
+		return fmt.Errorf("failed to insert legal hold: %v", err)
+	}
+	return nil
+}
+
+func (r *LegalHoldRepository) FindByID(ctx context.Context, id string) (*domain.LegalHold, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrLegalHoldNotFound
+	}
+
+	var hold domain.LegalHold
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&hold); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrLegalHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to find legal hold: %v", err)
+	}
+	return &hold, nil
+}
+
+func (r *LegalHoldRepository) FindAll(ctx context.Context, status domain.LegalHoldStatus, page, limit int) ([]domain.LegalHold, int64, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count legal holds: %v", err)
+	}
+
+	// Daftar arsip bisa besar, cukup ditampilkan di detail hold
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"archive_ids": 0})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find legal holds: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	holds := []domain.LegalHold{}
+	if err := cur.All(ctx, &holds); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode legal holds: %v", err)
+	}
+	return holds, total, nil
+}
+
+func (r *LegalHoldRepository) Update(ctx context.Context, hold *domain.LegalHold) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": hold.ID}, hold)
+	if err != nil {
+		return fmt.Errorf("failed to update legal hold: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrLegalHoldNotFound
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type JobRunRepository struct {
+	collection *mongo.Collection
+}
+
+func NewJobRunRepository(client *mongo.Client, dbName string) (*JobRunRepository, error) {
+	collection := client.Database(dbName).Collection("job_runs")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "started_at", Value: -1}}},
+		{Keys: bson.D{{Key: "job", Value: 1}, {Key: "started_at", Value: -1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job run indexes: %v", err)
+	}
+
+	return &JobRunRepository{collection: collection}, nil
+}
+
+func (r *JobRunRepository) Create(ctx context.Context, run *domain.JobRun) error {
+	if run.ID.IsZero() {
+		run.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, run); err != nil {
+		return fmt.Errorf("failed to insert job run: %v", err)
+	}
+	return nil
+}
+
+func (r *JobRunRepository) FindByID(ctx context.Context, id string) (*domain.JobRun, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrJobRunNotFound
+	}
+
+	var run domain.JobRun
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&run); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrJobRunNotFound
+		}
+		return nil, fmt.Errorf("failed to find job run: %v", err)
+	}
+	return &run, nil
+}
+
+func (r *JobRunRepository) FindRuns(ctx context.Context, job string, page, limit int) ([]domain.JobRun, int64, error) {
+	filter := bson.M{}
+	if job != "" {
+		filter["job"] = job
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count job runs: %v", err)
+	}
+
+	// Daftar arsip bisa besar, cukup ditampilkan di detail run
+	opts := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"items": 0})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find job runs: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	runs := []domain.JobRun{}
+	if err := cur.All(ctx, &runs); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode job runs: %v", err)
+	}
+	return runs, total, nil
+}
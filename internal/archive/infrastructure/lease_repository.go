@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaseRepository menyimpan satu dokumen per lease dengan _id nama lease. Waktu
+// kedaluwarsa dihitung dari jam replika, sehingga jam antar replika harus sinkron (NTP).
+type LeaseRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLeaseRepository(client *mongo.Client, dbName string) (*LeaseRepository, error) {
+	collection := client.Database(dbName).Collection("leases")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Lease yang ditinggalkan replika mati dihapus Mongo; Acquire tidak bergantung pada ini
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lease indexes: %v", err)
+	}
+
+	return &LeaseRepository{collection: collection}, nil
+}
+
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (*domain.Lease, bool, error) {
+	now := time.Now()
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	// Perpanjang lease milik sendiri tanpa mengubah acquired_at
+	var lease domain.Lease
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": name, "holder": holder},
+		bson.M{"$set": bson.M{"renewed_at": now, "expires_at": now.Add(ttl)}},
+		after,
+	).Decode(&lease)
+	if err == nil {
+		return &lease, true, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, fmt.Errorf("failed to renew lease: %v", err)
+	}
+
+	// Ambil alih lease yang kosong atau kedaluwarsa. Bila dokumennya ada dan masih
+	// berlaku, upsert gagal dengan duplicate key karena _id sudah dipakai.
+	err = r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": name, "expires_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{
+			"holder":      holder,
+			"acquired_at": now,
+			"renewed_at":  now,
+			"expires_at":  now.Add(ttl),
+		}},
+		after.SetUpsert(true),
+	).Decode(&lease)
+	if err == nil {
+		return &lease, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, fmt.Errorf("failed to acquire lease: %v", err)
+	}
+
+	current, err := r.Find(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	return current, false, nil
+}
+
+func (r *LeaseRepository) Release(ctx context.Context, name, holder string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": name, "holder": holder}); err != nil {
+		return fmt.Errorf("failed to release lease: %v", err)
+	}
+	return nil
+}
+
+func (r *LeaseRepository) Find(ctx context.Context, name string) (*domain.Lease, error) {
+	var lease domain.Lease
+	if err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&lease); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrLeaseNotFound
+		}
+		return nil, fmt.Errorf("failed to find lease: %v", err)
+	}
+	return &lease, nil
+}
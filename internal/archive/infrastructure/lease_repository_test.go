@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+func testLeases(t *testing.T) *LeaseRepository {
+	t.Helper()
+	client, dbName := testDatabase(t)
+	leases, err := NewLeaseRepository(client, dbName)
+	if err != nil {
+		t.Fatalf("lease repository: %v", err)
+	}
+	return leases
+}
+
+func TestLeaseAcquireRenewAndTakeover(t *testing.T) {
+	leases := testLeases(t)
+	ctx := context.Background()
+
+	first, acquired, err := leases.Acquire(ctx, domain.SchedulerLease, "replica-a", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("expected replica-a to acquire the empty lease, got %v (%v)", acquired, err)
+	}
+
+	renewed, acquired, err := leases.Acquire(ctx, domain.SchedulerLease, "replica-a", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("expected replica-a to renew its lease, got %v (%v)", acquired, err)
+	}
+	if !renewed.AcquiredAt.Equal(first.AcquiredAt) || renewed.ExpiresAt.Before(first.ExpiresAt) {
+		t.Fatalf("renewal must extend the lease without changing acquired_at, got %+v then %+v", first, renewed)
+	}
+
+	// Upsert replica-b gagal dengan duplicate key, yang berarti lease masih dipegang
+	current, acquired, err := leases.Acquire(ctx, domain.SchedulerLease, "replica-b", time.Minute)
+	if err != nil {
+		t.Fatalf("expected a held lease to be reported without error, got %v", err)
+	}
+	if acquired || current.Holder != "replica-a" {
+		t.Fatalf("expected replica-a to keep the lease, got acquired=%v holder=%s", acquired, current.Holder)
+	}
+
+	// TTL negatif membuat lease replica-a langsung kedaluwarsa
+	if _, _, err := leases.Acquire(ctx, domain.SchedulerLease, "replica-a", -time.Second); err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	taken, acquired, err := leases.Acquire(ctx, domain.SchedulerLease, "replica-b", time.Minute)
+	if err != nil || !acquired || taken.Holder != "replica-b" {
+		t.Fatalf("expected replica-b to take over the expired lease, got %+v acquired=%v (%v)", taken, acquired, err)
+	}
+}
+
+func TestLeaseReleaseOnlyByHolder(t *testing.T) {
+	leases := testLeases(t)
+	ctx := context.Background()
+
+	if _, acquired, err := leases.Acquire(ctx, domain.SchedulerLease, "replica-a", time.Minute); err != nil || !acquired {
+		t.Fatalf("acquire: %v (%v)", acquired, err)
+	}
+	if err := leases.Release(ctx, domain.SchedulerLease, "replica-b"); err != nil {
+		t.Fatalf("release by other: %v", err)
+	}
+	if lease, err := leases.Find(ctx, domain.SchedulerLease); err != nil || lease.Holder != "replica-a" {
+		t.Fatalf("another replica must not release the lease, got %+v (%v)", lease, err)
+	}
+
+	if err := leases.Release(ctx, domain.SchedulerLease, "replica-a"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := leases.Find(ctx, domain.SchedulerLease); !errors.Is(err, domain.ErrLeaseNotFound) {
+		t.Fatalf("expected ErrLeaseNotFound after release, got %v", err)
+	}
+	if _, acquired, err := leases.Acquire(ctx, domain.SchedulerLease, "replica-b", time.Minute); err != nil || !acquired {
+		t.Fatalf("expected replica-b to acquire the released lease, got %v (%v)", acquired, err)
+	}
+}
@@ -0,0 +1,122 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// LoadLifecycle membaca konfigurasi transisi dari file JSON; path kosong memakai default
+func LoadLifecycle(path string) (*domain.Lifecycle, error) {
+	if path == "" {
+		return domain.DefaultLifecycle(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lifecycle config: %v", err)
+	}
+	var lifecycle domain.Lifecycle
+	if err := json.Unmarshal(data, &lifecycle); err != nil {
+		return nil, fmt.Errorf("failed to parse lifecycle config: %v", err)
+	}
+	if err := lifecycle.Validate(); err != nil {
+		return nil, err
+	}
+	return &lifecycle, nil
+}
+
+func (r *ArchiveRepository) SetLifecycleState(ctx context.Context, id string, from, to domain.LifecycleState, reviewers []string, transition, userID string) (*domain.Archive, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	current, err := r.findDocument(ctx, bson.M{"_id": objID, "metadata.deleted_at": nil})
+	if err != nil {
+		return nil, err
+	}
+	if current.CurrentState() != from {
+		return nil, domain.ErrStateConflict
+	}
+
+	set := bson.M{"metadata.state": to}
+	update := bson.M{"$set": set}
+	if len(reviewers) > 0 {
+		set["metadata.reviewers"] = reviewers
+	} else {
+		update["$unset"] = bson.M{"metadata.reviewers": ""}
+	}
+
+	updated := *current
+	updated.State = to
+	updated.Reviewers = reviewers
+	// Nama transisi bukan field arsip, jadi dicatat di depan perubahan state dan reviewer
+	changeLog := NewChangeLog(domain.ActionTransition, userID, append(
+		[]domain.Change{{Field: "transition", OldValue: nil, NewValue: transition}},
+		DiffArchives(current, &updated)...,
+	))
+
+	// State dicek ulang saat update supaya dua transisi bersamaan tidak sama-sama berhasil
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": objID, "metadata.deleted_at": nil, "metadata.state": stateMatch(current.State)},
+		withChangeLog(update, changeLog),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update lifecycle state: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, domain.ErrStateConflict
+	}
+
+	return &updated, nil
+}
+
+// stateMatch mencocokkan state, termasuk dokumen lama yang belum memilikinya
+func stateMatch(state domain.LifecycleState) interface{} {
+	if state == "" {
+		return nil
+	}
+	return state
+}
+
+// visibleTo adalah filter arsip yang boleh dilihat viewer: draft hanya untuk pemiliknya
+func visibleTo(viewer string) bson.M {
+	notDraft := bson.M{"metadata.state": bson.M{"$ne": domain.StateDraft}}
+	switch viewer {
+	case domain.AnyViewer:
+		return bson.M{}
+	case "":
+		return notDraft
+	default:
+		return bson.M{"$or": []bson.M{notDraft, {"metadata.owner_id": viewer}}}
+	}
+}
+
+// LogNotifier menulis pemberitahuan ke log aplikasi sampai ada kanal pengiriman lain
+type LogNotifier struct {
+	logger *zap.Logger
+}
+
+func NewLogNotifier(logger *zap.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, notification domain.Notification) error {
+	n.logger.Info("Notifikasi arsip",
+		zap.Strings("recipients", notification.Recipients),
+		zap.String("subject", notification.Subject),
+		zap.String("message", notification.Message),
+		zap.String("archive_id", notification.ArchiveID),
+		zap.Time("timestamp", time.Now()),
+	)
+	return nil
+}
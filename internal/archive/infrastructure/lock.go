@@ -0,0 +1,183 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// claimTimeout adalah batas waktu claim tulis bila proses berhenti sebelum melepasnya
+const claimTimeout = 2 * time.Minute
+
+// claimName memastikan hanya satu request yang menulis versi baru untuk nama file
+// yang sama. Request kedua yang datang bersamaan mendapat ErrVersionConflict,
+// bukan ikut menulis versi N+1 yang sama.
+func (r *ArchiveRepository) claimName(ctx context.Context, name string) (func(), error) {
+	claim := func() error {
+		now := time.Now()
+		_, err := r.claims.InsertOne(ctx, bson.M{
+			"_id":        name,
+			"claimed_at": now,
+			"expires_at": now.Add(claimTimeout),
+		})
+		return err
+	}
+
+	err := claim()
+	if mongo.IsDuplicateKeyError(err) {
+		// Claim yang ditinggalkan proses yang berhenti dianggap kedaluwarsa
+		result, delErr := r.claims.DeleteOne(ctx, bson.M{"_id": name, "expires_at": bson.M{"$lt": time.Now()}})
+		if delErr != nil || result.DeletedCount == 0 {
+			return nil, domain.ErrVersionConflict
+		}
+		err = claim()
+	}
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to claim archive: %v", err)
+	}
+
+	return func() {
+		_, _ = r.claims.DeleteOne(context.Background(), bson.M{"_id": name})
+	}, nil
+}
+
+// checkWrite memeriksa If-Match dan lock sebelum versi baru atau metadata ditulis
+func checkWrite(current *domain.Archive, userID string, cond domain.Precondition) error {
+	if !cond.Matches(current) {
+		return domain.ErrPreconditionFailed
+	}
+	if current != nil && current.LockedFor(userID, time.Now()) {
+		return domain.ErrArchiveLocked
+	}
+	return nil
+}
+
+// writeConflict menjelaskan compare-and-set yang gagal dengan membaca ulang arsip:
+// arsip yang hilang atau dihapus menjadi ErrArchiveNotFound, If-Match yang tidak lagi
+// cocok ErrPreconditionFailed, lock user lain ErrArchiveLocked, selain itu ErrVersionConflict
+func (r *ArchiveRepository) writeConflict(ctx context.Context, id primitive.ObjectID, userID string, cond domain.Precondition) error {
+	current, err := r.FindMetadata(ctx, id.Hex())
+	if err != nil {
+		return err
+	}
+	if err := checkWrite(current, userID, cond); err != nil {
+		return err
+	}
+	return domain.ErrVersionConflict
+}
+
+// writableBy adalah filter untuk arsip yang tidak sedang di-check-out user lain
+func writableBy(userID string, now time.Time) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"metadata.lock": nil},
+		{"metadata.lock.owner": userID},
+		{"metadata.lock.expires_at": bson.M{"$lte": now}},
+	}}
+}
+
+// revisionMatch mencocokkan metadata_revision, termasuk dokumen lama yang belum memilikinya
+func revisionMatch(revision int) interface{} {
+	if revision == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return revision
+}
+
+// Checkout mengunci arsip untuk userID. Lock milik sendiri diperpanjang,
+// lock milik user lain yang masih aktif menghasilkan ErrArchiveLocked.
+func (r *ArchiveRepository) Checkout(ctx context.Context, id, userID string, ttl time.Duration) (*domain.Archive, error) {
+	current, err := r.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lock := domain.ArchiveLock{Owner: userID, AcquiredAt: now, ExpiresAt: now.Add(ttl)}
+	if current.Lock.Active(now) && current.Lock.Owner == userID {
+		lock.AcquiredAt = current.Lock.AcquiredAt
+	}
+
+	updated := *current
+	updated.Lock = &lock
+	changeLog := CreateChangeLog(domain.ActionCheckout, userID, current, &updated)
+
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{
+			"_id":                 current.ID,
+			"metadata.deleted_at": nil,
+			"$and":                []bson.M{writableBy(userID, now)},
+		},
+		withChangeLog(bson.M{"$set": bson.M{"metadata.lock": lock}}, changeLog),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout archive: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		// Arsip bisa saja dihapus di antara baca dan tulis
+		if err := r.writeConflict(ctx, current.ID, userID, domain.Precondition{}); !errors.Is(err, domain.ErrVersionConflict) {
+			return nil, err
+		}
+		return nil, domain.ErrArchiveLocked
+	}
+
+	return &updated, nil
+}
+
+// Checkin melepas lock milik userID. Dengan force, lock siapa pun dilepas (break-lock admin).
+func (r *ArchiveRepository) Checkin(ctx context.Context, id, userID string, force bool) error {
+	current, err := r.FindMetadata(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !current.Lock.Active(now) {
+		return domain.ErrNotCheckedOut
+	}
+	if current.Lock.Owner != userID && !force {
+		return domain.ErrArchiveLocked
+	}
+
+	action := domain.ActionCheckin
+	if current.Lock.Owner != userID {
+		action = domain.ActionBreakLock
+	}
+	updated := *current
+	updated.Lock = nil
+	changeLog := CreateChangeLog(action, userID, current, &updated)
+
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": current.ID, "metadata.lock.owner": current.Lock.Owner},
+		withChangeLog(bson.M{"$unset": bson.M{"metadata.lock": ""}}, changeLog),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to checkin archive: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+func decodeLock(v interface{}) *domain.ArchiveLock {
+	lock, ok := v.(bson.M)
+	if !ok {
+		return nil
+	}
+	return &domain.ArchiveLock{
+		Owner:      stringValue(lock["owner"]),
+		AcquiredAt: timeValue(lock["acquired_at"]),
+		ExpiresAt:  timeValue(lock["expires_at"]),
+	}
+}
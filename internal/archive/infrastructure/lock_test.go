@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+func TestCheckWriteMapsPreconditionAndLock(t *testing.T) {
+	now := time.Now()
+	archive := &domain.Archive{Version: 2, MetadataRevision: 3}
+	lockedByAlice := &domain.Archive{Version: 2, MetadataRevision: 3,
+		Lock: &domain.ArchiveLock{Owner: "alice", ExpiresAt: now.Add(time.Hour)}}
+	expiredLock := &domain.Archive{Version: 2, MetadataRevision: 3,
+		Lock: &domain.ArchiveLock{Owner: "alice", ExpiresAt: now.Add(-time.Minute)}}
+
+	tests := []struct {
+		name    string
+		current *domain.Archive
+		userID  string
+		ifMatch []string
+		want    error
+	}{
+		{"no precondition", archive, "bob", nil, nil},
+		{"current etag", archive, "bob", []string{`"2.3"`}, nil},
+		{"weak etag", archive, "bob", []string{`W/"2.3"`}, nil},
+		{"version only", archive, "bob", []string{"2"}, nil},
+		{"wildcard", archive, "bob", []string{"*"}, nil},
+		{"one of several tags", archive, "bob", []string{`"1.0"`, `"2.3"`}, nil},
+		{"stale metadata revision", archive, "bob", []string{`"2.2"`}, domain.ErrPreconditionFailed},
+		{"stale version", archive, "bob", []string{"1"}, domain.ErrPreconditionFailed},
+		{"new archive without precondition", nil, "bob", nil, nil},
+		{"new archive with precondition", nil, "bob", []string{"*"}, domain.ErrPreconditionFailed},
+		{"checked out by another user", lockedByAlice, "bob", nil, domain.ErrArchiveLocked},
+		{"checked out by the writer", lockedByAlice, "alice", nil, nil},
+		{"expired lock", expiredLock, "bob", nil, nil},
+		// If-Match diperiksa lebih dulu: klien dengan salinan basi harus membaca ulang
+		{"stale and locked", lockedByAlice, "bob", []string{"1"}, domain.ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkWrite(tt.current, tt.userID, domain.Precondition{IfMatch: tt.ifMatch})
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCheckoutCheckinAndBreakLock(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	archive := saveArchive(t, repo, "kontrak.pdf", "alice", "isi")
+	id := archive.ID.Hex()
+
+	first, err := repo.Checkout(ctx, id, "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	// Checkout ulang oleh pemilik lock memperpanjang tanpa mengubah waktu mulai
+	extended, err := repo.Checkout(ctx, id, "alice", 2*time.Hour)
+	if err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	if !extended.Lock.AcquiredAt.Equal(first.Lock.AcquiredAt) || !extended.Lock.ExpiresAt.After(first.Lock.ExpiresAt) {
+		t.Fatalf("expected the lock to be extended, got %+v then %+v", first.Lock, extended.Lock)
+	}
+
+	if _, err := repo.Checkout(ctx, id, "bob", time.Hour); !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("checkout by bob: expected ErrArchiveLocked, got %v", err)
+	}
+	category := "hukum"
+	if _, err := repo.UpdateMetadata(ctx, id, domain.ArchivePatch{Category: &category}, "bob", domain.Precondition{}); !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("update by bob: expected ErrArchiveLocked, got %v", err)
+	}
+	if err := repo.Checkin(ctx, id, "bob", false); !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("checkin by bob: expected ErrArchiveLocked, got %v", err)
+	}
+
+	// Admin memutus lock milik alice
+	if err := repo.Checkin(ctx, id, "admin", true); err != nil {
+		t.Fatalf("break lock: %v", err)
+	}
+	current, err := repo.FindMetadata(ctx, id)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if current.Lock != nil {
+		t.Fatalf("expected the lock to be removed, got %+v", current.Lock)
+	}
+	if last := current.ChangeLogs[len(current.ChangeLogs)-1]; last.Action != domain.ActionBreakLock || last.UserID != "admin" {
+		t.Fatalf("expected a break_lock entry by admin, got %s by %s", last.Action, last.UserID)
+	}
+	if err := repo.Checkin(ctx, id, "alice", false); !errors.Is(err, domain.ErrNotCheckedOut) {
+		t.Fatalf("expected ErrNotCheckedOut, got %v", err)
+	}
+
+	// Pemilik lock melepas lock-nya sendiri dengan checkin biasa
+	if _, err := repo.Checkout(ctx, id, "bob", time.Hour); err != nil {
+		t.Fatalf("checkout by bob: %v", err)
+	}
+	if err := repo.Checkin(ctx, id, "bob", false); err != nil {
+		t.Fatalf("checkin by bob: %v", err)
+	}
+	current, err = repo.FindMetadata(ctx, id)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if last := current.ChangeLogs[len(current.ChangeLogs)-1]; last.Action != domain.ActionCheckin {
+		t.Fatalf("expected a checkin entry, got %s", last.Action)
+	}
+}
+
+func TestCheckoutTakesOverExpiredLock(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	archive := saveArchive(t, repo, "memo.pdf", "alice", "isi")
+
+	// TTL negatif membuat lock alice langsung kedaluwarsa
+	if _, err := repo.Checkout(ctx, archive.ID.Hex(), "alice", -time.Second); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	locked, err := repo.Checkout(ctx, archive.ID.Hex(), "bob", time.Hour)
+	if err != nil {
+		t.Fatalf("expected bob to take over the expired lock, got %v", err)
+	}
+	if locked.Lock.Owner != "bob" {
+		t.Fatalf("expected bob to own the lock, got %s", locked.Lock.Owner)
+	}
+}
@@ -0,0 +1,159 @@
+package infrastructure
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// minHashSize adalah jumlah fungsi hash pada signature
+	minHashSize = 128
+	// lshBandCount x lshRows harus sama dengan minHashSize
+	lshBandCount = 32
+	lshRows      = minHashSize / lshBandCount
+	// shingleSize adalah jumlah kata per shingle
+	shingleSize = 5
+)
+
+var minHashSeeds = func() [minHashSize]uint64 {
+	var seeds [minHashSize]uint64
+	state := uint64(0x9e3779b97f4a7c15)
+	for i := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[i] = mix64(state)
+	}
+	return seeds
+}()
+
+// MinHashFingerprinter membuat signature MinHash dari teks PDF
+type MinHashFingerprinter struct{}
+
+func NewMinHashFingerprinter() *MinHashFingerprinter {
+	return &MinHashFingerprinter{}
+}
+
+// Fingerprint mengembalikan nil bila PDF tidak memiliki teks yang bisa diekstrak
+func (f *MinHashFingerprinter) Fingerprint(content []byte) []uint64 {
+	shingles := textShingles(ExtractPDFText(content))
+	if len(shingles) == 0 {
+		return nil
+	}
+	return minHash(shingles)
+}
+
+// textShingles menormalisasi teks lalu membentuk shingle kata yang di-hash
+func textShingles(text string) []uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < shingleSize {
+		return []uint64{hashString(strings.Join(words, " "))}
+	}
+
+	seen := make(map[uint64]struct{}, len(words))
+	shingles := make([]uint64, 0, len(words))
+	for i := 0; i+shingleSize <= len(words); i++ {
+		h := hashString(strings.Join(words[i:i+shingleSize], " "))
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		shingles = append(shingles, h)
+	}
+	return shingles
+}
+
+func minHash(shingles []uint64) []uint64 {
+	signature := make([]uint64, minHashSize)
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+	for _, shingle := range shingles {
+		for i := range signature {
+			if h := mix64(shingle ^ minHashSeeds[i]); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// signatureSimilarity memperkirakan Jaccard similarity dari dua signature
+func signatureSimilarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// lshBands membagi signature menjadi band untuk pencarian kandidat lewat index
+func lshBands(signature []uint64) []string {
+	if len(signature) != minHashSize {
+		return nil
+	}
+
+	bands := make([]string, 0, lshBandCount)
+	for b := 0; b < lshBandCount; b++ {
+		h := fnv.New64a()
+		for _, v := range signature[b*lshRows : (b+1)*lshRows] {
+			var buf [8]byte
+			for i := range buf {
+				buf[i] = byte(v >> (8 * i))
+			}
+			h.Write(buf[:])
+		}
+		bands = append(bands, fmt.Sprintf("%02d:%016x", b, h.Sum64()))
+	}
+	return bands
+}
+
+// signatureToBSON dan signatureFromBSON menyimpan uint64 sebagai int64 karena BSON tidak punya uint64
+func signatureToBSON(signature []uint64) []int64 {
+	out := make([]int64, len(signature))
+	for i, v := range signature {
+		out[i] = int64(v)
+	}
+	return out
+}
+
+func signatureFromBSON(v interface{}) []uint64 {
+	values, ok := v.(primitive.A)
+	if !ok {
+		return nil
+	}
+	out := make([]uint64, 0, len(values))
+	for _, value := range values {
+		out = append(out, uint64(int64Value(value)))
+	}
+	return out
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix64 adalah finalizer splitmix64
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
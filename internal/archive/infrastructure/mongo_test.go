@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testDatabase membuka database sementara pada MongoDB dari MONGODB_TEST_URI dan
+// menghapusnya setelah test selesai. Test yang membutuhkan MongoDB dilewati bila
+// variabel itu tidak diisi.
+func testDatabase(t *testing.T) (*mongo.Client, string) {
+	t.Helper()
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI tidak diisi, test MongoDB dilewati")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	dbName := fmt.Sprintf("archiven_test_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = client.Database(dbName).Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+	return client, dbName
+}
+
+func testRepository(t *testing.T) *ArchiveRepository {
+	t.Helper()
+	client, dbName := testDatabase(t)
+	repo, err := NewArchiveRepository(client, dbName)
+	if err != nil {
+		t.Fatalf("repository: %v", err)
+	}
+	return repo
+}
+
+// saveArchive mengunggah arsip atau versi baru dengan nama tertentu atas nama owner
+func saveArchive(t *testing.T, repo *ArchiveRepository, name, owner string, content string) *domain.Archive {
+	t.Helper()
+	archive, err := repo.SaveWithVersioning(context.Background(), domain.Archive{
+		Name:     name,
+		Category: "umum",
+		Type:     "pdf",
+		OwnerID:  owner,
+	}, []byte(content), domain.Precondition{})
+	if err != nil {
+		t.Fatalf("save %s: %v", name, err)
+	}
+	return archive
+}
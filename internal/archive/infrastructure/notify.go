@@ -0,0 +1,270 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+// notifyTimeout membatasi satu pengiriman bila context pemanggil tidak punya deadline
+const notifyTimeout = 10 * time.Second
+
+// SMTPConfig berisi server SMTP dan cara memetakan user ID ke alamat email
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// EmailDomain ditambahkan ke user ID yang bukan alamat email, misalnya "user123@example.com"
+	EmailDomain string
+}
+
+// SMTPNotifier mengirim pemberitahuan sebagai email teks biasa. STARTTLS dipakai bila
+// server menawarkannya, sehingga SMTP sink lokal tanpa TLS juga bisa dipakai.
+type SMTPNotifier struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	to := n.addresses(notification.Recipients)
+	if len(to) == 0 {
+		return domain.ErrNoEmailRecipient
+	}
+
+	ctx, cancel := withNotifyTimeout(ctx)
+	defer cancel()
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %v", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %v", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start tls: %v", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+				return fmt.Errorf("failed to authenticate to smtp server: %v", err)
+			}
+		}
+	}
+
+	if err := client.Mail(n.cfg.From); err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %v", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	if _, err := w.Write(n.message(to, notification)); err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	return client.Quit()
+}
+
+// addresses memetakan penerima ke alamat email; user ID tanpa EmailDomain dilewati
+func (n *SMTPNotifier) addresses(recipients []string) []string {
+	to := []string{}
+	for _, recipient := range recipients {
+		switch {
+		case strings.Contains(recipient, "@"):
+			to = append(to, recipient)
+		case recipient != "" && n.cfg.EmailDomain != "":
+			to = append(to, recipient+"@"+n.cfg.EmailDomain)
+		}
+	}
+	return to
+}
+
+func (n *SMTPNotifier) message(to []string, notification domain.Notification) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+
+	body := notification.Message
+	if len(notification.Links) > 0 {
+		body += "\n"
+		for _, name := range sortedKeys(notification.Links) {
+			body += fmt.Sprintf("\n%s: %s", strings.ToUpper(name[:1])+name[1:], notification.Links[name])
+		}
+	}
+	// Baris email harus diakhiri CRLF
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// WebhookNotifier mengirim pemberitahuan sebagai JSON ke satu URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+// webhookPayload adalah body JSON yang dikirim ke webhook
+type webhookPayload struct {
+	Event      string            `json:"event"`
+	ArchiveID  string            `json:"archive_id"`
+	Recipients []string          `json:"recipients"`
+	Subject    string            `json:"subject"`
+	Message    string            `json:"message"`
+	Links      map[string]string `json:"links,omitempty"`
+	SentAt     time.Time         `json:"sent_at"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	body, err := json.Marshal(webhookPayload{
+		Event:      notification.Event,
+		ArchiveID:  notification.ArchiveID,
+		Recipients: notification.Recipients,
+		Subject:    notification.Subject,
+		Message:    notification.Message,
+		Links:      notification.Links,
+		SentAt:     time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HTTPWebhookSender mengirim event arsip ke URL langganan webhook
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+// NewHTTPWebhookSender membuat sender dengan timeout per request; redirect tidak diikuti
+// agar body yang sudah ditandatangani tidak dikirim ke URL lain
+func NewHTTPWebhookSender(timeout time.Duration) *HTTPWebhookSender {
+	return &HTTPWebhookSender{client: &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (s *HTTPWebhookSender) Send(ctx context.Context, webhook domain.WebhookRequest) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(webhook.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "archiven-webhook/1.0")
+	for key, value := range webhook.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	// Body dibaca sebagian agar koneksi bisa dipakai ulang
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// MultiNotifier mengirim ke semua kanal; kegagalan satu kanal tidak menghentikan kanal lain
+type MultiNotifier struct {
+	channels []domain.NotificationChannel
+}
+
+func NewMultiNotifier(channels []domain.NotificationChannel) *MultiNotifier {
+	return &MultiNotifier{channels: channels}
+}
+
+func (n *MultiNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	var errs []error
+	for _, channel := range n.channels {
+		if err := channel.Notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func withNotifyTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, notifyTimeout)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+// smtpSink adalah server SMTP lokal minimal tanpa STARTTLS dan AUTH yang menyimpan email masuk
+type smtpSink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	from     string
+	rcpts    []string
+	data     string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	sink := &smtpSink{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go sink.serve()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *smtpSink) session(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP sink")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(cmd[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			s.mu.Unlock()
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifierSendsMail(t *testing.T) {
+	sink := newSMTPSink(t)
+	notifier := NewSMTPNotifier(SMTPConfig{
+		Host:        "127.0.0.1",
+		Port:        sink.port(),
+		From:        "archive@example.com",
+		EmailDomain: "example.com",
+	})
+
+	err := notifier.Notify(context.Background(), domain.Notification{
+		Recipients: []string{"user123", "reviewer@example.org", ""},
+		Subject:    "report.pdf expires soon",
+		Message:    "\"report.pdf\" expires tomorrow.",
+		Links:      map[string]string{"extend": "http://localhost/reminders/actions/abc.def"},
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.from != "archive@example.com" {
+		t.Fatalf("MAIL FROM %q", sink.from)
+	}
+	if strings.Join(sink.rcpts, ",") != "user123@example.com,reviewer@example.org" {
+		t.Fatalf("RCPT TO %v", sink.rcpts)
+	}
+	for _, want := range []string{
+		"To: user123@example.com, reviewer@example.org\r\n",
+		"Subject: report.pdf expires soon\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\"report.pdf\" expires tomorrow.\r\n\r\nExtend: http://localhost/reminders/actions/abc.def\r\n",
+	} {
+		if !strings.Contains(sink.data, want) {
+			t.Fatalf("message missing %q:\n%s", want, sink.data)
+		}
+	}
+}
+
+func TestSMTPNotifierWithoutAddress(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: 1, From: "archive@example.com"})
+
+	err := notifier.Notify(context.Background(), domain.Notification{Recipients: []string{"user123"}})
+	if !errors.Is(err, domain.ErrNoEmailRecipient) {
+		t.Fatalf("got %v, want ErrNoEmailRecipient", err)
+	}
+}
+
+func TestSMTPNotifierConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: port, From: "archive@example.com"})
+	if err := notifier.Notify(context.Background(), domain.Notification{Recipients: []string{"a@example.com"}}); err == nil {
+		t.Fatal("expected error when smtp server is down")
+	}
+}
@@ -0,0 +1,304 @@
+package infrastructure
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxStreamSize membatasi hasil dekompresi satu stream agar PDF rusak tidak menghabiskan memori
+const maxStreamSize = 16 << 20
+
+// ExtractPDFText mengambil teks dari content stream PDF. Ekstraksi ini sengaja
+// sederhana: hanya operator teks (Tj, TJ, ', ") pada stream tanpa filter atau
+// dengan FlateDecode yang dibaca. PDF hasil scan tanpa lapisan teks menghasilkan
+// string kosong.
+func ExtractPDFText(content []byte) string {
+	var out strings.Builder
+	for _, stream := range pdfStreams(content) {
+		extractTextOperators(stream, &out)
+	}
+	return out.String()
+}
+
+func pdfStreams(data []byte) [][]byte {
+	var streams [][]byte
+	keyword := []byte("stream")
+	pos := 0
+
+	for pos < len(data) {
+		i := bytes.Index(data[pos:], keyword)
+		if i < 0 {
+			break
+		}
+		start := pos + i
+
+		// Lewati keyword "endstream"
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			pos = start + len(keyword)
+			continue
+		}
+
+		bodyStart := start + len(keyword)
+		if bodyStart < len(data) && data[bodyStart] == '\r' {
+			bodyStart++
+		}
+		if bodyStart < len(data) && data[bodyStart] == '\n' {
+			bodyStart++
+		}
+
+		j := bytes.Index(data[bodyStart:], []byte("endstream"))
+		if j < 0 {
+			break
+		}
+		body := data[bodyStart : bodyStart+j]
+
+		// Dictionary stream berada di antara "obj" terakhir dan keyword stream
+		dict := data[pos:start]
+		if k := bytes.LastIndex(dict, []byte("obj")); k >= 0 {
+			dict = dict[k:]
+		}
+		pos = bodyStart + j + len("endstream")
+
+		switch {
+		case bytes.Contains(dict, []byte("/Image")), bytes.Contains(dict, []byte("/ObjStm")):
+			continue
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			r, err := zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				continue
+			}
+			// Stream yang terpotong tetap dipakai sebanyak yang terbaca
+			decoded, _ := io.ReadAll(io.LimitReader(r, maxStreamSize))
+			r.Close()
+			streams = append(streams, decoded)
+		case bytes.Contains(dict, []byte("/Filter")):
+			// Filter lain (DCT, JBIG2, dll.) berisi gambar, bukan teks
+			continue
+		default:
+			streams = append(streams, body)
+		}
+	}
+
+	return streams
+}
+
+// extractTextOperators membaca string yang dipakai operator teks pada content stream
+func extractTextOperators(stream []byte, out *strings.Builder) {
+	var pending []string
+	var operands []float64
+	inArray := false
+
+	for i := 0; i < len(stream); {
+		ch := stream[i]
+		switch {
+		case ch == '(':
+			str, next := readLiteralString(stream, i)
+			pending = append(pending, str)
+			i = next
+		case ch == '<' && i+1 < len(stream) && stream[i+1] != '<':
+			str, next := readHexString(stream, i)
+			pending = append(pending, str)
+			i = next
+		case ch == '[':
+			inArray = true
+			i++
+		case ch == ']':
+			inArray = false
+			i++
+		case ch == '%':
+			// Komentar sampai akhir baris
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case isPDFDelimiter(ch) || isPDFSpace(ch):
+			i++
+		default:
+			start := i
+			for i < len(stream) && !isPDFSpace(stream[i]) && !isPDFDelimiter(stream[i]) {
+				i++
+			}
+			word := string(stream[start:i])
+			n, numErr := strconv.ParseFloat(word, 64)
+			if inArray {
+				// Kerning negatif yang besar pada TJ biasanya berarti spasi antar kata
+				if numErr == nil && n < -200 {
+					pending = append(pending, " ")
+				}
+				continue
+			}
+			if numErr == nil {
+				operands = append(operands, n)
+				continue
+			}
+
+			switch word {
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(pending, ""))
+			case "'", "\"":
+				out.WriteString("\n")
+				out.WriteString(strings.Join(pending, ""))
+			case "Td", "TD":
+				// Perpindahan vertikal berarti baris baru, horizontal cukup spasi
+				if len(operands) >= 2 && operands[len(operands)-1] != 0 {
+					out.WriteString("\n")
+				} else {
+					out.WriteString(" ")
+				}
+			case "T*", "Tm", "ET":
+				out.WriteString("\n")
+			}
+			pending = pending[:0]
+			operands = operands[:0]
+		}
+	}
+	out.WriteString("\n")
+}
+
+func readLiteralString(data []byte, start int) (string, int) {
+	var buf []byte
+	depth := 0
+	i := start
+
+	for i < len(data) {
+		ch := data[i]
+		switch ch {
+		case '\\':
+			i++
+			if i >= len(data) {
+				break
+			}
+			switch esc := data[i]; esc {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b', 'f':
+				buf = append(buf, ' ')
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if esc >= '0' && esc <= '7' {
+					val := 0
+					n := 0
+					for n < 3 && i < len(data) && data[i] >= '0' && data[i] <= '7' {
+						val = val*8 + int(data[i]-'0')
+						i++
+						n++
+					}
+					buf = append(buf, byte(val))
+					continue
+				}
+				buf = append(buf, esc)
+			}
+			i++
+		case '(':
+			depth++
+			if depth > 1 {
+				buf = append(buf, ch)
+			}
+			i++
+		case ')':
+			depth--
+			i++
+			if depth == 0 {
+				return decodePDFBytes(buf), i
+			}
+			buf = append(buf, ch)
+		default:
+			buf = append(buf, ch)
+			i++
+		}
+	}
+	return decodePDFBytes(buf), i
+}
+
+func readHexString(data []byte, start int) (string, int) {
+	end := bytes.IndexByte(data[start:], '>')
+	if end < 0 {
+		return "", len(data)
+	}
+
+	var digits []byte
+	for _, ch := range data[start+1 : start+end] {
+		if isHexDigit(ch) {
+			digits = append(digits, ch)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	buf := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		buf = append(buf, hexValue(digits[i])<<4|hexValue(digits[i+1]))
+	}
+	return decodePDFBytes(buf), start + end + 1
+}
+
+// decodePDFBytes mengubah byte string PDF menjadi teks. Encoding dua byte
+// (byte tinggi selalu nol) disederhanakan menjadi satu byte.
+func decodePDFBytes(buf []byte) string {
+	if len(buf) >= 2 && len(buf)%2 == 0 {
+		wide := true
+		for i := 0; i < len(buf); i += 2 {
+			if buf[i] != 0 {
+				wide = false
+				break
+			}
+		}
+		if wide {
+			narrow := make([]byte, 0, len(buf)/2)
+			for i := 1; i < len(buf); i += 2 {
+				narrow = append(narrow, buf[i])
+			}
+			buf = narrow
+		}
+	}
+
+	runes := make([]rune, 0, len(buf))
+	for _, b := range buf {
+		r := rune(b)
+		if !unicode.IsPrint(r) {
+			r = ' '
+		}
+		runes = append(runes, r)
+	}
+	return string(runes)
+}
+
+func isPDFSpace(ch byte) bool {
+	switch ch {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(ch byte) bool {
+	switch ch {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+func hexValue(ch byte) byte {
+	switch {
+	case ch >= '0' && ch <= '9':
+		return ch - '0'
+	case ch >= 'a' && ch <= 'f':
+		return ch - 'a' + 10
+	default:
+		return ch - 'A' + 10
+	}
+}
@@ -0,0 +1,150 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RelationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRelationRepository(client *mongo.Client, dbName string) (*RelationRepository, error) {
+	collection := client.Database(dbName).Collection("archive_relations")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Satu jenis link hanya boleh ada sekali untuk pasangan source dan target yang sama
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "source_id", Value: 1}, {Key: "target_id", Value: 1}, {Key: "type", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "type", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relation indexes: %v", err)
+	}
+
+	return &RelationRepository{collection: collection}, nil
+}
+
+func (r *RelationRepository) Create(ctx context.Context, relation *domain.Relation) error {
+	if relation.ID.IsZero() {
+		relation.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, relation); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrRelationExists
+		}
+		return fmt.Errorf("failed to insert relation: %v", err)
+	}
+	return nil
+}
+
+func (r *RelationRepository) FindByID(ctx context.Context, id string) (*domain.Relation, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrRelationNotFound
+	}
+
+	var relation domain.Relation
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&relation); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrRelationNotFound
+		}
+		return nil, fmt.Errorf("failed to find relation: %v", err)
+	}
+	return &relation, nil
+}
+
+func (r *RelationRepository) FindByArchive(ctx context.Context, archiveID string) (*domain.ArchiveRelations, error) {
+	objID, err := primitive.ObjectIDFromHex(archiveID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	outgoing, err := r.find(ctx, bson.M{"source_id": objID})
+	if err != nil {
+		return nil, err
+	}
+	incoming, err := r.find(ctx, bson.M{"target_id": objID})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ArchiveRelations{Outgoing: outgoing, Incoming: incoming}, nil
+}
+
+func (r *RelationRepository) FindIncoming(ctx context.Context, archiveID string, relationType domain.RelationType) ([]domain.Relation, error) {
+	objID, err := primitive.ObjectIDFromHex(archiveID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
+	}
+	return r.find(ctx, bson.M{"target_id": objID, "type": relationType, "broken_at": nil})
+}
+
+func (r *RelationRepository) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrRelationNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete relation: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrRelationNotFound
+	}
+	return nil
+}
+
+// MarkBroken tidak menghapus link agar tetap terlihat bahwa arsip pernah terhubung
+func (r *RelationRepository) MarkBroken(ctx context.Context, archiveID string) ([]domain.Relation, error) {
+	objID, err := primitive.ObjectIDFromHex(archiveID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	filter := bson.M{
+		"$or":       []bson.M{{"source_id": objID}, {"target_id": objID}},
+		"broken_at": nil,
+	}
+	relations, err := r.find(ctx, filter)
+	if err != nil || len(relations) == 0 {
+		return nil, err
+	}
+
+	now := time.Now()
+	if _, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"broken_at": now}}); err != nil {
+		return nil, fmt.Errorf("failed to mark relations broken: %v", err)
+	}
+	for i := range relations {
+		relations[i].BrokenAt = &now
+	}
+	return relations, nil
+}
+
+func (r *RelationRepository) find(ctx context.Context, filter bson.M) ([]domain.Relation, error) {
+	cur, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find relations: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	relations := []domain.Relation{}
+	if err := cur.All(ctx, &relations); err != nil {
+		return nil, fmt.Errorf("failed to decode relations: %v", err)
+	}
+	return relations, nil
+}
@@ -0,0 +1,199 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// reminderTTL adalah lama reminder disimpan setelah tanggal penghapusannya lewat
+const reminderTTL = 30 * 24 * time.Hour
+
+type ReminderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReminderRepository(client *mongo.Client, dbName string) (*ReminderRepository, error) {
+	collection := client.Database(dbName).Collection("reminders")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// Satu reminder per arsip, jenis dan tanggal penghapusan, supaya tidak ada email ganda
+		{
+			Keys:    bson.D{{Key: "archive_id", Value: 1}, {Key: "kind", Value: 1}, {Key: "due_at", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "due_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(reminderTTL.Seconds())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reminder indexes: %v", err)
+	}
+
+	return &ReminderRepository{collection: collection}, nil
+}
+
+func (r *ReminderRepository) Claim(ctx context.Context, reminder *domain.Reminder) (*domain.Reminder, error) {
+	if reminder.ID.IsZero() {
+		reminder.ID = primitive.NewObjectID()
+	}
+	if reminder.Deliveries == nil {
+		reminder.Deliveries = []domain.ReminderDelivery{}
+	}
+
+	filter := bson.M{
+		"archive_id": reminder.ArchiveID,
+		"kind":       reminder.Kind,
+		"due_at":     reminder.DueAt,
+	}
+	update := bson.M{"$setOnInsert": bson.M{
+		"_id":        reminder.ID,
+		"name":       reminder.Name,
+		"recipient":  reminder.Recipient,
+		"created_at": reminder.CreatedAt,
+		"deliveries": reminder.Deliveries,
+	}}
+
+	var claimed domain.Reminder
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&claimed)
+	// Upsert bersamaan untuk reminder yang sama: salah satu gagal, baca milik yang menang
+	if mongo.IsDuplicateKeyError(err) {
+		err = r.collection.FindOne(ctx, filter).Decode(&claimed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim reminder: %v", err)
+	}
+	return &claimed, nil
+}
+
+func (r *ReminderRepository) FindByID(ctx context.Context, id string) (*domain.Reminder, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrReminderNotFound
+	}
+
+	var reminder domain.Reminder
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&reminder); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrReminderNotFound
+		}
+		return nil, fmt.Errorf("failed to find reminder: %v", err)
+	}
+	return &reminder, nil
+}
+
+func (r *ReminderRepository) ClaimDelivery(ctx context.Context, id primitive.ObjectID, channel string, now time.Time, lease time.Duration) (bool, error) {
+	until := now.Add(lease)
+
+	// Kanal yang belum pernah dicoba ditambahkan sekaligus dengan lease-nya
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deliveries.channel": bson.M{"$ne": channel}},
+		bson.M{"$push": bson.M{"deliveries": domain.ReminderDelivery{Channel: channel, Attempts: 1, SendingUntil: &until}}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder delivery: %v", err)
+	}
+	if result.ModifiedCount > 0 {
+		return true, nil
+	}
+
+	// Lease run yang mati di tengah pengiriman lepas sendiri setelah habis
+	result, err = r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deliveries": bson.M{"$elemMatch": bson.M{
+			"channel":  channel,
+			"sent_at":  nil,
+			"attempts": bson.M{"$lt": domain.MaxReminderAttempts},
+			"$or": []bson.M{
+				{"sending_until": nil},
+				{"sending_until": bson.M{"$lte": now}},
+			},
+		}}},
+		bson.M{
+			"$set": bson.M{"deliveries.$.sending_until": until},
+			"$inc": bson.M{"deliveries.$.attempts": 1},
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder delivery: %v", err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *ReminderRepository) FinishDelivery(ctx context.Context, id primitive.ObjectID, channel string, sentAt *time.Time, errMsg string) error {
+	set := bson.M{"deliveries.$.error": errMsg}
+	unset := bson.M{"deliveries.$.sending_until": ""}
+	if sentAt != nil {
+		set = bson.M{"deliveries.$.sent_at": sentAt}
+		unset["deliveries.$.error"] = ""
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deliveries.channel": channel},
+		bson.M{"$set": set, "$unset": unset},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update reminder: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrReminderNotFound
+	}
+	return nil
+}
+
+func (r *ReminderRepository) ClaimAction(ctx context.Context, id string, action domain.ReminderAction, at time.Time) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrReminderNotFound
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objID, "action": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"action": action, "action_at": at}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update reminder: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrReminderActionUsed
+	}
+	return nil
+}
+
+func (r *ReminderRepository) ReleaseAction(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrReminderNotFound
+	}
+
+	if _, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objID},
+		bson.M{"$unset": bson.M{"action": "", "action_at": ""}},
+	); err != nil {
+		return fmt.Errorf("failed to update reminder: %v", err)
+	}
+	return nil
+}
@@ -1,12 +1,13 @@
 package infrastructure
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"reflect"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
@@ -20,8 +21,15 @@ import (
 )
 
 type ArchiveRepository struct {
-	bucket *gridfs.Bucket
-	client *mongo.Client
+	bucket     *gridfs.Bucket
+	revisions  *gridfs.Bucket
+	client     *mongo.Client
+	tombstones *mongo.Collection
+	claims     *mongo.Collection
+	// cold menyimpan konten arsip yang sudah dipindah ke cold tier, nil bila tiering mati
+	cold domain.ColdStore
+	// onRemove dipanggil setelah arsip dihapus permanen, misalnya untuk membersihkan relasi
+	onRemove []func(ctx context.Context, id string)
 }
 
 func NewArchiveRepository(client *mongo.Client, dbName string) (*ArchiveRepository, error) {
@@ -38,9 +46,57 @@ func NewArchiveRepository(client *mongo.Client, dbName string) (*ArchiveReposito
 		return nil, fmt.Errorf("failed to create gridfs bucket: %v", err)
 	}
 
+	// Konten versi lama disimpan di bucket terpisah agar bisa di-rollback
+	revisions, err := gridfs.NewBucket(
+		client.Database(dbName),
+		options.GridFSBucket().
+			SetName("archive_revisions").
+			SetChunkSizeBytes(1024*1024).
+			SetWriteConcern(writeconcern.W1()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revision bucket: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Index band LSH untuk pencarian dokumen yang mirip, folder untuk listing isi folder,
+	// jatuh tempo retensi untuk disposition, legal hold dan state lifecycle
+	_, err = bucket.GetFilesCollection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "metadata.lsh_bands", Value: 1}}},
+		{Keys: bson.D{{Key: "metadata.folder_id", Value: 1}}},
+		{Keys: bson.D{{Key: "metadata.retention.due_at", Value: 1}}},
+		{Keys: bson.D{{Key: "metadata.legal_holds", Value: 1}}},
+		{Keys: bson.D{{Key: "metadata.state", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive indexes: %v", err)
+	}
+
+	_, err = revisions.GetFilesCollection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "metadata.archive_id", Value: 1}, {Key: "metadata.version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revision indexes: %v", err)
+	}
+
+	claims := client.Database(dbName).Collection("archive_write_claims")
+	_, err = claims.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create claim indexes: %v", err)
+	}
+
 	return &ArchiveRepository{
-		bucket: bucket,
-		client: client,
+		bucket:     bucket,
+		revisions:  revisions,
+		client:     client,
+		tombstones: client.Database(dbName).Collection("archive_tombstones"),
+		claims:     claims,
 	}, nil
 }
 
@@ -82,93 +138,143 @@ func (r *ArchiveRepository) FindByID(ctx context.Context, id string) (*domain.Ar
 		return nil, nil, fmt.Errorf("invalid object ID: %v", err)
 	}
 
-	filter := bson.M{
-		"_id": objID,
-		"$or": []bson.M{
-			{"deleted_at": nil},
-			{"expires_at": bson.M{"$gt": time.Now()}},
-		},
-	}
-
-	var results bson.M
-	cursor, err := r.bucket.Find(filter)
+	var result bson.M
+	err = r.bucket.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"_id": objID},
+		options.FindOne().SetProjection(listProjection(nil)),
+	).Decode(&result)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, domain.ErrArchiveNotFound
+		}
 		return nil, nil, err
 	}
-	defer cursor.Close(ctx)
 
-	if !cursor.Next(ctx) {
+	archive := mapToArchive(result)
+
+	// Check if file is deleted
+	if archive.DeletedAt != nil {
 		return nil, nil, domain.ErrArchiveNotFound
 	}
 
-	if err := cursor.Decode(&results); err != nil {
+	// Check if file has expired
+	if archive.Expired(time.Now()) {
+		return nil, nil, domain.ErrAlreadyExpire
+	}
+
+	// Download file content, dari cold store bila arsip sudah dipindah
+	content, err := r.readContent(ctx, &archive)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	// Convert primitive.DateTime to time.Time
-	uploadDate := time.Unix(int64(results["uploadDate"].(primitive.DateTime))/1000, 0)
+	return &archive, content, nil
+}
 
-	// Check if file is deleted
-	var deletedAt *time.Time
-	if val, ok := results["deleted_at"].(primitive.DateTime); ok {
-		t := val.Time()
-		deletedAt = &t
+// FindMetadata mengambil metadata arsip aktif tanpa mengunduh kontennya
+func (r *ArchiveRepository) FindMetadata(ctx context.Context, id string) (*domain.Archive, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
 	}
 
-	if deletedAt != nil {
-		return nil, nil, domain.ErrArchiveNotFound
+	var result bson.M
+	err = r.bucket.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"_id": objID, "metadata.deleted_at": nil},
+		options.FindOne().SetProjection(listProjection(nil)),
+	).Decode(&result)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrArchiveNotFound
+		}
+		return nil, fmt.Errorf("failed to find document: %v", err)
 	}
 
-	// Check if file has expired
-	var expiresAt *time.Time
-	if val, ok := results["expires_at"].(primitive.DateTime); ok {
-		t := val.Time()
-		expiresAt = &t
+	archive := mapToArchive(result)
+	return &archive, nil
+}
+
+// UpdateMetadata mengubah metadata di tempat sebagai revisi metadata, tanpa menaikkan versi konten
+func (r *ArchiveRepository) UpdateMetadata(ctx context.Context, id string, patch domain.ArchivePatch, userID string, cond domain.Precondition) (*domain.Archive, error) {
+	current, err := r.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWrite(current, userID, cond); err != nil {
+		return nil, err
 	}
 
-	if expiresAt != nil && expiresAt.Before(time.Now()) {
-		return nil, nil, domain.ErrAlreadyExpire
+	updated := *current
+	patch.Apply(&updated)
+
+	changeLog := CreateChangeLog(domain.ActionUpdateMetadata, userID, current, &updated)
+	if len(changeLog.Changes) == 0 {
+		return current, nil
 	}
 
-	// Handle potentially nil is_temp field
-	isTemp := false // default value
-	if tempVal, ok := results["is_temp"]; ok && tempVal != nil {
-		if boolVal, ok := tempVal.(bool); ok {
-			isTemp = boolVal
+	// Nama dipakai untuk versioning. Nama baru di-claim seperti pada upload, jadi upload
+	// bersamaan dengan nama yang sama tidak bisa membuat arsip kembar. Arsip lain yang
+	// memakai nama itu, termasuk draft user lain, ditolak dengan error yang sama seperti upload.
+	if updated.Name != current.Name {
+		release, err := r.claimName(ctx, updated.Name)
+		if err != nil {
+			return nil, err
 		}
-	}
+		defer release()
 
-	archive := &domain.Archive{
-		ID:        objID,
-		Name:      results["filename"].(string),
-		Size:      results["length"].(int64),
-		CreatedAt: uploadDate,
-		DeletedAt: deletedAt,
-		ExpiresAt: expiresAt,
-		IsTemp:    isTemp,
+		existing, err := r.FindExistingArchive(ctx, domain.Archive{Name: updated.Name})
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != current.ID {
+			return nil, domain.ErrNameConflict
+		}
 	}
-	archive.FormatSize()
 
-	// Download file content
-	var buf bytes.Buffer
-	_, err = r.bucket.DownloadToStream(objID, &buf)
+	updated.UpdatedAt = changeLog.Timestamp
+	updated.MetadataRevision = current.MetadataRevision + 1
+
+	// Update hanya berhasil bila versi dan revisi belum berubah sejak dibaca
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{
+			"_id":                        current.ID,
+			"metadata.deleted_at":        nil,
+			"metadata.version":           current.Version,
+			"metadata.metadata_revision": revisionMatch(current.MetadataRevision),
+			"$and":                       []bson.M{writableBy(userID, updated.UpdatedAt)},
+		},
+		bson.M{
+			"$set": bson.M{
+				"filename":             updated.Name,
+				"metadata.filename":    updated.Name,
+				"metadata.category":    updated.Category,
+				"metadata.type":        updated.Type,
+				"metadata.tags":        updated.Tags,
+				"metadata.description": updated.Description,
+				"metadata.event_date":  updated.EventDate,
+				"metadata.updated_at":  updated.UpdatedAt,
+			},
+			"$inc":  bson.M{"metadata.metadata_revision": 1},
+			"$push": bson.M{"metadata.change_logs": changeLog},
+		},
+	)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to download file: %v", err)
+		return nil, fmt.Errorf("failed to update metadata: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, r.writeConflict(ctx, current.ID, userID, cond)
 	}
 
-	return archive, buf.Bytes(), nil
+	return &updated, nil
 }
 
-func (r *ArchiveRepository) FindAll(ctx context.Context, page, limit int) ([]domain.Archive, int64, error) {
-	filter := bson.M{
-		"deleted_at": nil,
-		"$or": []bson.M{
-			{"expires_at": nil},
-			{"expires_at": bson.M{"$gt": time.Now()}},
-		},
-	}
+func (r *ArchiveRepository) FindAll(ctx context.Context, criteria domain.ArchiveFilter, fields []string, page, limit int) ([]domain.Archive, int64, error) {
+	filter := buildArchiveFilter(criteria)
 
-	// Hitung total dokumen yang tidak terhapus
+	// Hitung total dokumen sesuai filter
 	total, err := r.bucket.GetFilesCollection().CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
@@ -177,7 +283,8 @@ func (r *ArchiveRepository) FindAll(ctx context.Context, page, limit int) ([]dom
 	opts := options.Find().
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
+		SetSort(bson.D{{Key: "metadata.created_at", Value: -1}}).
+		SetProjection(listProjection(fields))
 
 	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter, opts)
 	if err != nil {
@@ -197,6 +304,142 @@ func (r *ArchiveRepository) FindAll(ctx context.Context, page, limit int) ([]dom
 	return archives, total, nil
 }
 
+func (r *ArchiveRepository) Count(ctx context.Context, criteria domain.ArchiveFilter) (int64, error) {
+	return r.bucket.GetFilesCollection().CountDocuments(ctx, buildArchiveFilter(criteria))
+}
+
+// buildArchiveFilter menerjemahkan filter listing menjadi query MongoDB
+func buildArchiveFilter(criteria domain.ArchiveFilter) bson.M {
+	criteria = criteria.Resolve(time.Now())
+
+	filter := bson.M{}
+	switch criteria.Deleted {
+	case domain.DeletedOnly:
+		filter["metadata.deleted_at"] = bson.M{"$ne": nil}
+	case domain.DeletedIncluded:
+	default:
+		filter["metadata.deleted_at"] = nil
+	}
+
+	// Arsip yang sudah kedaluwarsa tidak pernah ditampilkan. Arsip dengan jadwal
+	// retensi tetap tampil sampai disposition dijalankan, begitu juga arsip dalam legal hold.
+	filter["$or"] = []bson.M{
+		{"metadata.expires_at": nil},
+		{"metadata.expires_at": bson.M{"$gt": time.Now()}},
+		{"metadata.retention": bson.M{"$ne": nil}},
+		{heldField: bson.M{"$exists": true}},
+	}
+
+	if criteria.Category != "" {
+		filter["metadata.category"] = categoryMatch(criteria.Category, criteria.IncludeDescendants)
+	}
+	if criteria.Type != "" {
+		filter["metadata.type"] = criteria.Type
+	}
+	if len(criteria.Tags) > 0 {
+		filter["metadata.tags"] = bson.M{"$all": criteria.Tags}
+	}
+	if criteria.OwnerID != "" {
+		filter["metadata.owner_id"] = criteria.OwnerID
+	}
+	if criteria.HideSuperseded {
+		filter["metadata.superseded_by"] = nil
+	}
+	switch criteria.FolderID {
+	case "":
+	case domain.RootFolderID:
+		filter["metadata.folder_id"] = nil
+	default:
+		filter["metadata.folder_id"] = criteria.FolderID
+	}
+	if criteria.LegalHold != "" {
+		filter["metadata.legal_holds"] = criteria.LegalHold
+	}
+	if criteria.Scheduled {
+		filter["metadata.is_temp"] = true
+	}
+	if criteria.State != "" {
+		filter["metadata.state"] = criteria.State
+	}
+	switch criteria.Tier {
+	case domain.TierCold:
+		filter["metadata.tier"] = domain.TierCold
+	case domain.TierHot:
+		filter["metadata.tier"] = bson.M{"$ne": domain.TierCold}
+	}
+	and := []bson.M{visibleTo(criteria.Viewer)}
+	if criteria.Query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(criteria.Query), Options: "i"}
+		and = append(and, bson.M{
+			"$or": []bson.M{
+				{"filename": pattern},
+				{"metadata.description": pattern},
+			},
+		})
+	}
+	filter["$and"] = and
+	if criteria.CreatedFrom != nil || criteria.CreatedTo != nil {
+		createdAt := bson.M{}
+		if criteria.CreatedFrom != nil {
+			createdAt["$gte"] = *criteria.CreatedFrom
+		}
+		if criteria.CreatedTo != nil {
+			createdAt["$lt"] = *criteria.CreatedTo
+		}
+		filter["metadata.created_at"] = createdAt
+	}
+
+	return filter
+}
+
+// fieldPaths memetakan nama atribut pada response ke path dokumen GridFS
+var fieldPaths = map[string]string{
+	"id":                "_id",
+	"name":              "filename",
+	"size":              "length",
+	"size_mb":           "length",
+	"category":          "metadata.category",
+	"type":              "metadata.type",
+	"tags":              "metadata.tags",
+	"description":       "metadata.description",
+	"owner_id":          "metadata.owner_id",
+	"version":           "metadata.version",
+	"metadata_revision": "metadata.metadata_revision",
+	"created_at":        "metadata.created_at",
+	"updated_at":        "metadata.updated_at",
+	"deleted_at":        "metadata.deleted_at",
+	"deleted_by":        "metadata.deleted_by",
+	"expires_at":        "metadata.expires_at",
+	"is_temp":           "metadata.is_temp",
+	"change_logs":       "metadata.change_logs",
+	"superseded_by":     "metadata.superseded_by",
+	"folder_id":         "metadata.folder_id",
+	"event_date":        "metadata.event_date",
+	"retention":         "metadata.retention",
+	"legal_holds":       "metadata.legal_holds",
+	"state":             "metadata.state",
+	"reviewers":         "metadata.reviewers",
+	"tier":              "metadata.tier",
+	"tiered_at":         "metadata.tiered_at",
+	"last_accessed_at":  "metadata.last_accessed_at",
+}
+
+// listProjection membatasi field yang diambil dari MongoDB. Tanpa fields,
+// semua atribut diambil kecuali change_logs yang bisa sangat besar.
+func listProjection(fields []string) bson.M {
+	if len(fields) == 0 {
+		return bson.M{"metadata.change_logs": 0}
+	}
+
+	projection := bson.M{"_id": 1}
+	for _, field := range fields {
+		if path, ok := fieldPaths[field]; ok {
+			projection[path] = 1
+		}
+	}
+	return projection
+}
+
 func (r *ArchiveRepository) DownloadFile(id primitive.ObjectID) (int64, []byte, error) {
 	ctx := context.TODO()                        // Define ctx if not already defined
 	archive, _, err := r.FindByID(ctx, id.Hex()) // Convert id to string using Hex()
@@ -205,7 +448,7 @@ func (r *ArchiveRepository) DownloadFile(id primitive.ObjectID) (int64, []byte,
 	}
 
 	// File sudah expired (temp delete)
-	if archive.ExpiresAt != nil && archive.ExpiresAt.Before(time.Now()) {
+	if archive.Expired(time.Now()) {
 		return 0, nil, domain.ErrAlreadyExpire
 	}
 
@@ -214,13 +457,12 @@ func (r *ArchiveRepository) DownloadFile(id primitive.ObjectID) (int64, []byte,
 		return 0, nil, domain.ErrAlreadyDeleted
 	}
 
-	var buf bytes.Buffer
-	_, err = r.bucket.DownloadToStream(archive.ID, &buf)
+	content, err := r.readContent(ctx, archive)
 	if err != nil {
 		return 0, nil, err
 	}
 
-	return 0, buf.Bytes(), nil
+	return 0, content, nil
 }
 
 // Tambahkan implementasi repository
@@ -246,13 +488,7 @@ func (r *ArchiveRepository) FindByIDs(ctx context.Context, ids []string) ([]doma
 	// Find files with projection to include necessary fields
 	opts := options.Find().
 		SetSort(bson.D{{Key: "uploadDate", Value: -1}}).
-		SetProjection(bson.D{
-			{Key: "_id", Value: 1},
-			{Key: "filename", Value: 1},
-			{Key: "length", Value: 1},
-			{Key: "uploadDate", Value: 1},
-			{Key: "metadata", Value: 1},
-		})
+		SetProjection(listProjection(nil))
 
 	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter, opts)
 	if err != nil {
@@ -291,7 +527,7 @@ func (r *ArchiveRepository) FindByIDs(ctx context.Context, ids []string) ([]doma
 	return archives, nil
 }
 
-func (r *ArchiveRepository) Delete(ctx context.Context, id string, deleteType domain.DeleteType) error {
+func (r *ArchiveRepository) Delete(ctx context.Context, id string, deleteType domain.DeleteType, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
@@ -299,114 +535,250 @@ func (r *ArchiveRepository) Delete(ctx context.Context, id string, deleteType do
 
 	switch deleteType {
 	case domain.SoftDelete:
-		return r.softDelete(ctx, objID)
+		return r.softDelete(ctx, objID, userID)
 	case domain.HardDelete:
-		return r.hardDelete(ctx, objID)
+		return r.hardDelete(ctx, objID, userID)
 	case domain.TempDelete:
-		return r.tempDelete(ctx, objID)
+		return r.tempDelete(ctx, objID, userID)
 	default:
 		return domain.ErrDeleteNotAllowed
 	}
 }
 
-func (r *ArchiveRepository) softDelete(ctx context.Context, id primitive.ObjectID) error {
+func (r *ArchiveRepository) softDelete(ctx context.Context, id primitive.ObjectID, userID string) error {
+	current, err := r.findDocument(ctx, bson.M{"_id": id, "metadata.deleted_at": nil})
+	if err != nil {
+		if errors.Is(err, domain.ErrArchiveNotFound) {
+			return domain.ErrAlreadyDeleted
+		}
+		return err
+	}
+	if current.OnHold() {
+		return domain.ErrUnderLegalHold
+	}
+
 	now := time.Now()
-	_, err := r.bucket.GetFilesCollection().UpdateOne(
+	updated := *current
+	updated.DeletedAt = &now
+	updated.DeletedBy = userID
+
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
 		ctx,
-		bson.M{"_id": id},
-		bson.M{"$set": bson.M{"deleted_at": now}},
+		bson.M{"_id": id, "metadata.deleted_at": nil},
+		withChangeLog(bson.M{"$set": bson.M{
+			"metadata.deleted_at": now,
+			"metadata.deleted_by": userID,
+			"metadata.updated_at": now,
+		}}, CreateChangeLog(domain.ActionDelete, userID, current, &updated)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrAlreadyDeleted
+	}
+	return nil
 }
 
-func (r *ArchiveRepository) hardDelete(_ context.Context, id primitive.ObjectID) error {
-	return r.bucket.Delete(id)
+func (r *ArchiveRepository) hardDelete(ctx context.Context, id primitive.ObjectID, userID string) error {
+	return r.removeFile(ctx, id, domain.ActionDelete, userID, nil)
 }
 
-func (r *ArchiveRepository) tempDelete(ctx context.Context, id primitive.ObjectID) error {
-	expiresAt := time.Now().Add(24 * time.Hour)
-	_, err := r.bucket.GetFilesCollection().UpdateOne(
-		ctx,
-		bson.M{"_id": id},
-		bson.M{"$set": bson.M{
-			"is_temp":    true,
-			"expires_at": expiresAt,
-		}},
-	)
+// tempDelete menjadwalkan penghapusan dengan masa tenggang default
+func (r *ArchiveRepository) tempDelete(ctx context.Context, id primitive.ObjectID, userID string) error {
+	_, err := r.scheduleDeletion(ctx, id, time.Now().Add(defaultTempDeleteTTL), userID)
 	return err
 }
 
-func (r *ArchiveRepository) RestoreArchive(ctx context.Context, id string) error {
+// RestoreArchive memulihkan arsip dari trash. Nama arsip di-claim selama restore agar tidak
+// bentrok dengan upload yang berjalan bersamaan. Bila nama sudah dipakai arsip aktif lain,
+// strategy menentukan apakah restore ditolak, memakai nama baru, atau digabung sebagai versi baru.
+func (r *ArchiveRepository) RestoreArchive(ctx context.Context, id, userID string, strategy domain.RestoreStrategy) (*domain.RestoreResult, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("invalid object ID: %v", err)
+		return nil, fmt.Errorf("invalid object ID: %v", err)
 	}
 
-	// Find the file first with proper filter
-	filter := bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": true, "$ne": nil},
+	current, err := r.findDocument(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return nil, err
+	}
+	if current.DeletedAt == nil {
+		return nil, domain.ErrNotDeleted
 	}
 
-	var result bson.M
-	err = r.bucket.GetFilesCollection().FindOne(ctx, filter).Decode(&result)
+	release, err := r.claimName(ctx, current.Name)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return domain.ErrNotDeleted
-		}
-		return fmt.Errorf("failed to find document: %v", err)
+		return nil, err
 	}
+	defer release()
 
-	now := time.Now()
-	changeLog := domain.ChangeLog{
-		Timestamp: now,
-		Action:    "restore",
-		UserID:    result["metadata"].(bson.M)["owner_id"].(string),
-		Changes: []domain.Change{
-			{
-				Field:    "status",
-				OldValue: "deleted",
-				NewValue: "active",
-			},
-		},
+	live, err := r.FindExistingArchive(ctx, domain.Archive{Name: current.Name})
+	if err != nil {
+		return nil, err
+	}
+	if live == nil {
+		restored, err := r.undelete(ctx, current, current.Name, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.RestoreResult{Archive: restored}, nil
 	}
 
-	// Get existing change logs and append new one
-	var changeLogs []domain.ChangeLog
-	if logs, ok := result["metadata"].(bson.M)["change_logs"].(primitive.A); ok {
-		for _, l := range logs {
-			if log, ok := l.(bson.M); ok {
-				changeLogs = append(changeLogs, domain.ChangeLog{
-					Timestamp: log["timestamp"].(primitive.DateTime).Time(),
-					Action:    log["action"].(string),
-					UserID:    log["user_id"].(string),
-				})
-			}
+	result := &domain.RestoreResult{Strategy: strategy, ConflictID: live.ID.Hex()}
+	switch strategy {
+	case domain.RestoreRename:
+		name, releaseName, err := r.claimRestoredName(ctx, current.Name)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseName()
+
+		if result.Archive, err = r.undelete(ctx, current, name, userID); err != nil {
+			return nil, err
 		}
+		return result, nil
+	case domain.RestoreMerge:
+		if result.Archive, err = r.mergeInto(ctx, current, live, userID); err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
+		result.Strategy = domain.RestoreFail
+		return result, domain.ErrRestoreConflict
 	}
-	changeLogs = append(changeLogs, changeLog)
+}
 
-	// Update document - remove deleted_at field
-	update := bson.M{
+// undelete menghapus tanda hapus arsip, sekaligus mengganti namanya bila name berbeda
+func (r *ArchiveRepository) undelete(ctx context.Context, current *domain.Archive, name, userID string) (*domain.Archive, error) {
+	updated := *current
+	updated.Name = name
+	updated.DeletedAt = nil
+	updated.DeletedBy = ""
+	changeLog := CreateChangeLog(domain.ActionRestore, userID, current, &updated)
+	updated.UpdatedAt = changeLog.Timestamp
+
+	filter := bson.M{
+		"_id":                 current.ID,
+		"metadata.deleted_at": bson.M{"$exists": true, "$ne": nil},
+	}
+	update := withChangeLog(bson.M{
 		"$set": bson.M{
-			"metadata.change_logs": changeLogs,
-			"metadata.updated_at":  now,
+			"filename":            name,
+			"metadata.filename":   name,
+			"metadata.updated_at": updated.UpdatedAt,
 		},
 		"$unset": bson.M{
-			"deleted_at": "",
+			"metadata.deleted_at":          "",
+			"metadata.deleted_by":          "",
+			"metadata.deleted_with_folder": "",
 		},
+	}, changeLog)
+
+	result, err := r.bucket.GetFilesCollection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update document: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, domain.ErrNotDeleted
 	}
+	return &updated, nil
+}
 
-	_, err = r.bucket.GetFilesCollection().UpdateOne(
+// maxRestoredNames membatasi percobaan mencari nama bebas untuk restore dengan rename
+const maxRestoredNames = 100
+
+// claimRestoredName mencari dan meng-claim nama yang belum dipakai arsip aktif, misalnya
+// "laporan.pdf" menjadi "laporan (restored).pdf" lalu "laporan (restored 2).pdf"
+func (r *ArchiveRepository) claimRestoredName(ctx context.Context, name string) (string, func(), error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for n := 1; n <= maxRestoredNames; n++ {
+		candidate := fmt.Sprintf("%s (restored)%s", base, ext)
+		if n > 1 {
+			candidate = fmt.Sprintf("%s (restored %d)%s", base, n, ext)
+		}
+
+		release, err := r.claimName(ctx, candidate)
+		if errors.Is(err, domain.ErrVersionConflict) {
+			// Nama ini sedang dipakai upload lain
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		existing, err := r.FindExistingArchive(ctx, domain.Archive{Name: candidate})
+		if err != nil {
+			release()
+			return "", nil, err
+		}
+		if existing == nil {
+			return candidate, release, nil
+		}
+		release()
+	}
+	return "", nil, domain.ErrRestoreConflict
+}
+
+// mergeInto menyimpan konten arsip yang dihapus sebagai versi baru arsip aktif. Metadata
+// arsip aktif dipertahankan; arsip yang dihapus tetap di trash sampai di-purge.
+func (r *ArchiveRepository) mergeInto(ctx context.Context, deleted, live *domain.Archive, userID string) (*domain.Archive, error) {
+	content, err := r.readContent(ctx, deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := domain.Archive{
+		Name:        live.Name,
+		Category:    live.Category,
+		Type:        live.Type,
+		Tags:        live.Tags,
+		Description: live.Description,
+		OwnerID:     userID,
+	}
+	return r.saveVersion(ctx, archive, content, domain.Precondition{})
+}
+
+// findDocument mengambil metadata satu arsip (tanpa change_logs) sebagai nilai "sebelum" untuk change log
+func (r *ArchiveRepository) findDocument(ctx context.Context, filter bson.M) (*domain.Archive, error) {
+	var result bson.M
+	err := r.bucket.GetFilesCollection().FindOne(
 		ctx,
-		bson.M{"_id": objID},
-		update,
-	)
+		filter,
+		options.FindOne().SetProjection(listProjection(nil)),
+	).Decode(&result)
 	if err != nil {
-		return fmt.Errorf("failed to update document: %v", err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrArchiveNotFound
+		}
+		return nil, fmt.Errorf("failed to find document: %v", err)
 	}
 
-	return nil
+	archive := mapToArchive(result)
+	return &archive, nil
+}
+
+// findIDs mengambil _id semua file yang cocok dengan filter
+func (r *ArchiveRepository) findIDs(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cur.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %v", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cur.Err()
 }
 
 func (r *ArchiveRepository) Exists(ctx context.Context, id string) (bool, error) {
@@ -423,83 +795,293 @@ func (r *ArchiveRepository) Exists(ctx context.Context, id string) (bool, error)
 }
 
 func (r *ArchiveRepository) DeleteExpiredTempFiles(ctx context.Context) error {
-	_, err := r.bucket.GetFilesCollection().DeleteMany(
-		ctx,
-		bson.M{
-			"is_temp":    true,
-			"expires_at": bson.M{"$lt": time.Now()},
-		},
-	)
+	_, err := r.removeMatching(ctx, bson.M{
+		"metadata.is_temp":    true,
+		"metadata.expires_at": bson.M{"$lt": time.Now()},
+	}, domain.ActionExpire)
 	return err
 }
 
-func (r *ArchiveRepository) DeleteExpiredFiles(ctx context.Context) (int64, error) {
-	result, err := r.bucket.GetFilesCollection().DeleteMany(ctx, bson.M{
+// expiredFilter memilih arsip yang expires_at-nya sudah lewat
+func expiredFilter(now time.Time) bson.M {
+	return bson.M{
 		"metadata.expires_at": bson.M{
-			"$lt": time.Now(),
+			"$lt": now,
 		},
+		// Arsip dengan jadwal retensi ditangani oleh evaluasi retensi
+		"metadata.retention": nil,
+	}
+}
+
+// trashFilter memilih arsip di trash yang dihapus sebelum batas waktu
+func trashFilter(before time.Time) bson.M {
+	return bson.M{
+		"metadata.deleted_at": bson.M{"$ne": nil, "$lt": before},
+	}
+}
+
+func (r *ArchiveRepository) DeleteExpiredFiles(ctx context.Context) ([]string, error) {
+	removed, err := r.removeMatching(ctx, expiredFilter(time.Now()), domain.ActionExpire)
+	if err != nil && !errors.Is(err, domain.ErrUnderLegalHold) {
+		return removed, fmt.Errorf("failed to delete expired files: %v", err)
+	}
+	return removed, err
+}
+
+// FindTrash menampilkan arsip yang di-soft delete dan boleh dilihat viewer, terbaru lebih dulu
+func (r *ArchiveRepository) FindTrash(ctx context.Context, viewer string, page, limit int) ([]domain.Archive, int64, error) {
+	filter := bson.M{"$and": []bson.M{
+		{"metadata.deleted_at": bson.M{"$ne": nil}},
+		visibleTo(viewer),
+	}}
+
+	total, err := r.bucket.GetFilesCollection().CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count documents: %v", err)
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "metadata.deleted_at", Value: -1}}).
+		SetProjection(listProjection(nil))
+
+	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find documents: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var files []bson.M
+	if err = cur.All(ctx, &files); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode documents: %v", err)
+	}
+
+	var archives []domain.Archive
+	for _, file := range files {
+		archives = append(archives, mapToArchive(file))
+	}
+	return archives, total, nil
+}
+
+// Purge menghapus permanen arsip yang sudah berada di trash, termasuk chunks-nya
+func (r *ArchiveRepository) Purge(ctx context.Context, id, userID string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	count, err := r.bucket.GetFilesCollection().CountDocuments(ctx, bson.M{
+		"_id":                 objID,
+		"metadata.deleted_at": bson.M{"$ne": nil},
 	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete expired files: %v", err)
+		return fmt.Errorf("failed to find document: %v", err)
+	}
+	if count == 0 {
+		exists, err := r.Exists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrArchiveNotFound
+		}
+		return domain.ErrNotDeleted
 	}
-	return result.DeletedCount, nil
+
+	return r.removeFile(ctx, objID, domain.ActionPurge, userID, nil)
 }
 
-func (r *ArchiveRepository) DeleteByFilter(ctx context.Context, filter bson.M) (int64, error) {
-	result, err := r.bucket.GetFilesCollection().DeleteMany(ctx, filter)
+// PurgeDeletedBefore menghapus permanen arsip di trash yang dihapus sebelum batas waktu
+func (r *ArchiveRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	purged, err := r.removeMatching(ctx, trashFilter(before), domain.ActionPurge)
+	if err != nil && !errors.Is(err, domain.ErrUnderLegalHold) {
+		return purged, fmt.Errorf("failed to purge trashed files: %v", err)
+	}
+	return purged, err
+}
+
+func (r *ArchiveRepository) GetSignature(ctx context.Context, id string) ([]uint64, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	var result bson.M
+	err = r.bucket.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"_id": objID},
+		options.FindOne().SetProjection(bson.M{"metadata.minhash": 1}),
+	).Decode(&result)
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete files: %v", err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrArchiveNotFound
+		}
+		return nil, fmt.Errorf("failed to find document: %v", err)
 	}
-	return result.DeletedCount, nil
+
+	metadata, _ := result["metadata"].(bson.M)
+	return signatureFromBSON(metadata["minhash"]), nil
+}
+
+func (r *ArchiveRepository) SaveSignature(ctx context.Context, id string, signature []uint64) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	_, err = r.bucket.GetFilesCollection().UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+		"$set": bson.M{
+			"metadata.minhash":   signatureToBSON(signature),
+			"metadata.lsh_bands": lshBands(signature),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save signature: %v", err)
+	}
+	return nil
+}
+
+// FindSimilar mencari kandidat lewat band LSH lalu menghitung skor dari signature lengkap
+func (r *ArchiveRepository) FindSimilar(ctx context.Context, signature []uint64, excludeID string, threshold float64, limit int) ([]domain.SimilarArchive, error) {
+	bands := lshBands(signature)
+	if len(bands) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{
+		"metadata.lsh_bands":  bson.M{"$in": bands},
+		"metadata.deleted_at": nil,
+	}
+	if objID, err := primitive.ObjectIDFromHex(excludeID); err == nil {
+		filter["_id"] = bson.M{"$ne": objID}
+	}
+
+	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter,
+		options.Find().SetProjection(bson.M{"metadata.change_logs": 0, "metadata.lsh_bands": 0}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar documents: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var similar []domain.SimilarArchive
+	for cur.Next(ctx) {
+		var file bson.M
+		if err := cur.Decode(&file); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %v", err)
+		}
+
+		metadata, _ := file["metadata"].(bson.M)
+		score := signatureSimilarity(signature, signatureFromBSON(metadata["minhash"]))
+		if score < threshold {
+			continue
+		}
+		similar = append(similar, domain.SimilarArchive{Archive: mapToArchive(file), Score: score})
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(similar, func(i, j int) bool {
+		return similar[i].Score > similar[j].Score
+	})
+	if limit > 0 && len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// FindExpiredFiles mengembalikan arsip yang akan dihapus oleh DeleteExpiredFiles
+func (r *ArchiveRepository) FindExpiredFiles(ctx context.Context, now time.Time, limit int) ([]domain.Archive, error) {
+	return r.FindByFilter(ctx, expiredFilter(now), limit)
+}
+
+// FindDeletedBefore mengembalikan arsip yang akan dihapus oleh PurgeDeletedBefore
+func (r *ArchiveRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]domain.Archive, error) {
+	return r.FindByFilter(ctx, trashFilter(before), limit)
+}
+
+// FindByFilter mengembalikan arsip yang akan dihapus oleh DeleteByFilter dengan filter yang sama
+func (r *ArchiveRepository) FindByFilter(ctx context.Context, filter bson.M, limit int) ([]domain.Archive, error) {
+	return r.findArchives(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(listProjection(nil)))
+}
+
+func (r *ArchiveRepository) DeleteByFilter(ctx context.Context, filter bson.M) ([]string, error) {
+	removed, err := r.removeMatching(ctx, filter, domain.ActionCleanup)
+	if err != nil && !errors.Is(err, domain.ErrUnderLegalHold) {
+		return removed, fmt.Errorf("failed to delete files: %v", err)
+	}
+	return removed, err
 }
 
 func mapToArchive(file bson.M) domain.Archive {
+	archive := domain.Archive{
+		Name: stringValue(file["filename"]),
+		Size: int64Value(file["length"]),
+	}
+	if id, ok := file["_id"].(primitive.ObjectID); ok {
+		archive.ID = id
+	}
+
 	metadata, ok := file["metadata"].(bson.M)
 	if !ok {
-		// Return empty archive with basic fields if no metadata
-		return domain.Archive{
-			ID:        file["_id"].(primitive.ObjectID),
-			Name:      file["filename"].(string),
-			Size:      file["length"].(int64),
-			CreatedAt: file["uploadDate"].(primitive.DateTime).Time(),
+		// Return archive with basic fields if no metadata
+		archive.CreatedAt = timeValue(file["uploadDate"])
+		archive.FormatSize()
+		return archive
+	}
+
+	// Extract metadata fields. Field bisa tidak ada karena projection,
+	// jadi semua konversi dilakukan tanpa type assertion langsung.
+	archive.Category = stringValue(metadata["category"])
+	archive.Type = stringValue(metadata["type"])
+	archive.Description = stringValue(metadata["description"])
+	archive.OwnerID = stringValue(metadata["owner_id"])
+	archive.Version = int(int64Value(metadata["version"]))
+	archive.MetadataRevision = int(int64Value(metadata["metadata_revision"]))
+	archive.CreatedAt = timeValue(metadata["created_at"])
+	archive.UpdatedAt = timeValue(metadata["updated_at"])
+	archive.DeletedAt = timePointer(metadata["deleted_at"])
+	archive.ExpiresAt = timePointer(metadata["expires_at"])
+	archive.DeletedBy = stringValue(metadata["deleted_by"])
+	archive.IsTemp = boolValue(metadata["is_temp"])
+	archive.Lock = decodeLock(metadata["lock"])
+	archive.SupersededBy = stringValue(metadata["superseded_by"])
+	archive.FolderID = stringValue(metadata["folder_id"])
+	archive.EventDate = timePointer(metadata["event_date"])
+	archive.Retention = decodeRetention(metadata["retention"])
+	if holds, ok := metadata["legal_holds"].(primitive.A); ok {
+		for _, hold := range holds {
+			archive.LegalHolds = append(archive.LegalHolds, stringValue(hold))
 		}
 	}
-
-	// Extract metadata fields
-	archive := domain.Archive{
-		ID:          file["_id"].(primitive.ObjectID),
-		Name:        file["filename"].(string),
-		Size:        file["length"].(int64),
-		Category:    metadata["category"].(string),
-		Type:        metadata["type"].(string),
-		Description: metadata["description"].(string),
-		OwnerID:     metadata["owner_id"].(string),
-		Version:     int(metadata["version"].(int32)),
-		CreatedAt:   metadata["created_at"].(primitive.DateTime).Time(),
-		UpdatedAt:   metadata["updated_at"].(primitive.DateTime).Time(),
-		IsTemp:      metadata["is_temp"].(bool),
+	archive.State = domain.LifecycleState(stringValue(metadata["state"]))
+	if reviewers, ok := metadata["reviewers"].(primitive.A); ok {
+		for _, reviewer := range reviewers {
+			archive.Reviewers = append(archive.Reviewers, stringValue(reviewer))
+		}
+	}
+	archive.Tier = domain.StorageTier(stringValue(metadata["tier"]))
+	archive.TieredAt = timePointer(metadata["tiered_at"])
+	archive.LastAccessedAt = timePointer(metadata["last_accessed_at"])
+	if contentID, ok := metadata["content_id"].(primitive.ObjectID); ok {
+		archive.ContentID = contentID
 	}
 
 	// Handle optional arrays
 	if tags, ok := metadata["tags"].(primitive.A); ok {
-		archive.Tags = make([]string, len(tags))
-		for i, tag := range tags {
-			archive.Tags[i] = tag.(string)
+		archive.Tags = make([]string, 0, len(tags))
+		for _, tag := range tags {
+			archive.Tags = append(archive.Tags, stringValue(tag))
 		}
 	}
 
 	// Handle optional ChangeLogs
 	if changeLogs, ok := metadata["change_logs"].(primitive.A); ok {
-		archive.ChangeLogs = make([]domain.ChangeLog, len(changeLogs))
-		for i, cl := range changeLogs {
-			if logMap, ok := cl.(bson.M); ok {
-				archive.ChangeLogs[i] = domain.ChangeLog{
-					Timestamp: logMap["timestamp"].(primitive.DateTime).Time(),
-					Action:    logMap["action"].(string),
-					UserID:    logMap["user_id"].(string),
-				}
-			}
-		}
+		archive.ChangeLogs = decodeChangeLogs(changeLogs)
 	}
 
 	archive.FormatSize()
@@ -540,108 +1122,87 @@ func (r *ArchiveRepository) FindExistingArchive(ctx context.Context, archive dom
 	return latest, nil
 }
 
-func (r *ArchiveRepository) SaveWithVersioning(ctx context.Context, archive domain.Archive, content []byte) (*domain.Archive, error) {
+func (r *ArchiveRepository) SaveWithVersioning(ctx context.Context, archive domain.Archive, content []byte, cond domain.Precondition) (*domain.Archive, error) {
+	release, err := r.claimName(ctx, archive.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return r.saveVersion(ctx, archive, content, cond)
+}
+
+// saveVersion menulis versi baru untuk nama arsip, atau arsip baru bila nama belum dipakai.
+// Pemanggil harus sudah meng-claim nama arsip.
+func (r *ArchiveRepository) saveVersion(ctx context.Context, archive domain.Archive, content []byte, cond domain.Precondition) (*domain.Archive, error) {
 	existing, err := r.FindExistingArchive(ctx, archive)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkWrite(existing, archive.OwnerID, cond); err != nil {
+		return nil, err
+	}
+	if existing != nil && !existing.VisibleTo(archive.OwnerID) {
+		return nil, domain.ErrNameConflict
+	}
+	if existing != nil && existing.Frozen() {
+		return nil, domain.ErrContentFrozen
+	}
 
 	now := time.Now()
 	archive.UpdatedAt = now
+	archive.Size = int64(len(content))
 
 	if existing != nil {
 		// Always increment version for new uploads
 		archive.ID = existing.ID
 		archive.Version = existing.Version + 1
-		archive.CreatedAt = existing.CreatedAt
+		// Field yang dibawa dari versi sebelumnya hanya dipakai untuk change log;
+		// replaceVersion tidak menulisnya ulang. Pengunggah versi baru tidak menjadi pemilik.
+		uploader := archive.OwnerID
+		carryMetadata(&archive, existing)
 
 		// Track changes
-		changeLog := domain.ChangeLog{
-			Timestamp: now,
-			Action:    "update",
-			UserID:    archive.OwnerID,
-			Changes: []domain.Change{
-				{
-					Field:    "version",
-					OldValue: existing.Version,
-					NewValue: archive.Version,
-				},
-			},
-		}
-
-		// Add metadata changes to changelog
-		if existing.Category != archive.Category {
-			changeLog.Changes = append(changeLog.Changes, domain.Change{
-				Field:    "category",
-				OldValue: existing.Category,
-				NewValue: archive.Category,
-			})
-		}
-		if existing.Description != archive.Description {
-			changeLog.Changes = append(changeLog.Changes, domain.Change{
-				Field:    "description",
-				OldValue: existing.Description,
-				NewValue: archive.Description,
-			})
-		}
-		if !reflect.DeepEqual(existing.Tags, archive.Tags) {
-			changeLog.Changes = append(changeLog.Changes, domain.Change{
-				Field:    "tags",
-				OldValue: fmt.Sprintf("%v", existing.Tags),
-				NewValue: fmt.Sprintf("%v", archive.Tags),
-			})
-		}
-
-		archive.ChangeLogs = append(existing.ChangeLogs, changeLog)
-
-		// Delete old version
-		if err := r.bucket.Delete(existing.ID); err != nil {
-			return nil, fmt.Errorf("failed to delete old version: %v", err)
-		}
-	} else {
-		// Create new file
-		archive.ID = primitive.NewObjectID()
-		archive.Version = 1
-		archive.CreatedAt = now
-		archive.ChangeLogs = []domain.ChangeLog{
-			{
-				Timestamp: now,
-				Action:    "upload",
-				UserID:    archive.OwnerID,
-				Changes:   []domain.Change{},
-			},
-		}
+		changeLog := CreateChangeLog(domain.ActionUpdate, uploader, existing, &archive)
+		return r.replaceVersion(ctx, archive, changeLog, content, cond)
 	}
 
-	// Upload file with all metadata
-	metadata := bson.D{
-		{Key: "filename", Value: archive.Name},
-		{Key: "category", Value: archive.Category},
-		{Key: "type", Value: archive.Type},
-		{Key: "tags", Value: archive.Tags},
-		{Key: "description", Value: archive.Description},
-		{Key: "owner_id", Value: archive.OwnerID},
-		{Key: "version", Value: archive.Version},
-		{Key: "created_at", Value: archive.CreatedAt},
-		{Key: "updated_at", Value: now},
-		{Key: "is_temp", Value: archive.IsTemp},
-		{Key: "change_logs", Value: archive.ChangeLogs},
+	// Create new file
+	archive.ID = primitive.NewObjectID()
+	archive.Version = 1
+	archive.CreatedAt = now
+	if archive.State == "" {
+		archive.State = domain.StateDraft
 	}
+	archive.ChangeLogs = []domain.ChangeLog{
+		CreateChangeLog(domain.ActionUpload, archive.OwnerID, nil, &archive),
+	}
+	return r.writeFile(ctx, archive, archive.ChangeLogs, content)
+}
 
-	uploadOpts := options.GridFSUpload().SetMetadata(metadata)
-	uploadStream, err := r.bucket.OpenUploadStream(
+// writeFile mengunggah konten beserta seluruh metadata dengan ID arsip yang sudah ditentukan
+func (r *ArchiveRepository) writeFile(_ context.Context, archive domain.Archive, changeLogs interface{}, content []byte) (*domain.Archive, error) {
+	// Upload file with all metadata
+	uploadOpts := options.GridFSUpload().SetMetadata(fileMetadata(archive, changeLogs))
+	// ID dipertahankan antar versi supaya referensi ke arsip tetap valid
+	uploadStream, err := r.bucket.OpenUploadStreamWithID(
+		archive.ID,
 		archive.Name,
 		uploadOpts,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open upload stream: %v", err)
 	}
-	defer uploadStream.Close()
 
 	size, err := uploadStream.Write(content)
 	if err != nil {
+		uploadStream.Abort()
 		return nil, fmt.Errorf("failed to write file content: %v", err)
 	}
+	// Dokumen files baru ditulis saat Close, jadi errornya berarti upload gagal
+	if err := uploadStream.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish upload: %v", err)
+	}
 
 	archive.Size = int64(size)
 	archive.FormatSize()
@@ -649,61 +1210,105 @@ func (r *ArchiveRepository) SaveWithVersioning(ctx context.Context, archive doma
 	return &archive, nil
 }
 
+// fileMetadata menyusun metadata GridFS arsip; field opsional yang kosong tidak ditulis
+func fileMetadata(archive domain.Archive, changeLogs interface{}) bson.D {
+	metadata := bson.D{
+		{Key: "filename", Value: archive.Name},
+		{Key: "category", Value: archive.Category},
+		{Key: "type", Value: archive.Type},
+		{Key: "tags", Value: archive.Tags},
+		{Key: "description", Value: archive.Description},
+		{Key: "owner_id", Value: archive.OwnerID},
+		{Key: "version", Value: archive.Version},
+		{Key: "metadata_revision", Value: archive.MetadataRevision},
+		{Key: "created_at", Value: archive.CreatedAt},
+		{Key: "updated_at", Value: archive.UpdatedAt},
+		{Key: "is_temp", Value: archive.IsTemp},
+		{Key: "change_logs", Value: changeLogs},
+	}
+	if archive.Lock != nil {
+		metadata = append(metadata, bson.E{Key: "lock", Value: archive.Lock})
+	}
+	if archive.SupersededBy != "" {
+		metadata = append(metadata, bson.E{Key: "superseded_by", Value: archive.SupersededBy})
+	}
+	if archive.FolderID != "" {
+		metadata = append(metadata, bson.E{Key: "folder_id", Value: archive.FolderID})
+	}
+	if archive.EventDate != nil {
+		metadata = append(metadata, bson.E{Key: "event_date", Value: archive.EventDate})
+	}
+	if len(archive.LegalHolds) > 0 {
+		metadata = append(metadata, bson.E{Key: "legal_holds", Value: archive.LegalHolds})
+	}
+	if archive.State != "" {
+		metadata = append(metadata, bson.E{Key: "state", Value: archive.State})
+	}
+	if len(archive.Reviewers) > 0 {
+		metadata = append(metadata, bson.E{Key: "reviewers", Value: archive.Reviewers})
+	}
+	if archive.Retention != nil {
+		metadata = append(metadata, bson.E{Key: "retention", Value: archive.Retention})
+		if archive.ExpiresAt != nil {
+			metadata = append(metadata, bson.E{Key: "expires_at", Value: archive.ExpiresAt})
+		}
+	}
+	if len(archive.Signature) > 0 {
+		metadata = append(metadata,
+			bson.E{Key: "minhash", Value: signatureToBSON(archive.Signature)},
+			bson.E{Key: "lsh_bands", Value: lshBands(archive.Signature)},
+		)
+	}
+	return metadata
+}
+
 func (r *ArchiveRepository) GetHistory(ctx context.Context, id string) (*domain.History, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid object ID: %v", err)
 	}
 
-	var result bson.M
+	var (
+		result     bson.M
+		fileName   string
+		changeLogs primitive.A
+	)
 	err = r.bucket.GetFilesCollection().FindOne(ctx, bson.M{"_id": objID}).Decode(&result)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, domain.ErrArchiveNotFound
+	switch {
+	case err == nil:
+		metadata, ok := result["metadata"].(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("invalid metadata format")
 		}
+		fileName = stringValue(result["filename"])
+		changeLogs, _ = metadata["change_logs"].(primitive.A)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		// Arsip yang sudah dihapus permanen masih punya riwayat di tombstone
+		err = r.tombstones.FindOne(ctx, bson.M{"_id": objID}).Decode(&result)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, domain.ErrArchiveNotFound
+			}
+			return nil, fmt.Errorf("failed to find tombstone: %v", err)
+		}
+		fileName = stringValue(result["filename"])
+		changeLogs, _ = result["change_logs"].(primitive.A)
+	default:
 		return nil, fmt.Errorf("failed to find document: %v", err)
 	}
 
-	// Extract metadata
-	metadata, ok := result["metadata"].(bson.M)
-	if !ok {
-		return nil, fmt.Errorf("invalid metadata format")
-	}
-
 	history := domain.History{
 		ID:       id,
-		FileName: result["filename"].(string),
+		FileName: fileName,
 		Logs:     make([]domain.HistoryEntry, 0),
 	}
-
-	// Extract change logs from metadata
-	if changeLogs, exists := metadata["change_logs"]; exists && changeLogs != nil {
-		if logs, ok := changeLogs.(primitive.A); ok {
-			for _, logInterface := range logs {
-				if log, ok := logInterface.(bson.M); ok {
-					entry := domain.HistoryEntry{
-						Timestamp: log["timestamp"].(primitive.DateTime).Time(),
-						Action:    log["action"].(string),
-						User:      log["user_id"].(string),
-					}
-
-					// Handle changes array
-					if changesArray, ok := log["changes"].(primitive.A); ok {
-						for _, changeInterface := range changesArray {
-							if change, ok := changeInterface.(bson.M); ok {
-								entry.Changes = append(entry.Changes, domain.Change{
-									Field:    change["field"].(string),
-									OldValue: change["old_value"],
-									NewValue: change["new_value"],
-								})
-							}
-						}
-					}
-
-					history.Logs = append(history.Logs, entry)
-				}
-			}
-		}
+	for _, log := range decodeChangeLogs(changeLogs) {
+		history.Logs = append(history.Logs, domain.HistoryEntry{
+			Timestamp: log.Timestamp,
+			Action:    log.Action,
+			User:      log.UserID,
+			Changes:   log.Changes,
+		})
 	}
 
 	// Sort logs by timestamp descending
@@ -714,14 +1319,15 @@ func (r *ArchiveRepository) GetHistory(ctx context.Context, id string) (*domain.
 	return &history, nil
 }
 
-func (r *ArchiveRepository) GetByCategory(ctx context.Context, category string, page, limit int) ([]domain.Archive, int64, error) {
+func (r *ArchiveRepository) GetByCategory(ctx context.Context, category string, includeDescendants bool, viewer string, page, limit int) ([]domain.Archive, int64, error) {
 	// Build filter for metadata.category
 	filter := bson.M{
-		"metadata.category": category,
+		"metadata.category": categoryMatch(category, includeDescendants),
 		"$or": []bson.M{
 			{"metadata.deleted_at": nil},
 			{"metadata.deleted_at": bson.M{"$exists": false}},
 		},
+		"$and": []bson.M{visibleTo(viewer)},
 	}
 
 	// Count total documents
@@ -734,7 +1340,8 @@ func (r *ArchiveRepository) GetByCategory(ctx context.Context, category string,
 	opts := options.Find().
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "metadata.updated_at", Value: -1}})
+		SetSort(bson.D{{Key: "metadata.updated_at", Value: -1}}).
+		SetProjection(listProjection(nil))
 
 	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter, opts)
 	if err != nil {
@@ -757,7 +1364,43 @@ func (r *ArchiveRepository) GetByCategory(ctx context.Context, category string,
 	return archives, total, nil
 }
 
-func (r *ArchiveRepository) GetByTags(ctx context.Context, tags []string, page, limit int) ([]domain.Archive, int64, error) {
+// categoryMatch mencocokkan path kategori, termasuk turunannya bila diminta
+func categoryMatch(path string, includeDescendants bool) interface{} {
+	if !includeDescendants {
+		return path
+	}
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(path) + "(" + regexp.QuoteMeta(domain.CategoryPathSeparator) + "|$)"}
+}
+
+// ReassignCategory memindahkan semua arsip dari oldPath ke newPath dan mencatat perubahan di change log
+func (r *ArchiveRepository) ReassignCategory(ctx context.Context, oldPath, newPath, userID string) (int64, error) {
+	// Hanya kategori yang berubah dan nilai lamanya sama untuk semua arsip yang cocok
+	now := time.Now()
+	changeLog := CreateChangeLog(domain.ActionUpdate, userID,
+		&domain.Archive{Category: oldPath},
+		&domain.Archive{Category: newPath})
+
+	result, err := r.bucket.GetFilesCollection().UpdateMany(
+		ctx,
+		bson.M{"metadata.category": oldPath},
+		bson.M{
+			"$set": bson.M{
+				"metadata.category":   newPath,
+				"metadata.updated_at": now,
+			},
+			// Revisi metadata dinaikkan agar PATCH atau If-Match yang dibaca sebelum
+			// relokasi gagal dan tidak menulis kembali path lama
+			"$inc":  bson.M{"metadata.metadata_revision": 1},
+			"$push": bson.M{"metadata.change_logs": changeLog},
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reassign category: %v", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *ArchiveRepository) GetByTags(ctx context.Context, tags []string, viewer string, page, limit int) ([]domain.Archive, int64, error) {
 	// Build filter for metadata.tags
 	filter := bson.M{
 		"metadata.tags": bson.M{"$all": tags},
@@ -765,6 +1408,7 @@ func (r *ArchiveRepository) GetByTags(ctx context.Context, tags []string, page,
 			{"metadata.deleted_at": nil},
 			{"metadata.deleted_at": bson.M{"$exists": false}},
 		},
+		"$and": []bson.M{visibleTo(viewer)},
 	}
 
 	// Count total documents
@@ -777,7 +1421,8 @@ func (r *ArchiveRepository) GetByTags(ctx context.Context, tags []string, page,
 	opts := options.Find().
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "metadata.updated_at", Value: -1}})
+		SetSort(bson.D{{Key: "metadata.updated_at", Value: -1}}).
+		SetProjection(listProjection(nil))
 
 	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter, opts)
 	if err != nil {
@@ -804,76 +1449,47 @@ func (r *ArchiveRepository) CountDocuments(ctx context.Context, filter bson.M) (
 	return r.bucket.GetFilesCollection().CountDocuments(ctx, filter)
 }
 
+// DeleteArchive dipertahankan untuk kompatibilitas, gunakan Delete
 func (r *ArchiveRepository) DeleteArchive(ctx context.Context, id string, permanent bool, userID string) error {
+	if permanent {
+		return r.Delete(ctx, id, domain.HardDelete, userID)
+	}
+	return r.Delete(ctx, id, domain.SoftDelete, userID)
+}
+
+// OnRemove mendaftarkan hook yang dijalankan setelah arsip dihapus permanen. Arsip sudah
+// musnah saat hook berjalan, jadi hook menangani kegagalannya sendiri, misalnya dengan logging.
+func (r *ArchiveRepository) OnRemove(hook func(ctx context.Context, id string)) {
+	r.onRemove = append(r.onRemove, hook)
+}
+
+func (r *ArchiveRepository) SetSupersededBy(ctx context.Context, id, by, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid object ID: %v", err)
 	}
 
-	// Find the existing file first
-	var result bson.M
-	err = r.bucket.GetFilesCollection().FindOne(ctx, bson.M{"_id": objID}).Decode(&result)
+	current, err := r.findDocument(ctx, bson.M{"_id": objID})
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return domain.ErrArchiveNotFound
-		}
-		return fmt.Errorf("failed to find document: %v", err)
+		return err
 	}
-
-	now := time.Now()
-	metadata := result["metadata"].(bson.M)
-
-	if permanent {
-		// Permanent delete - remove file completely
-		if err := r.bucket.Delete(objID); err != nil {
-			return fmt.Errorf("failed to delete file: %v", err)
-		}
+	if current.SupersededBy == by {
 		return nil
 	}
 
-	// Soft delete - update metadata
-	changeLog := domain.ChangeLog{
-		Timestamp: now,
-		Action:    "delete",
-		UserID:    userID,
-		Changes: []domain.Change{
-			{
-				Field:    "status",
-				OldValue: "active",
-				NewValue: "deleted",
-			},
-		},
-	}
-
-	// Get existing change logs
-	var changeLogs []domain.ChangeLog
-	if logs, ok := metadata["change_logs"].(primitive.A); ok {
-		for _, l := range logs {
-			if log, ok := l.(bson.M); ok {
-				changeLogs = append(changeLogs, domain.ChangeLog{
-					Timestamp: log["timestamp"].(primitive.DateTime).Time(),
-					Action:    log["action"].(string),
-					UserID:    log["user_id"].(string),
-				})
-			}
-		}
-	}
-	changeLogs = append(changeLogs, changeLog)
-
-	// Update metadata
-	update := bson.M{
-		"$set": bson.M{
-			"metadata.deleted_at":  now,
-			"metadata.change_logs": changeLogs,
-			"metadata.deleted_by":  userID,
-			"metadata.updated_at":  now,
-		},
+	var update bson.M
+	if by == "" {
+		update = bson.M{"$unset": bson.M{"metadata.superseded_by": ""}}
+	} else {
+		update = bson.M{"$set": bson.M{"metadata.superseded_by": by}}
 	}
+	updated := *current
+	updated.SupersededBy = by
+	changeLog := CreateChangeLog(domain.ActionUpdate, userID, current, &updated)
 
-	_, err = r.bucket.GetFilesCollection().UpdateOne(ctx, bson.M{"_id": objID}, update)
+	_, err = r.bucket.GetFilesCollection().UpdateOne(ctx, bson.M{"_id": objID}, withChangeLog(update, changeLog))
 	if err != nil {
-		return fmt.Errorf("failed to update document: %v", err)
+		return fmt.Errorf("failed to update superseded flag: %v", err)
 	}
-
 	return nil
 }
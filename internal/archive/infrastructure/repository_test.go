@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+func TestReassignCategoryInvalidatesStaleMetadataWrites(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	archive := saveArchive(t, repo, "laporan.pdf", "alice", "isi")
+	stale := domain.Precondition{IfMatch: []string{archive.ETag()}}
+
+	if _, err := repo.ReassignCategory(ctx, "umum", "arsip.umum", "admin"); err != nil {
+		t.Fatalf("reassign: %v", err)
+	}
+
+	// PATCH dengan ETag dari sebelum relokasi harus ditolak (412), bukan menulis path lama
+	category := "umum"
+	_, err := repo.UpdateMetadata(ctx, archive.ID.Hex(), domain.ArchivePatch{Category: &category}, "alice", stale)
+	if !errors.Is(err, domain.ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+
+	current, err := repo.FindMetadata(ctx, archive.ID.Hex())
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if current.Category != "arsip.umum" {
+		t.Fatalf("expected category arsip.umum, got %s", current.Category)
+	}
+	if current.MetadataRevision != archive.MetadataRevision+1 {
+		t.Fatalf("expected metadata revision %d, got %d", archive.MetadataRevision+1, current.MetadataRevision)
+	}
+}
@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// deleteArchive memindahkan arsip ke trash atas nama owner
+func deleteArchive(t *testing.T, repo *ArchiveRepository, archive *domain.Archive) {
+	t.Helper()
+	if err := repo.Delete(context.Background(), archive.ID.Hex(), domain.SoftDelete, archive.OwnerID); err != nil {
+		t.Fatalf("delete %s: %v", archive.Name, err)
+	}
+}
+
+func TestRestoreWithoutConflict(t *testing.T) {
+	repo := testRepository(t)
+	archive := saveArchive(t, repo, "laporan.pdf", "alice", "isi")
+	deleteArchive(t, repo, archive)
+
+	result, err := repo.RestoreArchive(context.Background(), archive.ID.Hex(), "alice", domain.RestoreFail)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Strategy != "" || result.ConflictID != "" || result.Archive.Name != "laporan.pdf" {
+		t.Fatalf("expected a plain restore, got %+v", result)
+	}
+	if _, err := repo.RestoreArchive(context.Background(), archive.ID.Hex(), "alice", domain.RestoreFail); !errors.Is(err, domain.ErrNotDeleted) {
+		t.Fatalf("expected ErrNotDeleted, got %v", err)
+	}
+}
+
+func TestRestoreFailRejectsConflict(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	deleted := saveArchive(t, repo, "laporan.pdf", "alice", "isi lama")
+	deleteArchive(t, repo, deleted)
+	live := saveArchive(t, repo, "laporan.pdf", "bob", "isi baru")
+
+	// Strategy kosong diperlakukan sama dengan fail
+	for _, strategy := range []domain.RestoreStrategy{domain.RestoreFail, ""} {
+		result, err := repo.RestoreArchive(ctx, deleted.ID.Hex(), "alice", strategy)
+		if !errors.Is(err, domain.ErrRestoreConflict) {
+			t.Fatalf("strategy %q: expected ErrRestoreConflict, got %v", strategy, err)
+		}
+		if result.Strategy != domain.RestoreFail || result.ConflictID != live.ID.Hex() {
+			t.Fatalf("strategy %q: expected the conflicting archive to be reported, got %+v", strategy, result)
+		}
+	}
+	current, err := repo.findDocument(ctx, bson.M{"_id": deleted.ID})
+	if err != nil {
+		t.Fatalf("find deleted: %v", err)
+	}
+	if current.DeletedAt == nil {
+		t.Fatal("expected the archive to stay in trash")
+	}
+}
+
+func TestRestoreRenameUsesFreeName(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	first := saveArchive(t, repo, "laporan.pdf", "alice", "isi pertama")
+	deleteArchive(t, repo, first)
+	second := saveArchive(t, repo, "laporan.pdf", "alice", "isi kedua")
+	deleteArchive(t, repo, second)
+	live := saveArchive(t, repo, "laporan.pdf", "bob", "isi ketiga")
+
+	result, err := repo.RestoreArchive(ctx, first.ID.Hex(), "alice", domain.RestoreRename)
+	if err != nil {
+		t.Fatalf("restore first: %v", err)
+	}
+	if result.Strategy != domain.RestoreRename || result.ConflictID != live.ID.Hex() || result.Archive.Name != "laporan (restored).pdf" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	// Nama hasil rename pertama sudah dipakai, jadi restore berikutnya diberi nomor
+	result, err = repo.RestoreArchive(ctx, second.ID.Hex(), "alice", domain.RestoreRename)
+	if err != nil {
+		t.Fatalf("restore second: %v", err)
+	}
+	if result.Archive.Name != "laporan (restored 2).pdf" {
+		t.Fatalf("expected a numbered name, got %s", result.Archive.Name)
+	}
+
+	restored, err := repo.FindMetadata(ctx, first.ID.Hex())
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if restored.Name != "laporan (restored).pdf" || restored.DeletedAt != nil {
+		t.Fatalf("expected the renamed archive to be live, got %s deleted %v", restored.Name, restored.DeletedAt)
+	}
+	content, err := repo.readContent(ctx, restored)
+	if err != nil || string(content) != "isi pertama" {
+		t.Fatalf("expected the original content, got %q (%v)", content, err)
+	}
+	if last := restored.ChangeLogs[len(restored.ChangeLogs)-1]; last.Action != domain.ActionRestore {
+		t.Fatalf("expected a restore change log entry, got %s", last.Action)
+	}
+}
+
+func TestRestoreMergeAddsVersionToLiveArchive(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	deleted := saveArchive(t, repo, "laporan.pdf", "alice", "isi yang dihapus")
+	deleteArchive(t, repo, deleted)
+	// Arsip baru berstatus draft, jadi versi hanya bisa ditambahkan oleh pemiliknya
+	live := saveArchive(t, repo, "laporan.pdf", "alice", "isi aktif")
+	description := "laporan tahunan"
+	if _, err := repo.UpdateMetadata(ctx, live.ID.Hex(), domain.ArchivePatch{Description: &description}, "alice", domain.Precondition{}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	result, err := repo.RestoreArchive(ctx, deleted.ID.Hex(), "alice", domain.RestoreMerge)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Strategy != domain.RestoreMerge || result.ConflictID != live.ID.Hex() || result.Archive.ID != live.ID {
+		t.Fatalf("expected the live archive to receive the content, got %+v", result)
+	}
+
+	merged, err := repo.FindMetadata(ctx, live.ID.Hex())
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if merged.Version != live.Version+1 || merged.Description != description {
+		t.Fatalf("expected a new version keeping the live metadata, got version %d description %q", merged.Version, merged.Description)
+	}
+	content, err := repo.readContent(ctx, merged)
+	if err != nil || string(content) != "isi yang dihapus" {
+		t.Fatalf("expected the deleted content as the new version, got %q (%v)", content, err)
+	}
+
+	// Arsip yang dihapus tetap di trash sampai di-purge
+	trashed, err := repo.findDocument(ctx, bson.M{"_id": deleted.ID})
+	if err != nil {
+		t.Fatalf("find deleted: %v", err)
+	}
+	if trashed.DeletedAt == nil {
+		t.Fatal("expected the merged archive to stay in trash")
+	}
+}
@@ -0,0 +1,169 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScanActive membaca arsip yang belum dihapus dan bukan temp, diurutkan berdasarkan _id
+// sehingga bisa dibaca bertahap tanpa terpengaruh perubahan di tengah proses
+func (r *ArchiveRepository) ScanActive(ctx context.Context, afterID string, limit int) ([]domain.Archive, error) {
+	filter := bson.M{
+		"metadata.deleted_at": nil,
+		"metadata.is_temp":    bson.M{"$ne": true},
+	}
+	if afterID != "" {
+		objID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object ID: %v", err)
+		}
+		filter["_id"] = bson.M{"$gt": objID}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(listProjection(nil))
+	return r.findArchives(ctx, filter, opts)
+}
+
+func (r *ArchiveRepository) SetRetention(ctx context.Context, id string, state *domain.RetentionState, userID string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	current, err := r.findDocument(ctx, bson.M{"_id": objID, "metadata.deleted_at": nil})
+	if err != nil {
+		return err
+	}
+
+	updated := *current
+	updated.Retention = state
+	updated.ExpiresAt = nil
+	if state != nil {
+		updated.ExpiresAt = state.DueAt
+	}
+
+	// updated_at sengaja tidak diubah karena menjadi pemicu retensi "modified"
+	update := bson.M{}
+	set, unset := bson.M{}, bson.M{}
+	if state != nil {
+		set["metadata.retention"] = state
+	} else {
+		unset["metadata.retention"] = ""
+	}
+	if updated.ExpiresAt != nil {
+		set["metadata.expires_at"] = updated.ExpiresAt
+	} else {
+		unset["metadata.expires_at"] = ""
+	}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	changeLog := CreateChangeLog(domain.ActionRetention, userID, current, &updated)
+	changeLog.Changes = append(changeLog.Changes, domain.Change{
+		Field:    "retention_policy",
+		OldValue: retentionPolicyID(current.Retention),
+		NewValue: retentionPolicyID(state),
+	})
+
+	_, err = r.bucket.GetFilesCollection().UpdateOne(ctx, bson.M{"_id": objID}, withChangeLog(update, changeLog))
+	if err != nil {
+		return fmt.Errorf("failed to update retention: %v", err)
+	}
+	return nil
+}
+
+// FindRetentionDue mengembalikan arsip yang masa retensinya sudah lewat dan belum
+// menunggu review. Arsip dalam legal hold ditunda sampai hold dilepas.
+func (r *ArchiveRepository) FindRetentionDue(ctx context.Context, now time.Time, limit int) ([]domain.Archive, error) {
+	filter := notHeld(bson.M{
+		"metadata.deleted_at":             nil,
+		"metadata.retention.due_at":       bson.M{"$lte": now},
+		"metadata.retention.review_since": nil,
+	})
+	opts := options.Find().
+		SetSort(bson.D{{Key: "metadata.retention.due_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(listProjection(nil))
+	return r.findArchives(ctx, filter, opts)
+}
+
+func (r *ArchiveRepository) FindRetentionReview(ctx context.Context, page, limit int) ([]domain.Archive, int64, error) {
+	filter := bson.M{
+		"metadata.deleted_at":             nil,
+		"metadata.retention.review_since": bson.M{"$ne": nil},
+	}
+
+	total, err := r.bucket.GetFilesCollection().CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count documents: %v", err)
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "metadata.retention.review_since", Value: 1}}).
+		SetProjection(listProjection(nil))
+	archives, err := r.findArchives(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return archives, total, nil
+}
+
+func (r *ArchiveRepository) findArchives(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.Archive, error) {
+	cur, err := r.bucket.GetFilesCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var files []bson.M
+	if err := cur.All(ctx, &files); err != nil {
+		return nil, fmt.Errorf("failed to decode documents: %v", err)
+	}
+
+	archives := make([]domain.Archive, 0, len(files))
+	for _, file := range files {
+		archives = append(archives, mapToArchive(file))
+	}
+	return archives, nil
+}
+
+func retentionPolicyID(state *domain.RetentionState) interface{} {
+	if state == nil {
+		return nil
+	}
+	return state.PolicyID.Hex()
+}
+
+func decodeRetention(v interface{}) *domain.RetentionState {
+	retention, ok := v.(bson.M)
+	if !ok {
+		return nil
+	}
+	state := &domain.RetentionState{
+		Action:      domain.RetentionAction(stringValue(retention["action"])),
+		DueAt:       timePointer(retention["due_at"]),
+		ReviewSince: timePointer(retention["review_since"]),
+		RetainUntil: timePointer(retention["retain_until"]),
+		ApprovedBy:  stringValue(retention["approved_by"]),
+		ApprovedAt:  timePointer(retention["approved_at"]),
+	}
+	if id, ok := retention["policy_id"].(primitive.ObjectID); ok {
+		state.PolicyID = id
+	}
+	return state
+}
@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RetentionRepository struct {
+	policies *mongo.Collection
+	runs     *mongo.Collection
+}
+
+func NewRetentionRepository(client *mongo.Client, dbName string) (*RetentionRepository, error) {
+	db := client.Database(dbName)
+	runs := db.Collection("retention_runs")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := runs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "started_at", Value: -1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retention run indexes: %v", err)
+	}
+
+	return &RetentionRepository{
+		policies: db.Collection("retention_policies"),
+		runs:     runs,
+	}, nil
+}
+
+func (r *RetentionRepository) CreatePolicy(ctx context.Context, policy *domain.RetentionPolicy) error {
+	if policy.ID.IsZero() {
+		policy.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.policies.InsertOne(ctx, policy); err != nil {
+		return fmt.Errorf("failed to insert retention policy: %v", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepository) FindPolicy(ctx context.Context, id string) (*domain.RetentionPolicy, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrRetentionPolicyNotFound
+	}
+
+	var policy domain.RetentionPolicy
+	if err := r.policies.FindOne(ctx, bson.M{"_id": objID}).Decode(&policy); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrRetentionPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to find retention policy: %v", err)
+	}
+	return &policy, nil
+}
+
+func (r *RetentionRepository) FindPolicies(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "category", Value: 1}, {Key: "type", Value: 1}})
+
+	cur, err := r.policies.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find retention policies: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	policies := []domain.RetentionPolicy{}
+	if err := cur.All(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("failed to decode retention policies: %v", err)
+	}
+	return policies, nil
+}
+
+func (r *RetentionRepository) UpdatePolicy(ctx context.Context, policy *domain.RetentionPolicy) error {
+	result, err := r.policies.ReplaceOne(ctx, bson.M{"_id": policy.ID}, policy)
+	if err != nil {
+		return fmt.Errorf("failed to update retention policy: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+func (r *RetentionRepository) DeletePolicy(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrRetentionPolicyNotFound
+	}
+
+	result, err := r.policies.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete retention policy: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+func (r *RetentionRepository) SaveRun(ctx context.Context, run *domain.RetentionRun) error {
+	if run.ID.IsZero() {
+		run.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.runs.InsertOne(ctx, run); err != nil {
+		return fmt.Errorf("failed to insert retention run: %v", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepository) FindRuns(ctx context.Context, page, limit int) ([]domain.RetentionRun, int64, error) {
+	total, err := r.runs.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count retention runs: %v", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cur, err := r.runs.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find retention runs: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	runs := []domain.RetentionRun{}
+	if err := cur.All(ctx, &runs); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode retention runs: %v", err)
+	}
+	return runs, total, nil
+}
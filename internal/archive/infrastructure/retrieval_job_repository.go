@@ -0,0 +1,128 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RetrievalJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRetrievalJobRepository(client *mongo.Client, dbName string) (*RetrievalJobRepository, error) {
+	collection := client.Database(dbName).Collection("retrieval_jobs")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "archive_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retrieval job indexes: %v", err)
+	}
+
+	return &RetrievalJobRepository{collection: collection}, nil
+}
+
+func (r *RetrievalJobRepository) Create(ctx context.Context, job *domain.RetrievalJob) error {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("failed to insert retrieval job: %v", err)
+	}
+	return nil
+}
+
+func (r *RetrievalJobRepository) FindByID(ctx context.Context, id string) (*domain.RetrievalJob, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrRetrievalJobNotFound
+	}
+
+	var job domain.RetrievalJob
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&job); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrRetrievalJobNotFound
+		}
+		return nil, fmt.Errorf("failed to find retrieval job: %v", err)
+	}
+	return &job, nil
+}
+
+func (r *RetrievalJobRepository) FindActive(ctx context.Context, archiveID string) (*domain.RetrievalJob, error) {
+	var job domain.RetrievalJob
+	err := r.collection.FindOne(
+		ctx,
+		bson.M{
+			"archive_id": archiveID,
+			"status":     bson.M{"$in": []domain.RetrievalStatus{domain.RetrievalPending, domain.RetrievalRunning}},
+		},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find retrieval job: %v", err)
+	}
+	return &job, nil
+}
+
+func (r *RetrievalJobRepository) Update(ctx context.Context, job *domain.RetrievalJob) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	if err != nil {
+		return fmt.Errorf("failed to update retrieval job: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrRetrievalJobNotFound
+	}
+	return nil
+}
+
+func (r *RetrievalJobRepository) Heartbeat(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if _, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": []domain.RetrievalStatus{domain.RetrievalPending, domain.RetrievalRunning}}},
+		bson.M{"$set": bson.M{"heartbeat_at": at}},
+	); err != nil {
+		return fmt.Errorf("failed to update retrieval job: %v", err)
+	}
+	return nil
+}
+
+func (r *RetrievalJobRepository) FailInterrupted(ctx context.Context, owner string, staleBefore time.Time) (int64, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{
+			"status": bson.M{"$in": []domain.RetrievalStatus{domain.RetrievalPending, domain.RetrievalRunning}},
+			// Job replika lain yang masih hidup dibiarkan
+			"$or": []bson.M{
+				{"owner": owner},
+				{"heartbeat_at": nil},
+				{"heartbeat_at": bson.M{"$lt": staleBefore}},
+			},
+		},
+		bson.M{"$set": bson.M{
+			"status":      domain.RetrievalFailed,
+			"error":       "interrupted by server restart",
+			"finished_at": now,
+		}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark interrupted retrieval jobs: %v", err)
+	}
+	return result.ModifiedCount, nil
+}
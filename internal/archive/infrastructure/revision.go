@@ -0,0 +1,397 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// replaceVersion menyimpan versi saat ini sebagai revisi lalu menggantinya dengan
+// konten baru. Konten ditulis ke chunks dengan files_id baru dan baru dipakai setelah
+// compare-and-set pada metadata.version berhasil, sehingga upload yang gagal atau kalah
+// balapan tidak menghilangkan versi lama. Entri change log ditambahkan dengan $push.
+// Lock, legal hold, retensi, state dan field lain yang dikelola writer lain tidak
+// ditulis ulang, jadi perubahan bersamaan pada field itu tidak tertimpa.
+func (r *ArchiveRepository) replaceVersion(ctx context.Context, archive domain.Archive, changeLog domain.ChangeLog, content []byte, cond domain.Precondition) (*domain.Archive, error) {
+	var file bson.M
+	err := r.bucket.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"_id": archive.ID},
+		options.FindOne().SetProjection(bson.M{
+			"chunkSize":           1,
+			"metadata.version":    1,
+			"metadata.tier":       1,
+			"metadata.content_id": 1,
+			"metadata.is_temp":    1,
+			"metadata.retention":  1,
+		}),
+	).Decode(&file)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrArchiveNotFound
+		}
+		return nil, fmt.Errorf("failed to find document: %v", err)
+	}
+	previous := mapToArchive(file)
+	if previous.Version != archive.Version-1 {
+		return nil, r.writeConflict(ctx, archive.ID, changeLog.UserID, cond)
+	}
+
+	if err := r.snapshotRevision(ctx, archive.ID); err != nil {
+		return nil, err
+	}
+
+	chunkSize := int(int64Value(file["chunkSize"]))
+	if chunkSize <= 0 {
+		chunkSize = 1024 * 1024
+	}
+	newContentID := primitive.NewObjectID()
+	if err := r.writeChunks(ctx, newContentID, content, chunkSize); err != nil {
+		return nil, err
+	}
+
+	set := bson.M{
+		"filename":            archive.Name,
+		"length":              int64(len(content)),
+		"chunkSize":           chunkSize,
+		"uploadDate":          archive.UpdatedAt,
+		"metadata.content_id": newContentID,
+	}
+	// Versi baru selalu hot dan aktif
+	unset := bson.M{
+		"metadata.deleted_at":     "",
+		"metadata.deleted_by":     "",
+		"metadata.tier":           "",
+		"metadata.tiered_at":      "",
+		"metadata.rehydrating_at": "",
+	}
+	for _, field := range fileMetadata(archive, nil) {
+		if field.Key != "change_logs" && !carriedMetadata[field.Key] {
+			set["metadata."+field.Key] = field.Value
+		}
+	}
+	if len(archive.Signature) == 0 {
+		unset["metadata.minhash"] = ""
+		unset["metadata.lsh_bands"] = ""
+	}
+	// Versi baru membatalkan jadwal hapus sementara, kecuali tanggal kedaluwarsa dari retensi
+	if previous.IsTemp && previous.Retention == nil {
+		unset["metadata.expires_at"] = ""
+	}
+
+	// Compare-and-set pada versi, revisi metadata dan lock; metadata yang berubah
+	// sejak dibaca membuat If-Match dan pemeriksaan lock harus diulang
+	var updated bson.M
+	err = r.bucket.GetFilesCollection().FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":                        archive.ID,
+			"metadata.deleted_at":        nil,
+			"metadata.version":           previous.Version,
+			"metadata.metadata_revision": revisionMatch(archive.MetadataRevision),
+			"$and":                       []bson.M{writableBy(changeLog.UserID, time.Now())},
+		},
+		bson.M{"$set": set, "$unset": unset, "$push": bson.M{"metadata.change_logs": changeLog}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if _, cleanupErr := r.bucket.GetChunksCollection().DeleteMany(ctx, bson.M{"files_id": newContentID}); cleanupErr != nil {
+			return nil, fmt.Errorf("failed to update document: %v; failed to delete chunks: %v", err, cleanupErr)
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, r.writeConflict(ctx, archive.ID, changeLog.UserID, cond)
+		}
+		return nil, fmt.Errorf("failed to update document: %v", err)
+	}
+
+	// Konten lama baru dihapus setelah versi baru terpasang. Objek cold versi lama sudah
+	// tersalin ke revisi, jadi objek yang gagal dihapus hanya menjadi sampah yang tidak
+	// pernah terbaca lagi. Chunks yang gagal dihapus dilaporkan seperti pada removeFile;
+	// versi baru tetap sudah tersimpan.
+	if _, err := r.bucket.GetChunksCollection().DeleteMany(ctx, bson.M{"files_id": contentID(&previous)}); err != nil {
+		return nil, fmt.Errorf("failed to delete chunks: %v", err)
+	}
+	_ = r.dropCold(ctx, &previous)
+
+	written := mapToArchive(updated)
+	written.Signature = archive.Signature
+	return &written, nil
+}
+
+// carriedMetadata adalah field yang dibawa dari versi sebelumnya dan diubah lewat
+// operasinya sendiri, bukan lewat upload versi baru
+var carriedMetadata = map[string]bool{
+	"owner_id":          true,
+	"lock":              true,
+	"superseded_by":     true,
+	"folder_id":         true,
+	"event_date":        true,
+	"legal_holds":       true,
+	"state":             true,
+	"reviewers":         true,
+	"retention":         true,
+	"expires_at":        true,
+	"created_at":        true,
+	"metadata_revision": true,
+}
+
+// carryMetadata menyalin field yang dibawa dari versi saat ini ke versi baru, sesuai
+// dengan yang ditulis replaceVersion, agar change log mencatat perubahan yang sebenarnya
+func carryMetadata(archive, current *domain.Archive) {
+	archive.OwnerID = current.OwnerID
+	archive.Lock = current.Lock
+	archive.SupersededBy = current.SupersededBy
+	archive.FolderID = current.FolderID
+	archive.EventDate = current.EventDate
+	archive.LegalHolds = current.LegalHolds
+	archive.State = current.State
+	archive.Reviewers = current.Reviewers
+	archive.Retention = current.Retention
+	archive.CreatedAt = current.CreatedAt
+	archive.MetadataRevision = current.MetadataRevision
+	archive.ExpiresAt = current.ExpiresAt
+	if current.IsTemp && current.Retention == nil {
+		archive.ExpiresAt = nil
+	}
+	archive.Tier = ""
+	archive.TieredAt = nil
+}
+
+// snapshotRevision menyalin konten dan metadata versi saat ini ke bucket revisi.
+// Revisi yang sudah tersimpan untuk versi yang sama tidak ditulis ulang.
+func (r *ArchiveRepository) snapshotRevision(ctx context.Context, id primitive.ObjectID) error {
+	var file bson.M
+	err := r.bucket.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"_id": id},
+		options.FindOne().SetProjection(listProjection(nil)),
+	).Decode(&file)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.ErrArchiveNotFound
+		}
+		return fmt.Errorf("failed to find document: %v", err)
+	}
+
+	metadata := bson.M{}
+	if m, ok := file["metadata"].(bson.M); ok {
+		for k, v := range m {
+			metadata[k] = v
+		}
+	}
+	// Band LSH, lock, penanda usang, folder, retensi, legal hold, state dan tier hanya berlaku pada versi terbaru
+	delete(metadata, "lsh_bands")
+	delete(metadata, "lock")
+	delete(metadata, "superseded_by")
+	delete(metadata, "folder_id")
+	delete(metadata, "retention")
+	delete(metadata, "legal_holds")
+	delete(metadata, "state")
+	delete(metadata, "reviewers")
+	delete(metadata, "tier")
+	delete(metadata, "tiered_at")
+	delete(metadata, "rehydrating_at")
+	delete(metadata, "last_accessed_at")
+	delete(metadata, "content_id")
+	metadata["archive_id"] = id
+
+	count, err := r.revisions.GetFilesCollection().CountDocuments(ctx, bson.M{
+		"metadata.archive_id": id,
+		"metadata.version":    metadata["version"],
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check revision: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	current := mapToArchive(file)
+	content, err := r.readContent(ctx, &current)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.revisions.UploadFromStream(
+		stringValue(file["filename"]),
+		bytes.NewReader(content),
+		options.GridFSUpload().SetMetadata(metadata),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store revision: %v", err)
+	}
+	return nil
+}
+
+// ListRevisions mengembalikan versi terbaru diikuti revisi sebelumnya, terbaru lebih dulu
+func (r *ArchiveRepository) ListRevisions(ctx context.Context, id string) ([]domain.Archive, error) {
+	current, err := r.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := r.revisions.GetFilesCollection().Find(
+		ctx,
+		bson.M{"metadata.archive_id": current.ID},
+		options.Find().
+			SetSort(bson.D{{Key: "metadata.version", Value: -1}}).
+			SetProjection(bson.M{"metadata.change_logs": 0, "metadata.minhash": 0}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find revisions: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var files []bson.M
+	if err = cur.All(ctx, &files); err != nil {
+		return nil, fmt.Errorf("failed to decode revisions: %v", err)
+	}
+
+	revisions := []domain.Archive{*current}
+	for _, file := range files {
+		revisions = append(revisions, mapToRevision(file, current.ID))
+	}
+	return revisions, nil
+}
+
+// FindRevision mengambil metadata dan konten satu versi, baik versi terbaru maupun revisi lama
+func (r *ArchiveRepository) FindRevision(ctx context.Context, id string, version int) (*domain.Archive, []byte, error) {
+	current, err := r.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if version == current.Version {
+		content, err := r.readContent(ctx, current)
+		if err != nil {
+			return nil, nil, err
+		}
+		signature, err := r.GetSignature(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		current.Signature = signature
+		return current, content, nil
+	}
+
+	var file bson.M
+	err = r.revisions.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"metadata.archive_id": current.ID, "metadata.version": version},
+		options.FindOne().SetProjection(bson.M{"metadata.change_logs": 0}),
+	).Decode(&file)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, domain.ErrRevisionNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to find revision: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.revisions.DownloadToStream(file["_id"], &buf); err != nil {
+		return nil, nil, fmt.Errorf("failed to download revision: %v", err)
+	}
+
+	revision := mapToRevision(file, current.ID)
+	if metadata, ok := file["metadata"].(bson.M); ok {
+		revision.Signature = signatureFromBSON(metadata["minhash"])
+	}
+	return &revision, buf.Bytes(), nil
+}
+
+// Rollback menjadikan konten dan metadata revisi lama sebagai versi terbaru yang baru.
+// Riwayat tidak ditulis ulang: versi saat ini tetap tersimpan sebagai revisi.
+func (r *ArchiveRepository) Rollback(ctx context.Context, id string, version int, userID string) (*domain.Archive, error) {
+	current, err := r.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version < 1 || version >= current.Version {
+		return nil, domain.ErrInvalidVersion
+	}
+
+	release, err := r.claimName(ctx, current.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Baca ulang setelah claim agar versi yang diganti adalah versi terbaru
+	current, err = r.FindMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWrite(current, userID, domain.Precondition{}); err != nil {
+		return nil, err
+	}
+	if current.Frozen() {
+		return nil, domain.ErrContentFrozen
+	}
+
+	target, content, err := r.FindRevision(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := *target
+	archive.ID = current.ID
+	archive.Version = current.Version + 1
+	archive.UpdatedAt = time.Now()
+	archive.Size = int64(len(content))
+	archive.IsTemp = false
+	archive.DeletedAt = nil
+	archive.DeletedBy = ""
+	// Field yang dibawa dari versi saat ini hanya dipakai untuk change log;
+	// replaceVersion tidak menulisnya ulang
+	carryMetadata(&archive, current)
+
+	changeLog := CreateChangeLog(domain.ActionRollback, userID, current, &archive)
+	changeLog.Changes = append(changeLog.Changes, domain.Change{
+		Field:    "rollback",
+		OldValue: current.Version,
+		NewValue: version,
+	})
+
+	return r.replaceVersion(ctx, archive, changeLog, content, domain.Precondition{})
+}
+
+// removeRevisions menghapus semua revisi arsip beserta chunks-nya
+func (r *ArchiveRepository) removeRevisions(ctx context.Context, id primitive.ObjectID) error {
+	cur, err := r.revisions.GetFilesCollection().Find(
+		ctx,
+		bson.M{"metadata.archive_id": id},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to find revisions: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode revision: %v", err)
+		}
+		if err := r.revisions.Delete(doc.ID); err != nil {
+			return fmt.Errorf("failed to delete revision %s: %v", doc.ID.Hex(), err)
+		}
+	}
+	return cur.Err()
+}
+
+// mapToRevision memetakan dokumen revisi menjadi Archive dengan ID arsip induknya
+func mapToRevision(file bson.M, archiveID primitive.ObjectID) domain.Archive {
+	revision := mapToArchive(file)
+	revision.ID = archiveID
+	return revision
+}
@@ -0,0 +1,130 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// readContent membaca konten versi terbaru arsip
+func readContent(t *testing.T, repo *ArchiveRepository, id string) (*domain.Archive, string) {
+	t.Helper()
+	archive, content, err := repo.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("find %s: %v", id, err)
+	}
+	return archive, string(content)
+}
+
+func TestRollbackRestoresOldContentAsNewVersion(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	saveArchive(t, repo, "laporan.pdf", "alice", "versi satu")
+	second := saveArchive(t, repo, "laporan.pdf", "alice", "versi dua")
+	id := second.ID.Hex()
+
+	rolledBack, err := repo.Rollback(ctx, id, 1, "alice")
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if rolledBack.Version != 3 {
+		t.Fatalf("expected rollback to create version 3, got %d", rolledBack.Version)
+	}
+	current, content := readContent(t, repo, id)
+	if content != "versi satu" || current.Size != int64(len("versi satu")) {
+		t.Fatalf("expected the content of version 1, got %q (size %d)", content, current.Size)
+	}
+	last := current.ChangeLogs[len(current.ChangeLogs)-1]
+	if last.Action != domain.ActionRollback {
+		t.Fatalf("expected a rollback change log entry, got %s", last.Action)
+	}
+
+	// Riwayat tidak ditulis ulang: versi 2 tetap bisa dibaca sebagai revisi
+	revisions, err := repo.ListRevisions(ctx, id)
+	if err != nil {
+		t.Fatalf("revisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("expected versions 1 and 2 as revisions, got %d", len(revisions))
+	}
+	if _, old, err := repo.FindRevision(ctx, id, 2); err != nil || string(old) != "versi dua" {
+		t.Fatalf("expected version 2 kept as a revision, got %q (%v)", old, err)
+	}
+
+	for _, version := range []int{0, 3, 4} {
+		if _, err := repo.Rollback(ctx, id, version, "alice"); !errors.Is(err, domain.ErrInvalidVersion) {
+			t.Fatalf("rollback to %d: expected ErrInvalidVersion, got %v", version, err)
+		}
+	}
+}
+
+func TestRollbackRefusedWhileCheckedOutByOtherUser(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	saveArchive(t, repo, "kontrak.pdf", "alice", "versi satu")
+	second := saveArchive(t, repo, "kontrak.pdf", "alice", "versi dua")
+	if _, err := repo.Checkout(ctx, second.ID.Hex(), "alice", time.Hour); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	if _, err := repo.Rollback(ctx, second.ID.Hex(), 1, "bob"); !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("expected ErrArchiveLocked, got %v", err)
+	}
+	if _, content := readContent(t, repo, second.ID.Hex()); content != "versi dua" {
+		t.Fatalf("expected the current content to stay, got %q", content)
+	}
+}
+
+func TestReplaceVersionKeepsCurrentContentWhenSwapFails(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	first := saveArchive(t, repo, "neraca.xlsx", "alice", "versi satu")
+	if _, err := repo.Checkout(ctx, first.ID.Hex(), "alice", time.Hour); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	// Chunks baru sudah ditulis sebelum compare-and-set gagal karena lock milik alice
+	next := *first
+	next.Version = first.Version + 1
+	next.UpdatedAt = time.Now()
+	changeLog := domain.ChangeLog{Action: domain.ActionUpdate, UserID: "bob", Timestamp: time.Now()}
+	_, err := repo.replaceVersion(ctx, next, changeLog, []byte("versi bob"), domain.Precondition{})
+	if !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("expected ErrArchiveLocked, got %v", err)
+	}
+
+	current, content := readContent(t, repo, first.ID.Hex())
+	if content != "versi satu" || current.Version != first.Version {
+		t.Fatalf("expected version %d with the original content, got version %d %q", first.Version, current.Version, content)
+	}
+	// Chunks dari swap yang gagal dibersihkan, tidak ada konten yatim
+	orphans, err := repo.bucket.GetChunksCollection().CountDocuments(ctx, bson.M{"files_id": bson.M{"$ne": contentID(current)}})
+	if err != nil {
+		t.Fatalf("count chunks: %v", err)
+	}
+	if orphans != 0 {
+		t.Fatalf("expected no orphaned chunks, got %d", orphans)
+	}
+}
+
+func TestReplaceVersionLosingRaceReportsConflict(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	first := saveArchive(t, repo, "memo.pdf", "alice", "versi satu")
+	saveArchive(t, repo, "memo.pdf", "alice", "versi dua")
+
+	// Penulis kedua masih mengira versi 1 adalah versi terbaru
+	stale := *first
+	stale.Version = first.Version + 1
+	changeLog := domain.ChangeLog{Action: domain.ActionUpdate, UserID: "alice", Timestamp: time.Now()}
+	if _, err := repo.replaceVersion(ctx, stale, changeLog, []byte("versi basi"), domain.Precondition{}); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if current, content := readContent(t, repo, first.ID.Hex()); content != "versi dua" || current.Version != 2 {
+		t.Fatalf("expected version 2 to stay current, got version %d %q", current.Version, content)
+	}
+}
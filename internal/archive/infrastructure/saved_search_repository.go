@@ -0,0 +1,126 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SavedSearchRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSavedSearchRepository(client *mongo.Client, dbName string) (*SavedSearchRepository, error) {
+	collection := client.Database(dbName).Collection("saved_searches")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Nama saved search unik per pemilik
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "shared_with", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saved search indexes: %v", err)
+	}
+
+	return &SavedSearchRepository{collection: collection}, nil
+}
+
+func (r *SavedSearchRepository) Create(ctx context.Context, search *domain.SavedSearch) error {
+	if search.ID.IsZero() {
+		search.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, search); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrSavedSearchExists
+		}
+		return fmt.Errorf("failed to insert saved search: %v", err)
+	}
+	return nil
+}
+
+func (r *SavedSearchRepository) FindByID(ctx context.Context, id string) (*domain.SavedSearch, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrSavedSearchNotFound
+	}
+
+	var search domain.SavedSearch
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&search); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSavedSearchNotFound
+		}
+		return nil, fmt.Errorf("failed to find saved search: %v", err)
+	}
+	return &search, nil
+}
+
+func (r *SavedSearchRepository) FindVisible(ctx context.Context, userID string) ([]domain.SavedSearch, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"owner_id": userID},
+			{"shared_with": userID},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find saved searches: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var searches []domain.SavedSearch
+	if err := cur.All(ctx, &searches); err != nil {
+		return nil, fmt.Errorf("failed to decode saved searches: %v", err)
+	}
+	return searches, nil
+}
+
+func (r *SavedSearchRepository) Update(ctx context.Context, search *domain.SavedSearch) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": search.ID}, bson.M{
+		"$set": bson.M{
+			"name":        search.Name,
+			"description": search.Description,
+			"filter":      search.Filter,
+			"shared_with": search.SharedWith,
+			"updated_at":  search.UpdatedAt,
+		},
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrSavedSearchExists
+		}
+		return fmt.Errorf("failed to update saved search: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+func (r *SavedSearchRepository) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrSavedSearchNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrSavedSearchNotFound
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultTempDeleteTTL dipakai bila temp delete dipanggil lewat Delete tanpa durasi
+const defaultTempDeleteTTL = 24 * time.Hour
+
+func (r *ArchiveRepository) ScheduleDeletion(ctx context.Context, id string, expiresAt time.Time, userID string) (*domain.Archive, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
+	}
+	return r.scheduleDeletion(ctx, objID, expiresAt, userID)
+}
+
+// scheduleDeletion menandai arsip sebagai temp dengan expires_at. Arsip tetap bisa
+// diunduh sampai expires_at lewat, lalu dihapus oleh cleanup task. Arsip yang dibekukan
+// atau di-check-out user lain di antara pembacaan dan update tidak diubah.
+func (r *ArchiveRepository) scheduleDeletion(ctx context.Context, id primitive.ObjectID, expiresAt time.Time, userID string) (*domain.Archive, error) {
+	current, err := r.scheduledDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.OnHold() {
+		return nil, domain.ErrUnderLegalHold
+	}
+	if err := checkWrite(current, userID, domain.Precondition{}); err != nil {
+		return nil, err
+	}
+
+	action := domain.ActionTempDelete
+	if current.IsTemp {
+		action = domain.ActionExtendDeletion
+	}
+
+	updated := *current
+	updated.IsTemp = true
+	updated.ExpiresAt = &expiresAt
+	updated.Retention = nil
+
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		notHeld(bson.M{
+			"_id":                 id,
+			"metadata.deleted_at": nil,
+			"$and":                []bson.M{writableBy(userID, time.Now())},
+		}),
+		withChangeLog(bson.M{
+			"$set": bson.M{
+				"metadata.is_temp":    true,
+				"metadata.expires_at": expiresAt,
+			},
+			// Temp delete menggantikan jadwal retensi
+			"$unset": bson.M{"metadata.retention": ""},
+		}, CreateChangeLog(action, userID, current, &updated)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule deletion: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, r.scheduleConflict(ctx, id, userID)
+	}
+	return &updated, nil
+}
+
+// scheduleConflict menjelaskan penjadwalan yang tidak mengenai dokumen apa pun dengan
+// membaca ulang arsip: sudah dihapus, baru dibekukan, atau baru di-check-out user lain
+func (r *ArchiveRepository) scheduleConflict(ctx context.Context, id primitive.ObjectID, userID string) error {
+	current, err := r.findDocument(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	switch {
+	case current.DeletedAt != nil:
+		return domain.ErrAlreadyDeleted
+	case current.OnHold():
+		return domain.ErrUnderLegalHold
+	case current.LockedFor(userID, time.Now()):
+		return domain.ErrArchiveLocked
+	}
+	return domain.ErrVersionConflict
+}
+
+// CancelDeletion membatalkan temp delete; jadwal retensi dihitung ulang pada evaluasi berikutnya
+func (r *ArchiveRepository) CancelDeletion(ctx context.Context, id, userID string) (*domain.Archive, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %v", err)
+	}
+
+	current, err := r.scheduledDocument(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+	if !current.IsTemp {
+		return nil, domain.ErrNotScheduled
+	}
+
+	updated := *current
+	updated.IsTemp = false
+	updated.ExpiresAt = nil
+
+	_, err = r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": objID, "metadata.is_temp": true},
+		withChangeLog(bson.M{
+			"$set":   bson.M{"metadata.is_temp": false},
+			"$unset": bson.M{"metadata.expires_at": ""},
+		}, CreateChangeLog(domain.ActionCancelDeletion, userID, current, &updated)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel deletion: %v", err)
+	}
+	return &updated, nil
+}
+
+// scheduledDocument mengambil arsip aktif yang jadwal penghapusannya masih bisa diubah
+func (r *ArchiveRepository) scheduledDocument(ctx context.Context, id primitive.ObjectID) (*domain.Archive, error) {
+	current, err := r.findDocument(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+	if current.DeletedAt != nil {
+		return nil, domain.ErrAlreadyDeleted
+	}
+	if current.IsTemp && current.Expired(time.Now()) {
+		return nil, domain.ErrAlreadyExpire
+	}
+	return current, nil
+}
@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestScheduleDeletionRefusesHeldAndCheckedOutArchives(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	locked := saveArchive(t, repo, "kontrak.pdf", "alice", "isi")
+	if _, err := repo.Checkout(ctx, locked.ID.Hex(), "alice", time.Hour); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if _, err := repo.ScheduleDeletion(ctx, locked.ID.Hex(), expiresAt, "bob"); !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("expected ErrArchiveLocked, got %v", err)
+	}
+
+	held := saveArchive(t, repo, "laporan.pdf", "alice", "isi")
+	dueAt := time.Now().Add(24 * time.Hour)
+	retention := &domain.RetentionState{PolicyID: primitive.NewObjectID(), DueAt: &dueAt}
+	if err := repo.SetRetention(ctx, held.ID.Hex(), retention, domain.SystemUserID); err != nil {
+		t.Fatalf("retention: %v", err)
+	}
+	if _, err := repo.ApplyLegalHold(ctx, "hold-1", []string{held.ID.Hex()}, "legal"); err != nil {
+		t.Fatalf("hold: %v", err)
+	}
+	if _, err := repo.ScheduleDeletion(ctx, held.ID.Hex(), expiresAt, "alice"); !errors.Is(err, domain.ErrUnderLegalHold) {
+		t.Fatalf("expected ErrUnderLegalHold, got %v", err)
+	}
+
+	current, err := repo.FindMetadata(ctx, held.ID.Hex())
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if current.IsTemp || current.Retention == nil {
+		t.Fatalf("held archive must keep its retention schedule, got is_temp=%v retention=%v", current.IsTemp, current.Retention)
+	}
+}
+
+func TestScheduleConflictExplainsUnmatchedUpdate(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+
+	archive := saveArchive(t, repo, "laporan.pdf", "alice", "isi")
+	if _, err := repo.Checkout(ctx, archive.ID.Hex(), "alice", time.Hour); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if err := repo.scheduleConflict(ctx, archive.ID, "bob"); !errors.Is(err, domain.ErrArchiveLocked) {
+		t.Fatalf("expected ErrArchiveLocked, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, archive.ID.Hex(), domain.SoftDelete, "alice"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.scheduleConflict(ctx, archive.ID, "alice"); !errors.Is(err, domain.ErrAlreadyDeleted) {
+		t.Fatalf("expected ErrAlreadyDeleted, got %v", err)
+	}
+}
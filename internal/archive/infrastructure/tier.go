@@ -0,0 +1,334 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// rehydrateClaimTTL membatasi lama klaim rehydrate, supaya klaim dari proses yang
+// mati di tengah jalan tidak mengunci arsip selamanya
+const rehydrateClaimTTL = 10 * time.Minute
+
+// UseColdStore mengaktifkan cold tier; tanpa cold store semua konten tetap di GridFS
+func (r *ArchiveRepository) UseColdStore(store domain.ColdStore) {
+	r.cold = store
+}
+
+// coldKey mengikat objek cold ke versi konten, sehingga objek versi lama tidak
+// pernah terbaca sebagai versi baru
+func coldKey(archive *domain.Archive) string {
+	return fmt.Sprintf("%s-v%d", archive.ID.Hex(), archive.Version)
+}
+
+// readContent membaca konten versi terbaru dari GridFS atau dari cold store
+// tanpa memindahkan arsip antar tier
+func (r *ArchiveRepository) readContent(ctx context.Context, archive *domain.Archive) ([]byte, error) {
+	if archive.Cold() {
+		if r.cold == nil {
+			return nil, domain.ErrColdStoreDisabled
+		}
+		content, err := r.cold.Get(ctx, coldKey(archive))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cold content: %v", err)
+		}
+		return content, nil
+	}
+
+	return r.readChunks(ctx, contentID(archive), archive.Size)
+}
+
+// contentID mengembalikan files_id chunks konten arsip. Upload pertama menulis chunks
+// dengan ID arsip; versi berikutnya ditulis dengan files_id baru lalu ditukar.
+func contentID(archive *domain.Archive) primitive.ObjectID {
+	if archive.ContentID.IsZero() {
+		return archive.ID
+	}
+	return archive.ContentID
+}
+
+// readChunks menyusun konten dari chunks GridFS berurutan dan memastikan ukurannya utuh
+func (r *ArchiveRepository) readChunks(ctx context.Context, filesID primitive.ObjectID, size int64) ([]byte, error) {
+	cur, err := r.bucket.GetChunksCollection().Find(
+		ctx,
+		bson.M{"files_id": filesID},
+		options.Find().SetSort(bson.D{{Key: "n", Value: 1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var buf bytes.Buffer
+	for cur.Next(ctx) {
+		var chunk struct {
+			Data []byte `bson:"data"`
+		}
+		if err := cur.Decode(&chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode chunk: %v", err)
+		}
+		buf.Write(chunk.Data)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("failed to download file: %v", err)
+	}
+	if int64(buf.Len()) != size {
+		return nil, fmt.Errorf("failed to download file: expected %d bytes, got %d", size, buf.Len())
+	}
+	return buf.Bytes(), nil
+}
+
+// writeChunks memecah konten menjadi chunks GridFS dengan files_id yang diberikan
+func (r *ArchiveRepository) writeChunks(ctx context.Context, filesID primitive.ObjectID, content []byte, chunkSize int) error {
+	chunks := r.bucket.GetChunksCollection()
+	docs := make([]interface{}, 0, len(content)/chunkSize+1)
+	for n, start := 0, 0; start < len(content); n, start = n+1, start+chunkSize {
+		docs = append(docs, bson.M{
+			"_id":      primitive.NewObjectID(),
+			"files_id": filesID,
+			"n":        n,
+			"data":     primitive.Binary{Data: content[start:min(start+chunkSize, len(content))]},
+		})
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	if _, err := chunks.InsertMany(ctx, docs); err != nil {
+		chunks.DeleteMany(ctx, bson.M{"files_id": filesID})
+		return fmt.Errorf("failed to write chunks: %v", err)
+	}
+	return nil
+}
+
+// dropCold menghapus objek cold milik arsip; objek yang sudah tidak ada diabaikan
+func (r *ArchiveRepository) dropCold(ctx context.Context, archive *domain.Archive) error {
+	if !archive.Cold() || r.cold == nil {
+		return nil
+	}
+	if err := r.cold.Delete(ctx, coldKey(archive)); err != nil && !errors.Is(err, domain.ErrColdObjectNotFound) {
+		return fmt.Errorf("failed to delete cold content: %v", err)
+	}
+	return nil
+}
+
+func (r *ArchiveRepository) FindTierCandidates(ctx context.Context, policy domain.TierPolicy, now time.Time, limit int) ([]domain.Archive, error) {
+	createdBefore, idleSince := policy.Cutoffs(now)
+	clauses := []bson.M{
+		// Upload versi baru atau edit metadata juga dihitung sebagai akses
+		{"$or": []bson.M{
+			{"metadata.last_accessed_at": nil},
+			{"metadata.last_accessed_at": bson.M{"$lt": idleSince}},
+		}},
+		{"$or": []bson.M{
+			{"metadata.updated_at": nil, "uploadDate": bson.M{"$lt": idleSince}},
+			{"metadata.updated_at": bson.M{"$lt": idleSince}},
+		}},
+	}
+	if len(policy.Categories) > 0 {
+		categories := make([]bson.M, 0, len(policy.Categories))
+		for _, category := range policy.Categories {
+			categories = append(categories, bson.M{"metadata.category": categoryMatch(category, true)})
+		}
+		clauses = append(clauses, bson.M{"$or": categories})
+	}
+
+	filter := bson.M{
+		"metadata.deleted_at": nil,
+		"metadata.is_temp":    bson.M{"$ne": true},
+		"metadata.tier":       bson.M{"$ne": domain.TierCold},
+		"metadata.created_at": bson.M{"$lt": createdBefore},
+		"$and":                clauses,
+	}
+	return r.findArchives(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(listProjection(nil)))
+}
+
+// MoveToCold menyalin konten ke cold store, menandai metadata sebagai cold, lalu
+// menghapus chunks GridFS. Penandaan memakai versi yang dibaca, jadi upload versi
+// baru di tengah proses membuat pemindahan dibatalkan.
+func (r *ArchiveRepository) MoveToCold(ctx context.Context, id string) (*domain.Archive, error) {
+	if r.cold == nil {
+		return nil, domain.ErrColdStoreDisabled
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrArchiveNotFound
+	}
+
+	archive, err := r.findDocument(ctx, bson.M{"_id": objID, "metadata.deleted_at": nil})
+	if err != nil {
+		return nil, err
+	}
+	if archive.Cold() {
+		return archive, nil
+	}
+
+	content, err := r.readContent(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+	key := coldKey(archive)
+	if err := r.cold.Put(ctx, key, content); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updated := *archive
+	updated.Tier = domain.TierCold
+	updated.TieredAt = &now
+	result, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{
+			"_id":                 objID,
+			"metadata.version":    archive.Version,
+			"metadata.deleted_at": nil,
+			"metadata.tier":       bson.M{"$ne": domain.TierCold},
+		},
+		withChangeLog(bson.M{"$set": bson.M{
+			"metadata.tier":      domain.TierCold,
+			"metadata.tiered_at": now,
+		}}, CreateChangeLog(domain.ActionMoveToCold, domain.SystemUserID, archive, &updated)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark archive as cold: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		if err := r.cold.Delete(ctx, key); err != nil && !errors.Is(err, domain.ErrColdObjectNotFound) {
+			return nil, fmt.Errorf("failed to discard cold content: %v", err)
+		}
+		return nil, domain.ErrTierConflict
+	}
+
+	if _, err := r.bucket.GetChunksCollection().DeleteMany(ctx, bson.M{"files_id": contentID(archive)}); err != nil {
+		return nil, fmt.Errorf("failed to delete hot chunks: %v", err)
+	}
+
+	return &updated, nil
+}
+
+// Rehydrate menulis ulang chunks GridFS dari cold store dengan ID dan metadata yang
+// sama, lalu menghapus objek cold. Rehydrate paralel untuk arsip yang sama ditolak
+// dengan ErrTierConflict; konten tetap bisa dibaca langsung dari cold store.
+func (r *ArchiveRepository) Rehydrate(ctx context.Context, id string) (*domain.Archive, error) {
+	if r.cold == nil {
+		return nil, domain.ErrColdStoreDisabled
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrArchiveNotFound
+	}
+
+	var file bson.M
+	err = r.bucket.GetFilesCollection().FindOne(
+		ctx,
+		bson.M{"_id": objID},
+		options.FindOne().SetProjection(listProjection(nil)),
+	).Decode(&file)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrArchiveNotFound
+		}
+		return nil, fmt.Errorf("failed to find document: %v", err)
+	}
+	archive := mapToArchive(file)
+	if !archive.Cold() {
+		return nil, domain.ErrNotCold
+	}
+
+	now := time.Now()
+	claim, err := r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{
+			"_id":              objID,
+			"metadata.tier":    domain.TierCold,
+			"metadata.version": archive.Version,
+			"$or": []bson.M{
+				{"metadata.rehydrating_at": nil},
+				{"metadata.rehydrating_at": bson.M{"$lt": now.Add(-rehydrateClaimTTL)}},
+			},
+		},
+		bson.M{"$set": bson.M{"metadata.rehydrating_at": now}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim archive: %v", err)
+	}
+	if claim.MatchedCount == 0 {
+		return nil, domain.ErrTierConflict
+	}
+
+	if err := r.restoreChunks(ctx, &archive, int(int64Value(file["chunkSize"]))); err != nil {
+		// Klaim dilepas agar rehydrate bisa dicoba lagi
+		r.bucket.GetFilesCollection().UpdateOne(ctx, bson.M{"_id": objID},
+			bson.M{"$unset": bson.M{"metadata.rehydrating_at": ""}})
+		return nil, err
+	}
+
+	updated := archive
+	updated.Tier = ""
+	updated.TieredAt = nil
+	_, err = r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": objID, "metadata.tier": domain.TierCold},
+		withChangeLog(bson.M{"$unset": bson.M{
+			"metadata.tier":           "",
+			"metadata.tiered_at":      "",
+			"metadata.rehydrating_at": "",
+		}}, CreateChangeLog(domain.ActionRehydrate, domain.SystemUserID, &archive, &updated)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark archive as hot: %v", err)
+	}
+	if err := r.dropCold(ctx, &archive); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// restoreChunks memecah konten cold menjadi chunks GridFS dengan ukuran chunk asli file
+func (r *ArchiveRepository) restoreChunks(ctx context.Context, archive *domain.Archive, chunkSize int) error {
+	content, err := r.readContent(ctx, archive)
+	if err != nil {
+		return err
+	}
+	if int64(len(content)) != archive.Size {
+		return fmt.Errorf("cold content size mismatch: expected %d bytes, got %d", archive.Size, len(content))
+	}
+	if chunkSize <= 0 {
+		chunkSize = 1024 * 1024
+	}
+
+	// Sisa chunks dari percobaan sebelumnya yang gagal dibersihkan dulu
+	if _, err := r.bucket.GetChunksCollection().DeleteMany(ctx, bson.M{"files_id": contentID(archive)}); err != nil {
+		return fmt.Errorf("failed to clear chunks: %v", err)
+	}
+	return r.writeChunks(ctx, contentID(archive), content, chunkSize)
+}
+
+// TouchAccess tidak menaikkan metadata_revision, jadi ETag arsip tidak berubah
+func (r *ArchiveRepository) TouchAccess(ctx context.Context, id string, at time.Time) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrArchiveNotFound
+	}
+
+	_, err = r.bucket.GetFilesCollection().UpdateOne(
+		ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": bson.M{"metadata.last_accessed_at": at}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record access: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMoveToColdAndRehydrate(t *testing.T) {
+	repo := testRepository(t)
+	cold, err := NewFilesystemColdStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cold store: %v", err)
+	}
+	repo.UseColdStore(cold)
+	ctx := context.Background()
+	archive := saveArchive(t, repo, "arsip-lama.pdf", "alice", "isi yang jarang dibuka")
+	id := archive.ID.Hex()
+
+	moved, err := repo.MoveToCold(ctx, id)
+	if err != nil {
+		t.Fatalf("move to cold: %v", err)
+	}
+	if !moved.Cold() || moved.TieredAt == nil {
+		t.Fatalf("expected the archive to be cold, got tier %q", moved.Tier)
+	}
+	hotChunks, err := repo.bucket.GetChunksCollection().CountDocuments(ctx, bson.M{"files_id": contentID(moved)})
+	if err != nil || hotChunks != 0 {
+		t.Fatalf("expected the hot chunks to be removed, got %d (%v)", hotChunks, err)
+	}
+	// Konten cold tetap bisa dibaca langsung dari cold store
+	content, err := repo.readContent(ctx, moved)
+	if err != nil || string(content) != "isi yang jarang dibuka" {
+		t.Fatalf("expected to read the cold content, got %q (%v)", content, err)
+	}
+	// Memindahkan arsip yang sudah cold tidak melakukan apa-apa
+	if again, err := repo.MoveToCold(ctx, id); err != nil || !again.Cold() {
+		t.Fatalf("expected a cold archive to stay cold, got %v", err)
+	}
+
+	rehydrated, err := repo.Rehydrate(ctx, id)
+	if err != nil {
+		t.Fatalf("rehydrate: %v", err)
+	}
+	if rehydrated.Cold() {
+		t.Fatal("expected the archive to be hot after rehydrate")
+	}
+	current, err := repo.FindMetadata(ctx, id)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if current.Cold() || current.Version != archive.Version {
+		t.Fatalf("expected the same version back in the hot tier, got tier %q version %d", current.Tier, current.Version)
+	}
+	content, err = repo.readContent(ctx, current)
+	if err != nil || string(content) != "isi yang jarang dibuka" {
+		t.Fatalf("expected the hot content to be restored, got %q (%v)", content, err)
+	}
+	if _, err := cold.Get(ctx, coldKey(current)); !errors.Is(err, domain.ErrColdObjectNotFound) {
+		t.Fatalf("expected the cold object to be removed, got %v", err)
+	}
+	if _, err := repo.Rehydrate(ctx, id); !errors.Is(err, domain.ErrNotCold) {
+		t.Fatalf("expected ErrNotCold for a hot archive, got %v", err)
+	}
+
+	last := current.ChangeLogs[len(current.ChangeLogs)-1]
+	if last.Action != domain.ActionRehydrate {
+		t.Fatalf("expected a rehydrate change log entry, got %s", last.Action)
+	}
+}
+
+func TestTieringWithoutColdStoreIsDisabled(t *testing.T) {
+	repo := testRepository(t)
+	archive := saveArchive(t, repo, "memo.pdf", "alice", "isi")
+
+	if _, err := repo.MoveToCold(context.Background(), archive.ID.Hex()); !errors.Is(err, domain.ErrColdStoreDisabled) {
+		t.Fatalf("expected ErrColdStoreDisabled, got %v", err)
+	}
+	if _, err := repo.Rehydrate(context.Background(), archive.ID.Hex()); !errors.Is(err, domain.ErrColdStoreDisabled) {
+		t.Fatalf("expected ErrColdStoreDisabled, got %v", err)
+	}
+}
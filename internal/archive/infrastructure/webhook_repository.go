@@ -0,0 +1,280 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookSubscriptionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookSubscriptionRepository(client *mongo.Client, dbName string) (*WebhookSubscriptionRepository, error) {
+	collection := client.Database(dbName).Collection("webhook_subscriptions")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "active", Value: 1}, {Key: "events", Value: 1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription indexes: %v", err)
+	}
+
+	return &WebhookSubscriptionRepository{collection: collection}, nil
+}
+
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	if subscription.ID.IsZero() {
+		subscription.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, subscription); err != nil {
+		return fmt.Errorf("failed to insert webhook subscription: %v", err)
+	}
+	return nil
+}
+
+func (r *WebhookSubscriptionRepository) FindByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	var subscription domain.WebhookSubscription
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&subscription); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook subscription: %v", err)
+	}
+	return &subscription, nil
+}
+
+func (r *WebhookSubscriptionRepository) FindAll(ctx context.Context, page, limit int) ([]domain.WebhookSubscription, int64, error) {
+	filter := bson.M{}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook subscriptions: %v", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find webhook subscriptions: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	subscriptions := []domain.WebhookSubscription{}
+	if err := cur.All(ctx, &subscriptions); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode webhook subscriptions: %v", err)
+	}
+	return subscriptions, total, nil
+}
+
+func (r *WebhookSubscriptionRepository) FindByEvent(ctx context.Context, eventType string) ([]domain.WebhookSubscription, error) {
+	filter := bson.M{
+		"active": true,
+		"events": bson.M{"$in": []string{eventType, domain.EventAll}},
+	}
+
+	cur, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook subscriptions: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	subscriptions := []domain.WebhookSubscription{}
+	if err := cur.All(ctx, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook subscriptions: %v", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": subscription.ID}, bson.M{
+		"$set": bson.M{
+			"url":         subscription.URL,
+			"events":      subscription.Events,
+			"active":      subscription.Active,
+			"description": subscription.Description,
+			"updated_at":  subscription.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrWebhookNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// webhookDeliveryTTL adalah lama log delivery disimpan
+const webhookDeliveryTTL = 30 * 24 * time.Hour
+
+type WebhookDeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookDeliveryRepository(client *mongo.Client, dbName string) (*WebhookDeliveryRepository, error) {
+	collection := client.Database(dbName).Collection("webhook_deliveries")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// Worker mencari delivery pending yang sudah waktunya dikirim
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(webhookDeliveryTTL.Seconds())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery indexes: %v", err)
+	}
+
+	return &WebhookDeliveryRepository{collection: collection}, nil
+}
+
+func (r *WebhookDeliveryRepository) CreateMany(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(deliveries))
+	for i := range deliveries {
+		if deliveries[i].ID.IsZero() {
+			deliveries[i].ID = primitive.NewObjectID()
+		}
+		docs = append(docs, deliveries[i])
+	}
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to insert webhook deliveries: %v", err)
+	}
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) FindByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+
+	var delivery domain.WebhookDelivery
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&delivery); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook delivery: %v", err)
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookDeliveryRepository) FindBySubscription(ctx context.Context, subscriptionID string, status domain.DeliveryStatus, page, limit int) ([]domain.WebhookDelivery, int64, error) {
+	filter := bson.M{"subscription_id": subscriptionID}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %v", err)
+	}
+
+	// Payload dan riwayat percobaan cukup ditampilkan di detail delivery
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"payload": 0, "attempts": 0})
+
+	cur, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find webhook deliveries: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	deliveries := []domain.WebhookDelivery{}
+	if err := cur.All(ctx, &deliveries); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode webhook deliveries: %v", err)
+	}
+	return deliveries, total, nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*domain.WebhookDelivery, error) {
+	filter := bson.M{
+		"status":          domain.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+		// Kunci worker yang mati di tengah pengiriman lepas sendiri setelah lease habis
+		"$or": []bson.M{
+			{"locked_until": nil},
+			{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+
+	var delivery domain.WebhookDelivery
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{"locked_until": now.Add(lease)}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&delivery)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim webhook delivery: %v", err)
+	}
+	return &delivery, nil
+}
+
+// Update hanya menulis bila delivery masih dikunci dengan lease dari ClaimDue. Lease yang
+// sudah habis bisa diambil worker lain, jadi hasil percobaan ini dibuang agar riwayat
+// percobaan worker lain tidak tertimpa.
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	filter := bson.M{"_id": delivery.ID, "locked_until": delivery.LockedUntil}
+	delivery.LockedUntil = nil
+	result, err := r.collection.ReplaceOne(ctx, filter, delivery)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrWebhookLeaseLost
+	}
+	return nil
+}
@@ -7,33 +7,124 @@ import (
 )
 
 type Config struct {
-	ServerPort       int
-	MongoURI         string
-	DBName           string
-	BucketName       string
-	UploadDir        string
-	Host             string
-	AllowedTypes     []string
-	MaxUploadSize    int64
-	LogDir           string
-	LogFileFormat    string
-	LogRetentionDays int
-	LogLevel         string
+	ServerPort            int
+	MongoURI              string
+	DBName                string
+	BucketName            string
+	UploadDir             string
+	Host                  string
+	AllowedTypes          []string
+	MaxUploadSize         int64
+	LogDir                string
+	LogFileFormat         string
+	LogRetentionDays      int
+	LogLevel              string
+	TrashRetentionDays    int
+	SimilarityThreshold   float64
+	CheckoutTTLMinutes    int
+	TempDeleteTTLHours    int
+	TempDeleteMaxHours    int
+	DispositionSigningKey string `json:"-"`
+	LifecycleConfig       string
+	ColdTierBackend       string
+	ColdTierDir           string
+	ColdTierDB            string
+	ColdTierMinAgeDays    int
+	ColdTierIdleDays      int
+	ColdTierCategories    []string
+	ColdTierRetrieval     string
+	// Jadwal cron per job; string kosong berarti job hanya dijalankan manual
+	ScheduleExpiredFiles string
+	ScheduleTempFiles    string
+	ScheduleTrashPurge   string
+	ScheduleRetention    string
+	ScheduleDisposition  string
+	ScheduleTiering      string
+	ScheduleReminders    string
+	JobTimeoutMinutes    int
+	// InstanceID menamai replika di lease leader; kosong berarti hostname ditambah suffix acak
+	InstanceID         string
+	LeaderLeaseSeconds int
+	LeaderRenewSeconds int
+	// Reminder sebelum arsip kedaluwarsa atau di-purge
+	ReminderWindowHours int
+	ReminderSigningKey  string `json:"-"`
+	// PublicBaseURL adalah alamat API yang bisa dibuka user, dipakai untuk link di email
+	PublicBaseURL string
+	// SMTP kosong berarti pemberitahuan tidak dikirim lewat email
+	SMTPHost          string
+	SMTPPort          int
+	SMTPUsername      string
+	SMTPPassword      string `json:"-"`
+	SMTPFrom          string
+	NotifyEmailDomain string
+	NotifyWebhookURL  string
+	// Webhook event arsip: percobaan ulang dengan backoff eksponensial sebelum dead-letter
+	WebhookMaxAttempts    int
+	WebhookBackoffSeconds int
+	WebhookTimeoutSeconds int
+	WebhookWorkers        int
 }
 
 func Load() *Config {
 	allowedTypes := strings.Split(getEnvString("ALLOWED_TYPES", "application/pdf"), ",")
+	dbName := getEnvString("DB_NAME", "archive_db")
+	var coldCategories []string
+	for _, category := range strings.Split(getEnvString("COLD_TIER_CATEGORIES", ""), ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			coldCategories = append(coldCategories, category)
+		}
+	}
 	return &Config{
-		ServerPort:       getEnvInt("SERVER_PORT", 8080),
-		MongoURI:         getEnvString("MONGODB_URI", "mongodb://localhost:27017"),
-		DBName:           getEnvString("DB_NAME", "archive_db"),
-		Host:             getEnvString("HOST", "localhost"),
-		AllowedTypes:     allowedTypes,
-		MaxUploadSize:    int64(getEnvInt("MAX_UPLOAD_SIZE", 3145728)), // 3 MB
-		LogDir:           getEnvString("LOG_DIR", "logs"),
-		LogFileFormat:    getEnvString("LOG_FILE_FORMAT", "2006-01-02.log"),
-		LogRetentionDays: getEnvInt("LOG_RETENTION_DAYS", 7),
-		LogLevel:         getEnvString("LOG_LEVEL", "info"),
+		ServerPort:            getEnvInt("SERVER_PORT", 8080),
+		MongoURI:              getEnvString("MONGODB_URI", "mongodb://localhost:27017"),
+		DBName:                dbName,
+		Host:                  getEnvString("HOST", "localhost"),
+		AllowedTypes:          allowedTypes,
+		MaxUploadSize:         int64(getEnvInt("MAX_UPLOAD_SIZE", 3145728)), // 3 MB
+		LogDir:                getEnvString("LOG_DIR", "logs"),
+		LogFileFormat:         getEnvString("LOG_FILE_FORMAT", "2006-01-02.log"),
+		LogRetentionDays:      getEnvInt("LOG_RETENTION_DAYS", 7),
+		LogLevel:              getEnvString("LOG_LEVEL", "info"),
+		TrashRetentionDays:    getEnvInt("TRASH_RETENTION_DAYS", 30),
+		SimilarityThreshold:   getEnvFloat("SIMILARITY_THRESHOLD", 0.8),
+		CheckoutTTLMinutes:    getEnvInt("CHECKOUT_TTL_MINUTES", 120),
+		TempDeleteTTLHours:    getEnvInt("TEMP_DELETE_TTL_HOURS", 24),
+		TempDeleteMaxHours:    getEnvInt("TEMP_DELETE_MAX_HOURS", 720),
+		DispositionSigningKey: getEnvString("DISPOSITION_SIGNING_KEY", ""),
+		LifecycleConfig:       getEnvString("LIFECYCLE_CONFIG", ""),
+		ColdTierBackend:       getEnvString("COLD_TIER_BACKEND", ""),
+		ColdTierDir:           getEnvString("COLD_TIER_DIR", "cold"),
+		ColdTierDB:            getEnvString("COLD_TIER_DB", dbName+"_cold"),
+		ColdTierMinAgeDays:    getEnvInt("COLD_TIER_MIN_AGE_DAYS", 365),
+		ColdTierIdleDays:      getEnvInt("COLD_TIER_IDLE_DAYS", 180),
+		ColdTierCategories:    coldCategories,
+		ColdTierRetrieval:     getEnvString("COLD_TIER_RETRIEVAL", "sync"),
+		ScheduleExpiredFiles:  getEnvString("SCHEDULE_EXPIRED_FILES", "@hourly"),
+		ScheduleTempFiles:     getEnvString("SCHEDULE_TEMP_FILES", "@hourly"),
+		ScheduleTrashPurge:    getEnvString("SCHEDULE_TRASH_PURGE", "@hourly"),
+		ScheduleRetention:     getEnvString("SCHEDULE_RETENTION", "@hourly"),
+		ScheduleDisposition:   getEnvString("SCHEDULE_DISPOSITION", "@hourly"),
+		ScheduleTiering:       getEnvString("SCHEDULE_TIERING", "@hourly"),
+		ScheduleReminders:     getEnvString("SCHEDULE_REMINDERS", "@hourly"),
+		JobTimeoutMinutes:     getEnvInt("JOB_TIMEOUT_MINUTES", 5),
+		InstanceID:            getEnvString("INSTANCE_ID", ""),
+		LeaderLeaseSeconds:    getEnvInt("LEADER_LEASE_SECONDS", 15),
+		LeaderRenewSeconds:    getEnvInt("LEADER_RENEW_SECONDS", 5),
+		ReminderWindowHours:   getEnvInt("REMINDER_WINDOW_HOURS", 72),
+		ReminderSigningKey:    getEnvString("REMINDER_SIGNING_KEY", ""),
+		PublicBaseURL:         getEnvString("PUBLIC_BASE_URL", "http://localhost:8080"),
+		SMTPHost:              getEnvString("SMTP_HOST", ""),
+		SMTPPort:              getEnvInt("SMTP_PORT", 25),
+		SMTPUsername:          getEnvString("SMTP_USERNAME", ""),
+		SMTPPassword:          getEnvString("SMTP_PASSWORD", ""),
+		SMTPFrom:              getEnvString("SMTP_FROM", "archiven@localhost"),
+		NotifyEmailDomain:     getEnvString("NOTIFY_EMAIL_DOMAIN", ""),
+		NotifyWebhookURL:      getEnvString("NOTIFY_WEBHOOK_URL", ""),
+		WebhookMaxAttempts:    getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookBackoffSeconds: getEnvInt("WEBHOOK_BACKOFF_SECONDS", 30),
+		WebhookTimeoutSeconds: getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+		WebhookWorkers:        getEnvInt("WEBHOOK_WORKERS", 4),
 	}
 }
 
@@ -52,3 +143,12 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
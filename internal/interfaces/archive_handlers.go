@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
@@ -17,13 +18,14 @@ import (
 
 type ArchiveHandler struct {
 	service   *application.ArchiveService
+	tiering   *application.TieringService
 	validator *FileValidator
 	logger    *zap.Logger
 }
 
-func NewArchiveHandler(service *application.ArchiveService, validator *FileValidator,
+func NewArchiveHandler(service *application.ArchiveService, tiering *application.TieringService, validator *FileValidator,
 	logger *zap.Logger) *ArchiveHandler {
-	return &ArchiveHandler{service: service, validator: validator,
+	return &ArchiveHandler{service: service, tiering: tiering, validator: validator,
 		logger: logger}
 }
 
@@ -66,7 +68,7 @@ func (h *ArchiveHandler) Upload(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorOpenFile))
 	}
 
-	archive, errUpload := h.service.UploadArchive(c.Request().Context(), domain.FileContent{
+	result, errUpload := h.service.UploadArchive(c.Request().Context(), domain.FileContent{
 		Name:    req.File.Filename,
 		Content: content,
 		Size:    req.File.Size,
@@ -76,24 +78,80 @@ func (h *ArchiveHandler) Upload(c echo.Context) error {
 		Tags:        req.Tags,
 		Description: req.Description,
 		OwnerID:     userID,
-	})
+	}, parsePrecondition(c))
 
 	if errUpload != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorUploadToMongo))
+		if status, ok := concurrencyStatus(errUpload); ok {
+			return c.JSON(status, ErrorResponse(errUpload.Error()))
+		}
+		switch {
+		case errors.Is(errUpload, domain.ErrArchiveNotFound):
+			// Arsip yang akan diberi versi baru dihapus saat upload berjalan
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		case errors.Is(errUpload, domain.ErrInvalidCategory):
+			return c.JSON(http.StatusBadRequest, ErrorResponse("Invalid category"))
+		case errors.Is(errUpload, domain.ErrCategoryDeprecated):
+			return c.JSON(http.StatusBadRequest, ErrorResponse("Category is deprecated"))
+		case errors.Is(errUpload, domain.ErrNameConflict):
+			return c.JSON(http.StatusConflict, ErrorResponse(errUpload.Error()))
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorUploadToMongo))
+		}
 	}
 
 	h.logger.Info("Upload berhasil",
 		zap.String("filename", req.File.Filename),
 		zap.Duration("duration", time.Since(startTime)),
 	)
-	// Return success response
-	SuccessResponseData := NewSuccessResponseWithDataVersion(map[string]interface{}{
+	archive := result.Archive
+	c.Response().Header().Set("ETag", archive.ETag())
+	data := map[string]interface{}{
+		"id":      archive.ID.Hex(),
 		"version": archive.Version,
 		"isNew":   archive.Version == 1,
-	})
+	}
+	if len(result.Similar) > 0 {
+		h.logger.Warn("Upload mirip dengan arsip lain",
+			zap.String("filename", req.File.Filename),
+			zap.String("similar_id", result.Similar[0].Archive.ID.Hex()),
+			zap.Float64("score", result.Similar[0].Score),
+		)
+		data["warning"] = ResponseWarningSimilarArchive
+		data["similar"] = ToSimilarArchiveResponses(result.Similar)
+	}
+
+	// Return success response
+	SuccessResponseData := NewSuccessResponseWithDataVersion(data)
 
 	return c.JSON(http.StatusCreated, SuccessResponseData)
 }
+
+// parsePrecondition membaca header If-Match, nilainya bisa lebih dari satu dipisah koma
+func parsePrecondition(c echo.Context) domain.Precondition {
+	var cond domain.Precondition
+	for _, tag := range strings.Split(c.Request().Header.Get("If-Match"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cond.IfMatch = append(cond.IfMatch, tag)
+		}
+	}
+	return cond
+}
+
+// concurrencyStatus memetakan error If-Match, lock, penulisan bersamaan dan konten yang dibekukan ke status HTTP
+func concurrencyStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, true
+	case errors.Is(err, domain.ErrArchiveLocked):
+		return http.StatusLocked, true
+	case errors.Is(err, domain.ErrVersionConflict),
+		errors.Is(err, domain.ErrContentFrozen):
+		return http.StatusConflict, true
+	default:
+		return 0, false
+	}
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -103,10 +161,8 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
-func (h *ArchiveHandler) List(c echo.Context) error {
-	// Error NewErrorResponseBuilder
-	ErrorResponse := NewErrorResponseBuilder()
-
+// parsePagination membaca page & limit dengan default yang sama seperti listing lain
+func parsePagination(c echo.Context) (int, int) {
 	page, _ := strconv.Atoi(c.QueryParam("page"))
 	if page < 1 {
 		page = 1
@@ -116,15 +172,145 @@ func (h *ArchiveHandler) List(c echo.Context) error {
 	if limit < 1 || limit > 100 {
 		limit = 10
 	}
+	return page, limit
+}
+
+func paginationResponse(page, limit int, total int64) map[string]interface{} {
+	return map[string]interface{}{
+		"page":       page,
+		"limit":      limit,
+		"totalData":  total,
+		"totalPages": (total + int64(limit) - 1) / int64(limit),
+	}
+}
+
+// parseArchiveFilter membaca filter listing dari query string
+func parseArchiveFilter(c echo.Context) (domain.ArchiveFilter, error) {
+	filter := domain.ArchiveFilter{
+		Category:           c.QueryParam("category"),
+		IncludeDescendants: c.QueryParam("include_descendants") == "true",
+		Type:               c.QueryParam("type"),
+		Tags:               c.QueryParams()["tag"],
+		OwnerID:            c.QueryParam("owner_id"),
+		Query:              c.QueryParam("q"),
+		Period:             c.QueryParam("period"),
+		HideSuperseded:     c.QueryParam("hide_superseded") == "true",
+		FolderID:           c.QueryParam("folder_id"),
+		LegalHold:          c.QueryParam("legal_hold"),
+		Scheduled:          c.QueryParam("scheduled") == "true",
+		State:              domain.LifecycleState(c.QueryParam("state")),
+		Tier:               domain.StorageTier(c.QueryParam("tier")),
+	}
+	if userID, ok := c.Get("user_id").(string); ok {
+		filter.Viewer = userID
+	}
 
-	archives, total, err := h.service.ListArchives(c.Request().Context(), page, limit)
+	switch {
+	case c.QueryParam("only_deleted") == "true":
+		filter.Deleted = domain.DeletedOnly
+	case c.QueryParam("include_deleted") == "true":
+		filter.Deleted = domain.DeletedIncluded
+	}
+
+	for param, target := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		t, err := parseDateParam(value)
+		if err != nil {
+			return filter, err
+		}
+		*target = &t
+	}
+
+	return filter, filter.Validate()
+}
+
+// parseFields membaca daftar atribut dari fields=name,size,tags
+func parseFields(c echo.Context) []string {
+	raw := c.QueryParam("fields")
+	if raw == "" {
+		return nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func parseDateParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
 	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return t, nil
+}
+
+// parseTTL membaca durasi Go (72h, 90m) atau jumlah hari (3d); kosong berarti default
+func parseTTL(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	var ttl time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, ErrInvalidTTL
+		}
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, ErrInvalidTTL
+		}
+		ttl = parsed
+	}
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+	return ttl, nil
+}
+
+func (h *ArchiveHandler) List(c echo.Context) error {
+	// Error NewErrorResponseBuilder
+	ErrorResponse := NewErrorResponseBuilder()
+
+	page, limit := parsePagination(c)
+
+	filter, err := parseArchiveFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	fields := parseFields(c)
+
+	archives, total, err := h.service.ListArchives(c.Request().Context(), filter, fields, page, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownField) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorListArchive))
 	}
 
 	// Convert archives to response format
-	var response []ArchiveResponse
+	var response []interface{}
 	for _, a := range archives {
+		if len(fields) > 0 {
+			response = append(response, ToSparseArchiveResponse(&a, fields))
+			continue
+		}
 		response = append(response, ToArchiveResponse(&a))
 	}
 
@@ -142,7 +328,11 @@ func (h *ArchiveHandler) List(c echo.Context) error {
 func (h *ArchiveHandler) Download(c echo.Context) error {
 	id := c.Param("id")
 
-	_, buf, err := h.service.GetArchive(c.Request().Context(), id)
+	userID := c.Get("user_id").(string)
+	archive, buf, err := h.service.GetArchive(c.Request().Context(), id, userID)
+	if errors.Is(err, domain.ErrArchiveCold) {
+		return h.retrieveCold(c, id, userID)
+	}
 	if err != nil {
 		errBuilder := NewErrorResponseBuilder()
 		switch {
@@ -157,9 +347,35 @@ func (h *ArchiveHandler) Download(c echo.Context) error {
 		}
 	}
 
+	c.Response().Header().Set("ETag", archive.ETag())
 	return c.Blob(200, "application/octet-stream", buf)
 }
 
+// retrieveCold menjawab download arsip cold dengan retrieval job; client mengecek
+// status job lalu mengulang download setelah job selesai
+func (h *ArchiveHandler) retrieveCold(c echo.Context, id, userID string) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	job, err := h.tiering.Retrieve(c.Request().Context(), id, userID)
+	if errors.Is(err, domain.ErrNotCold) {
+		// Arsip baru saja dikembalikan ke hot tier, download bisa langsung diulang
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	}
+	if err != nil {
+		h.logger.Error("Gagal membuat retrieval job",
+			zap.String("archive_id", id),
+			zap.Error(err),
+		)
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorTiering))
+	}
+
+	c.Response().Header().Set("Location", "/storage/retrievals/"+job.ID.Hex())
+	return c.JSON(http.StatusAccepted, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"message": domain.ErrArchiveCold.Error(),
+		"job":     job,
+	}))
+}
+
 // Tambahkan handler baru
 func (h *ArchiveHandler) GetByIDs(c echo.Context) error {
 	ErrorResponse := NewErrorResponseBuilder()
@@ -170,7 +386,7 @@ func (h *ArchiveHandler) GetByIDs(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse("Missing IDs parameter"))
 	}
 
-	archives, err := h.service.GetArchivesByIDs(c.Request().Context(), ids)
+	archives, err := h.service.GetArchivesByIDs(c.Request().Context(), ids, c.Get("user_id").(string))
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrArchiveNotFound):
@@ -211,19 +427,29 @@ func (h *ArchiveHandler) DeleteArchive(c echo.Context) error {
 
 	ctx := c.Request().Context()
 
-	// Convert permanent bool to DeleteType
+	// Convert permanent bool and mode to DeleteType
 	deleteType := domain.SoftDelete
-	if permanent {
+	switch mode := c.QueryParam("mode"); {
+	case permanent, mode == "permanent", mode == "hard":
+		permanent = true
 		deleteType = domain.HardDelete
+	case mode == "temporary":
+		return h.scheduleDeletion(c, id)
+	case mode != "" && mode != "soft":
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ErrInvalidDeleteMode.Error()))
 	}
 
-	err := h.service.DeleteArchive(ctx, id, deleteType)
+	err := h.service.DeleteArchive(ctx, id, deleteType, c.Get("user_id").(string))
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrArchiveNotFound):
 			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
 		case errors.Is(err, domain.ErrAlreadyDeleted):
 			return c.JSON(http.StatusBadRequest, ErrorResponse("File already deleted"))
+		case errors.Is(err, domain.ErrUnderLegalHold):
+			return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+		case errors.Is(err, domain.ErrCertificateProtected):
+			return c.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse("Failed to delete file"))
 		}
@@ -239,31 +465,172 @@ func (h *ArchiveHandler) DeleteArchive(c echo.Context) error {
 	})
 }
 
-func (h *ArchiveHandler) RestoreArchive(c echo.Context) error {
-	id := c.Param("id")
-	//ErrorResponse := NewErrorResponseBuilder()
+// scheduleDeletion menjalankan temp delete; arsip tetap bisa diunduh sampai expires_at
+func (h *ArchiveHandler) scheduleDeletion(c echo.Context, id string) error {
+	ErrorResponse := NewErrorResponseBuilder()
 
-	err := h.service.RestoreArchive(c.Request().Context(), id)
+	ttl, err := parseTTL(c.QueryParam("ttl"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"status": "error",
-			"error":  err.Error(),
-		})
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
 	}
+
+	userID := c.Get("user_id").(string)
+	archive, err := h.service.ScheduleDeletion(c.Request().Context(), id, ttl, userID)
+	if err != nil {
+		return h.scheduleError(c, err)
+	}
+
+	h.logger.Info("Arsip dijadwalkan untuk dihapus",
+		zap.String("id", id),
+		zap.Timep("expires_at", archive.ExpiresAt),
+		zap.String("user_id", userID),
+	)
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"status": "success",
 		"data": map[string]interface{}{
-			"message": "File restored successfully",
-			"id":      id,
+			"message":    "File scheduled for deletion",
+			"permanent":  false,
+			"mode":       domain.TempDelete.String(),
+			"id":         id,
+			"expires_at": archive.ExpiresAt,
 		},
 	})
 }
 
+// ExtendDeletion menunda jadwal temp delete sebesar ttl
+func (h *ArchiveHandler) ExtendDeletion(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req ScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+	ttl, err := parseTTL(req.TTL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	id := c.Param("id")
+	userID := c.Get("user_id").(string)
+	archive, err := h.service.ExtendDeletion(c.Request().Context(), id, ttl, userID)
+	if err != nil {
+		return h.scheduleError(c, err)
+	}
+
+	h.logger.Info("Jadwal penghapusan arsip diperpanjang",
+		zap.String("id", id),
+		zap.Timep("expires_at", archive.ExpiresAt),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"archive": ToArchiveResponse(archive),
+	}))
+}
+
+func (h *ArchiveHandler) CancelDeletion(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("user_id").(string)
+
+	archive, err := h.service.CancelDeletion(c.Request().Context(), id, userID)
+	if err != nil {
+		return h.scheduleError(c, err)
+	}
+
+	h.logger.Info("Jadwal penghapusan arsip dibatalkan",
+		zap.String("id", id),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"archive": ToArchiveResponse(archive),
+	}))
+}
+
+func (h *ArchiveHandler) scheduleError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrAlreadyExpire):
+		return c.JSON(http.StatusForbidden, ErrorResponse("File has expired"))
+	case errors.Is(err, domain.ErrAlreadyDeleted),
+		errors.Is(err, domain.ErrNotScheduled),
+		errors.Is(err, domain.ErrUnderLegalHold),
+		errors.Is(err, domain.ErrVersionConflict):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrArchiveLocked):
+		return c.JSON(http.StatusLocked, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrCertificateProtected):
+		return c.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Gagal mengubah jadwal penghapusan arsip", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse("Failed to schedule deletion"))
+	}
+}
+
+// RestoreArchive memulihkan arsip dari trash. ?strategy=rename|merge menentukan hasilnya bila
+// nama arsip sudah dipakai arsip aktif lain; tanpa strategy restore ditolak dengan 409.
+func (h *ArchiveHandler) RestoreArchive(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("user_id").(string)
+	ErrorResponse := NewErrorResponseBuilder()
+
+	result, err := h.service.RestoreArchive(c.Request().Context(), id, userID, domain.RestoreStrategy(c.QueryParam("strategy")))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArchiveNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		case errors.Is(err, domain.ErrInvalidRestoreStrategy):
+			return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		case errors.Is(err, domain.ErrRestoreConflict) && result != nil:
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"status":      "error",
+				"message":     err.Error(),
+				"conflict_id": result.ConflictID,
+			})
+		case errors.Is(err, domain.ErrNotDeleted),
+			errors.Is(err, domain.ErrRestoreConflict):
+			return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+		}
+		if status, ok := concurrencyStatus(err); ok {
+			return c.JSON(status, ErrorResponse(err.Error()))
+		}
+		h.logger.Error("Gagal memulihkan arsip", zap.String("id", id), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse("Failed to restore file"))
+	}
+
+	if result.Strategy != "" {
+		h.logger.Info("Arsip dipulihkan dengan konflik nama",
+			zap.String("id", id),
+			zap.String("strategy", string(result.Strategy)),
+			zap.String("conflict_id", result.ConflictID),
+			zap.String("user_id", userID),
+		)
+	}
+
+	data := map[string]interface{}{
+		"message": "File restored successfully",
+		"id":      id,
+		"archive": ToArchiveResponse(result.Archive),
+	}
+	if result.Strategy != "" {
+		data["strategy"] = result.Strategy
+		data["conflict_id"] = result.ConflictID
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data":   data,
+	})
+}
+
 func (h *ArchiveHandler) GetHistory(c echo.Context) error {
 	id := c.Param("id")
 	ErrorResponse := NewErrorResponseBuilder()
 
-	history, err := h.service.GetHistory(c.Request().Context(), id)
+	history, err := h.service.GetHistory(c.Request().Context(), id, c.Get("user_id").(string))
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrArchiveNotFound):
@@ -284,6 +651,114 @@ func (h *ArchiveHandler) GetHistory(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+func (h *ArchiveHandler) ListVersions(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	versions, err := h.service.ListVersions(c.Request().Context(), id, c.Get("user_id").(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArchiveNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorGetArchive))
+		}
+	}
+	if len(versions) == 0 {
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	}
+
+	responses := make([]ArchiveResponse, 0, len(versions))
+	for i := range versions {
+		responses = append(responses, ToArchiveResponse(&versions[i]))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":              id,
+		"current_version": versions[0].Version,
+		"data":            responses,
+	})
+}
+
+func (h *ArchiveHandler) GetDiff(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	from, errFrom := strconv.Atoi(c.QueryParam("from"))
+	to, errTo := strconv.Atoi(c.QueryParam("to"))
+	if errFrom != nil || errTo != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse("Missing from or to parameter"))
+	}
+
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "patch" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse("Invalid format, use json or patch"))
+	}
+
+	diff, err := h.service.CompareVersions(c.Request().Context(), id, from, to, c.Get("user_id").(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArchiveNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		case errors.Is(err, domain.ErrRevisionNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+		case errors.Is(err, domain.ErrInvalidVersion):
+			return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		default:
+			h.logger.Error("Gagal membandingkan versi", zap.String("id", id), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorDiff))
+		}
+	}
+
+	if format == "patch" {
+		return c.String(http.StatusOK, ToVersionDiffPatch(diff))
+	}
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"diff": ToVersionDiffResponse(diff),
+	}))
+}
+
+func (h *ArchiveHandler) Rollback(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req RollbackRequest
+	if err := c.Bind(&req); err != nil || req.Version <= 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse("Missing version parameter"))
+	}
+
+	userID := c.Get("user_id").(string)
+	archive, err := h.service.RollbackArchive(c.Request().Context(), id, req.Version, userID)
+	if err != nil {
+		if status, ok := concurrencyStatus(err); ok {
+			return c.JSON(status, ErrorResponse(err.Error()))
+		}
+		switch {
+		case errors.Is(err, domain.ErrArchiveNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		case errors.Is(err, domain.ErrRevisionNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+		case errors.Is(err, domain.ErrInvalidVersion):
+			return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		default:
+			h.logger.Error("Gagal rollback arsip", zap.String("id", id), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorRollback))
+		}
+	}
+
+	h.logger.Info("Arsip di-rollback",
+		zap.String("id", id),
+		zap.Int("target_version", req.Version),
+		zap.Int("version", archive.Version),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"archive":        ToArchiveResponse(archive),
+		"rolled_back_to": req.Version,
+	}))
+}
+
 func (h *ArchiveHandler) GetByCategory(c echo.Context) error {
 	category := c.Param("category")
 	ErrorResponse := NewErrorResponseBuilder()
@@ -298,7 +773,9 @@ func (h *ArchiveHandler) GetByCategory(c echo.Context) error {
 		limit = 10
 	}
 
-	archives, total, err := h.service.GetByCategory(c.Request().Context(), category, page, limit)
+	includeDescendants := c.QueryParam("include_descendants") == "true"
+
+	archives, total, err := h.service.GetByCategory(c.Request().Context(), category, includeDescendants, c.Get("user_id").(string), page, limit)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInvalidCategory):
@@ -322,7 +799,8 @@ func (h *ArchiveHandler) GetByCategory(c echo.Context) error {
 			"total_data":  total,
 			"total_pages": (total + int64(limit) - 1) / int64(limit),
 		},
-		"category": category,
+		"category":            category,
+		"include_descendants": includeDescendants,
 	})
 }
 
@@ -331,7 +809,7 @@ func (h *ArchiveHandler) GetByTags(c echo.Context) error {
 	page, _ := strconv.Atoi(c.QueryParam("page"))
 	limit, _ := strconv.Atoi(c.QueryParam("limit"))
 
-	archives, total, err := h.service.GetByTags(c.Request().Context(), tags, page, limit)
+	archives, total, err := h.service.GetByTags(c.Request().Context(), tags, c.Get("user_id").(string), page, limit)
 	if err != nil {
 		return h.validator.MapDomainError(err)
 	}
@@ -344,3 +822,277 @@ func (h *ArchiveHandler) GetByTags(c echo.Context) error {
 		"tags":  tags,
 	})
 }
+
+func (h *ArchiveHandler) ListTrash(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	page, limit := parsePagination(c)
+
+	items, total, err := h.service.ListTrash(c.Request().Context(), c.Get("user_id").(string), page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorListArchive))
+	}
+
+	var response []TrashItemResponse
+	for _, item := range items {
+		response = append(response, ToTrashItemResponse(item))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": response,
+		"pagination": map[string]interface{}{
+			"page":       page,
+			"limit":      limit,
+			"totalData":  total,
+			"totalPages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+func (h *ArchiveHandler) RestoreTrash(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req RestoreTrashRequest
+	if err := c.Bind(&req); err != nil || len(req.IDs) == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse("Missing IDs parameter"))
+	}
+
+	results, err := h.service.RestoreArchives(c.Request().Context(), req.IDs, c.Get("user_id").(string), req.Strategy)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+
+	h.logger.Info("Restore massal dari trash",
+		zap.Int("requested", len(req.IDs)),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"results": results,
+		},
+	})
+}
+
+func (h *ArchiveHandler) PurgeTrash(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req BulkIDsRequest
+	if err := c.Bind(&req); err != nil || len(req.IDs) == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse("Missing IDs parameter"))
+	}
+
+	results := h.service.PurgeArchives(c.Request().Context(), req.IDs, c.Get("user_id").(string))
+
+	h.logger.Info("Purge massal dari trash",
+		zap.Int("requested", len(req.IDs)),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"results": results,
+		},
+	})
+}
+
+func (h *ArchiveHandler) PurgeTrashItem(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	if err := h.service.PurgeArchive(c.Request().Context(), id, c.Get("user_id").(string)); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArchiveNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		case errors.Is(err, domain.ErrNotDeleted):
+			return c.JSON(http.StatusConflict, ErrorResponse("File is not in trash"))
+		case errors.Is(err, domain.ErrUnderLegalHold):
+			return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+		case errors.Is(err, domain.ErrCertificateProtected):
+			return c.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse("Failed to purge file"))
+		}
+	}
+
+	h.logger.Info("Arsip dihapus permanen dari trash",
+		zap.String("id", id),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"message": "File purged successfully",
+			"id":      id,
+		},
+	})
+}
+
+func (h *ArchiveHandler) GetSimilar(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	threshold, _ := strconv.ParseFloat(c.QueryParam("threshold"), 64)
+
+	similar, err := h.service.FindSimilar(c.Request().Context(), id, threshold, c.Get("user_id").(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrArchiveNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		case errors.Is(err, domain.ErrNoSignature):
+			return c.JSON(http.StatusUnprocessableEntity, ErrorResponse(err.Error()))
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorGetArchive))
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":   id,
+		"data": ToSimilarArchiveResponses(similar),
+	})
+}
+
+func (h *ArchiveHandler) UpdateMetadata(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+	ctx := c.Request().Context()
+
+	var req UpdateMetadataRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	current, err := h.service.GetArchiveInfo(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrArchiveNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorGetArchive))
+	}
+
+	// Validasi metadata hasil gabungan data lama dan perubahan
+	patch := req.ToPatch()
+	merged := *current
+	patch.Apply(&merged)
+	if err := h.validator.ValidateMetadata(domain.ArchiveMetadata{
+		Name:        merged.Name,
+		Category:    merged.Category,
+		Type:        merged.Type,
+		Tags:        merged.Tags,
+		Description: merged.Description,
+		OwnerID:     merged.OwnerID,
+	}); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	}
+	if req.Name != nil {
+		if err := h.validator.ValidateFileName(*req.Name); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		}
+	}
+
+	archive, err := h.service.UpdateArchive(ctx, id, patch, userID, parsePrecondition(c))
+	if err != nil {
+		if status, ok := concurrencyStatus(err); ok {
+			return c.JSON(status, ErrorResponse(err.Error()))
+		}
+		switch {
+		case errors.Is(err, domain.ErrArchiveNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+		case errors.Is(err, domain.ErrInvalidCategory), errors.Is(err, domain.ErrCategoryDeprecated):
+			return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+		case errors.Is(err, domain.ErrNameConflict):
+			return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+		default:
+			h.logger.Error("Gagal mengubah metadata", zap.String("id", id), zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorUpdateMetadata))
+		}
+	}
+
+	h.logger.Info("Metadata arsip diubah",
+		zap.String("id", id),
+		zap.Int("metadata_revision", archive.MetadataRevision),
+		zap.String("user_id", userID),
+	)
+
+	c.Response().Header().Set("ETag", archive.ETag())
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"archive": ToArchiveResponse(archive),
+	}))
+}
+
+func (h *ArchiveHandler) Checkout(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req CheckoutRequest
+	if err := c.Bind(&req); err != nil || req.TTLMinutes < 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	archive, err := h.service.Checkout(c.Request().Context(), id, userID, time.Duration(req.TTLMinutes)*time.Minute)
+	if err != nil {
+		return h.lockError(c, id, err)
+	}
+
+	h.logger.Info("Arsip di-checkout",
+		zap.String("id", id),
+		zap.String("user_id", userID),
+		zap.Time("expires_at", archive.Lock.ExpiresAt),
+	)
+
+	c.Response().Header().Set("ETag", archive.ETag())
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"archive": ToArchiveResponse(archive),
+	}))
+}
+
+func (h *ArchiveHandler) Checkin(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("user_id").(string)
+
+	if err := h.service.Checkin(c.Request().Context(), id, userID); err != nil {
+		return h.lockError(c, id, err)
+	}
+
+	h.logger.Info("Arsip di-checkin", zap.String("id", id), zap.String("user_id", userID))
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"message": "Archive checked in",
+		"id":      id,
+	}))
+}
+
+func (h *ArchiveHandler) BreakLock(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("user_id").(string)
+
+	if err := h.service.BreakLock(c.Request().Context(), id, userID); err != nil {
+		return h.lockError(c, id, err)
+	}
+
+	h.logger.Warn("Lock arsip dilepas paksa", zap.String("id", id), zap.String("user_id", userID))
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"message": "Archive lock released",
+		"id":      id,
+	}))
+}
+
+func (h *ArchiveHandler) lockError(c echo.Context, id string, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+	if status, ok := concurrencyStatus(err); ok {
+		return c.JSON(status, ErrorResponse(err.Error()))
+	}
+	switch {
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrNotCheckedOut):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Gagal memproses lock arsip", zap.String("id", id), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorLock))
+	}
+}
@@ -1,6 +1,12 @@
 package interfaces
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
 
 type UploadRequest struct {
 	File        *multipart.FileHeader `form:"file"`
@@ -9,3 +15,170 @@ type UploadRequest struct {
 	Tags        []string              `form:"tags"`
 	Description string                `form:"description"`
 }
+
+type CategoryRequest struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	ParentID    string  `json:"parent_id"`
+}
+
+type MoveCategoryRequest struct {
+	ParentID string `json:"parent_id"`
+}
+
+type FolderRequest struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+}
+
+type MoveFolderRequest struct {
+	ParentID string `json:"parent_id"`
+}
+
+type SavedSearchRequest struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Filter      domain.ArchiveFilter `json:"filter"`
+}
+
+type ShareSavedSearchRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+type BulkIDsRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// RestoreTrashRequest memulihkan beberapa arsip sekaligus; strategy berlaku untuk arsip
+// yang namanya sudah dipakai arsip aktif lain
+type RestoreTrashRequest struct {
+	IDs      []string               `json:"ids"`
+	Strategy domain.RestoreStrategy `json:"strategy"`
+}
+
+type BulkEditArchivesRequest struct {
+	IDs       []string                 `json:"ids"`
+	Filter    *domain.ArchiveFilter    `json:"filter"`
+	Operation domain.BulkEditOperation `json:"operation"`
+	DryRun    bool                     `json:"dry_run"`
+}
+
+type RelationRequest struct {
+	TargetID string `json:"target_id"`
+	Type     string `json:"type"`
+}
+
+type CommentRequest struct {
+	Body     string                `json:"body"`
+	ParentID string                `json:"parent_id"`
+	Anchor   *domain.CommentAnchor `json:"anchor"`
+}
+
+type LegalHoldRequest struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	IDs         []string              `json:"ids"`
+	Filter      *domain.ArchiveFilter `json:"filter"`
+}
+
+type LegalHoldArchivesRequest struct {
+	IDs    []string              `json:"ids"`
+	Filter *domain.ArchiveFilter `json:"filter"`
+}
+
+type ReleaseLegalHoldRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RetentionPolicyRequest struct {
+	Name     string                  `json:"name"`
+	Category string                  `json:"category"`
+	Type     string                  `json:"type"`
+	Period   domain.RetentionPeriod  `json:"period"`
+	Trigger  domain.RetentionTrigger `json:"trigger"`
+	Action   domain.RetentionAction  `json:"action"`
+}
+
+func (r RetentionPolicyRequest) ToPolicy() domain.RetentionPolicy {
+	return domain.RetentionPolicy{
+		Name:     strings.TrimSpace(r.Name),
+		Category: r.Category,
+		Type:     r.Type,
+		Period:   r.Period,
+		Trigger:  r.Trigger,
+		Action:   r.Action,
+	}
+}
+
+type DispositionDecisionRequest struct {
+	IDs      []string `json:"ids"`
+	Decision string   `json:"decision"`
+	// Until wajib untuk defer, Category wajib untuk transfer
+	Until    *time.Time `json:"until"`
+	Category string     `json:"category"`
+}
+
+type TransitionRequest struct {
+	Transition string   `json:"transition"`
+	Reviewers  []string `json:"reviewers"`
+	Comment    string   `json:"comment"`
+}
+
+type RollbackRequest struct {
+	Version int `json:"version"`
+}
+
+type ScheduleRequest struct {
+	// TTL adalah tambahan masa tenggang, misalnya "48h" atau "3d"
+	TTL string `json:"ttl"`
+}
+
+type CheckoutRequest struct {
+	TTLMinutes int `json:"ttl_minutes"`
+}
+
+type UpdateMetadataRequest struct {
+	Name        *string   `json:"name"`
+	Category    *string   `json:"category"`
+	Type        *string   `json:"type"`
+	Tags        *[]string `json:"tags"`
+	Description *string   `json:"description"`
+	// EventDate adalah tanggal pemicu retensi "event", misalnya akhir kontrak
+	EventDate *time.Time `json:"event_date"`
+}
+
+func (r UpdateMetadataRequest) ToPatch() domain.ArchivePatch {
+	return domain.ArchivePatch{
+		Name:        r.Name,
+		Category:    r.Category,
+		Type:        r.Type,
+		Tags:        r.Tags,
+		Description: r.Description,
+		EventDate:   r.EventDate,
+	}
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret kosong berarti dibuatkan server dan ditampilkan sekali di response
+	Secret      string `json:"secret"`
+	Description string `json:"description"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+	Description *string  `json:"description"`
+}
+
+func (r UpdateWebhookRequest) ToPatch() domain.WebhookSubscriptionPatch {
+	return domain.WebhookSubscriptionPatch{
+		URL:         r.URL,
+		Events:      r.Events,
+		Active:      r.Active,
+		Description: r.Description,
+	}
+}
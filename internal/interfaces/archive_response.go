@@ -2,6 +2,9 @@ package interfaces
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
@@ -20,6 +23,26 @@ const (
 	ResponseErrorUploadFile       = "failed to upload file"
 	ResponseErrorHeaderRead       = "failed to read header"
 	ResponseErrorValidationStages = "failed validation stage"
+	ResponseErrorListCategory     = "failed to get list categories"
+	ResponseErrorCategory         = "failed to process category"
+	ResponseErrorSavedSearch      = "failed to process saved search"
+	ResponseErrorUpdateMetadata   = "failed to update archive metadata"
+	ResponseErrorRollback         = "failed to rollback archive"
+	ResponseErrorDiff             = "failed to compare archive versions"
+	ResponseErrorLock             = "failed to process archive lock"
+	ResponseErrorRelation         = "failed to process archive relation"
+	ResponseErrorFolder           = "failed to process folder"
+	ResponseErrorComment          = "failed to process comment"
+	ResponseErrorBulkJob          = "failed to process bulk job"
+	ResponseErrorRetention        = "failed to process retention"
+	ResponseErrorLegalHold        = "failed to process legal hold"
+	ResponseErrorDisposition      = "failed to process disposition"
+	ResponseErrorLifecycle        = "failed to process lifecycle transition"
+	ResponseErrorTiering          = "failed to process storage tiering"
+	ResponseErrorJob              = "failed to process scheduled job"
+	ResponseErrorLeader           = "failed to read leader status"
+	ResponseErrorReminder         = "failed to process reminder link"
+	ResponseErrorWebhook          = "failed to process webhook"
 )
 
 var (
@@ -37,6 +60,9 @@ var (
 	ErrTooManyTags       = errors.New("too many tags, maximum 5 allowed")
 	ErrTypeRequired      = errors.New("type is required")
 	ErrTagsRequired      = errors.New("tags are required")
+	ErrInvalidDate       = errors.New("invalid date, use RFC3339 or YYYY-MM-DD")
+	ErrInvalidTTL        = errors.New("invalid ttl, use a duration such as 72h or 3d")
+	ErrInvalidDeleteMode = errors.New("invalid delete mode, use soft, permanent or temporary")
 )
 
 // Success response
@@ -44,34 +70,216 @@ const (
 	ResponseSuccessUpload = "File uploaded successfully"
 )
 
+// Warning response
+const (
+	ResponseWarningSimilarArchive = "file closely matches an existing archive"
+)
+
 type ArchiveResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Size        int64     `json:"size"`
-	SizeMB      string    `json:"size_mb"`
-	Category    string    `json:"category"`
-	Type        string    `json:"type"`
-	Tags        []string  `json:"tags"`
-	Description string    `json:"description"`
-	Version     int       `json:"version"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Size         int64                  `json:"size"`
+	SizeMB       string                 `json:"size_mb"`
+	Category     string                 `json:"category"`
+	Type         string                 `json:"type"`
+	Tags         []string               `json:"tags"`
+	Description  string                 `json:"description"`
+	Version      int                    `json:"version"`
+	Revision     int                    `json:"metadata_revision"`
+	ETag         string                 `json:"etag"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	DeletedAt    *time.Time             `json:"deleted_at,omitempty"`
+	Lock         *domain.ArchiveLock    `json:"lock,omitempty"`
+	SupersededBy string                 `json:"superseded_by,omitempty"`
+	FolderID     string                 `json:"folder_id,omitempty"`
+	EventDate    *time.Time             `json:"event_date,omitempty"`
+	ExpiresAt    *time.Time             `json:"expires_at,omitempty"`
+	Retention    *domain.RetentionState `json:"retention,omitempty"`
+	LegalHolds   []string               `json:"legal_holds,omitempty"`
+	State        domain.LifecycleState  `json:"state,omitempty"`
+	Reviewers    []string               `json:"reviewers,omitempty"`
+	Tier         domain.StorageTier     `json:"tier"`
+	TieredAt     *time.Time             `json:"tiered_at,omitempty"`
 }
 
 func ToArchiveResponse(a *domain.Archive) ArchiveResponse {
-	return ArchiveResponse{
-		ID:          a.ID.Hex(),
-		Name:        a.Name,
-		Size:        a.Size,
-		SizeMB:      a.SizeMB,
-		Category:    a.Category,
-		Type:        a.Type,
-		Tags:        a.Tags,
-		Description: a.Description,
-		Version:     a.Version,
-		CreatedAt:   a.CreatedAt,
-		UpdatedAt:   a.UpdatedAt,
+	response := ArchiveResponse{
+		ID:           a.ID.Hex(),
+		Name:         a.Name,
+		Size:         a.Size,
+		SizeMB:       a.SizeMB,
+		Category:     a.Category,
+		Type:         a.Type,
+		Tags:         a.Tags,
+		Description:  a.Description,
+		Version:      a.Version,
+		Revision:     a.MetadataRevision,
+		ETag:         a.ETag(),
+		CreatedAt:    a.CreatedAt,
+		UpdatedAt:    a.UpdatedAt,
+		DeletedAt:    a.DeletedAt,
+		SupersededBy: a.SupersededBy,
+		FolderID:     a.FolderID,
+		EventDate:    a.EventDate,
+		ExpiresAt:    a.ExpiresAt,
+		Retention:    a.Retention,
+		LegalHolds:   a.LegalHolds,
+		State:        a.State,
+		Reviewers:    a.Reviewers,
+		Tier:         a.CurrentTier(),
+		TieredAt:     a.TieredAt,
+	}
+	// Lock yang sudah kedaluwarsa tidak lagi berlaku
+	if a.Lock.Active(time.Now()) {
+		response.Lock = a.Lock
+	}
+	return response
+}
+
+// ToSparseArchiveResponse hanya mengisi atribut yang diminta lewat fields=
+func ToSparseArchiveResponse(a *domain.Archive, fields []string) map[string]interface{} {
+	response := map[string]interface{}{"id": a.ID.Hex()}
+	for _, field := range fields {
+		switch field {
+		case "name":
+			response[field] = a.Name
+		case "size":
+			response[field] = a.Size
+		case "size_mb":
+			response[field] = a.SizeMB
+		case "category":
+			response[field] = a.Category
+		case "type":
+			response[field] = a.Type
+		case "tags":
+			response[field] = a.Tags
+		case "description":
+			response[field] = a.Description
+		case "owner_id":
+			response[field] = a.OwnerID
+		case "version":
+			response[field] = a.Version
+		case "metadata_revision":
+			response[field] = a.MetadataRevision
+		case "created_at":
+			response[field] = a.CreatedAt
+		case "updated_at":
+			response[field] = a.UpdatedAt
+		case "deleted_at":
+			response[field] = a.DeletedAt
+		case "deleted_by":
+			response[field] = a.DeletedBy
+		case "expires_at":
+			response[field] = a.ExpiresAt
+		case "is_temp":
+			response[field] = a.IsTemp
+		case "change_logs":
+			response[field] = a.ChangeLogs
+		case "superseded_by":
+			response[field] = a.SupersededBy
+		case "folder_id":
+			response[field] = a.FolderID
+		case "event_date":
+			response[field] = a.EventDate
+		case "retention":
+			response[field] = a.Retention
+		case "legal_holds":
+			response[field] = a.LegalHolds
+		case "state":
+			response[field] = a.State
+		case "reviewers":
+			response[field] = a.Reviewers
+		case "tier":
+			response[field] = a.CurrentTier()
+		case "tiered_at":
+			response[field] = a.TieredAt
+		case "last_accessed_at":
+			response[field] = a.LastAccessedAt
+		}
+	}
+	return response
+}
+
+type TrashItemResponse struct {
+	ArchiveResponse
+	DeletedBy string    `json:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+func ToTrashItemResponse(item domain.TrashItem) TrashItemResponse {
+	return TrashItemResponse{
+		ArchiveResponse: ToArchiveResponse(&item.Archive),
+		DeletedBy:       item.Archive.DeletedBy,
+		PurgeAt:         item.PurgeAt,
+	}
+}
+
+type SimilarArchiveResponse struct {
+	ArchiveResponse
+	Score float64 `json:"score"`
+}
+
+func ToSimilarArchiveResponses(similar []domain.SimilarArchive) []SimilarArchiveResponse {
+	response := make([]SimilarArchiveResponse, 0, len(similar))
+	for _, s := range similar {
+		response = append(response, SimilarArchiveResponse{
+			ArchiveResponse: ToArchiveResponse(&s.Archive),
+			Score:           math.Round(s.Score*1000) / 1000,
+		})
+	}
+	return response
+}
+
+type VersionDiffResponse struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	From           int             `json:"from"`
+	To             int             `json:"to"`
+	Metadata       []domain.Change `json:"metadata"`
+	SizeFrom       int64           `json:"size_from"`
+	SizeTo         int64           `json:"size_to"`
+	ChecksumFrom   string          `json:"checksum_from"`
+	ChecksumTo     string          `json:"checksum_to"`
+	ContentChanged bool            `json:"content_changed"`
+	TextDiff       string          `json:"text_diff"`
+}
+
+func ToVersionDiffResponse(d *domain.VersionDiff) VersionDiffResponse {
+	return VersionDiffResponse{
+		ID:             d.ArchiveID,
+		Name:           d.Name,
+		From:           d.From,
+		To:             d.To,
+		Metadata:       d.Metadata,
+		SizeFrom:       d.SizeFrom,
+		SizeTo:         d.SizeTo,
+		ChecksumFrom:   d.ChecksumFrom,
+		ChecksumTo:     d.ChecksumTo,
+		ContentChanged: d.ContentChanged,
+		TextDiff:       d.TextDiff,
+	}
+}
+
+// ToVersionDiffPatch menulis perbandingan versi sebagai patch teks. Ringkasan
+// metadata ditulis sebelum header "---" sehingga patch tetap bisa dibaca tool diff.
+func ToVersionDiffPatch(d *domain.VersionDiff) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Archive: %s (%s)\n", d.Name, d.ArchiveID)
+	fmt.Fprintf(&out, "Version: %d -> %d\n", d.From, d.To)
+	fmt.Fprintf(&out, "Size: %d -> %d\n", d.SizeFrom, d.SizeTo)
+	fmt.Fprintf(&out, "Checksum: %s -> %s\n", d.ChecksumFrom, d.ChecksumTo)
+	if len(d.Metadata) > 0 {
+		out.WriteString("Metadata:\n")
+		for _, change := range d.Metadata {
+			fmt.Fprintf(&out, "  %s: %v -> %v\n", change.Field, change.OldValue, change.NewValue)
+		}
+	}
+	if d.TextDiff != "" {
+		out.WriteString("\n")
+		out.WriteString(d.TextDiff)
 	}
+	return out.String()
 }
 
 type SuccessResponse struct {
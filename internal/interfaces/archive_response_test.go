@@ -0,0 +1,81 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
+
+func testVersionDiff() *domain.VersionDiff {
+	return &domain.VersionDiff{
+		ArchiveID:      "65f000000000000000000001",
+		Name:           "laporan.pdf",
+		From:           1,
+		To:             2,
+		Metadata:       []domain.Change{{Field: "description", OldValue: "draf", NewValue: "final"}},
+		SizeFrom:       10,
+		SizeTo:         12,
+		ChecksumFrom:   "aaa",
+		ChecksumTo:     "bbb",
+		ContentChanged: true,
+		TextDiff:       "--- laporan.pdf@v1\n+++ laporan.pdf@v2\n@@ -1,1 +1,1 @@\n-Biaya 50\n+Biaya 70\n",
+	}
+}
+
+func TestVersionDiffPatch(t *testing.T) {
+	want := "Archive: laporan.pdf (65f000000000000000000001)\n" +
+		"Version: 1 -> 2\n" +
+		"Size: 10 -> 12\n" +
+		"Checksum: aaa -> bbb\n" +
+		"Metadata:\n" +
+		"  description: draf -> final\n" +
+		"\n" +
+		"--- laporan.pdf@v1\n+++ laporan.pdf@v2\n@@ -1,1 +1,1 @@\n-Biaya 50\n+Biaya 70\n"
+	if got := ToVersionDiffPatch(testVersionDiff()); got != want {
+		t.Fatalf("unexpected patch:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Tanpa perubahan metadata dan teks hanya ringkasan yang ditulis
+	diff := testVersionDiff()
+	diff.Metadata = nil
+	diff.TextDiff = ""
+	want = "Archive: laporan.pdf (65f000000000000000000001)\nVersion: 1 -> 2\nSize: 10 -> 12\nChecksum: aaa -> bbb\n"
+	if got := ToVersionDiffPatch(diff); got != want {
+		t.Fatalf("unexpected patch:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestVersionDiffResponseJSON(t *testing.T) {
+	body, err := json.Marshal(ToVersionDiffResponse(testVersionDiff()))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	for key, want := range map[string]interface{}{
+		"id":              "65f000000000000000000001",
+		"name":            "laporan.pdf",
+		"from":            float64(1),
+		"to":              float64(2),
+		"size_from":       float64(10),
+		"size_to":         float64(12),
+		"checksum_from":   "aaa",
+		"checksum_to":     "bbb",
+		"content_changed": true,
+	} {
+		if got[key] != want {
+			t.Fatalf("%s: expected %v, got %v", key, want, got[key])
+		}
+	}
+	if got["text_diff"] != testVersionDiff().TextDiff {
+		t.Fatalf("unexpected text_diff %v", got["text_diff"])
+	}
+	metadata, ok := got["metadata"].([]interface{})
+	if !ok || len(metadata) != 1 {
+		t.Fatalf("expected one metadata change, got %v", got["metadata"])
+	}
+}
@@ -0,0 +1,116 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type BulkHandler struct {
+	service *application.BulkEditService
+	logger  *zap.Logger
+}
+
+func NewBulkHandler(service *application.BulkEditService, logger *zap.Logger) *BulkHandler {
+	return &BulkHandler{service: service, logger: logger}
+}
+
+// EditMetadata menjalankan edit metadata massal sebagai job, atau hanya
+// menghitung dampaknya bila dry_run true
+func (h *BulkHandler) EditMetadata(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req BulkEditArchivesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	if req.Filter != nil {
+		// Draft user lain tidak ikut terpilih lewat filter
+		req.Filter.Viewer = userID
+	}
+	input := application.BulkEditRequest{
+		IDs:       req.IDs,
+		Filter:    req.Filter,
+		Operation: req.Operation,
+	}
+
+	if req.DryRun {
+		preview, err := h.service.Preview(c.Request().Context(), input, userID)
+		if err != nil {
+			return h.bulkError(c, err)
+		}
+		return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+			"dry_run": true,
+			"preview": preview,
+		}))
+	}
+
+	job, err := h.service.Start(c.Request().Context(), input, userID)
+	if err != nil {
+		return h.bulkError(c, err)
+	}
+
+	h.logger.Info("Job edit metadata massal dibuat",
+		zap.String("job_id", job.ID.Hex()),
+		zap.Int("total", job.Total),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusAccepted, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"job": job,
+	}))
+}
+
+func (h *BulkHandler) ListJobs(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	jobs, total, err := h.service.List(c.Request().Context(), c.Get("user_id").(string), page, limit)
+	if err != nil {
+		return h.bulkError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       jobs,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *BulkHandler) GetJob(c echo.Context) error {
+	job, err := h.service.Get(c.Request().Context(), c.Param("id"), c.Get("user_id").(string))
+	if err != nil {
+		return h.bulkError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": job,
+	})
+}
+
+func (h *BulkHandler) bulkError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrBulkJobNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrBulkTooManyItems):
+		return c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrEmptyBulkOperation),
+		errors.Is(err, domain.ErrInvalidBulkOperation),
+		errors.Is(err, domain.ErrBulkTargetRequired),
+		errors.Is(err, domain.ErrInvalidCategory),
+		errors.Is(err, domain.ErrCategoryDeprecated),
+		errors.Is(err, domain.ErrInvalidPeriod),
+		errors.Is(err, domain.ErrInvalidDateRange),
+		errors.Is(err, domain.ErrInvalidDeletedScope):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi massal gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorBulkJob))
+	}
+}
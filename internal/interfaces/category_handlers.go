@@ -0,0 +1,163 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type CategoryHandler struct {
+	service *application.CategoryService
+	logger  *zap.Logger
+}
+
+func NewCategoryHandler(service *application.CategoryService, logger *zap.Logger) *CategoryHandler {
+	return &CategoryHandler{service: service, logger: logger}
+}
+
+func (h *CategoryHandler) Create(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req CategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	category, err := h.service.Create(c.Request().Context(), application.CategoryInput{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}, userID)
+	if err != nil {
+		return h.categoryError(c, err)
+	}
+
+	h.logger.Info("Kategori dibuat",
+		zap.String("path", category.Path),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"category": category,
+	}))
+}
+
+func (h *CategoryHandler) Tree(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	includeDeprecated := c.QueryParam("include_deprecated") == "true"
+	tree, err := h.service.Tree(c.Request().Context(), includeDeprecated)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorListCategory))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": tree,
+	})
+}
+
+func (h *CategoryHandler) Get(c echo.Context) error {
+	category, err := h.service.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.categoryError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": category,
+	})
+}
+
+func (h *CategoryHandler) Rename(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req CategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	result, err := h.service.Rename(c.Request().Context(), c.Param("id"), application.CategoryInput{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+	}, userID)
+	if err != nil {
+		return h.categoryError(c, err)
+	}
+
+	h.logger.Info("Kategori diubah",
+		zap.String("path", result.Category.Path),
+		zap.Int64("archives_modified", result.ArchivesModified),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"result": result,
+	}))
+}
+
+func (h *CategoryHandler) Move(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req MoveCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	result, err := h.service.Move(c.Request().Context(), c.Param("id"), req.ParentID, userID)
+	if err != nil {
+		return h.categoryError(c, err)
+	}
+
+	h.logger.Info("Kategori dipindahkan",
+		zap.String("path", result.Category.Path),
+		zap.Int64("archives_modified", result.ArchivesModified),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"result": result,
+	}))
+}
+
+func (h *CategoryHandler) Deprecate(c echo.Context) error {
+	category, err := h.service.Deprecate(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.categoryError(c, err)
+	}
+
+	h.logger.Info("Kategori dinonaktifkan",
+		zap.String("path", category.Path),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"category": category,
+	}))
+}
+
+func (h *CategoryHandler) categoryError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrCategoryExists):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrInvalidCategoryCode),
+		errors.Is(err, domain.ErrInvalidCategoryMove),
+		errors.Is(err, domain.ErrCategoryNameRequired),
+		errors.Is(err, domain.ErrCategoryDeprecated):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi kategori gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorCategory))
+	}
+}
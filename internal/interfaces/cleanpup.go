@@ -1,51 +1,80 @@
 package interfaces
 
 import (
-	"context"
 	"time"
 
 	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"github.com/yhartanto178dev/archiven-api/internal/configs"
 	"go.uber.org/zap"
 )
 
-func startCleanupTask(service *application.ArchiveService, interval time.Duration, logger *zap.Logger) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		for range ticker.C {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+// cleanupJob menghubungkan job dengan jadwalnya di konfigurasi
+type cleanupJob struct {
+	name     string
+	schedule string
+	job      application.Job
+}
 
-			count, err := service.CleanupExpiredFiles(ctx)
-			if err != nil {
-				logger.Error("Failed to cleanup expired files",
-					zap.Error(err),
-					zap.String("task", "cleanup"),
-					zap.Time("timestamp", time.Now()),
-				)
-			} else {
-				logger.Info("Successfully cleaned up expired files",
-					zap.Int64("files_removed", count),
-					zap.String("task", "cleanup"),
-					zap.Time("timestamp", time.Now()),
-				)
-			}
+// registerCleanupJobs mendaftarkan job pembersihan, retensi, disposisi, tiering dan reminder ke
+// scheduler. Jadwal kosong berarti job hanya bisa dijalankan manual lewat endpoint admin.
+func registerCleanupJobs(scheduler *application.Scheduler, cfg *configs.Config, service *application.ArchiveService,
+	retention *application.RetentionService, disposition *application.DispositionService,
+	tiering *application.TieringService, tieringEnabled bool, reminders *application.ReminderService, logger *zap.Logger) error {
+	jobs := []cleanupJob{
+		{domain.JobExpiredFiles, cfg.ScheduleExpiredFiles, application.ExpiredFilesJob(service)},
+		{domain.JobTempFiles, cfg.ScheduleTempFiles, application.TempFilesJob(service)},
+		{domain.JobTrashPurge, cfg.ScheduleTrashPurge, application.TrashPurgeJob(service)},
+		{domain.JobRetention, cfg.ScheduleRetention, application.RetentionJob(retention)},
+		{domain.JobDisposition, cfg.ScheduleDisposition, application.DispositionJob(disposition)},
+		{domain.JobReminders, cfg.ScheduleReminders, application.ReminderJob(reminders)},
+	}
+	// Tanpa cold store, job tiering tidak punya pekerjaan
+	if tieringEnabled {
+		jobs = append(jobs, cleanupJob{domain.JobTiering, cfg.ScheduleTiering, application.TieringJob(tiering)})
+	}
 
-			// Cleanup temporary files separately
-			tempCount, err := service.CleanupTempFiles(ctx)
+	for _, job := range jobs {
+		var schedule *domain.CronSchedule
+		if job.schedule != "" {
+			parsed, err := domain.ParseCron(job.schedule)
 			if err != nil {
-				logger.Error("Failed to cleanup temporary files",
-					zap.Error(err),
-					zap.String("task", "temp_cleanup"),
-					zap.Time("timestamp", time.Now()),
-				)
-			} else {
-				logger.Info("Successfully cleaned up temporary files",
-					zap.Int64("temp_files_removed", tempCount),
-					zap.String("task", "temp_cleanup"),
-					zap.Time("timestamp", time.Now()),
+				logger.Error("Jadwal job tidak valid",
+					zap.String("job", job.name),
+					zap.String("schedule", job.schedule),
 				)
+				return err
 			}
-
-			cancel()
+			schedule = parsed
 		}
-	}()
+		scheduler.Register(job.name, schedule, job.job)
+	}
+
+	scheduler.OnFinish(func(run *domain.JobRun, saveErr error) {
+		logJobRun(logger, run, saveErr)
+	})
+	return nil
+}
+
+func logJobRun(logger *zap.Logger, run *domain.JobRun, saveErr error) {
+	fields := []zap.Field{
+		zap.String("task", run.Job),
+		zap.String("trigger", string(run.Trigger)),
+		zap.Bool("dry_run", run.DryRun),
+		zap.Any("counts", run.Counts),
+		zap.Int64("duration_ms", run.DurationMS),
+		zap.Time("timestamp", time.Now()),
+	}
+
+	switch {
+	case run.Error != "":
+		logger.Error("Job failed", append(fields, zap.String("error", run.Error))...)
+	case run.Warning != "":
+		logger.Warn("Job finished with warning", append(fields, zap.String("warning", run.Warning))...)
+	default:
+		logger.Info("Job finished", fields...)
+	}
+	if saveErr != nil {
+		logger.Error("Gagal menyimpan riwayat job", zap.String("task", run.Job), zap.Error(saveErr))
+	}
 }
@@ -0,0 +1,177 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type CommentHandler struct {
+	service *application.CommentService
+	logger  *zap.Logger
+}
+
+func NewCommentHandler(service *application.CommentService, logger *zap.Logger) *CommentHandler {
+	return &CommentHandler{service: service, logger: logger}
+}
+
+func (h *CommentHandler) Create(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	comment, err := h.service.Create(c.Request().Context(), id, application.CommentInput{
+		Body:     req.Body,
+		ParentID: req.ParentID,
+		Anchor:   req.Anchor,
+	}, userID)
+	if err != nil {
+		return h.commentError(c, err)
+	}
+
+	h.logger.Info("Komentar ditambahkan",
+		zap.String("archive_id", id),
+		zap.String("comment_id", comment.ID.Hex()),
+		zap.Strings("mentions", comment.Mentions),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"comment": comment,
+	}))
+}
+
+func (h *CommentHandler) List(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var resolved *bool
+	if raw := c.QueryParam("resolved"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse("Invalid resolved parameter"))
+		}
+		resolved = &value
+	}
+
+	threads, err := h.service.List(c.Request().Context(), id, resolved, c.Get("user_id").(string))
+	if err != nil {
+		return h.commentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":   id,
+		"data": threads,
+	})
+}
+
+func (h *CommentHandler) Edit(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	comment, err := h.service.Edit(c.Request().Context(), c.Param("id"), c.Param("commentId"), req.Body, c.Get("user_id").(string))
+	if err != nil {
+		return h.commentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"comment": comment,
+	}))
+}
+
+func (h *CommentHandler) Delete(c echo.Context) error {
+	commentID := c.Param("commentId")
+
+	if err := h.service.Delete(c.Request().Context(), c.Param("id"), commentID, c.Get("user_id").(string)); err != nil {
+		return h.commentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"message": "Comment deleted successfully",
+			"id":      commentID,
+		},
+	})
+}
+
+func (h *CommentHandler) Resolve(c echo.Context) error {
+	return h.setResolved(c, true)
+}
+
+func (h *CommentHandler) Reopen(c echo.Context) error {
+	return h.setResolved(c, false)
+}
+
+func (h *CommentHandler) setResolved(c echo.Context, resolved bool) error {
+	comment, err := h.service.SetResolved(c.Request().Context(), c.Param("id"), c.Param("commentId"), resolved, c.Get("user_id").(string))
+	if err != nil {
+		return h.commentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"comment": comment,
+	}))
+}
+
+// Annotations mengekspor anotasi satu versi sebagai file JSON
+func (h *CommentHandler) Annotations(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	version := 0
+	if raw := c.QueryParam("version"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse("Invalid version parameter"))
+		}
+		version = v
+	}
+
+	export, err := h.service.Annotations(c.Request().Context(), id, version, c.Get("user_id").(string))
+	if err != nil {
+		return h.commentError(c, err)
+	}
+
+	c.Response().Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="annotations-%s-v%d.json"`, export.ArchiveID, export.Version))
+	return c.JSON(http.StatusOK, export)
+}
+
+func (h *CommentHandler) commentError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrCommentNotFound), errors.Is(err, domain.ErrRevisionNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrCommentForbidden):
+		return c.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrCommentDeleted):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrCommentBodyRequired),
+		errors.Is(err, domain.ErrInvalidAnchor),
+		errors.Is(err, domain.ErrInvalidReply),
+		errors.Is(err, domain.ErrResolveReply):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi komentar gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorComment))
+	}
+}
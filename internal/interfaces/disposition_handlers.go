@@ -0,0 +1,154 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type DispositionHandler struct {
+	service *application.DispositionService
+	logger  *zap.Logger
+}
+
+func NewDispositionHandler(service *application.DispositionService, logger *zap.Logger) *DispositionHandler {
+	return &DispositionHandler{service: service, logger: logger}
+}
+
+func (h *DispositionHandler) Queue(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	archives, total, err := h.service.Queue(c.Request().Context(), page, limit)
+	if err != nil {
+		return h.dispositionError(c, err)
+	}
+
+	responses := make([]ArchiveResponse, 0, len(archives))
+	for i := range archives {
+		responses = append(responses, ToArchiveResponse(&archives[i]))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       responses,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *DispositionHandler) Decide(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req DispositionDecisionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	results, err := h.service.Decide(c.Request().Context(), application.DispositionDecision{
+		IDs:      req.IDs,
+		Decision: req.Decision,
+		Until:    req.Until,
+		Category: req.Category,
+	}, userID)
+	if err != nil {
+		return h.dispositionError(c, err)
+	}
+
+	h.logger.Info("Keputusan disposisi dicatat",
+		zap.String("decision", req.Decision),
+		zap.Int("count", len(results)),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"results": results,
+	}))
+}
+
+// Execute memusnahkan arsip yang sudah disetujui tanpa menunggu cleanup task
+func (h *DispositionHandler) Execute(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	result, err := h.service.Execute(c.Request().Context(), userID)
+	if err != nil {
+		return h.dispositionError(c, err)
+	}
+	if result == nil {
+		result = &application.DispositionResult{Failures: []application.BulkItemResult{}}
+	}
+
+	if result.Certificate != nil {
+		h.logger.Info("Pemusnahan arsip dijalankan",
+			zap.String("certificate_id", result.Certificate.ID.Hex()),
+			zap.Int("destroyed", len(result.Certificate.Items)),
+			zap.Int("failed", len(result.Failures)),
+			zap.String("user_id", userID),
+		)
+	}
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"certificate": result.Certificate,
+		"failures":    result.Failures,
+	}))
+}
+
+func (h *DispositionHandler) ListCertificates(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	certs, total, err := h.service.Certificates(c.Request().Context(), page, limit)
+	if err != nil {
+		return h.dispositionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       certs,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *DispositionHandler) GetCertificate(c echo.Context) error {
+	cert, err := h.service.Certificate(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.dispositionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": cert,
+	})
+}
+
+func (h *DispositionHandler) VerifyCertificate(c echo.Context) error {
+	verification, err := h.service.Verify(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.dispositionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": verification,
+	})
+}
+
+func (h *DispositionHandler) dispositionError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrCertificateNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrInvalidReviewDecision),
+		errors.Is(err, domain.ErrDispositionDateRequired),
+		errors.Is(err, domain.ErrTransferCategoryRequired),
+		errors.Is(err, domain.ErrInvalidCategory),
+		errors.Is(err, domain.ErrCategoryDeprecated),
+		errors.Is(err, domain.ErrBulkTargetRequired),
+		errors.Is(err, domain.ErrBulkTooManyItems):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrSigningKeyMissing):
+		h.logger.Error("Kunci tanda tangan sertifikat belum dikonfigurasi", zap.Error(err))
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi disposisi gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorDisposition))
+	}
+}
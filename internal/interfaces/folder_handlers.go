@@ -0,0 +1,219 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type FolderHandler struct {
+	service *application.FolderService
+	logger  *zap.Logger
+}
+
+func NewFolderHandler(service *application.FolderService, logger *zap.Logger) *FolderHandler {
+	return &FolderHandler{service: service, logger: logger}
+}
+
+func (h *FolderHandler) Create(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req FolderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	folder, err := h.service.Create(c.Request().Context(), req.Name, req.ParentID, userID)
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	h.logger.Info("Folder dibuat",
+		zap.String("folder_id", folder.ID.Hex()),
+		zap.String("name", folder.Name),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"folder": folder,
+	}))
+}
+
+func (h *FolderHandler) Get(c echo.Context) error {
+	folder, breadcrumbs, err := h.service.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":        folder,
+		"breadcrumbs": breadcrumbs,
+	})
+}
+
+// Contents menampilkan isi folder; tanpa :id menampilkan level root
+func (h *FolderHandler) Contents(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	contents, err := h.service.Contents(c.Request().Context(), c.Param("id"), c.Get("user_id").(string), page, limit)
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	archives := make([]ArchiveResponse, 0, len(contents.Archives))
+	for _, a := range contents.Archives {
+		archives = append(archives, ToArchiveResponse(&a))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"folder":      contents.Folder,
+		"breadcrumbs": contents.Breadcrumbs,
+		"folders": map[string]interface{}{
+			"data":       contents.Folders,
+			"pagination": paginationResponse(page, limit, contents.FolderTotal),
+		},
+		"archives": map[string]interface{}{
+			"data":       archives,
+			"pagination": paginationResponse(page, limit, contents.ArchiveTotal),
+		},
+	})
+}
+
+func (h *FolderHandler) Rename(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req FolderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	folder, err := h.service.Rename(c.Request().Context(), c.Param("id"), req.Name)
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"folder": folder,
+	}))
+}
+
+func (h *FolderHandler) Move(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req MoveFolderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	folder, err := h.service.Move(c.Request().Context(), c.Param("id"), req.ParentID)
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	h.logger.Info("Folder dipindahkan",
+		zap.String("folder_id", folder.ID.Hex()),
+		zap.String("parent_id", req.ParentID),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"folder": folder,
+	}))
+}
+
+func (h *FolderHandler) Delete(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	result, err := h.service.Delete(c.Request().Context(), c.Param("id"), userID)
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	h.logger.Info("Folder dihapus",
+		zap.String("folder_id", c.Param("id")),
+		zap.Int("folders", result.FoldersAffected),
+		zap.Int64("archives", result.ArchivesAffected),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"message": "Folder deleted successfully",
+		"result":  result,
+	}))
+}
+
+func (h *FolderHandler) Restore(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	result, err := h.service.Restore(c.Request().Context(), c.Param("id"), userID)
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	h.logger.Info("Folder dipulihkan",
+		zap.String("folder_id", c.Param("id")),
+		zap.Int("folders", result.FoldersAffected),
+		zap.Int64("archives", result.ArchivesAffected),
+		zap.Strings("conflicts", result.Conflicts),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"message": "Folder restored successfully",
+		"result":  result,
+	}))
+}
+
+// MoveArchives memindahkan arsip ke folder :id ("root" untuk keluar dari folder)
+func (h *FolderHandler) MoveArchives(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req BulkIDsRequest
+	if err := c.Bind(&req); err != nil || len(req.IDs) == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse("Missing IDs parameter"))
+	}
+
+	userID := c.Get("user_id").(string)
+	results, err := h.service.MoveArchives(c.Request().Context(), c.Param("id"), req.IDs, userID)
+	if err != nil {
+		return h.folderError(c, err)
+	}
+
+	h.logger.Info("Arsip dipindahkan ke folder",
+		zap.String("folder_id", c.Param("id")),
+		zap.Int("requested", len(req.IDs)),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"results": results,
+		},
+	})
+}
+
+func (h *FolderHandler) folderError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrFolderNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrFolderExists),
+		errors.Is(err, domain.ErrFolderDeleted),
+		errors.Is(err, domain.ErrFolderNotDeleted),
+		errors.Is(err, domain.ErrFolderParentDeleted),
+		errors.Is(err, domain.ErrUnderLegalHold):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrArchiveLocked):
+		return c.JSON(http.StatusLocked, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrFolderNameRequired), errors.Is(err, domain.ErrInvalidFolderMove):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi folder gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorFolder))
+	}
+}
@@ -0,0 +1,177 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type LegalHoldHandler struct {
+	service *application.LegalHoldService
+	logger  *zap.Logger
+}
+
+func NewLegalHoldHandler(service *application.LegalHoldService, logger *zap.Logger) *LegalHoldHandler {
+	return &LegalHoldHandler{service: service, logger: logger}
+}
+
+func (h *LegalHoldHandler) Create(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req LegalHoldRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	target := application.LegalHoldTarget{IDs: req.IDs, Filter: req.Filter}
+	result, err := h.service.Create(c.Request().Context(), req.Name, req.Description, target, userID)
+	if err != nil {
+		return h.holdError(c, err)
+	}
+
+	h.logger.Info("Legal hold dibuat",
+		zap.String("hold_id", result.Hold.ID.Hex()),
+		zap.String("name", result.Hold.Name),
+		zap.Int("matched", result.Matched),
+		zap.Int64("applied", result.Applied),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"hold":    result.Hold,
+		"matched": result.Matched,
+		"applied": result.Applied,
+	}))
+}
+
+func (h *LegalHoldHandler) List(c echo.Context) error {
+	page, limit := parsePagination(c)
+	status := domain.LegalHoldStatus(c.QueryParam("status"))
+
+	holds, total, err := h.service.List(c.Request().Context(), status, page, limit)
+	if err != nil {
+		return h.holdError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       holds,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *LegalHoldHandler) Get(c echo.Context) error {
+	hold, err := h.service.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.holdError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": hold,
+	})
+}
+
+func (h *LegalHoldHandler) Archives(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	archives, total, err := h.service.Archives(c.Request().Context(), c.Param("id"), page, limit)
+	if err != nil {
+		return h.holdError(c, err)
+	}
+
+	responses := make([]ArchiveResponse, 0, len(archives))
+	for i := range archives {
+		responses = append(responses, ToArchiveResponse(&archives[i]))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       responses,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *LegalHoldHandler) AddArchives(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req LegalHoldArchivesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	target := application.LegalHoldTarget{IDs: req.IDs, Filter: req.Filter}
+	result, err := h.service.AddArchives(c.Request().Context(), c.Param("id"), target, userID)
+	if err != nil {
+		return h.holdError(c, err)
+	}
+
+	h.logger.Info("Arsip ditambahkan ke legal hold",
+		zap.String("hold_id", result.Hold.ID.Hex()),
+		zap.Int("matched", result.Matched),
+		zap.Int64("applied", result.Applied),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"hold":    result.Hold,
+		"matched": result.Matched,
+		"applied": result.Applied,
+	}))
+}
+
+func (h *LegalHoldHandler) Release(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req ReleaseLegalHoldRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	hold, released, err := h.service.Release(c.Request().Context(), c.Param("id"), req.Reason, userID)
+	if err != nil {
+		return h.holdError(c, err)
+	}
+
+	h.logger.Warn("Legal hold dilepas",
+		zap.String("hold_id", hold.ID.Hex()),
+		zap.String("name", hold.Name),
+		zap.String("reason", hold.ReleaseReason),
+		zap.Int64("archives_released", released),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"hold":     hold,
+		"released": released,
+	}))
+}
+
+func (h *LegalHoldHandler) holdError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrLegalHoldNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrLegalHoldReleased):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrBulkTooManyItems):
+		return c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrLegalHoldNameRequired),
+		errors.Is(err, domain.ErrReleaseReasonRequired),
+		errors.Is(err, domain.ErrHoldTargetRequired),
+		errors.Is(err, domain.ErrInvalidPeriod),
+		errors.Is(err, domain.ErrInvalidDateRange),
+		errors.Is(err, domain.ErrInvalidDeletedScope):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi legal hold gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorLegalHold))
+	}
+}
@@ -0,0 +1,96 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type JobHandler struct {
+	scheduler *application.Scheduler
+	logger    *zap.Logger
+}
+
+func NewJobHandler(scheduler *application.Scheduler, logger *zap.Logger) *JobHandler {
+	return &JobHandler{scheduler: scheduler, logger: logger}
+}
+
+func (h *JobHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": h.scheduler.Jobs(),
+	})
+}
+
+// Run menjalankan job saat ini juga; dry_run=true hanya menampilkan arsip yang akan diproses
+func (h *JobHandler) Run(c echo.Context) error {
+	name := c.Param("name")
+	dryRun := c.QueryParam("dry_run") == "true"
+	userID := c.Get("user_id").(string)
+
+	run, err := h.scheduler.Trigger(name, dryRun, userID)
+	if err != nil && run == nil {
+		return h.jobError(c, err)
+	}
+	if err != nil {
+		// Job sudah selesai, hanya riwayatnya yang gagal disimpan
+		h.logger.Error("Gagal menyimpan riwayat job", zap.String("job", name), zap.Error(err))
+	}
+
+	h.logger.Info("Job dijalankan manual",
+		zap.String("job", name),
+		zap.Bool("dry_run", dryRun),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"run": run,
+	}))
+}
+
+func (h *JobHandler) ListRuns(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	runs, total, err := h.scheduler.Runs(c.Request().Context(), c.QueryParam("job"), page, limit)
+	if err != nil {
+		return h.jobError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       runs,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *JobHandler) GetRun(c echo.Context) error {
+	run, err := h.scheduler.Run(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.jobError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": run,
+	})
+}
+
+func (h *JobHandler) jobError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrUnknownJob),
+		errors.Is(err, domain.ErrJobRunNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrJobRunning):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrDryRunUnsupported):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrSchedulerStopped):
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi job gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorJob))
+	}
+}
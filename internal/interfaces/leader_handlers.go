@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"go.uber.org/zap"
+)
+
+type LeaderHandler struct {
+	elector *application.LeaderElector
+	logger  *zap.Logger
+}
+
+func NewLeaderHandler(elector *application.LeaderElector, logger *zap.Logger) *LeaderHandler {
+	return &LeaderHandler{elector: elector, logger: logger}
+}
+
+// Status menampilkan replika yang menjawab request, apakah replika itu leader, dan
+// lease yang sedang berlaku
+func (h *LeaderHandler) Status(c echo.Context) error {
+	status, err := h.elector.Status(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Gagal membaca status leader", zap.Error(err))
+		ErrorResponse := NewErrorResponseBuilder()
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorLeader))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": status,
+	})
+}
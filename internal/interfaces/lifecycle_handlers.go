@@ -0,0 +1,93 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type LifecycleHandler struct {
+	service *application.LifecycleService
+	logger  *zap.Logger
+}
+
+func NewLifecycleHandler(service *application.LifecycleService, logger *zap.Logger) *LifecycleHandler {
+	return &LifecycleHandler{service: service, logger: logger}
+}
+
+// Transitions menampilkan konfigurasi alur kerja yang berlaku
+func (h *LifecycleHandler) Transitions(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": h.service.Transitions(),
+	})
+}
+
+// Available menampilkan transisi yang bisa dijalankan user pada arsip
+func (h *LifecycleHandler) Available(c echo.Context) error {
+	roles, _ := c.Get("user_roles").([]string)
+	transitions, err := h.service.Available(c.Request().Context(), c.Param("id"), c.Get("user_id").(string), roles)
+	if err != nil {
+		return h.lifecycleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": transitions,
+	})
+}
+
+func (h *LifecycleHandler) Transition(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req TransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	id := c.Param("id")
+	userID := c.Get("user_id").(string)
+	roles, _ := c.Get("user_roles").([]string)
+	archive, err := h.service.Transition(c.Request().Context(), id, application.TransitionRequest{
+		Transition: req.Transition,
+		Reviewers:  req.Reviewers,
+		Comment:    req.Comment,
+	}, userID, roles)
+	if err != nil {
+		return h.lifecycleError(c, err)
+	}
+
+	h.logger.Info("State arsip berpindah",
+		zap.String("archive_id", id),
+		zap.String("transition", req.Transition),
+		zap.String("state", string(archive.State)),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"archive": ToArchiveResponse(archive),
+	}))
+}
+
+func (h *LifecycleHandler) lifecycleError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrUnknownTransition),
+		errors.Is(err, domain.ErrReviewersRequired):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrTransitionForbidden):
+		return c.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrTransitionNotAllowed),
+		errors.Is(err, domain.ErrStateConflict),
+		errors.Is(err, domain.ErrAlreadyDeleted):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Transisi lifecycle gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorLifecycle))
+	}
+}
@@ -1,12 +1,57 @@
 package middlewares
 
-import "github.com/labstack/echo/v4"
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+)
 
 func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		// Implementasi auth logic
 		userID := "user123" // Contoh
+		// Sementara identitas dan role dibaca dari header sampai auth sebenarnya tersedia
+		if header := strings.TrimSpace(c.Request().Header.Get("X-User-ID")); header != "" {
+			userID = header
+		}
+		// ID internal seperti "*" memberi akses ke draft semua user
+		if domain.ReservedUserID(userID) {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"status":  "error",
+				"message": "reserved user id",
+			})
+		}
 		c.Set("user_id", userID)
+
+		roles := []string{}
+		for _, role := range strings.Split(c.Request().Header.Get("X-User-Roles"), ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		c.Set("user_roles", roles)
 		return next(c)
 	}
 }
+
+// RequireRole menolak request dari user yang tidak memiliki salah satu role, dipasang setelah AuthMiddleware
+func RequireRole(allowed ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			roles, _ := c.Get("user_roles").([]string)
+			for _, r := range roles {
+				for _, role := range allowed {
+					if r == role {
+						return next(c)
+					}
+				}
+			}
+			return c.JSON(http.StatusForbidden, map[string]interface{}{
+				"status":  "error",
+				"message": "insufficient role",
+			})
+		}
+	}
+}
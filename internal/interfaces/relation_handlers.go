@@ -0,0 +1,97 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type RelationHandler struct {
+	service *application.RelationService
+	logger  *zap.Logger
+}
+
+func NewRelationHandler(service *application.RelationService, logger *zap.Logger) *RelationHandler {
+	return &RelationHandler{service: service, logger: logger}
+}
+
+func (h *RelationHandler) Create(c echo.Context) error {
+	id := c.Param("id")
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req RelationRequest
+	if err := c.Bind(&req); err != nil || req.TargetID == "" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	relation, err := h.service.Create(c.Request().Context(), id, req.TargetID, domain.RelationType(req.Type), userID)
+	if err != nil {
+		return h.relationError(c, err)
+	}
+
+	h.logger.Info("Relasi arsip dibuat",
+		zap.String("source_id", id),
+		zap.String("target_id", req.TargetID),
+		zap.String("type", req.Type),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"relation": relation,
+	}))
+}
+
+func (h *RelationHandler) List(c echo.Context) error {
+	id := c.Param("id")
+
+	relations, err := h.service.List(c.Request().Context(), id, c.Get("user_id").(string))
+	if err != nil {
+		return h.relationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":       id,
+		"outgoing": relations.Outgoing,
+		"incoming": relations.Incoming,
+	})
+}
+
+func (h *RelationHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+	relationID := c.Param("relationId")
+
+	if err := h.service.Delete(c.Request().Context(), id, relationID, c.Get("user_id").(string)); err != nil {
+		return h.relationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"message": "Relation deleted successfully",
+			"id":      relationID,
+		},
+	})
+}
+
+func (h *RelationHandler) relationError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrRelationNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrRelationExists), errors.Is(err, domain.ErrRelationCycle):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrInvalidRelationType), errors.Is(err, domain.ErrSelfRelation):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi relasi arsip gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorRelation))
+	}
+}
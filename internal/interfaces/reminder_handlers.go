@@ -0,0 +1,110 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+// reminderPage adalah halaman sederhana untuk link di email. GET hanya menampilkan
+// konfirmasi agar pemindai link di email tidak ikut menjalankan tindakan.
+var reminderPage = template.Must(template.New("reminder").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{if .Confirm}}<form method="post"><button type="submit">{{.Confirm}}</button></form>{{end}}
+</body></html>`))
+
+type reminderPageData struct {
+	Title   string
+	Message string
+	Confirm string
+}
+
+type ReminderHandler struct {
+	service *application.ReminderService
+	logger  *zap.Logger
+}
+
+func NewReminderHandler(service *application.ReminderService, logger *zap.Logger) *ReminderHandler {
+	return &ReminderHandler{service: service, logger: logger}
+}
+
+// Confirm menampilkan tindakan yang akan dijalankan link reminder
+func (h *ReminderHandler) Confirm(c echo.Context) error {
+	reminder, action, err := h.service.Inspect(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		return h.reminderError(c, err)
+	}
+
+	due := reminder.DueAt.Format(time.RFC1123)
+	data := reminderPageData{Title: reminder.Name}
+	switch action {
+	case domain.ReminderExtend:
+		data.Message = "This archive expires on " + due + "."
+		data.Confirm = "Keep it longer"
+	case domain.ReminderRestore:
+		data.Message = "This archive will be permanently deleted on " + due + "."
+		data.Confirm = "Restore it"
+	}
+	return h.render(c, http.StatusOK, data)
+}
+
+// Act menjalankan link reminder atas nama pemilik arsip
+func (h *ReminderHandler) Act(c echo.Context) error {
+	reminder, archive, err := h.service.Act(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		return h.reminderError(c, err)
+	}
+
+	h.logger.Info("Link reminder dipakai",
+		zap.String("archive_id", reminder.ArchiveID),
+		zap.String("action", string(reminder.Action)),
+		zap.String("user_id", reminder.Recipient),
+	)
+
+	data := reminderPageData{Title: archive.Name, Message: "Done. The archive has been restored."}
+	if reminder.Action == domain.ReminderExtend && archive.ExpiresAt != nil {
+		data.Message = "Done. The archive now expires on " + archive.ExpiresAt.Format(time.RFC1123) + "."
+	}
+	return h.render(c, http.StatusOK, data)
+}
+
+func (h *ReminderHandler) render(c echo.Context, status int, data reminderPageData) error {
+	var buf bytes.Buffer
+	if err := reminderPage.Execute(&buf, data); err != nil {
+		return err
+	}
+	return c.HTML(status, buf.String())
+}
+
+func (h *ReminderHandler) reminderError(c echo.Context, err error) error {
+	data := reminderPageData{Title: "Archive reminder", Message: err.Error()}
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidReminderToken),
+		errors.Is(err, domain.ErrReminderNotFound):
+		return h.render(c, http.StatusNotFound, reminderPageData{Title: data.Title, Message: domain.ErrInvalidReminderToken.Error()})
+	case errors.Is(err, domain.ErrReminderTokenExpired):
+		return h.render(c, http.StatusGone, data)
+	case errors.Is(err, domain.ErrReminderActionUsed),
+		errors.Is(err, domain.ErrNotScheduled),
+		errors.Is(err, domain.ErrNotDeleted),
+		errors.Is(err, domain.ErrArchiveNotFound),
+		errors.Is(err, domain.ErrUnderLegalHold):
+		return h.render(c, http.StatusConflict, data)
+	case errors.Is(err, domain.ErrReminderLinksDisabled):
+		return h.render(c, http.StatusServiceUnavailable, data)
+	default:
+		h.logger.Error("Link reminder gagal diproses", zap.Error(err))
+		return h.render(c, http.StatusInternalServerError, reminderPageData{Title: data.Title, Message: ResponseErrorReminder})
+	}
+}
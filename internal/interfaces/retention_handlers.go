@@ -0,0 +1,162 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type RetentionHandler struct {
+	service *application.RetentionService
+	logger  *zap.Logger
+}
+
+func NewRetentionHandler(service *application.RetentionService, logger *zap.Logger) *RetentionHandler {
+	return &RetentionHandler{service: service, logger: logger}
+}
+
+func (h *RetentionHandler) ListPolicies(c echo.Context) error {
+	policies, err := h.service.ListPolicies(c.Request().Context())
+	if err != nil {
+		return h.retentionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": policies,
+	})
+}
+
+func (h *RetentionHandler) GetPolicy(c echo.Context) error {
+	policy, err := h.service.GetPolicy(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.retentionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": policy,
+	})
+}
+
+func (h *RetentionHandler) CreatePolicy(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req RetentionPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	policy := req.ToPolicy()
+	userID := c.Get("user_id").(string)
+	if err := h.service.CreatePolicy(c.Request().Context(), &policy, userID); err != nil {
+		return h.retentionError(c, err)
+	}
+
+	h.logger.Info("Kebijakan retensi dibuat",
+		zap.String("policy_id", policy.ID.Hex()),
+		zap.String("category", policy.Category),
+		zap.String("type", policy.Type),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"policy": policy,
+	}))
+}
+
+func (h *RetentionHandler) UpdatePolicy(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req RetentionPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	policy, err := h.service.UpdatePolicy(c.Request().Context(), c.Param("id"), req.ToPolicy())
+	if err != nil {
+		return h.retentionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"policy": policy,
+	}))
+}
+
+func (h *RetentionHandler) DeletePolicy(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.service.DeletePolicy(c.Request().Context(), id); err != nil {
+		return h.retentionError(c, err)
+	}
+
+	h.logger.Info("Kebijakan retensi dihapus",
+		zap.String("policy_id", id),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"message": "Retention policy deleted",
+			"id":      id,
+		},
+	})
+}
+
+// Run menjalankan evaluasi retensi sekarang tanpa menunggu cleanup task
+func (h *RetentionHandler) Run(c echo.Context) error {
+	run, err := h.service.Evaluate(c.Request().Context())
+	if err != nil {
+		return h.retentionError(c, err)
+	}
+
+	h.logger.Info("Evaluasi retensi dijalankan manual",
+		zap.Int("evaluated", run.Evaluated),
+		zap.Int("disposed", len(run.Disposed)),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"run": run,
+	}))
+}
+
+func (h *RetentionHandler) ListRuns(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	runs, total, err := h.service.ListRuns(c.Request().Context(), page, limit)
+	if err != nil {
+		return h.retentionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       runs,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *RetentionHandler) retentionError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrRetentionPolicyNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrAlreadyDeleted),
+		errors.Is(err, domain.ErrUnderLegalHold):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrRetentionNameRequired),
+		errors.Is(err, domain.ErrRetentionScopeRequired),
+		errors.Is(err, domain.ErrInvalidRetentionPeriod),
+		errors.Is(err, domain.ErrInvalidRetentionTrigger),
+		errors.Is(err, domain.ErrInvalidRetentionAction),
+		errors.Is(err, domain.ErrInvalidCategory):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi retensi gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorRetention))
+	}
+}
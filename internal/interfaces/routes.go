@@ -1,10 +1,15 @@
 package interfaces
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
 	"github.com/yhartanto178dev/archiven-api/internal/archive/infrastructure"
 	"github.com/yhartanto178dev/archiven-api/internal/configs"
 	middlewares "github.com/yhartanto178dev/archiven-api/internal/interfaces/middleware"
@@ -14,15 +19,213 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func RegisterRoutes(e *echo.Echo, client *mongo.Client, cfg *configs.Config, logger *zap.Logger) {
+// RegisterRoutes menyiapkan dependency dan route. Scheduler, leader elector dan worker
+// webhook yang dikembalikan sudah berjalan; hentikan scheduler lebih dulu saat aplikasi
+// dimatikan, lalu worker webhook, baru elector agar lease dilepas setelah job selesai.
+func RegisterRoutes(e *echo.Echo, client *mongo.Client, cfg *configs.Config, logger *zap.Logger) (*application.Scheduler, *application.LeaderElector, *application.WebhookService) {
 	// Initialize Repository
 	repo, err := infrastructure.NewArchiveRepository(client, cfg.DBName)
 	if err != nil {
 		e.Logger.Fatal("Failed to initialize archive repository:", err)
 	}
 
+	categoryRepo, err := infrastructure.NewCategoryRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize category repository:", err)
+	}
+
+	savedSearchRepo, err := infrastructure.NewSavedSearchRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize saved search repository:", err)
+	}
+
+	relationRepo, err := infrastructure.NewRelationRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize relation repository:", err)
+	}
+
+	folderRepo, err := infrastructure.NewFolderRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize folder repository:", err)
+	}
+
+	commentRepo, err := infrastructure.NewCommentRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize comment repository:", err)
+	}
+
+	bulkJobRepo, err := infrastructure.NewBulkJobRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize bulk job repository:", err)
+	}
+
+	retentionRepo, err := infrastructure.NewRetentionRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize retention repository:", err)
+	}
+
+	legalHoldRepo, err := infrastructure.NewLegalHoldRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize legal hold repository:", err)
+	}
+
+	certificateRepo, err := infrastructure.NewCertificateRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize certificate repository:", err)
+	}
+
+	retrievalJobRepo, err := infrastructure.NewRetrievalJobRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize retrieval job repository:", err)
+	}
+
+	// Backend kosong berarti tiering dimatikan dan semua konten tetap di GridFS
+	coldStore, err := infrastructure.NewColdStore(cfg.ColdTierBackend, cfg.ColdTierDir, client, cfg.ColdTierDB)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize cold storage:", err)
+	}
+	if coldStore != nil {
+		repo.UseColdStore(coldStore)
+	}
+	tierPolicy := domain.TierPolicy{
+		MinAge:     time.Duration(cfg.ColdTierMinAgeDays) * 24 * time.Hour,
+		IdleFor:    time.Duration(cfg.ColdTierIdleDays) * 24 * time.Hour,
+		Categories: cfg.ColdTierCategories,
+		Retrieval:  domain.RetrievalMode(cfg.ColdTierRetrieval),
+	}
+	if err := tierPolicy.Validate(); err != nil {
+		e.Logger.Fatal("Invalid cold tier configuration:", err)
+	}
+
+	jobRunRepo, err := infrastructure.NewJobRunRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize job run repository:", err)
+	}
+
+	reminderRepo, err := infrastructure.NewReminderRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize reminder repository:", err)
+	}
+
+	leaseRepo, err := infrastructure.NewLeaseRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize lease repository:", err)
+	}
+
+	webhookRepo, err := infrastructure.NewWebhookSubscriptionRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize webhook subscription repository:", err)
+	}
+
+	webhookDeliveryRepo, err := infrastructure.NewWebhookDeliveryRepository(client, cfg.DBName)
+	if err != nil {
+		e.Logger.Fatal("Failed to initialize webhook delivery repository:", err)
+	}
+
+	lifecycle, err := infrastructure.LoadLifecycle(cfg.LifecycleConfig)
+	if err != nil {
+		e.Logger.Fatal("Failed to load lifecycle configuration:", err)
+	}
+
 	// Initialize service
-	service := application.NewArchiveService(repo)
+	service := application.NewArchiveService(repo, categoryRepo, infrastructure.NewMinHashFingerprinter(), infrastructure.NewTextComparer(), application.ArchiveServiceConfig{
+		TrashRetention:      time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour,
+		SimilarityThreshold: cfg.SimilarityThreshold,
+		SimilarLimit:        10,
+		CheckoutTTL:         time.Duration(cfg.CheckoutTTLMinutes) * time.Minute,
+		TempDeleteTTL:       time.Duration(cfg.TempDeleteTTLHours) * time.Hour,
+		TempDeleteMaxTTL:    time.Duration(cfg.TempDeleteMaxHours) * time.Hour,
+		ColdRetrieval:       tierPolicy.Retrieval,
+	})
+	categoryService := application.NewCategoryService(categoryRepo, repo)
+	savedSearchService := application.NewSavedSearchService(savedSearchRepo, repo)
+	relationService := application.NewRelationService(relationRepo, repo)
+	folderService := application.NewFolderService(folderRepo, repo)
+	commentService := application.NewCommentService(commentRepo, repo)
+	bulkService := application.NewBulkEditService(bulkJobRepo, repo, categoryRepo)
+	retentionService := application.NewRetentionService(retentionRepo, repo, categoryRepo)
+	legalHoldService := application.NewLegalHoldService(legalHoldRepo, repo)
+	// Pemberitahuan selalu ditulis ke log, email dan webhook hanya bila dikonfigurasi
+	channels := []domain.NotificationChannel{{Name: "log", Notifier: infrastructure.NewLogNotifier(logger)}}
+	if cfg.SMTPHost != "" {
+		channels = append(channels, domain.NotificationChannel{Name: "email", Notifier: infrastructure.NewSMTPNotifier(infrastructure.SMTPConfig{
+			Host:        cfg.SMTPHost,
+			Port:        cfg.SMTPPort,
+			Username:    cfg.SMTPUsername,
+			Password:    cfg.SMTPPassword,
+			From:        cfg.SMTPFrom,
+			EmailDomain: cfg.NotifyEmailDomain,
+		})})
+	}
+	if cfg.NotifyWebhookURL != "" {
+		channels = append(channels, domain.NotificationChannel{Name: "webhook", Notifier: infrastructure.NewWebhookNotifier(cfg.NotifyWebhookURL)})
+	}
+	lifecycleService := application.NewLifecycleService(repo, lifecycle, infrastructure.NewMultiNotifier(channels))
+	lifecycleService.OnNotifyError(func(archiveID string, err error) {
+		logger.Warn("Gagal mengirim pemberitahuan transisi", zap.String("id", archiveID), zap.Error(err))
+	})
+	dispositionService := application.NewDispositionService(repo, certificateRepo, categoryRepo, infrastructure.NewPDFCertificateRenderer(), cfg.DispositionSigningKey)
+	// Satu identitas per proses untuk lease leader, lease job dan pemilik retrieval job
+	instance := instanceID(cfg)
+	tieringService := application.NewTieringService(repo, retrievalJobRepo, tierPolicy, coldStore != nil)
+	tieringService.UseInstance(instance)
+	reminderService := application.NewReminderService(repo, reminderRepo, service, channels, application.ReminderServiceConfig{
+		Window:         time.Duration(cfg.ReminderWindowHours) * time.Hour,
+		TrashRetention: time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour,
+		BaseURL:        cfg.PublicBaseURL,
+		SigningKey:     []byte(cfg.ReminderSigningKey),
+	})
+	// Job yang masih berjalan saat server berhenti tidak akan dilanjutkan
+	if interrupted, err := bulkService.RecoverInterrupted(context.Background()); err != nil {
+		logger.Error("Gagal menandai job massal yang terputus", zap.Error(err))
+	} else if interrupted > 0 {
+		logger.Warn("Job massal terputus ditandai gagal", zap.Int64("jobs", interrupted))
+	}
+	if interrupted, err := tieringService.RecoverInterrupted(context.Background()); err != nil {
+		logger.Error("Gagal menandai retrieval job yang terputus", zap.Error(err))
+	} else if interrupted > 0 {
+		logger.Warn("Retrieval job terputus ditandai gagal", zap.Int64("jobs", interrupted))
+	}
+	// Event arsip dikirim ke langganan webhook oleh worker di semua replika
+	webhookService := application.NewWebhookService(webhookRepo, webhookDeliveryRepo,
+		infrastructure.NewHTTPWebhookSender(time.Duration(cfg.WebhookTimeoutSeconds)*time.Second), application.WebhookServiceConfig{
+			MaxAttempts:  cfg.WebhookMaxAttempts,
+			BackoffBase:  time.Duration(cfg.WebhookBackoffSeconds) * time.Second,
+			Timeout:      time.Duration(cfg.WebhookTimeoutSeconds) * time.Second,
+			PollInterval: 5 * time.Second,
+			Workers:      cfg.WebhookWorkers,
+		})
+	webhookService.OnDelivery(func(delivery *domain.WebhookDelivery, err error) {
+		switch {
+		case delivery == nil:
+			logger.Error("Gagal membaca antrean webhook", zap.Error(err))
+		case delivery.Status == domain.DeliveryDead:
+			logger.Error("Webhook berhenti dicoba, delivery masuk dead-letter",
+				zap.String("delivery_id", delivery.ID.Hex()),
+				zap.String("subscription_id", delivery.SubscriptionID),
+				zap.String("event", delivery.EventType),
+				zap.Int("attempts", delivery.AttemptCount),
+				zap.Error(err),
+			)
+		case err != nil:
+			logger.Warn("Pengiriman webhook gagal",
+				zap.String("delivery_id", delivery.ID.Hex()),
+				zap.String("event", delivery.EventType),
+				zap.Int("attempts", delivery.AttemptCount),
+				zap.Error(err),
+			)
+		}
+	})
+	service.UseEvents(webhookService)
+	retentionService.UseEvents(webhookService)
+	// Komentar ikut tampil di riwayat arsip
+	service.AddHistorySource(commentService.HistoryEntries)
+	// Link ke arsip yang dihapus permanen ditandai rusak
+	repo.OnRemove(func(ctx context.Context, id string) {
+		if err := relationService.HandleArchiveRemoved(ctx, id); err != nil {
+			logger.Warn("Gagal menandai relasi arsip yang dihapus", zap.String("id", id), zap.Error(err))
+		}
+	})
 
 	fileValidator := NewFileValidator(
 		3*1024*1024, // 3MB
@@ -32,22 +235,199 @@ func RegisterRoutes(e *echo.Echo, client *mongo.Client, cfg *configs.Config, log
 	)
 
 	// Initialize handlers
-	handler := NewArchiveHandler(service, fileValidator, logger)
-	startCleanupTask(service, 1*time.Hour, logger)
+	handler := NewArchiveHandler(service, tieringService, fileValidator, logger)
+	categoryHandler := NewCategoryHandler(categoryService, logger)
+	savedSearchHandler := NewSavedSearchHandler(savedSearchService, logger)
+	relationHandler := NewRelationHandler(relationService, logger)
+	folderHandler := NewFolderHandler(folderService, logger)
+	commentHandler := NewCommentHandler(commentService, logger)
+	bulkHandler := NewBulkHandler(bulkService, logger)
+	retentionHandler := NewRetentionHandler(retentionService, logger)
+	legalHoldHandler := NewLegalHoldHandler(legalHoldService, logger)
+	dispositionHandler := NewDispositionHandler(dispositionService, logger)
+	lifecycleHandler := NewLifecycleHandler(lifecycleService, logger)
+	tieringHandler := NewTieringHandler(tieringService, logger)
+	reminderHandler := NewReminderHandler(reminderService, logger)
+	webhookHandler := NewWebhookHandler(webhookService, logger)
+	scheduler := application.NewScheduler(jobRunRepo, time.Duration(cfg.JobTimeoutMinutes)*time.Minute)
+	if err := registerCleanupJobs(scheduler, cfg, service, retentionService, dispositionService, tieringService, coldStore != nil, reminderService, logger); err != nil {
+		e.Logger.Fatal("Failed to register scheduled jobs:", err)
+	}
+	jobHandler := NewJobHandler(scheduler, logger)
+	// Hanya replika yang memegang lease yang menjalankan job terjadwal
+	leaderTTL := time.Duration(cfg.LeaderLeaseSeconds) * time.Second
+	elector, err := application.NewLeaderElector(leaseRepo, domain.SchedulerLease, instance,
+		leaderTTL, time.Duration(cfg.LeaderRenewSeconds)*time.Second)
+	if err != nil {
+		e.Logger.Fatal("Invalid leader election configuration:", err)
+	}
+	elector.OnChange(func(leader bool) {
+		if leader {
+			logger.Info("Replika ini menjadi leader, job terjadwal dijalankan di sini", zap.String("instance", elector.Instance()))
+			return
+		}
+		// Replika lain bisa mengambil alih, jadi job terjadwal yang masih berjalan dihentikan
+		scheduler.CancelScheduled()
+		logger.Warn("Replika ini bukan leader lagi", zap.String("instance", elector.Instance()))
+	})
+	scheduler.UseLeader(elector)
+	// Job manual di replika mana pun tidak boleh bersamaan dengan job terjadwal di leader
+	scheduler.UseJobLocks(leaseRepo, instance, leaderTTL)
+	leaderHandler := NewLeaderHandler(elector, logger)
+	if cfg.DispositionSigningKey == "" {
+		logger.Warn("DISPOSITION_SIGNING_KEY kosong, arsip yang disetujui tidak akan dimusnahkan")
+	}
+	if cfg.ReminderSigningKey == "" {
+		logger.Warn("REMINDER_SIGNING_KEY kosong, reminder dikirim tanpa link extend dan restore")
+	}
+	elector.Start()
+	scheduler.Start()
+	webhookService.Start()
 	// Register routes
 	// Routes
 	e.POST("/archives", handler.Upload, middlewares.AuthMiddleware)
-	e.GET("/archives", handler.List)
-	e.GET("/download/:id", handler.Download)
-	e.GET("/archives/list", handler.GetByIDs)
-	e.DELETE("/archives/:id", handler.DeleteArchive)
-	e.DELETE("/archives/:id/permanent", handler.DeleteArchive)
-	e.POST("/archives/:id/restore", handler.RestoreArchive)
-	e.GET("/archives/:id/history", handler.GetHistory)
+	// Listing dan download membaca user agar draft milik user lain disembunyikan
+	e.GET("/archives", handler.List, middlewares.AuthMiddleware)
+	e.GET("/download/:id", handler.Download, middlewares.AuthMiddleware)
+	e.GET("/archives/list", handler.GetByIDs, middlewares.AuthMiddleware)
+	e.PATCH("/archives/:id", handler.UpdateMetadata, middlewares.AuthMiddleware)
+	e.DELETE("/archives/:id", handler.DeleteArchive, middlewares.AuthMiddleware)
+	e.DELETE("/archives/:id/permanent", handler.DeleteArchive, middlewares.AuthMiddleware)
+	e.PATCH("/archives/:id/schedule", handler.ExtendDeletion, middlewares.AuthMiddleware)
+	e.DELETE("/archives/:id/schedule", handler.CancelDeletion, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/restore", handler.RestoreArchive, middlewares.AuthMiddleware)
+	e.GET("/archives/:id/history", handler.GetHistory, middlewares.AuthMiddleware)
+	e.GET("/archives/:id/similar", handler.GetSimilar, middlewares.AuthMiddleware)
+	e.GET("/archives/:id/versions", handler.ListVersions, middlewares.AuthMiddleware)
+	e.GET("/archives/:id/diff", handler.GetDiff, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/rollback", handler.Rollback, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/checkout", handler.Checkout, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/checkin", handler.Checkin, middlewares.AuthMiddleware)
+	e.DELETE("/archives/:id/lock", handler.BreakLock, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/archives/:id/relations", relationHandler.List, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/relations", relationHandler.Create, middlewares.AuthMiddleware)
+	e.DELETE("/archives/:id/relations/:relationId", relationHandler.Delete, middlewares.AuthMiddleware)
+	e.GET("/archives/:id/comments", commentHandler.List, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/comments", commentHandler.Create, middlewares.AuthMiddleware)
+	e.PATCH("/archives/:id/comments/:commentId", commentHandler.Edit, middlewares.AuthMiddleware)
+	e.DELETE("/archives/:id/comments/:commentId", commentHandler.Delete, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/comments/:commentId/resolve", commentHandler.Resolve, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/comments/:commentId/reopen", commentHandler.Reopen, middlewares.AuthMiddleware)
+	e.GET("/archives/:id/annotations", commentHandler.Annotations, middlewares.AuthMiddleware)
+	e.GET("/archives/:id/transitions", lifecycleHandler.Available, middlewares.AuthMiddleware)
+	e.POST("/archives/:id/transitions", lifecycleHandler.Transition, middlewares.AuthMiddleware)
+	e.GET("/lifecycle", lifecycleHandler.Transitions)
+
+	// Trash
+	e.GET("/archives/trash", handler.ListTrash, middlewares.AuthMiddleware)
+	e.POST("/archives/trash/restore", handler.RestoreTrash, middlewares.AuthMiddleware)
+	e.POST("/archives/trash/purge", handler.PurgeTrash, middlewares.AuthMiddleware)
+	e.DELETE("/archives/trash/:id", handler.PurgeTrashItem, middlewares.AuthMiddleware)
+
+	// Bulk metadata edit
+	e.POST("/archives/bulk/edit", bulkHandler.EditMetadata, middlewares.AuthMiddleware)
+	e.GET("/bulk-jobs", bulkHandler.ListJobs, middlewares.AuthMiddleware)
+	e.GET("/bulk-jobs/:id", bulkHandler.GetJob, middlewares.AuthMiddleware)
+
+	// Retention
+	e.GET("/retention/policies", retentionHandler.ListPolicies)
+	e.GET("/retention/policies/:id", retentionHandler.GetPolicy)
+	e.POST("/retention/policies", retentionHandler.CreatePolicy, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.PUT("/retention/policies/:id", retentionHandler.UpdatePolicy, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.DELETE("/retention/policies/:id", retentionHandler.DeletePolicy, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.POST("/retention/run", retentionHandler.Run, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/retention/runs", retentionHandler.ListRuns, middlewares.AuthMiddleware)
+
+	// Antrean disposisi berisi arsip semua user termasuk draft, jadi antrean, keputusan
+	// dan eksekusi hanya untuk admin dan records manager
+	e.GET("/dispositions/queue", dispositionHandler.Queue, middlewares.AuthMiddleware, middlewares.RequireRole("admin", "records_manager"))
+	e.POST("/dispositions/decisions", dispositionHandler.Decide, middlewares.AuthMiddleware, middlewares.RequireRole("admin", "records_manager"))
+	e.POST("/dispositions/execute", dispositionHandler.Execute, middlewares.AuthMiddleware, middlewares.RequireRole("admin", "records_manager"))
+	e.GET("/dispositions/certificates", dispositionHandler.ListCertificates, middlewares.AuthMiddleware)
+	e.GET("/dispositions/certificates/:id", dispositionHandler.GetCertificate, middlewares.AuthMiddleware)
+	e.GET("/dispositions/certificates/:id/verify", dispositionHandler.VerifyCertificate, middlewares.AuthMiddleware)
+
+	// Cold storage tiering
+	e.POST("/storage/tiering/run", tieringHandler.Run, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/storage/retrievals/:id", tieringHandler.GetRetrieval, middlewares.AuthMiddleware)
+
+	// Job terjadwal, hanya admin yang boleh menjalankan manual dan melihat riwayat
+	e.GET("/jobs", jobHandler.List, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/jobs/runs", jobHandler.ListRuns, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/jobs/runs/:id", jobHandler.GetRun, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.POST("/jobs/:name/run", jobHandler.Run, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/cluster/leader", leaderHandler.Status, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+
+	// Link satu klik dari reminder; token yang ditandatangani menggantikan login
+	e.GET("/reminders/actions/:token", reminderHandler.Confirm)
+	e.POST("/reminders/actions/:token", reminderHandler.Act)
+
+	// Webhook event arsip, hanya admin yang boleh mendaftarkan dan melihat log delivery
+	e.GET("/webhooks", webhookHandler.List, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.POST("/webhooks", webhookHandler.Create, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/webhooks/:id", webhookHandler.Get, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.PATCH("/webhooks/:id", webhookHandler.Update, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.DELETE("/webhooks/:id", webhookHandler.Delete, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/webhook-deliveries/:id", webhookHandler.GetDelivery, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.POST("/webhook-deliveries/:id/redeliver", webhookHandler.Redeliver, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+
+	// Legal hold, hanya admin dan tim legal yang boleh membuat dan melepas hold
+	e.GET("/legal-holds", legalHoldHandler.List, middlewares.AuthMiddleware)
+	e.GET("/legal-holds/:id", legalHoldHandler.Get, middlewares.AuthMiddleware)
+	e.GET("/legal-holds/:id/archives", legalHoldHandler.Archives, middlewares.AuthMiddleware)
+	e.POST("/legal-holds", legalHoldHandler.Create, middlewares.AuthMiddleware, middlewares.RequireRole("admin", "legal"))
+	e.POST("/legal-holds/:id/archives", legalHoldHandler.AddArchives, middlewares.AuthMiddleware, middlewares.RequireRole("admin", "legal"))
+	e.POST("/legal-holds/:id/release", legalHoldHandler.Release, middlewares.AuthMiddleware, middlewares.RequireRole("admin", "legal"))
 
 	// Get by category
-	e.GET("/archives/category/:category", handler.GetByCategory)
+	e.GET("/archives/category/:category", handler.GetByCategory, middlewares.AuthMiddleware)
 
 	// Get by tags
-	e.GET("/archives/tags", handler.GetByTags)
+	e.GET("/archives/tags", handler.GetByTags, middlewares.AuthMiddleware)
+
+	// Category taxonomy
+	e.GET("/categories", categoryHandler.Tree)
+	e.POST("/categories", categoryHandler.Create, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.GET("/categories/:id", categoryHandler.Get)
+	e.PATCH("/categories/:id", categoryHandler.Rename, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.POST("/categories/:id/move", categoryHandler.Move, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+	e.POST("/categories/:id/deprecate", categoryHandler.Deprecate, middlewares.AuthMiddleware, middlewares.RequireRole("admin"))
+
+	// Folders
+	e.GET("/folders", folderHandler.Contents, middlewares.AuthMiddleware)
+	e.POST("/folders", folderHandler.Create, middlewares.AuthMiddleware)
+	e.GET("/folders/:id", folderHandler.Get, middlewares.AuthMiddleware)
+	e.GET("/folders/:id/children", folderHandler.Contents, middlewares.AuthMiddleware)
+	e.PATCH("/folders/:id", folderHandler.Rename, middlewares.AuthMiddleware)
+	e.POST("/folders/:id/move", folderHandler.Move, middlewares.AuthMiddleware)
+	e.DELETE("/folders/:id", folderHandler.Delete, middlewares.AuthMiddleware)
+	e.POST("/folders/:id/restore", folderHandler.Restore, middlewares.AuthMiddleware)
+	e.POST("/folders/:id/archives", folderHandler.MoveArchives, middlewares.AuthMiddleware)
+
+	// Saved searches & smart collections
+	e.GET("/saved-searches", savedSearchHandler.List, middlewares.AuthMiddleware)
+	e.POST("/saved-searches", savedSearchHandler.Create, middlewares.AuthMiddleware)
+	e.GET("/saved-searches/collections", savedSearchHandler.Collections, middlewares.AuthMiddleware)
+	e.GET("/saved-searches/:id", savedSearchHandler.Get, middlewares.AuthMiddleware)
+	e.DELETE("/saved-searches/:id", savedSearchHandler.Delete, middlewares.AuthMiddleware)
+	e.PUT("/saved-searches/:id/share", savedSearchHandler.Share, middlewares.AuthMiddleware)
+	e.GET("/saved-searches/:id/run", savedSearchHandler.Run, middlewares.AuthMiddleware)
+
+	return scheduler, elector, webhookService
+}
+
+// instanceID memakai INSTANCE_ID bila diisi. Suffix acak mencegah dua replika dengan
+// hostname sama dianggap satu pemegang lease.
+func instanceID(cfg *configs.Config) string {
+	if cfg.InstanceID != "" {
+		return cfg.InstanceID
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "archiven"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
 }
@@ -0,0 +1,155 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type SavedSearchHandler struct {
+	service *application.SavedSearchService
+	logger  *zap.Logger
+}
+
+func NewSavedSearchHandler(service *application.SavedSearchService, logger *zap.Logger) *SavedSearchHandler {
+	return &SavedSearchHandler{service: service, logger: logger}
+}
+
+func (h *SavedSearchHandler) Create(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req SavedSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	search, err := h.service.Create(c.Request().Context(), userID, req.Name, req.Description, req.Filter)
+	if err != nil {
+		return h.savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"saved_search": search,
+	}))
+}
+
+func (h *SavedSearchHandler) List(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	searches, err := h.service.List(c.Request().Context(), c.Get("user_id").(string))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorSavedSearch))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": searches,
+	})
+}
+
+func (h *SavedSearchHandler) Get(c echo.Context) error {
+	search, err := h.service.Get(c.Request().Context(), c.Param("id"), c.Get("user_id").(string))
+	if err != nil {
+		return h.savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": search,
+	})
+}
+
+func (h *SavedSearchHandler) Run(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	search, archives, total, err := h.service.Run(c.Request().Context(), c.Param("id"), c.Get("user_id").(string), page, limit)
+	if err != nil {
+		return h.savedSearchError(c, err)
+	}
+
+	var response []ArchiveResponse
+	for _, a := range archives {
+		response = append(response, ToArchiveResponse(&a))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": response,
+		"pagination": map[string]interface{}{
+			"page":       page,
+			"limit":      limit,
+			"totalData":  total,
+			"totalPages": (total + int64(limit) - 1) / int64(limit),
+		},
+		"saved_search": search,
+	})
+}
+
+func (h *SavedSearchHandler) Share(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req ShareSavedSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	search, err := h.service.Share(c.Request().Context(), c.Param("id"), c.Get("user_id").(string), req.UserIDs)
+	if err != nil {
+		return h.savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"saved_search": search,
+	}))
+}
+
+func (h *SavedSearchHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request().Context(), id, c.Get("user_id").(string)); err != nil {
+		return h.savedSearchError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"message": "Saved search deleted successfully",
+			"id":      id,
+		},
+	})
+}
+
+func (h *SavedSearchHandler) Collections(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	collections, err := h.service.Collections(c.Request().Context(), c.Get("user_id").(string))
+	if err != nil {
+		h.logger.Error("Gagal menghitung smart collection", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorSavedSearch))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": collections,
+	})
+}
+
+func (h *SavedSearchHandler) savedSearchError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrSavedSearchNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrSavedSearchForbidden):
+		return c.JSON(http.StatusForbidden, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrSavedSearchExists):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrSavedSearchNameRequired),
+		errors.Is(err, domain.ErrInvalidPeriod),
+		errors.Is(err, domain.ErrInvalidDateRange):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi saved search gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorSavedSearch))
+	}
+}
@@ -0,0 +1,68 @@
+package interfaces
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type TieringHandler struct {
+	service *application.TieringService
+	logger  *zap.Logger
+}
+
+func NewTieringHandler(service *application.TieringService, logger *zap.Logger) *TieringHandler {
+	return &TieringHandler{service: service, logger: logger}
+}
+
+// Run menjalankan kebijakan tiering tanpa menunggu jadwal cleanup
+func (h *TieringHandler) Run(c echo.Context) error {
+	run, err := h.service.Run(c.Request().Context())
+	if err != nil {
+		return h.tieringError(c, err)
+	}
+
+	h.logger.Info("Tiering dijalankan manual",
+		zap.Int("evaluated", run.Evaluated),
+		zap.Int("moved", run.Moved),
+		zap.Int("failed", len(run.Failures)),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"run": run,
+	}))
+}
+
+func (h *TieringHandler) GetRetrieval(c echo.Context) error {
+	job, err := h.service.Job(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.tieringError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": job,
+	})
+}
+
+func (h *TieringHandler) tieringError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrRetrievalJobNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrArchiveNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(ResponseErrorFileNotFound))
+	case errors.Is(err, domain.ErrColdStoreDisabled):
+		return c.JSON(http.StatusServiceUnavailable, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrNotCold):
+		return c.JSON(http.StatusConflict, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi tiering gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorTiering))
+	}
+}
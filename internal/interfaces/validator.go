@@ -5,6 +5,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
@@ -103,6 +104,14 @@ func (v *FileValidator) ValidateMetadata(metadata domain.ArchiveMetadata) error
 	return nil
 }
 
+// ValidateFileName memastikan nama baru tetap memakai ekstensi yang diizinkan
+func (v *FileValidator) ValidateFileName(name string) error {
+	if strings.TrimSpace(name) == "" || filepath.Ext(name) != v.AllowedExt {
+		return ErrInvalidExtension
+	}
+	return nil
+}
+
 func (v *FileValidator) MapDomainError(err error) *echo.HTTPError {
 	switch {
 	case errors.Is(err, ErrFileTooLarge):
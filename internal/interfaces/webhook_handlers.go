@@ -0,0 +1,192 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/application"
+	"github.com/yhartanto178dev/archiven-api/internal/archive/domain"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	service *application.WebhookService
+	logger  *zap.Logger
+}
+
+func NewWebhookHandler(service *application.WebhookService, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{service: service, logger: logger}
+}
+
+// WebhookDeliveryResponse menampilkan payload sebagai JSON, bukan string
+type WebhookDeliveryResponse struct {
+	domain.WebhookDelivery
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func ToWebhookDeliveryResponse(d *domain.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{WebhookDelivery: *d}
+	if d.Payload != "" {
+		response.Payload = json.RawMessage(d.Payload)
+	}
+	return response
+}
+
+// Create mendaftarkan langganan; secret hanya ditampilkan di response ini
+func (h *WebhookHandler) Create(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req WebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	userID := c.Get("user_id").(string)
+	subscription, err := h.service.Create(c.Request().Context(), domain.WebhookSubscription{
+		URL:         req.URL,
+		Events:      req.Events,
+		Secret:      req.Secret,
+		Description: req.Description,
+	}, userID)
+	if err != nil {
+		return h.webhookError(c, err)
+	}
+
+	h.logger.Info("Webhook didaftarkan",
+		zap.String("webhook_id", subscription.ID.Hex()),
+		zap.String("url", subscription.URL),
+		zap.Strings("events", subscription.Events),
+		zap.String("user_id", userID),
+	)
+
+	return c.JSON(http.StatusCreated, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"webhook": subscription,
+		"secret":  subscription.Secret,
+	}))
+}
+
+func (h *WebhookHandler) List(c echo.Context) error {
+	page, limit := parsePagination(c)
+
+	subscriptions, total, err := h.service.List(c.Request().Context(), page, limit)
+	if err != nil {
+		return h.webhookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       subscriptions,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+func (h *WebhookHandler) Get(c echo.Context) error {
+	subscription, err := h.service.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.webhookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": subscription,
+	})
+}
+
+func (h *WebhookHandler) Update(c echo.Context) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	var req UpdateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse(ResponseErrorValidRequest))
+	}
+
+	subscription, err := h.service.Update(c.Request().Context(), c.Param("id"), req.ToPatch())
+	if err != nil {
+		return h.webhookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"webhook": subscription,
+	}))
+}
+
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		return h.webhookError(c, err)
+	}
+
+	h.logger.Info("Webhook dihapus", zap.String("webhook_id", id), zap.String("user_id", c.Get("user_id").(string)))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"message": "Webhook deleted successfully",
+			"id":      id,
+		},
+	})
+}
+
+// Deliveries menampilkan log delivery langganan, bisa difilter dengan ?status=pending|delivered|dead
+func (h *WebhookHandler) Deliveries(c echo.Context) error {
+	page, limit := parsePagination(c)
+	status := domain.DeliveryStatus(c.QueryParam("status"))
+
+	deliveries, total, err := h.service.Deliveries(c.Request().Context(), c.Param("id"), status, page, limit)
+	if err != nil {
+		return h.webhookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":       deliveries,
+		"pagination": paginationResponse(page, limit, total),
+	})
+}
+
+// GetDelivery menampilkan payload dan semua percobaan kirim satu delivery
+func (h *WebhookHandler) GetDelivery(c echo.Context) error {
+	delivery, err := h.service.Delivery(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return h.webhookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": ToWebhookDeliveryResponse(delivery),
+	})
+}
+
+// Redeliver mengirim ulang payload delivery sebagai delivery baru
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	id := c.Param("id")
+	delivery, err := h.service.Redeliver(c.Request().Context(), id)
+	if err != nil {
+		return h.webhookError(c, err)
+	}
+
+	h.logger.Info("Webhook dikirim ulang",
+		zap.String("delivery_id", id),
+		zap.String("new_delivery_id", delivery.ID.Hex()),
+		zap.String("user_id", c.Get("user_id").(string)),
+	)
+
+	return c.JSON(http.StatusAccepted, NewSuccessResponseWithDataVersion(map[string]interface{}{
+		"delivery": ToWebhookDeliveryResponse(delivery),
+	}))
+}
+
+func (h *WebhookHandler) webhookError(c echo.Context, err error) error {
+	ErrorResponse := NewErrorResponseBuilder()
+
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound),
+		errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse(err.Error()))
+	case errors.Is(err, domain.ErrInvalidWebhookURL),
+		errors.Is(err, domain.ErrInvalidWebhookEvents),
+		errors.Is(err, domain.ErrInvalidDeliveryStatus):
+		return c.JSON(http.StatusBadRequest, ErrorResponse(err.Error()))
+	default:
+		h.logger.Error("Operasi webhook gagal", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, ErrorResponse(ResponseErrorWebhook))
+	}
+}